@@ -0,0 +1,309 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import Header from '@/components/header'
+import Footer from '@/components/footer'
+import { LogOut, Save } from 'lucide-react'
+import { getCustomerToken, setCustomerToken, customerAuthHeaders, boardHeaders, setBoardToken } from '@/lib/customer-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface Customer {
+  id: number
+  email: string
+  name: string
+  company: string
+  phone: string
+  legal_name: string
+  tax_id: string
+  address: string
+  version: number
+}
+
+interface ContactRequest {
+  id: number
+  message: string
+  created_at: string
+}
+
+interface Quote {
+  id: number
+  number: string
+  items: { name: string; quantity: number; unit_price: number }[]
+  currency: string
+  total: number
+  status: string
+  valid_until: string | null
+  created_at: string
+}
+
+const quoteStatusLabels: Record<string, string> = {
+  sent: 'Отправлено',
+  accepted: 'Принято',
+  rejected: 'Отклонено',
+  expired: 'Истекло',
+}
+
+const inputClass = 'w-full px-4 py-3 border border-border rounded-lg bg-background text-foreground placeholder-muted-foreground focus:outline-none focus:ring-2 focus:ring-primary'
+
+const profileFields: { key: keyof Customer; label: string }[] = [
+  { key: 'name', label: 'Контактное лицо' },
+  { key: 'phone', label: 'Телефон' },
+  { key: 'company', label: 'Компания / отель' },
+  { key: 'legal_name', label: 'Юридическое название' },
+  { key: 'tax_id', label: 'ИНН' },
+  { key: 'address', label: 'Адрес' },
+]
+
+function AuthForms({ onLogin }: { onLogin: () => void }) {
+  const [mode, setMode] = useState<'login' | 'register'>('login')
+  const [form, setForm] = useState({ email: '', password: '', name: '', company: '' })
+  const [message, setMessage] = useState('')
+  const [error, setError] = useState('')
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setError('')
+    setMessage('')
+
+    const res = await fetch(`${API_URL}/account/${mode}`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(form),
+    })
+
+    if (mode === 'register') {
+      if (res.ok) {
+        setMessage('Мы отправили письмо для подтверждения адреса. Перейдите по ссылке из письма и войдите.')
+        setMode('login')
+      } else {
+        setError(await res.text())
+      }
+      return
+    }
+
+    if (res.ok) {
+      const data = await res.json()
+      setCustomerToken(data.token)
+      // Boards collected before logging in move to the account
+      const claim = await fetch(`${API_URL}/boards/claim`, { method: 'POST', headers: boardHeaders() })
+      if (claim.ok) {
+        setBoardToken(null)
+      }
+      onLogin()
+    } else if (res.status === 403) {
+      setError('Адрес электронной почты не подтверждён. Проверьте почту.')
+    } else if (res.status === 429) {
+      setError('Слишком много попыток входа. Попробуйте позже.')
+    } else {
+      setError('Неверный email или пароль')
+    }
+  }
+
+  return (
+    <form onSubmit={handleSubmit} className="space-y-6 max-w-md mx-auto">
+      <div className="flex gap-4 justify-center">
+        <button type="button" onClick={() => setMode('login')} className={mode === 'login' ? 'font-semibold text-primary' : 'text-muted-foreground'}>
+          Вход
+        </button>
+        <button type="button" onClick={() => setMode('register')} className={mode === 'register' ? 'font-semibold text-primary' : 'text-muted-foreground'}>
+          Регистрация
+        </button>
+      </div>
+      <input type="email" required placeholder="Email" value={form.email} onChange={(e) => setForm({ ...form, email: e.target.value })} className={inputClass} />
+      <input type="password" required placeholder="Пароль" value={form.password} onChange={(e) => setForm({ ...form, password: e.target.value })} className={inputClass} />
+      {mode === 'register' && (
+        <>
+          <input placeholder="Контактное лицо" value={form.name} onChange={(e) => setForm({ ...form, name: e.target.value })} className={inputClass} />
+          <input placeholder="Компания / отель" value={form.company} onChange={(e) => setForm({ ...form, company: e.target.value })} className={inputClass} />
+        </>
+      )}
+      {error && <p className="text-red-600 text-center">{error}</p>}
+      {message && <p className="text-green-700 text-center">{message}</p>}
+      <button type="submit" className="w-full px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition">
+        {mode === 'login' ? 'Войти' : 'Зарегистрироваться'}
+      </button>
+      {mode === 'login' && (
+        <p className="text-center">
+          <Link href="/account/reset-password" className="text-sm text-muted-foreground hover:text-foreground">
+            Забыли пароль?
+          </Link>
+        </p>
+      )}
+    </form>
+  )
+}
+
+export default function AccountPage() {
+  const [customer, setCustomer] = useState<Customer | null>(null)
+  const [requests, setRequests] = useState<ContactRequest[]>([])
+  const [quotes, setQuotes] = useState<Quote[]>([])
+  const [loading, setLoading] = useState(true)
+  const [saving, setSaving] = useState(false)
+
+  useEffect(() => {
+    fetchAccount()
+  }, [])
+
+  const fetchAccount = async () => {
+    if (!getCustomerToken()) {
+      setLoading(false)
+      return
+    }
+    try {
+      const res = await fetch(`${API_URL}/account`, { headers: customerAuthHeaders() })
+      if (res.status === 401) {
+        setCustomerToken(null)
+        return
+      }
+      setCustomer(await res.json())
+      const [requestsRes, quotesRes] = await Promise.all([
+        fetch(`${API_URL}/account/requests`, { headers: customerAuthHeaders() }),
+        fetch(`${API_URL}/account/quotes`, { headers: customerAuthHeaders() }),
+      ])
+      setRequests(await requestsRes.json())
+      setQuotes(await quotesRes.json())
+    } catch (error) {
+      console.error('Error fetching account:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleSave = async (e: React.FormEvent) => {
+    e.preventDefault()
+    if (!customer) return
+    setSaving(true)
+
+    const patch: Record<string, string> = {}
+    for (const { key } of profileFields) {
+      patch[key] = customer[key] as string
+    }
+
+    try {
+      const res = await fetch(`${API_URL}/account`, {
+        method: 'PATCH',
+        headers: {
+          'Content-Type': 'application/merge-patch+json',
+          'If-Match': `"${customer.version}"`,
+          ...customerAuthHeaders(),
+        },
+        body: JSON.stringify(patch),
+      })
+      if (res.ok) {
+        setCustomer(await res.json())
+      } else {
+        alert('Ошибка при сохранении профиля')
+      }
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  const handleLogout = async () => {
+    await fetch(`${API_URL}/account/logout`, { method: 'POST', headers: customerAuthHeaders() })
+    setCustomerToken(null)
+    setCustomer(null)
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <Header />
+
+      <main className="max-w-5xl mx-auto px-4 sm:px-6 lg:px-8 py-12 sm:py-16">
+        <h1 className="text-3xl sm:text-4xl font-serif font-bold text-foreground mb-8 text-center">Личный кабинет</h1>
+
+        {loading ? (
+          <p className="text-muted-foreground text-center">Загрузка...</p>
+        ) : !customer ? (
+          <AuthForms onLogin={() => { setLoading(true); fetchAccount() }} />
+        ) : (
+          <div className="space-y-12">
+            <section className="bg-card border border-border rounded-lg p-6 sm:p-8">
+              <div className="flex items-center justify-between mb-6">
+                <div>
+                  <h2 className="text-2xl font-serif font-bold text-foreground">Профиль компании</h2>
+                  <p className="text-muted-foreground">{customer.email}</p>
+                </div>
+                <button onClick={handleLogout} className="flex items-center gap-2 text-muted-foreground hover:text-foreground">
+                  <LogOut className="w-4 h-4" />
+                  Выйти
+                </button>
+              </div>
+              <form onSubmit={handleSave} className="grid md:grid-cols-2 gap-6">
+                {profileFields.map(({ key, label }) => (
+                  <div key={key} className={key === 'address' ? 'md:col-span-2' : ''}>
+                    <label className="block text-sm font-medium text-foreground mb-2">{label}</label>
+                    <input
+                      value={customer[key] as string}
+                      onChange={(e) => setCustomer({ ...customer, [key]: e.target.value })}
+                      className={inputClass}
+                    />
+                  </div>
+                ))}
+                <button
+                  type="submit"
+                  disabled={saving}
+                  className="md:col-span-2 flex items-center justify-center gap-2 px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition disabled:opacity-50"
+                >
+                  <Save className="w-5 h-5" />
+                  {saving ? 'Сохранение...' : 'Сохранить'}
+                </button>
+              </form>
+            </section>
+
+            <section>
+              <h2 className="text-2xl font-serif font-bold text-foreground mb-4">Коммерческие предложения</h2>
+              {quotes.length === 0 ? (
+                <p className="text-muted-foreground">Предложений пока нет</p>
+              ) : (
+                <div className="space-y-4">
+                  {quotes.map((quote) => (
+                    <div key={quote.id} className="bg-card border border-border rounded-lg p-6">
+                      <div className="flex justify-between mb-2">
+                        <p className="font-semibold text-foreground">{quote.number}</p>
+                        <p className="text-muted-foreground">{quoteStatusLabels[quote.status] || quote.status}</p>
+                      </div>
+                      <ul className="text-sm text-muted-foreground mb-2">
+                        {quote.items.map((item, idx) => (
+                          <li key={idx}>{item.name} × {item.quantity}</li>
+                        ))}
+                      </ul>
+                      <p className="font-semibold text-foreground">
+                        {quote.total.toLocaleString('ru-RU')} {quote.currency}
+                        {quote.valid_until && (
+                          <span className="text-sm font-normal text-muted-foreground"> · действительно до {new Date(quote.valid_until).toLocaleDateString('ru-RU')}</span>
+                        )}
+                      </p>
+                    </div>
+                  ))}
+                </div>
+              )}
+            </section>
+
+            <section>
+              <h2 className="text-2xl font-serif font-bold text-foreground mb-4">Мои запросы</h2>
+              {requests.length === 0 ? (
+                <p className="text-muted-foreground">Запросов пока нет</p>
+              ) : (
+                <div className="space-y-4">
+                  {requests.map((request) => (
+                    <div key={request.id} className="bg-card border border-border rounded-lg p-6">
+                      <p className="text-sm text-muted-foreground mb-2">{new Date(request.created_at).toLocaleString('ru-RU')}</p>
+                      <p className="text-foreground whitespace-pre-line">{request.message}</p>
+                    </div>
+                  ))}
+                </div>
+              )}
+            </section>
+          </div>
+        )}
+      </main>
+
+      <Footer />
+    </div>
+  )
+}
@@ -0,0 +1,91 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import Header from '@/components/header'
+import Footer from '@/components/footer'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+const inputClass = 'w-full px-4 py-3 border border-border rounded-lg bg-background text-foreground placeholder-muted-foreground focus:outline-none focus:ring-2 focus:ring-primary'
+
+export default function ResetPasswordPage() {
+  const [token, setToken] = useState<string | null>(null)
+  const [email, setEmail] = useState('')
+  const [password, setPassword] = useState('')
+  const [message, setMessage] = useState('')
+  const [error, setError] = useState('')
+  const [done, setDone] = useState(false)
+
+  useEffect(() => {
+    setToken(new URLSearchParams(window.location.search).get('token'))
+  }, [])
+
+  const handleRequest = async (e: React.FormEvent) => {
+    e.preventDefault()
+    const res = await fetch(`${API_URL}/account/password-reset`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ email }),
+    })
+    setMessage(res.status === 429
+      ? 'Слишком много запросов. Попробуйте позже.'
+      : 'Если этот адрес зарегистрирован, мы отправили на него ссылку для сброса пароля.')
+  }
+
+  const handleConfirm = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setError('')
+    const res = await fetch(`${API_URL}/account/password-reset/confirm`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ token, password }),
+    })
+    if (res.ok) {
+      setDone(true)
+    } else {
+      setError(res.status === 400 ? 'Ссылка недействительна или устарела' : await res.text())
+    }
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <Header />
+
+      <main className="max-w-md mx-auto px-4 sm:px-6 lg:px-8 py-16 space-y-6">
+        <h1 className="text-3xl font-serif font-bold text-foreground text-center">Сброс пароля</h1>
+
+        {done ? (
+          <div className="text-center space-y-6">
+            <p className="text-foreground">Пароль изменён.</p>
+            <Link href="/account" className="inline-block px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition">
+              Войти
+            </Link>
+          </div>
+        ) : token ? (
+          <form onSubmit={handleConfirm} className="space-y-6">
+            <input type="password" required minLength={8} placeholder="Новый пароль" value={password} onChange={(e) => setPassword(e.target.value)} className={inputClass} />
+            {error && <p className="text-red-600 text-center">{error}</p>}
+            <button type="submit" className="w-full px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition">
+              Сохранить пароль
+            </button>
+          </form>
+        ) : (
+          <form onSubmit={handleRequest} className="space-y-6">
+            <input type="email" required placeholder="Email" value={email} onChange={(e) => setEmail(e.target.value)} className={inputClass} />
+            {message && <p className="text-green-700 text-center">{message}</p>}
+            <button type="submit" className="w-full px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition">
+              Отправить ссылку
+            </button>
+          </form>
+        )}
+      </main>
+
+      <Footer />
+    </div>
+  )
+}
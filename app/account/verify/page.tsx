@@ -0,0 +1,54 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import Header from '@/components/header'
+import Footer from '@/components/footer'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export default function VerifyEmailPage() {
+  const [status, setStatus] = useState<'pending' | 'verified' | 'failed'>('pending')
+
+  useEffect(() => {
+    const token = new URLSearchParams(window.location.search).get('token')
+    if (!token) {
+      setStatus('failed')
+      return
+    }
+
+    fetch(`${API_URL}/account/verify`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ token }),
+    })
+      .then(res => setStatus(res.ok ? 'verified' : 'failed'))
+      .catch(() => setStatus('failed'))
+  }, [])
+
+  return (
+    <div className="min-h-screen bg-background">
+      <Header />
+
+      <main className="max-w-xl mx-auto px-4 sm:px-6 lg:px-8 py-16 text-center space-y-6">
+        <h1 className="text-3xl font-serif font-bold text-foreground">Подтверждение email</h1>
+        {status === 'pending' && <p className="text-muted-foreground">Проверяем ссылку...</p>}
+        {status === 'verified' && (
+          <>
+            <p className="text-foreground">Адрес подтверждён. Теперь вы можете войти в личный кабинет.</p>
+            <Link href="/account" className="inline-block px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition">
+              Войти
+            </Link>
+          </>
+        )}
+        {status === 'failed' && (
+          <p className="text-muted-foreground">Ссылка недействительна или устарела. Войдите, чтобы получить новое письмо.</p>
+        )}
+      </main>
+
+      <Footer />
+    </div>
+  )
+}
@@ -0,0 +1,161 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface ProductReport {
+  product_id: number
+  name: string
+  views: number
+  sessions: number
+  gallery_interactions: number
+  contact_opens: number
+}
+
+interface SearchReport {
+  query: string
+  searches: number
+  zero_results: number
+}
+
+interface Funnel {
+  sessions: number
+  viewed_product: number
+  opened_contact: number
+  submitted_contact: number
+  view_to_contact_rate: number
+}
+
+const daysAgo = (days: number) => new Date(Date.now() - days * 86400000).toISOString().slice(0, 10)
+
+export default function AnalyticsPage() {
+  const [from, setFrom] = useState(daysAgo(29))
+  const [to, setTo] = useState(daysAgo(0))
+  const [products, setProducts] = useState<ProductReport[]>([])
+  const [searches, setSearches] = useState<SearchReport[]>([])
+  const [funnel, setFunnel] = useState<Funnel | null>(null)
+  const [loading, setLoading] = useState(true)
+
+  useEffect(() => {
+    fetchReports()
+  }, [from, to])
+
+  const fetchReports = async () => {
+    setLoading(true)
+    const range = `from=${from}&to=${to}`
+    try {
+      const [productsRes, searchesRes, funnelRes] = await Promise.all([
+        adminFetch(`${API_URL}/admin/analytics/top-products?${range}`),
+        adminFetch(`${API_URL}/admin/analytics/zero-result-searches?${range}`),
+        adminFetch(`${API_URL}/admin/analytics/funnel?${range}`),
+      ])
+      setProducts(await productsRes.json())
+      setSearches(await searchesRes.json())
+      setFunnel(await funnelRes.json())
+    } catch (error) {
+      console.error('Error fetching analytics:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const funnelSteps = funnel ? [
+    { label: 'Сессии', value: funnel.sessions },
+    { label: 'Смотрели товар', value: funnel.viewed_product },
+    { label: 'Открыли форму связи', value: funnel.opened_contact },
+    { label: 'Отправили запрос', value: funnel.submitted_contact },
+  ] : []
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="mb-8 flex flex-wrap items-end justify-between gap-4">
+          <div>
+            <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Аналитика</h1>
+            <p className="text-muted-foreground">Данные обновляются раз в час</p>
+          </div>
+          <div className="flex gap-2 items-center">
+            <input type="date" value={from} onChange={(e) => setFrom(e.target.value)} className="px-4 py-2 border border-border rounded-lg bg-background text-foreground" />
+            <span className="text-muted-foreground">—</span>
+            <input type="date" value={to} onChange={(e) => setTo(e.target.value)} className="px-4 py-2 border border-border rounded-lg bg-background text-foreground" />
+          </div>
+        </div>
+
+        {loading ? (
+          <p className="text-muted-foreground">Загрузка...</p>
+        ) : (
+          <div className="space-y-8">
+            {funnel && (
+              <div className="bg-card border border-border rounded-lg p-6">
+                <h2 className="text-xl font-semibold text-foreground mb-4">Воронка: просмотр → запрос</h2>
+                <div className="grid sm:grid-cols-4 gap-4">
+                  {funnelSteps.map((step) => (
+                    <div key={step.label}>
+                      <p className="text-3xl font-bold text-primary">{step.value}</p>
+                      <p className="text-sm text-muted-foreground">{step.label}</p>
+                    </div>
+                  ))}
+                </div>
+                <p className="text-sm text-muted-foreground mt-4">
+                  Конверсия из просмотра в запрос: {(funnel.view_to_contact_rate * 100).toFixed(1)}%
+                </p>
+              </div>
+            )}
+
+            <div className="bg-card border border-border rounded-lg overflow-hidden">
+              <h2 className="text-xl font-semibold text-foreground p-6 pb-4">Популярные товары</h2>
+              <table className="w-full">
+                <thead className="bg-muted">
+                  <tr>
+                    <th className="px-6 py-3 text-left text-sm font-semibold text-foreground">Товар</th>
+                    <th className="px-6 py-3 text-left text-sm font-semibold text-foreground">Просмотры</th>
+                    <th className="px-6 py-3 text-left text-sm font-semibold text-foreground">Сессии</th>
+                    <th className="px-6 py-3 text-left text-sm font-semibold text-foreground">Галерея</th>
+                    <th className="px-6 py-3 text-left text-sm font-semibold text-foreground">Запросы</th>
+                  </tr>
+                </thead>
+                <tbody>
+                  {products.map((p) => (
+                    <tr key={p.product_id} className="border-t border-border">
+                      <td className="px-6 py-4 text-sm text-foreground">{p.name || `Товар #${p.product_id}`}</td>
+                      <td className="px-6 py-4 text-sm text-foreground">{p.views}</td>
+                      <td className="px-6 py-4 text-sm text-foreground">{p.sessions}</td>
+                      <td className="px-6 py-4 text-sm text-foreground">{p.gallery_interactions}</td>
+                      <td className="px-6 py-4 text-sm text-foreground">{p.contact_opens}</td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+              {products.length === 0 && <p className="text-muted-foreground p-6">Нет данных за период</p>}
+            </div>
+
+            <div className="bg-card border border-border rounded-lg p-6">
+              <h2 className="text-xl font-semibold text-foreground mb-4">Поиск без результатов</h2>
+              {searches.length === 0 ? (
+                <p className="text-muted-foreground">Нет данных за период</p>
+              ) : (
+                <ul className="space-y-2">
+                  {searches.map((s) => (
+                    <li key={s.query} className="flex justify-between text-sm">
+                      <span className="text-foreground">«{s.query}»</span>
+                      <span className="text-muted-foreground">{s.zero_results} из {s.searches}</span>
+                    </li>
+                  ))}
+                </ul>
+              )}
+            </div>
+          </div>
+        )}
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,306 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Plus, Trash2, KeyRound } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface PriceList {
+  id: number
+  name: string
+  description: string
+  adjustment_percent: number
+  items: { product_id: number; price?: number; adjustment_percent?: number }[]
+  version: number
+}
+
+interface Customer {
+  id: number
+  email: string
+  name: string
+  company: string
+  price_list_id: number | null
+  version: number
+}
+
+export default function B2BPage() {
+  const [priceLists, setPriceLists] = useState<PriceList[]>([])
+  const [customers, setCustomers] = useState<Customer[]>([])
+  const [loading, setLoading] = useState(true)
+  const [newList, setNewList] = useState({ name: '', adjustment_percent: '' })
+  const [newCustomer, setNewCustomer] = useState({ email: '', name: '', company: '', price_list_id: '' })
+  const [issuedKey, setIssuedKey] = useState<{ email: string; key: string } | null>(null)
+
+  useEffect(() => {
+    fetchAll()
+  }, [])
+
+  const fetchAll = async () => {
+    try {
+      const [listsRes, customersRes] = await Promise.all([
+        adminFetch(`${API_URL}/admin/price-lists`),
+        adminFetch(`${API_URL}/admin/customers`),
+      ])
+      setPriceLists(await listsRes.json())
+      setCustomers(await customersRes.json())
+    } catch (error) {
+      console.error('Error fetching B2B data:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleCreateList = async (e: React.FormEvent) => {
+    e.preventDefault()
+    const res = await adminFetch(`${API_URL}/admin/price-lists`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({
+        name: newList.name,
+        adjustment_percent: parseFloat(newList.adjustment_percent || '0'),
+      }),
+    })
+    if (res.ok) {
+      setNewList({ name: '', adjustment_percent: '' })
+      fetchAll()
+    } else {
+      alert(`Ошибка при создании прайс-листа: ${await res.text()}`)
+    }
+  }
+
+  const handleDeleteList = async (list: PriceList) => {
+    if (!confirm(`Удалить прайс-лист «${list.name}»? Клиенты с ним будут видеть розничные цены.`)) return
+    const res = await adminFetch(`${API_URL}/admin/price-lists/${list.id}`, {
+      method: 'DELETE',
+      headers: {
+        'If-Match': `"${list.version}"`,
+      },
+    })
+    if (res.status === 412) {
+      alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+      return
+    }
+    fetchAll()
+  }
+
+  const handleCreateCustomer = async (e: React.FormEvent) => {
+    e.preventDefault()
+    const res = await adminFetch(`${API_URL}/admin/customers`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({
+        email: newCustomer.email,
+        name: newCustomer.name,
+        company: newCustomer.company,
+        price_list_id: newCustomer.price_list_id ? parseInt(newCustomer.price_list_id) : null,
+      }),
+    })
+    if (res.ok) {
+      const created = await res.json()
+      setIssuedKey({ email: created.email, key: created.api_key })
+      setNewCustomer({ email: '', name: '', company: '', price_list_id: '' })
+      fetchAll()
+    } else {
+      alert(`Ошибка при создании клиента: ${await res.text()}`)
+    }
+  }
+
+  const handleAssign = async (customer: Customer, priceListId: string) => {
+    const res = await adminFetch(`${API_URL}/admin/customers/${customer.id}`, {
+      method: 'PATCH',
+      headers: {
+        'Content-Type': 'application/merge-patch+json',
+        'If-Match': `"${customer.version}"`,
+      },
+      body: JSON.stringify({ price_list_id: priceListId ? parseInt(priceListId) : null }),
+    })
+    if (res.status === 412) {
+      alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+      return
+    }
+    fetchAll()
+  }
+
+  const handleRotateKey = async (customer: Customer) => {
+    if (!confirm(`Выпустить новый API-ключ для ${customer.email}? Старый перестанет работать.`)) return
+    const res = await adminFetch(`${API_URL}/admin/customers/${customer.id}/api-key`, { method: 'POST' })
+    if (res.ok) {
+      const data = await res.json()
+      setIssuedKey({ email: customer.email, key: data.api_key })
+    }
+  }
+
+  const handleDeleteCustomer = async (customer: Customer) => {
+    if (!confirm(`Удалить клиента ${customer.email}?`)) return
+    const res = await adminFetch(`${API_URL}/admin/customers/${customer.id}`, {
+      method: 'DELETE',
+      headers: {
+        'If-Match': `"${customer.version}"`,
+      },
+    })
+    if (res.status === 412) {
+      alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+      return
+    }
+    fetchAll()
+  }
+
+  const inputClass = 'px-4 py-2 border border-border rounded-lg bg-background text-foreground'
+
+  if (loading) {
+    return (
+      <div className="min-h-screen bg-background">
+        <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+          <p className="text-muted-foreground">Загрузка...</p>
+        </div>
+      </div>
+    )
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <h1 className="text-4xl font-serif font-bold text-foreground mb-8">Прайс-листы и B2B-клиенты</h1>
+
+        {issuedKey && (
+          <div className="bg-card border border-primary rounded-lg p-6 mb-8">
+            <p className="font-semibold text-foreground mb-2">API-ключ для {issuedKey.email}</p>
+            <p className="text-sm text-muted-foreground mb-2">Ключ показывается один раз. Передайте его клиенту.</p>
+            <code className="block p-3 bg-muted rounded break-all">{issuedKey.key}</code>
+          </div>
+        )}
+
+        <section className="mb-12">
+          <h2 className="text-2xl font-semibold text-foreground mb-4">Прайс-листы</h2>
+          <form onSubmit={handleCreateList} className="flex gap-4 mb-6">
+            <input
+              required
+              placeholder="Название"
+              value={newList.name}
+              onChange={(e) => setNewList({ ...newList, name: e.target.value })}
+              className={`flex-1 ${inputClass}`}
+            />
+            <input
+              type="number"
+              step="0.01"
+              placeholder="Скидка/наценка, %"
+              value={newList.adjustment_percent}
+              onChange={(e) => setNewList({ ...newList, adjustment_percent: e.target.value })}
+              className={`w-48 ${inputClass}`}
+            />
+            <button type="submit" className="flex items-center gap-2 px-4 py-2 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition">
+              <Plus className="w-4 h-4" />
+              Добавить
+            </button>
+          </form>
+          <div className="space-y-4">
+            {priceLists.map((list) => (
+              <div key={list.id} className="bg-card border border-border rounded-lg p-6 flex items-center justify-between gap-4">
+                <div>
+                  <p className="font-semibold text-foreground">{list.name}</p>
+                  <p className="text-sm text-muted-foreground">
+                    {list.adjustment_percent > 0 ? '+' : ''}{list.adjustment_percent}% к рознице · индивидуальных цен: {list.items.length}
+                  </p>
+                </div>
+                <button
+                  onClick={() => handleDeleteList(list)}
+                  className="p-2 text-red-500 hover:bg-red-50 dark:hover:bg-red-950 rounded-lg transition"
+                  title="Удалить"
+                >
+                  <Trash2 className="w-5 h-5" />
+                </button>
+              </div>
+            ))}
+          </div>
+        </section>
+
+        <section>
+          <h2 className="text-2xl font-semibold text-foreground mb-4">Клиенты</h2>
+          <form onSubmit={handleCreateCustomer} className="grid md:grid-cols-5 gap-4 mb-6">
+            <input
+              required
+              type="email"
+              placeholder="Email"
+              value={newCustomer.email}
+              onChange={(e) => setNewCustomer({ ...newCustomer, email: e.target.value })}
+              className={inputClass}
+            />
+            <input
+              placeholder="Контактное лицо"
+              value={newCustomer.name}
+              onChange={(e) => setNewCustomer({ ...newCustomer, name: e.target.value })}
+              className={inputClass}
+            />
+            <input
+              placeholder="Компания"
+              value={newCustomer.company}
+              onChange={(e) => setNewCustomer({ ...newCustomer, company: e.target.value })}
+              className={inputClass}
+            />
+            <select
+              value={newCustomer.price_list_id}
+              onChange={(e) => setNewCustomer({ ...newCustomer, price_list_id: e.target.value })}
+              className={inputClass}
+            >
+              <option value="">Розница</option>
+              {priceLists.map((list) => (
+                <option key={list.id} value={list.id}>{list.name}</option>
+              ))}
+            </select>
+            <button type="submit" className="flex items-center justify-center gap-2 px-4 py-2 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition">
+              <Plus className="w-4 h-4" />
+              Добавить
+            </button>
+          </form>
+          <div className="space-y-4">
+            {customers.map((customer) => (
+              <div key={customer.id} className="bg-card border border-border rounded-lg p-6 flex items-center justify-between gap-4">
+                <div>
+                  <p className="font-semibold text-foreground">{customer.company || customer.name || customer.email}</p>
+                  <p className="text-sm text-muted-foreground">{customer.email}</p>
+                </div>
+                <div className="flex items-center gap-2">
+                  <select
+                    value={customer.price_list_id ?? ''}
+                    onChange={(e) => handleAssign(customer, e.target.value)}
+                    className={inputClass}
+                  >
+                    <option value="">Розница</option>
+                    {priceLists.map((list) => (
+                      <option key={list.id} value={list.id}>{list.name}</option>
+                    ))}
+                  </select>
+                  <button
+                    onClick={() => handleRotateKey(customer)}
+                    className="p-2 text-primary hover:bg-primary/10 rounded-lg transition"
+                    title="Новый API-ключ"
+                  >
+                    <KeyRound className="w-5 h-5" />
+                  </button>
+                  <button
+                    onClick={() => handleDeleteCustomer(customer)}
+                    className="p-2 text-red-500 hover:bg-red-50 dark:hover:bg-red-950 rounded-lg transition"
+                    title="Удалить"
+                  >
+                    <Trash2 className="w-5 h-5" />
+                  </button>
+                </div>
+              </div>
+            ))}
+          </div>
+        </section>
+      </div>
+    </div>
+  )
+}
@@ -4,6 +4,8 @@ import { useState, useEffect } from 'react'
 import { useRouter, useParams } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Save } from 'lucide-react'
+import { SEOFields, SEO, defaultSEO, seoFromEntity } from '@/components/seo-fields'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -12,8 +14,10 @@ export default function EditCategoryPage() {
   const params = useParams()
   const [loading, setLoading] = useState(true)
   const [saving, setSaving] = useState(false)
+  const [version, setVersion] = useState(0)
   const [uploading, setUploading] = useState(false)
   const [imagePreview, setImagePreview] = useState<string>('')
+  const [seo, setSeo] = useState<SEO>(defaultSEO)
   const [formData, setFormData] = useState({
     name: '',
     description: '',
@@ -28,13 +32,15 @@ export default function EditCategoryPage() {
 
   const fetchCategory = async () => {
     try {
-      const res = await fetch(`${API_URL}/categories`)
+      const res = await adminFetch(`${API_URL}/admin/categories`)
       const categories = await res.json()
       const category = categories.find((c: any) => c.id === parseInt(params.id as string))
       if (category) {
         const isDocker = API_URL.includes('backend:')
         const backendUrl = isDocker ? 'http://localhost:8080' : API_URL.replace('/api', '')
         const imagePath = category.image || ''
+        setVersion(category.version)
+        setSeo(seoFromEntity(category))
         setFormData({
           name: category.name,
           description: category.description,
@@ -78,7 +84,7 @@ export default function EditCategoryPage() {
       const formDataUpload = new FormData()
       formDataUpload.append('image', file)
 
-      const res = await fetch(`${API_URL}/admin/upload`, {
+      const res = await adminFetch(`${API_URL}/admin/upload`, {
         method: 'POST',
         body: formDataUpload,
       })
@@ -109,12 +115,20 @@ export default function EditCategoryPage() {
     setSaving(true)
 
     try {
-      const res = await fetch(`${API_URL}/admin/categories/${params.id}`, {
+      const res = await adminFetch(`${API_URL}/admin/categories/${params.id}`, {
         method: 'PUT',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify(formData),
+        headers: {
+          'Content-Type': 'application/json',
+          'If-Match': `"${version}"`,
+        },
+        body: JSON.stringify({ ...formData, ...seo }),
       })
 
+      if (res.status === 412) {
+        alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+        return
+      }
+
       if (res.ok) {
         router.push('/admin/categories')
       } else {
@@ -204,6 +218,8 @@ export default function EditCategoryPage() {
             )}
           </div>
 
+          <SEOFields value={seo} onChange={setSeo} />
+
           <div className="flex gap-4 pt-6">
             <button
               type="submit"
@@ -4,6 +4,8 @@ import { useState } from 'react'
 import { useRouter } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Save } from 'lucide-react'
+import { SEOFields, SEO, defaultSEO } from '@/components/seo-fields'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -12,6 +14,7 @@ export default function NewCategoryPage() {
   const [loading, setLoading] = useState(false)
   const [uploading, setUploading] = useState(false)
   const [imagePreview, setImagePreview] = useState<string>('')
+  const [seo, setSeo] = useState<SEO>(defaultSEO)
   const [formData, setFormData] = useState({
     name: '',
     description: '',
@@ -40,7 +43,7 @@ export default function NewCategoryPage() {
       const formDataUpload = new FormData()
       formDataUpload.append('image', file)
 
-      const res = await fetch(`${API_URL}/admin/upload`, {
+      const res = await adminFetch(`${API_URL}/admin/upload`, {
         method: 'POST',
         body: formDataUpload,
       })
@@ -71,10 +74,10 @@ export default function NewCategoryPage() {
     setLoading(true)
 
     try {
-      const res = await fetch(`${API_URL}/admin/categories`, {
+      const res = await adminFetch(`${API_URL}/admin/categories`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify(formData),
+        body: JSON.stringify({ ...formData, ...seo }),
       })
 
       if (res.ok) {
@@ -165,6 +168,8 @@ export default function NewCategoryPage() {
             )}
           </div>
 
+          <SEOFields value={seo} onChange={setSeo} />
+
           <div className="flex gap-4 pt-6">
             <button
               type="submit"
@@ -3,11 +3,13 @@
 import { useState, useEffect } from 'react'
 import Link from 'next/link'
 import { Plus, Edit, Trash2, ArrowLeft } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
 interface Category {
   id: number
+  version: number
   name: string
   description: string
   icon: string
@@ -25,7 +27,7 @@ export default function CategoriesPage() {
 
   const fetchCategories = async () => {
     try {
-      const res = await fetch(`${API_URL}/categories`)
+      const res = await adminFetch(`${API_URL}/admin/categories`)
       const data = await res.json()
       setCategories(data)
     } catch (error) {
@@ -35,12 +37,13 @@ export default function CategoriesPage() {
     }
   }
 
-  const handleDelete = async (id: number) => {
+  const handleDelete = async (id: number, version: number) => {
     if (!confirm('Вы уверены, что хотите удалить эту категорию?')) return
 
     try {
-      const res = await fetch(`${API_URL}/admin/categories/${id}`, {
+      const res = await adminFetch(`${API_URL}/admin/categories/${id}`, {
         method: 'DELETE',
+        headers: { 'If-Match': `"${version}"` },
       })
       if (res.ok) {
         setCategories(categories.filter(c => c.id !== id))
@@ -95,7 +98,7 @@ export default function CategoriesPage() {
                     <Edit className="w-4 h-4" />
                   </Link>
                   <button
-                    onClick={() => handleDelete(category.id)}
+                    onClick={() => handleDelete(category.id, category.version)}
                     className="p-2 text-destructive hover:bg-destructive/10 rounded transition"
                   >
                     <Trash2 className="w-4 h-4" />
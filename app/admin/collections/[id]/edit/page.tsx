@@ -4,6 +4,10 @@ import { useState, useEffect } from 'react'
 import { useRouter, useParams } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Save } from 'lucide-react'
+import { PublicationFields, Publication, defaultPublication, publicationFromEntity, publicationPayload } from '@/components/publication-fields'
+import { SEOFields, SEO, defaultSEO, seoFromEntity } from '@/components/seo-fields'
+import { PreviewButton } from '@/components/preview-button'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -20,10 +24,13 @@ export default function EditCollectionPage() {
   const params = useParams()
   const [loading, setLoading] = useState(true)
   const [saving, setSaving] = useState(false)
+  const [version, setVersion] = useState(0)
   const [uploading, setUploading] = useState(false)
   const [imagePreview, setImagePreview] = useState<string>('')
   const [allProducts, setAllProducts] = useState<Product[]>([])
   const [collectionProducts, setCollectionProducts] = useState<number[]>([])
+  const [publication, setPublication] = useState<Publication>(defaultPublication)
+  const [seo, setSeo] = useState<SEO>(defaultSEO)
   const [formData, setFormData] = useState({
     name: '',
     description: '',
@@ -51,7 +58,7 @@ export default function EditCollectionPage() {
       const formDataUpload = new FormData()
       formDataUpload.append('image', file)
 
-      const res = await fetch(`${API_URL}/admin/upload`, {
+      const res = await adminFetch(`${API_URL}/admin/upload`, {
         method: 'POST',
         body: formDataUpload,
       })
@@ -83,7 +90,7 @@ export default function EditCollectionPage() {
 
   const fetchAllProducts = async () => {
     try {
-      const res = await fetch(`${API_URL}/products`)
+      const res = await adminFetch(`${API_URL}/admin/products`)
       if (res.ok) {
         const data = await res.json()
         setAllProducts(data)
@@ -95,8 +102,11 @@ export default function EditCollectionPage() {
 
   const fetchCollection = async () => {
     try {
-      const res = await fetch(`${API_URL}/collections/${params.id}`)
+      const res = await adminFetch(`${API_URL}/admin/collections/${params.id}`)
       const collection = await res.json()
+      setVersion(collection.version)
+      setPublication(publicationFromEntity(collection))
+      setSeo(seoFromEntity(collection))
       setFormData({
         name: collection.name,
         description: collection.description,
@@ -135,14 +145,14 @@ export default function EditCollectionPage() {
   const saveProducts = async () => {
     try {
       // Get current products
-      const currentRes = await fetch(`${API_URL}/collections/${params.id}`)
+      const currentRes = await adminFetch(`${API_URL}/admin/collections/${params.id}`)
       const currentCollection = await currentRes.json()
       const currentProductIds = currentCollection.products ? currentCollection.products.map((p: Product) => p.id) : []
 
       // Remove products that are no longer selected
       for (const productId of currentProductIds) {
         if (!collectionProducts.includes(productId)) {
-          await fetch(`${API_URL}/admin/collections/${params.id}/products/${productId}`, {
+          await adminFetch(`${API_URL}/admin/collections/${params.id}/products/${productId}`, {
             method: 'DELETE',
           })
         }
@@ -151,7 +161,7 @@ export default function EditCollectionPage() {
       // Add new products
       for (const productId of collectionProducts) {
         if (!currentProductIds.includes(productId)) {
-          await fetch(`${API_URL}/admin/collections/${params.id}/products`, {
+          await adminFetch(`${API_URL}/admin/collections/${params.id}/products`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ product_id: productId }),
@@ -175,14 +185,24 @@ export default function EditCollectionPage() {
         name: formData.name,
         description: formData.description,
         image: formData.image,
+        ...publicationPayload(publication),
+        ...seo,
       }
 
-      const res = await fetch(`${API_URL}/admin/collections/${params.id}`, {
+      const res = await adminFetch(`${API_URL}/admin/collections/${params.id}`, {
         method: 'PUT',
-        headers: { 'Content-Type': 'application/json' },
+        headers: {
+          'Content-Type': 'application/json',
+          'If-Match': `"${version}"`,
+        },
         body: JSON.stringify(collection),
       })
 
+      if (res.status === 412) {
+        alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+        return
+      }
+
       if (res.ok) {
         router.push('/admin/collections')
       } else {
@@ -212,7 +232,10 @@ export default function EditCollectionPage() {
           Назад к коллекциям
         </Link>
 
-        <h1 className="text-4xl font-serif font-bold text-foreground mb-8">Редактировать коллекцию</h1>
+        <div className="flex flex-wrap items-center justify-between gap-4 mb-8">
+          <h1 className="text-4xl font-serif font-bold text-foreground">Редактировать коллекцию</h1>
+          <PreviewButton path={`/collections/${params.id}`} />
+        </div>
 
         <form onSubmit={handleSubmit} className="bg-card border border-border rounded-lg p-8 space-y-6">
           <div>
@@ -293,6 +316,10 @@ export default function EditCollectionPage() {
             </button>
           </div>
 
+          <PublicationFields value={publication} onChange={setPublication} />
+
+          <SEOFields value={seo} onChange={setSeo} />
+
           <div className="flex gap-4 pt-6">
             <button
               type="submit"
@@ -4,6 +4,9 @@ import { useState, useEffect } from 'react'
 import { useRouter } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Save } from 'lucide-react'
+import { PublicationFields, Publication, defaultPublication, publicationPayload } from '@/components/publication-fields'
+import { SEOFields, SEO, defaultSEO } from '@/components/seo-fields'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -22,6 +25,8 @@ export default function NewCollectionPage() {
   const [imagePreview, setImagePreview] = useState<string>('')
   const [allProducts, setAllProducts] = useState<Product[]>([])
   const [selectedProducts, setSelectedProducts] = useState<number[]>([])
+  const [publication, setPublication] = useState<Publication>(defaultPublication)
+  const [seo, setSeo] = useState<SEO>(defaultSEO)
   const [formData, setFormData] = useState({
     name: '',
     description: '',
@@ -34,7 +39,7 @@ export default function NewCollectionPage() {
 
   const fetchAllProducts = async () => {
     try {
-      const res = await fetch(`${API_URL}/products`)
+      const res = await adminFetch(`${API_URL}/admin/products`)
       if (res.ok) {
         const data = await res.json()
         setAllProducts(data)
@@ -74,7 +79,7 @@ export default function NewCollectionPage() {
       const formDataUpload = new FormData()
       formDataUpload.append('image', file)
 
-      const res = await fetch(`${API_URL}/admin/upload`, {
+      const res = await adminFetch(`${API_URL}/admin/upload`, {
         method: 'POST',
         body: formDataUpload,
       })
@@ -108,9 +113,11 @@ export default function NewCollectionPage() {
         name: formData.name,
         description: formData.description,
         image: formData.image,
+        ...publicationPayload(publication),
+        ...seo,
       }
 
-      const res = await fetch(`${API_URL}/admin/collections`, {
+      const res = await adminFetch(`${API_URL}/admin/collections`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify(collection),
@@ -121,7 +128,7 @@ export default function NewCollectionPage() {
         // Add products to collection
         if (selectedProducts.length > 0) {
           for (const productId of selectedProducts) {
-            await fetch(`${API_URL}/admin/collections/${newCollection.id}/products`, {
+            await adminFetch(`${API_URL}/admin/collections/${newCollection.id}/products`, {
               method: 'POST',
               headers: { 'Content-Type': 'application/json' },
               body: JSON.stringify({ product_id: productId }),
@@ -222,6 +229,10 @@ export default function NewCollectionPage() {
             </div>
           </div>
 
+          <PublicationFields value={publication} onChange={setPublication} />
+
+          <SEOFields value={seo} onChange={setSeo} />
+
           <div className="flex gap-4 pt-6">
             <button
               type="submit"
@@ -3,11 +3,13 @@
 import { useState, useEffect } from 'react'
 import Link from 'next/link'
 import { Plus, Edit, Trash2, ArrowLeft } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
 interface Collection {
   id: number
+  version: number
   name: string
   description: string
   image: string
@@ -24,7 +26,7 @@ export default function CollectionsPage() {
 
   const fetchCollections = async () => {
     try {
-      const res = await fetch(`${API_URL}/collections`)
+      const res = await adminFetch(`${API_URL}/admin/collections`)
       const data = await res.json()
       setCollections(data)
     } catch (error) {
@@ -34,12 +36,13 @@ export default function CollectionsPage() {
     }
   }
 
-  const handleDelete = async (id: number) => {
+  const handleDelete = async (id: number, version: number) => {
     if (!confirm('Вы уверены, что хотите удалить эту коллекцию?')) return
 
     try {
-      const res = await fetch(`${API_URL}/admin/collections/${id}`, {
+      const res = await adminFetch(`${API_URL}/admin/collections/${id}`, {
         method: 'DELETE',
+        headers: { 'If-Match': `"${version}"` },
       })
       if (res.ok) {
         setCollections(collections.filter(c => c.id !== id))
@@ -102,7 +105,7 @@ export default function CollectionsPage() {
                       <Edit className="w-4 h-4" />
                     </Link>
                     <button
-                      onClick={() => handleDelete(collection.id)}
+                      onClick={() => handleDelete(collection.id, collection.version)}
                       className="p-2 text-destructive hover:bg-destructive/10 rounded transition"
                     >
                       <Trash2 className="w-4 h-4" />
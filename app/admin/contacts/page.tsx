@@ -2,7 +2,8 @@
 
 import { useState, useEffect } from 'react'
 import Link from 'next/link'
-import { ArrowLeft, Trash2, Mail, Phone, User, MessageSquare } from 'lucide-react'
+import { ArrowLeft, Trash2, Mail, Phone, User, MessageSquare, CheckCircle2, Circle } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -12,6 +13,7 @@ interface Contact {
   email: string
   phone: string
   message: string
+  handled: boolean
   created_at: string
 }
 
@@ -25,7 +27,7 @@ export default function ContactsPage() {
 
   const fetchContacts = async () => {
     try {
-      const res = await fetch(`${API_URL}/admin/contacts`)
+      const res = await adminFetch(`${API_URL}/admin/contacts`)
       const data = await res.json()
       setContacts(data)
     } catch (error) {
@@ -35,13 +37,35 @@ export default function ContactsPage() {
     }
   }
 
+  const toggleHandled = async (contact: Contact) => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/contacts/${contact.id}`, {
+        method: 'PATCH',
+        headers: {
+          'Content-Type': 'application/merge-patch+json',
+        },
+        body: JSON.stringify({ handled: !contact.handled }),
+      })
+
+      if (res.ok) {
+        const updated = await res.json()
+        setContacts(contacts.map(c => (c.id === contact.id ? updated : c)))
+      } else {
+        alert('Ошибка при сохранении')
+      }
+    } catch (error) {
+      console.error('Error updating contact:', error)
+      alert('Ошибка при сохранении')
+    }
+  }
+
   const handleDelete = async (id: number) => {
     if (!confirm('Вы уверены, что хотите удалить этот контакт?')) {
       return
     }
 
     try {
-      const res = await fetch(`${API_URL}/admin/contacts/${id}`, {
+      const res = await adminFetch(`${API_URL}/admin/contacts/${id}`, {
         method: 'DELETE',
       })
 
@@ -141,6 +165,13 @@ export default function ContactsPage() {
                       <p className="text-foreground whitespace-pre-wrap">{contact.message}</p>
                     </div>
                   </div>
+                  <button
+                    onClick={() => toggleHandled(contact)}
+                    className={`p-2 rounded-lg transition hover:bg-muted ${contact.handled ? 'text-green-600' : 'text-muted-foreground'}`}
+                    title={contact.handled ? 'Обработано' : 'Отметить как обработанное'}
+                  >
+                    {contact.handled ? <CheckCircle2 className="w-5 h-5" /> : <Circle className="w-5 h-5" />}
+                  </button>
                   <button
                     onClick={() => handleDelete(contact.id)}
                     className="p-2 text-red-500 hover:bg-red-50 dark:hover:bg-red-950 rounded-lg transition"
@@ -0,0 +1,431 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Plus, Save, Trash2, ExternalLink } from 'lucide-react'
+import { statusLabels, type PublicationStatus } from '@/components/publication-fields'
+import type { BlockType } from '@/lib/content'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+const locales = ['ru', 'en', 'zh']
+
+const blockTypeLabels: Record<BlockType, string> = {
+  rich_text: 'Текст',
+  image: 'Изображение',
+  carousel: 'Карусель изображений',
+  key_facts: 'Ключевые факты',
+  cta: 'Призыв к действию',
+}
+
+// New blocks are created as drafts with sample content that passes validation
+const newBlockData: Record<BlockType, Record<string, any>> = {
+  rich_text: { text: 'Новый текст' },
+  image: { url: '/placeholder.svg' },
+  carousel: { images: [{ url: '/placeholder.svg' }] },
+  key_facts: { facts: [{ value: '100+', label: 'Довольных клиентов' }] },
+  cta: { title: 'Заголовок', button_label: 'Изучить каталог', button_url: '/catalog' },
+}
+
+interface Section {
+  id: number
+  page: string
+  name: string
+  title: string
+  order: number
+  version: number
+}
+
+interface Block {
+  id: number
+  section_id: number
+  type: BlockType
+  locale: string
+  order: number
+  status: PublicationStatus
+  data: Record<string, any>
+  version: number
+}
+
+// Lists of images and facts are edited as lines of "|"-separated fields
+const toLines = (items: Record<string, any>[], fields: string[]) =>
+  (items || []).map((item) => fields.map((f) => item[f] || '').join(' | ').replace(/( \| )+$/, '')).join('\n')
+
+const fromLines = (text: string, fields: string[]) =>
+  text
+    .split('\n')
+    .map((line) => line.trim())
+    .filter(Boolean)
+    .map((line) => {
+      const parts = line.split('|').map((p) => p.trim())
+      return Object.fromEntries(fields.map((f, i) => [f, parts[i] || '']).filter(([, v]) => v !== ''))
+    })
+
+const inputClass = 'w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground'
+
+function Field({ label, children }: { label: string; children: React.ReactNode }) {
+  return (
+    <div>
+      <label className="block text-sm font-medium text-foreground mb-2">{label}</label>
+      {children}
+    </div>
+  )
+}
+
+function BlockDataFields({ type, data, onChange }: { type: BlockType; data: Record<string, any>; onChange: (data: Record<string, any>) => void }) {
+  const text = (key: string, label: string, placeholder = '') => (
+    <Field label={label}>
+      <input type="text" value={data[key] || ''} placeholder={placeholder} onChange={(e) => onChange({ ...data, [key]: e.target.value })} className={inputClass} />
+    </Field>
+  )
+
+  switch (type) {
+    case 'rich_text':
+      return (
+        <div className="space-y-4">
+          {text('title', 'Заголовок')}
+          <Field label="Текст">
+            <textarea value={data.text || ''} rows={8} onChange={(e) => onChange({ ...data, text: e.target.value })} className={inputClass} />
+            <p className="mt-1 text-xs text-muted-foreground">
+              Абзацы разделяются пустой строкой. **жирный**, *курсив*, [ссылка](/catalog)
+            </p>
+          </Field>
+        </div>
+      )
+    case 'image':
+      return (
+        <div className="grid md:grid-cols-2 gap-4">
+          {text('url', 'Адрес изображения', '/uploads/...')}
+          {text('alt', 'Альтернативный текст')}
+          {text('caption', 'Подпись')}
+          {text('link', 'Ссылка', '/catalog')}
+        </div>
+      )
+    case 'carousel':
+      return (
+        <div className="space-y-4">
+          {text('title', 'Заголовок')}
+          <Field label="Изображения">
+            <textarea
+              defaultValue={toLines(data.images, ['url', 'alt', 'caption'])}
+              rows={6}
+              onChange={(e) => onChange({ ...data, images: fromLines(e.target.value, ['url', 'alt', 'caption']) })}
+              className={`${inputClass} font-mono text-sm`}
+            />
+            <p className="mt-1 text-xs text-muted-foreground">По одному на строку: адрес | альтернативный текст | подпись</p>
+          </Field>
+        </div>
+      )
+    case 'key_facts':
+      return (
+        <div className="space-y-4">
+          {text('title', 'Заголовок')}
+          <Field label="Факты">
+            <textarea
+              defaultValue={toLines(data.facts, ['value', 'label'])}
+              rows={6}
+              onChange={(e) => onChange({ ...data, facts: fromLines(e.target.value, ['value', 'label']) })}
+              className={`${inputClass} font-mono text-sm`}
+            />
+            <p className="mt-1 text-xs text-muted-foreground">По одному на строку: значение | подпись, например «40% | влияние дизайна на оценку отеля»</p>
+          </Field>
+        </div>
+      )
+    case 'cta':
+      return (
+        <div className="space-y-4">
+          {text('title', 'Заголовок')}
+          <Field label="Текст">
+            <textarea value={data.text || ''} rows={3} onChange={(e) => onChange({ ...data, text: e.target.value })} className={inputClass} />
+          </Field>
+          <div className="grid md:grid-cols-3 gap-4">
+            {text('button_label', 'Текст кнопки')}
+            {text('button_url', 'Ссылка кнопки', '/catalog')}
+            {text('image', 'Фоновое изображение', '/uploads/...')}
+          </div>
+        </div>
+      )
+  }
+}
+
+// Drops empty optional strings so the backend stores only what was filled in
+function cleanData(data: Record<string, any>) {
+  return Object.fromEntries(Object.entries(data).filter(([, v]) => v !== ''))
+}
+
+function BlockEditor({ block, onSaved, onDeleted }: { block: Block; onSaved: () => void; onDeleted: () => void }) {
+  const [draft, setDraft] = useState(block)
+  const [saving, setSaving] = useState(false)
+  const [error, setError] = useState('')
+
+  const save = async () => {
+    setSaving(true)
+    setError('')
+    try {
+      const res = await adminFetch(`${API_URL}/admin/content-blocks/${block.id}`, {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json', 'If-Match': `"${block.version}"` },
+        body: JSON.stringify({ ...draft, data: cleanData(draft.data) }),
+      })
+      if (res.status === 412) {
+        setError('Блок был изменён другим пользователем. Обновите страницу.')
+        return
+      }
+      if (!res.ok) {
+        setError(await res.text())
+        return
+      }
+      onSaved()
+    } catch (error) {
+      console.error('Error saving block:', error)
+      setError('Не удалось сохранить')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  const remove = async () => {
+    if (!confirm('Удалить блок?')) return
+    const res = await adminFetch(`${API_URL}/admin/content-blocks/${block.id}`, {
+      method: 'DELETE',
+      headers: { 'If-Match': `"${block.version}"` },
+    })
+    if (res.ok) onDeleted()
+  }
+
+  return (
+    <div className="bg-card border border-border rounded-lg p-6 space-y-4">
+      <div className="flex flex-wrap items-end gap-4">
+        <div className="font-semibold text-foreground mr-auto">{blockTypeLabels[block.type]}</div>
+        <Field label="Порядок">
+          <input type="number" value={draft.order} onChange={(e) => setDraft({ ...draft, order: parseInt(e.target.value) || 0 })} className={`${inputClass} w-24`} />
+        </Field>
+        <Field label="Статус">
+          <select value={draft.status} onChange={(e) => setDraft({ ...draft, status: e.target.value as PublicationStatus })} className={inputClass}>
+            {(Object.keys(statusLabels) as PublicationStatus[]).map((status) => (
+              <option key={status} value={status}>{statusLabels[status]}</option>
+            ))}
+          </select>
+        </Field>
+      </div>
+      <BlockDataFields type={block.type} data={draft.data} onChange={(data) => setDraft({ ...draft, data })} />
+      <div className="flex flex-wrap items-center gap-4">
+        <button onClick={save} disabled={saving} className="flex items-center gap-2 px-6 py-2 bg-primary text-primary-foreground rounded-lg hover:bg-primary/90 transition disabled:opacity-50">
+          <Save className="w-4 h-4" />
+          {saving ? 'Сохранение...' : 'Сохранить'}
+        </button>
+        <button onClick={remove} className="flex items-center gap-2 px-4 py-2 text-destructive hover:bg-destructive/10 rounded-lg transition">
+          <Trash2 className="w-4 h-4" />
+          Удалить
+        </button>
+        {error && <p className="text-sm text-destructive">{error}</p>}
+      </div>
+    </div>
+  )
+}
+
+export default function ContentPage() {
+  const [page, setPage] = useState('home')
+  const [sections, setSections] = useState<Section[]>([])
+  const [selected, setSelected] = useState<Section | null>(null)
+  const [locale, setLocale] = useState('ru')
+  const [blocks, setBlocks] = useState<Block[]>([])
+  const [newSection, setNewSection] = useState({ name: '', title: '', order: 0 })
+  const [newBlockType, setNewBlockType] = useState<BlockType>('rich_text')
+  const [error, setError] = useState('')
+
+  useEffect(() => {
+    fetchSections()
+  }, [page])
+
+  useEffect(() => {
+    if (selected) fetchBlocks()
+  }, [selected, locale])
+
+  const fetchSections = async () => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/content-sections?page=${encodeURIComponent(page)}`)
+      const data: Section[] = await res.json()
+      setSections(data)
+      setSelected((current) => data.find((s) => s.id === current?.id) || data[0] || null)
+    } catch (error) {
+      console.error('Error fetching sections:', error)
+    }
+  }
+
+  const fetchBlocks = async () => {
+    if (!selected) return
+    try {
+      const res = await adminFetch(`${API_URL}/admin/content-blocks?section_id=${selected.id}&locale=${locale}`)
+      setBlocks(await res.json())
+    } catch (error) {
+      console.error('Error fetching blocks:', error)
+    }
+  }
+
+  const createSection = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setError('')
+    const res = await adminFetch(`${API_URL}/admin/content-sections`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ ...newSection, page }),
+    })
+    if (!res.ok) {
+      setError(await res.text())
+      return
+    }
+    const section: Section = await res.json()
+    setNewSection({ name: '', title: '', order: 0 })
+    setSelected(section)
+    fetchSections()
+  }
+
+  const deleteSection = async (section: Section) => {
+    if (!confirm(`Удалить секцию «${section.title || section.name}» вместе со всеми блоками?`)) return
+    const res = await adminFetch(`${API_URL}/admin/content-sections/${section.id}`, {
+      method: 'DELETE',
+      headers: { 'If-Match': `"${section.version}"` },
+    })
+    if (res.ok) {
+      setSelected(null)
+      fetchSections()
+    }
+  }
+
+  const createBlock = async () => {
+    if (!selected) return
+    setError('')
+    const res = await adminFetch(`${API_URL}/admin/content-blocks`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({
+        section_id: selected.id,
+        type: newBlockType,
+        locale,
+        order: blocks.length ? Math.max(...blocks.map((b) => b.order)) + 1 : 0,
+        status: 'draft',
+        data: newBlockData[newBlockType],
+      }),
+    })
+    if (!res.ok) {
+      setError(await res.text())
+      return
+    }
+    fetchBlocks()
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="flex flex-wrap items-end justify-between gap-4 mb-8">
+          <div>
+            <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Контент страниц</h1>
+            <p className="text-muted-foreground">
+              Секции главной страницы hero, chaos-facts, interiors, about и newsletter заменяют встроенные блоки, остальные секции выводятся перед FAQ
+            </p>
+          </div>
+          <div className="flex items-end gap-4">
+            <Field label="Страница">
+              <input type="text" value={page} onChange={(e) => setPage(e.target.value)} className={`${inputClass} w-40`} />
+            </Field>
+            {page === 'home' && (
+              <a href="/" target="_blank" rel="noopener noreferrer" className="inline-flex items-center gap-2 text-primary hover:underline py-2">
+                <ExternalLink className="w-4 h-4" />
+                Открыть
+              </a>
+            )}
+          </div>
+        </div>
+
+        {error && <p className="text-sm text-destructive mb-4">{error}</p>}
+
+        <div className="grid lg:grid-cols-[280px_1fr] gap-6">
+          <div className="space-y-4">
+            <div className="bg-card border border-border rounded-lg divide-y divide-border">
+              {sections.length === 0 && <p className="p-4 text-sm text-muted-foreground">Секций пока нет</p>}
+              {sections.map((section) => (
+                <div
+                  key={section.id}
+                  className={`flex items-center justify-between gap-2 p-4 cursor-pointer ${selected?.id === section.id ? 'bg-muted' : 'hover:bg-muted/50'}`}
+                  onClick={() => setSelected(section)}
+                >
+                  <div>
+                    <div className="font-medium text-foreground">{section.title || section.name}</div>
+                    <div className="text-xs text-muted-foreground font-mono">{section.name} · {section.order}</div>
+                  </div>
+                  <button
+                    onClick={(e) => {
+                      e.stopPropagation()
+                      deleteSection(section)
+                    }}
+                    className="p-2 text-muted-foreground hover:text-destructive transition"
+                    title="Удалить"
+                  >
+                    <Trash2 className="w-4 h-4" />
+                  </button>
+                </div>
+              ))}
+            </div>
+
+            <form onSubmit={createSection} className="bg-card border border-border rounded-lg p-4 space-y-3">
+              <h2 className="font-semibold text-foreground">Новая секция</h2>
+              <input type="text" required placeholder="Ключ, например hero" value={newSection.name} onChange={(e) => setNewSection({ ...newSection, name: e.target.value })} className={inputClass} />
+              <input type="text" placeholder="Название" value={newSection.title} onChange={(e) => setNewSection({ ...newSection, title: e.target.value })} className={inputClass} />
+              <input type="number" placeholder="Порядок" value={newSection.order} onChange={(e) => setNewSection({ ...newSection, order: parseInt(e.target.value) || 0 })} className={inputClass} />
+              <button type="submit" className="flex items-center gap-2 px-4 py-2 bg-primary text-primary-foreground rounded-lg hover:bg-primary/90 transition">
+                <Plus className="w-4 h-4" />
+                Добавить
+              </button>
+            </form>
+          </div>
+
+          <div className="space-y-6">
+            {selected ? (
+              <>
+                <div className="flex flex-wrap items-end gap-4">
+                  <Field label="Язык">
+                    <select value={locale} onChange={(e) => setLocale(e.target.value)} className={inputClass}>
+                      {locales.map((l) => (
+                        <option key={l} value={l}>{l}</option>
+                      ))}
+                    </select>
+                  </Field>
+                  <Field label="Новый блок">
+                    <select value={newBlockType} onChange={(e) => setNewBlockType(e.target.value as BlockType)} className={inputClass}>
+                      {(Object.keys(blockTypeLabels) as BlockType[]).map((type) => (
+                        <option key={type} value={type}>{blockTypeLabels[type]}</option>
+                      ))}
+                    </select>
+                  </Field>
+                  <button onClick={createBlock} className="flex items-center gap-2 px-4 py-2 bg-primary text-primary-foreground rounded-lg hover:bg-primary/90 transition">
+                    <Plus className="w-4 h-4" />
+                    Добавить блок
+                  </button>
+                </div>
+
+                {blocks.length === 0 && (
+                  <p className="text-muted-foreground">
+                    В секции нет блоков на этом языке. Без блоков секция показывается на языке по умолчанию или встроенной версией.
+                  </p>
+                )}
+                {blocks.map((block) => (
+                  <BlockEditor key={`${block.id}-${block.version}`} block={block} onSaved={fetchBlocks} onDeleted={fetchBlocks} />
+                ))}
+              </>
+            ) : (
+              <p className="text-muted-foreground">Выберите или создайте секцию</p>
+            )}
+          </div>
+        </div>
+      </div>
+    </div>
+  )
+}
+
@@ -3,6 +3,7 @@
 import { useState } from 'react'
 import Link from 'next/link'
 import { Database, Download, Upload, Loader2, CheckCircle2, AlertCircle, ArrowLeft } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -20,6 +21,7 @@ export default function DatabasePage() {
   const [restoring, setRestoring] = useState(false)
   const [message, setMessage] = useState<{ type: 'success' | 'error'; text: string } | null>(null)
   const [dumpFile, setDumpFile] = useState<File | null>(null)
+  const [lastDump, setLastDump] = useState<string | null>(null)
 
   const handleCreateDump = async () => {
     setCreatingDump(true)
@@ -27,13 +29,14 @@ export default function DatabasePage() {
 
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/db/dump`, {
+      const res = await adminFetch(`${apiUrl}/admin/db/dump`, {
         method: 'POST',
       })
 
       const data = await res.json()
 
       if (res.ok) {
+        setLastDump(data.filename)
         setMessage({
           type: 'success',
           text: `Дамп успешно создан: ${data.filename}. ${data.telegram_sent ? 'Отправлен в Telegram.' : ''}`,
@@ -54,6 +57,28 @@ export default function DatabasePage() {
     }
   }
 
+  // Dumps are only served to admins, so the file is downloaded through fetch
+  const handleDownloadDump = async (filename: string) => {
+    try {
+      const res = await adminFetch(`${getApiUrl()}/admin/db/dumps/${encodeURIComponent(filename)}`)
+      if (!res.ok) {
+        setMessage({ type: 'error', text: `Ошибка при скачивании дампа: ${await res.text()}` })
+        return
+      }
+      const url = URL.createObjectURL(await res.blob())
+      const link = document.createElement('a')
+      link.href = url
+      link.download = filename
+      link.click()
+      URL.revokeObjectURL(url)
+    } catch (error: any) {
+      setMessage({
+        type: 'error',
+        text: `Ошибка: ${error.message || 'Неизвестная ошибка'}`,
+      })
+    }
+  }
+
   const handleFileSelect = (e: React.ChangeEvent<HTMLInputElement>) => {
     const file = e.target.files?.[0]
     if (file) {
@@ -83,7 +108,7 @@ export default function DatabasePage() {
       formData.append('dump', dumpFile)
 
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/db/restore`, {
+      const res = await adminFetch(`${apiUrl}/admin/db/restore`, {
         method: 'POST',
         body: formData,
       })
@@ -179,6 +204,15 @@ export default function DatabasePage() {
                 </>
               )}
             </button>
+            {lastDump && (
+              <button
+                onClick={() => handleDownloadDump(lastDump)}
+                className="mt-4 px-4 py-2 border border-border rounded-lg hover:bg-muted transition flex items-center gap-2"
+              >
+                <Download className="w-4 h-4" />
+                Скачать {lastDump}
+              </button>
+            )}
           </div>
 
           {/* Restore Dump Section */}
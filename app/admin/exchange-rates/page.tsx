@@ -0,0 +1,178 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Save, Upload } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface Currency {
+  code: string
+  rate: number | null
+  source?: string
+  updated_at?: string
+}
+
+interface CurrencyList {
+  base: string
+  currencies: Currency[]
+}
+
+const sourceLabels: Record<string, string> = {
+  manual: 'вручную',
+  import: 'из файла',
+}
+
+export default function ExchangeRatesPage() {
+  const [base, setBase] = useState('')
+  const [currencies, setCurrencies] = useState<Currency[]>([])
+  const [rates, setRates] = useState<Record<string, string>>({})
+  const [loading, setLoading] = useState(true)
+  const [importing, setImporting] = useState(false)
+
+  useEffect(() => {
+    fetchRates()
+  }, [])
+
+  const applyList = (data: CurrencyList) => {
+    setBase(data.base)
+    setCurrencies(data.currencies)
+    const initial: Record<string, string> = {}
+    for (const c of data.currencies) {
+      initial[c.code] = c.rate !== null ? c.rate.toString() : ''
+    }
+    setRates(initial)
+  }
+
+  const fetchRates = async () => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/exchange-rates`)
+      applyList(await res.json())
+    } catch (error) {
+      console.error('Error fetching exchange rates:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleSave = async (code: string) => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/exchange-rates/${code}`, {
+        method: 'PUT',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify({ rate: parseFloat(rates[code]) }),
+      })
+
+      if (res.ok) {
+        applyList(await res.json())
+      } else {
+        alert(`Ошибка при сохранении курса: ${await res.text()}`)
+      }
+    } catch (error) {
+      console.error('Error saving exchange rate:', error)
+      alert('Ошибка при сохранении курса')
+    }
+  }
+
+  const handleImport = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0]
+    if (!file) return
+    setImporting(true)
+
+    try {
+      const formData = new FormData()
+      formData.append('file', file)
+
+      const res = await adminFetch(`${API_URL}/admin/exchange-rates/import`, {
+        method: 'POST',
+        body: formData,
+      })
+
+      if (res.ok) {
+        applyList(await res.json())
+      } else {
+        alert(`Ошибка при импорте курсов: ${await res.text()}`)
+      }
+    } catch (error) {
+      console.error('Error importing exchange rates:', error)
+      alert('Ошибка при импорте курсов')
+    } finally {
+      setImporting(false)
+      e.target.value = ''
+    }
+  }
+
+  if (loading) {
+    return (
+      <div className="min-h-screen bg-background">
+        <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+          <p className="text-muted-foreground">Загрузка...</p>
+        </div>
+      </div>
+    )
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="mb-8 flex items-end justify-between gap-4">
+          <div>
+            <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Курсы валют</h1>
+            <p className="text-muted-foreground">
+              Сколько {base} стоит одна единица валюты
+            </p>
+          </div>
+          <label className="flex items-center gap-2 px-4 py-2 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition cursor-pointer">
+            <Upload className="w-4 h-4" />
+            {importing ? 'Импорт...' : 'Импорт из файла'}
+            <input type="file" accept=".json,.csv,.txt" onChange={handleImport} className="hidden" disabled={importing} />
+          </label>
+        </div>
+
+        <div className="space-y-4">
+          {currencies.map((c) => (
+            <div key={c.code} className="bg-card border border-border rounded-lg p-6 flex items-center justify-between gap-4">
+              <div>
+                <p className="font-semibold text-foreground">{c.code}</p>
+                <p className="text-sm text-muted-foreground">
+                  {c.code === base
+                    ? 'Базовая валюта'
+                    : c.updated_at
+                      ? `Обновлено ${new Date(c.updated_at).toLocaleString('ru-RU')} ${sourceLabels[c.source || ''] || ''}`
+                      : 'Курс не задан'}
+                </p>
+              </div>
+              {c.code !== base && (
+                <div className="flex gap-2">
+                  <input
+                    type="number"
+                    step="0.0001"
+                    min="0"
+                    value={rates[c.code] || ''}
+                    onChange={(e) => setRates({ ...rates, [c.code]: e.target.value })}
+                    className="w-40 px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+                  />
+                  <button
+                    onClick={() => handleSave(c.code)}
+                    className="p-2 text-primary hover:bg-primary/10 rounded-lg transition"
+                    title="Сохранить"
+                  >
+                    <Save className="w-5 h-5" />
+                  </button>
+                </div>
+              )}
+            </div>
+          ))}
+        </div>
+      </div>
+    </div>
+  )
+}
@@ -4,6 +4,8 @@ import { useState, useEffect } from 'react'
 import { useRouter, useParams } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Loader2 } from 'lucide-react'
+import { FAQLinkFields } from '@/components/faq-links'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -26,25 +28,33 @@ export default function EditFAQPage() {
   const params = useParams()
   const [loading, setLoading] = useState(true)
   const [saving, setSaving] = useState(false)
+  const [version, setVersion] = useState(0)
   const [faq, setFaq] = useState<FAQ | null>(null)
   const [formData, setFormData] = useState({
     question: '',
     answer: '',
     order: 0,
+    group: '',
+    product_ids: [] as number[],
+    category_ids: [] as number[],
   })
 
   useEffect(() => {
     const fetchFAQ = async () => {
       try {
         const apiUrl = getApiUrl()
-        const res = await fetch(`${apiUrl}/admin/faqs/${params.id}`)
+        const res = await adminFetch(`${apiUrl}/admin/faqs/${params.id}`)
         if (res.ok) {
           const data = await res.json()
           setFaq(data)
+          setVersion(data.version)
           setFormData({
             question: data.question,
             answer: data.answer,
             order: data.order,
+            group: data.group || '',
+            product_ids: data.product_ids || [],
+            category_ids: data.category_ids || [],
           })
         } else {
           alert('Ошибка при загрузке вопроса')
@@ -70,14 +80,20 @@ export default function EditFAQPage() {
 
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/faqs/${params.id}`, {
+      const res = await adminFetch(`${apiUrl}/admin/faqs/${params.id}`, {
         method: 'PUT',
         headers: {
           'Content-Type': 'application/json',
+          'If-Match': `"${version}"`,
         },
         body: JSON.stringify(formData),
       })
 
+      if (res.status === 412) {
+        alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+        return
+      }
+
       if (res.ok) {
         router.push('/admin/faqs')
       } else {
@@ -147,6 +163,8 @@ export default function EditFAQPage() {
             />
           </div>
 
+          <FAQLinkFields value={formData} onChange={(links) => setFormData({ ...formData, ...links })} />
+
           <div>
             <label htmlFor="order" className="block text-sm font-medium text-foreground mb-2">
               Порядок отображения
@@ -159,7 +177,7 @@ export default function EditFAQPage() {
               className="w-full px-4 py-2 bg-background border border-border rounded-lg text-foreground focus:outline-none focus:ring-2 focus:ring-primary"
               placeholder="0"
             />
-            <p className="mt-1 text-sm text-muted-foreground">Вопросы сортируются по этому полю (меньше = выше)</p>
+            <p className="mt-1 text-sm text-muted-foreground">Вопросы сортируются по этому полю (меньше = выше). Весь список можно упорядочить на странице FAQ</p>
           </div>
 
           <div className="flex items-center gap-4">
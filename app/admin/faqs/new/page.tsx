@@ -4,6 +4,8 @@ import { useState } from 'react'
 import { useRouter } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Loader2 } from 'lucide-react'
+import { FAQLinkFields } from '@/components/faq-links'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -21,6 +23,9 @@ export default function NewFAQPage() {
     question: '',
     answer: '',
     order: 0,
+    group: '',
+    product_ids: [] as number[],
+    category_ids: [] as number[],
   })
 
   const handleSubmit = async (e: React.FormEvent) => {
@@ -29,7 +34,7 @@ export default function NewFAQPage() {
 
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/faqs`, {
+      const res = await adminFetch(`${apiUrl}/admin/faqs`, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
@@ -94,6 +99,8 @@ export default function NewFAQPage() {
             />
           </div>
 
+          <FAQLinkFields value={formData} onChange={(links) => setFormData({ ...formData, ...links })} />
+
           <div>
             <label htmlFor="order" className="block text-sm font-medium text-foreground mb-2">
               Порядок отображения
@@ -106,7 +113,7 @@ export default function NewFAQPage() {
               className="w-full px-4 py-2 bg-background border border-border rounded-lg text-foreground focus:outline-none focus:ring-2 focus:ring-primary"
               placeholder="0"
             />
-            <p className="mt-1 text-sm text-muted-foreground">Вопросы сортируются по этому полю (меньше = выше)</p>
+            <p className="mt-1 text-sm text-muted-foreground">Вопросы сортируются по этому полю (меньше = выше). Весь список можно упорядочить на странице FAQ</p>
           </div>
 
           <div className="flex items-center gap-4">
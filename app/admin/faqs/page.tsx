@@ -2,7 +2,9 @@
 
 import { useState, useEffect } from 'react'
 import Link from 'next/link'
-import { HelpCircle, Plus, Edit, Trash2, ArrowLeft, Loader2 } from 'lucide-react'
+import { HelpCircle, Plus, Edit, Trash2, ArrowLeft, Loader2, ArrowUp, ArrowDown } from 'lucide-react'
+import { faqGroupLabels } from '@/components/faq'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -15,23 +17,30 @@ function getApiUrl() {
 
 interface FAQ {
   id: number
+  version: number
   question: string
   answer: string
   order: number
+  group: string
+  product_ids: number[]
+  category_ids: number[]
 }
 
 export default function FAQsPage() {
   const [faqs, setFaqs] = useState<FAQ[]>([])
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
+  const [orderChanged, setOrderChanged] = useState(false)
+  const [savingOrder, setSavingOrder] = useState(false)
 
   const fetchFAQs = async () => {
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/faqs`)
+      const res = await adminFetch(`${apiUrl}/admin/faqs`)
       if (res.ok) {
         const data = await res.json()
         setFaqs(Array.isArray(data) ? data : [])
+        setOrderChanged(false)
         setError(null)
       } else {
         const errorText = await res.text()
@@ -52,15 +61,16 @@ export default function FAQsPage() {
     fetchFAQs()
   }, [])
 
-  const handleDelete = async (id: number) => {
+  const handleDelete = async (id: number, version: number) => {
     if (!confirm('Вы уверены, что хотите удалить этот вопрос?')) {
       return
     }
 
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/faqs/${id}`, {
+      const res = await adminFetch(`${apiUrl}/admin/faqs/${id}`, {
         method: 'DELETE',
+        headers: { 'If-Match': `"${version}"` },
       })
 
       if (res.ok) {
@@ -74,6 +84,43 @@ export default function FAQsPage() {
     }
   }
 
+  const move = (index: number, offset: number) => {
+    const target = index + offset
+    if (target < 0 || target >= faqs.length) return
+    const next = [...faqs]
+    const [item] = next.splice(index, 1)
+    next.splice(target, 0, item)
+    setFaqs(next)
+    setOrderChanged(true)
+  }
+
+  const saveOrder = async () => {
+    setSavingOrder(true)
+    try {
+      const apiUrl = getApiUrl()
+      const res = await adminFetch(`${apiUrl}/admin/faqs/order`, {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ ids: faqs.map(faq => faq.id) }),
+      })
+
+      if (res.ok) {
+        setFaqs(await res.json())
+        setOrderChanged(false)
+      } else if (res.status === 422) {
+        alert('Список вопросов изменился. Порядок будет загружен заново.')
+        fetchFAQs()
+      } else {
+        alert(`Ошибка при сохранении порядка: ${await res.text()}`)
+      }
+    } catch (error) {
+      console.error('Error reordering FAQs:', error)
+      alert('Ошибка при сохранении порядка')
+    } finally {
+      setSavingOrder(false)
+    }
+  }
+
   if (loading) {
     return (
       <div className="min-h-screen bg-background flex items-center justify-center">
@@ -101,13 +148,24 @@ export default function FAQsPage() {
                 <p className="text-muted-foreground">Управление часто задаваемыми вопросами</p>
               </div>
             </div>
-            <Link
-              href="/admin/faqs/new"
-              className="px-4 py-2 bg-primary text-primary-foreground font-medium rounded-lg hover:opacity-90 transition flex items-center gap-2"
-            >
-              <Plus className="w-5 h-5" />
-              Добавить вопрос
-            </Link>
+            <div className="flex items-center gap-3">
+              {orderChanged && (
+                <button
+                  onClick={saveOrder}
+                  disabled={savingOrder}
+                  className="px-4 py-2 border border-primary text-primary font-medium rounded-lg hover:bg-primary/10 transition disabled:opacity-50"
+                >
+                  {savingOrder ? 'Сохранение...' : 'Сохранить порядок'}
+                </button>
+              )}
+              <Link
+                href="/admin/faqs/new"
+                className="px-4 py-2 bg-primary text-primary-foreground font-medium rounded-lg hover:opacity-90 transition flex items-center gap-2"
+              >
+                <Plus className="w-5 h-5" />
+                Добавить вопрос
+              </Link>
+            </div>
           </div>
         </div>
 
@@ -132,12 +190,30 @@ export default function FAQsPage() {
           </div>
         ) : (
           <div className="space-y-4">
-            {faqs.map((faq) => (
+            {faqs.map((faq, index) => (
               <div key={faq.id} className="bg-card border border-border rounded-lg p-6">
                 <div className="flex items-start justify-between gap-4">
+                  <div className="flex flex-col">
+                    <button
+                      onClick={() => move(index, -1)}
+                      disabled={index === 0}
+                      className="p-1 text-muted-foreground hover:text-foreground disabled:opacity-30 transition"
+                    >
+                      <ArrowUp className="w-4 h-4" />
+                    </button>
+                    <button
+                      onClick={() => move(index, 1)}
+                      disabled={index === faqs.length - 1}
+                      className="p-1 text-muted-foreground hover:text-foreground disabled:opacity-30 transition"
+                    >
+                      <ArrowDown className="w-4 h-4" />
+                    </button>
+                  </div>
                   <div className="flex-1">
-                    <div className="flex items-center gap-2 mb-2">
-                      <span className="text-xs text-muted-foreground">Порядок: {faq.order}</span>
+                    <div className="flex items-center gap-3 mb-2 text-xs text-muted-foreground">
+                      <span className="px-2 py-0.5 rounded bg-muted">{faqGroupLabels[faq.group] || 'Общие вопросы'}</span>
+                      {faq.product_ids.length > 0 && <span>Товаров: {faq.product_ids.length}</span>}
+                      {faq.category_ids.length > 0 && <span>Категорий: {faq.category_ids.length}</span>}
                     </div>
                     <h3 className="text-lg font-semibold text-foreground mb-2">{faq.question}</h3>
                     <p className="text-muted-foreground line-clamp-2">{faq.answer}</p>
@@ -150,7 +226,7 @@ export default function FAQsPage() {
                       <Edit className="w-5 h-5" />
                     </Link>
                     <button
-                      onClick={() => handleDelete(faq.id)}
+                      onClick={() => handleDelete(faq.id, faq.version)}
                       className="p-2 text-red-600 hover:bg-red-500/10 rounded-lg transition"
                     >
                       <Trash2 className="w-5 h-5" />
@@ -0,0 +1,28 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import { usePathname, useRouter } from 'next/navigation'
+import { getAdminToken } from '@/lib/admin-auth'
+
+// The admin API only answers logged-in admins, so every admin page except the
+// login form waits for a session
+export default function AdminLayout({ children }: { children: React.ReactNode }) {
+  const pathname = usePathname()
+  const router = useRouter()
+  const isLogin = pathname === '/admin/login'
+  const [ready, setReady] = useState(false)
+
+  useEffect(() => {
+    if (isLogin || getAdminToken()) {
+      setReady(true)
+      return
+    }
+    setReady(false)
+    router.replace(`/admin/login?next=${encodeURIComponent(pathname)}`)
+  }, [isLogin, pathname, router])
+
+  if (!ready) {
+    return <div className="min-h-screen bg-background" />
+  }
+  return <>{children}</>
+}
@@ -0,0 +1,81 @@
+'use client'
+
+import { useState } from 'react'
+import { useRouter } from 'next/navigation'
+import { setAdminSession } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+const inputClass = 'w-full px-4 py-3 border border-border rounded-lg bg-background text-foreground placeholder-muted-foreground focus:outline-none focus:ring-2 focus:ring-primary'
+
+export default function AdminLoginPage() {
+  const router = useRouter()
+  const [form, setForm] = useState({ username: '', password: '' })
+  const [error, setError] = useState('')
+  const [loading, setLoading] = useState(false)
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setError('')
+    setLoading(true)
+
+    try {
+      const res = await fetch(`${API_URL}/admin/login`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(form),
+      })
+      if (res.ok) {
+        const data = await res.json()
+        setAdminSession(data.token, data.admin.username)
+        const next = new URLSearchParams(window.location.search).get('next')
+        // Only return to pages of the admin panel
+        router.replace(next && next.startsWith('/admin') ? next : '/admin')
+      } else if (res.status === 429) {
+        setError('Слишком много попыток входа. Попробуйте позже.')
+      } else {
+        setError('Неверное имя пользователя или пароль')
+      }
+    } catch (error) {
+      console.error('Error logging in:', error)
+      setError('Не удалось связаться с сервером')
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  return (
+    <div className="min-h-screen bg-background flex items-center justify-center px-4">
+      <form onSubmit={handleSubmit} className="w-full max-w-md bg-card border border-border rounded-lg p-8 space-y-6">
+        <h1 className="text-3xl font-serif font-bold text-foreground text-center">Вход в панель</h1>
+        <input
+          required
+          autoComplete="username"
+          placeholder="Имя пользователя"
+          value={form.username}
+          onChange={(e) => setForm({ ...form, username: e.target.value })}
+          className={inputClass}
+        />
+        <input
+          type="password"
+          required
+          autoComplete="current-password"
+          placeholder="Пароль"
+          value={form.password}
+          onChange={(e) => setForm({ ...form, password: e.target.value })}
+          className={inputClass}
+        />
+        {error && <p className="text-red-600 text-center">{error}</p>}
+        <button
+          type="submit"
+          disabled={loading}
+          className="w-full px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition disabled:opacity-50"
+        >
+          Войти
+        </button>
+      </form>
+    </div>
+  )
+}
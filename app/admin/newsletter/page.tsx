@@ -0,0 +1,336 @@
+'use client'
+
+import { useState, useEffect, useCallback } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Download, Send, Trash2 } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface Subscriber {
+  id: number
+  email: string
+  status: 'pending' | 'confirmed' | 'unsubscribed'
+  source: string
+  consent_at: string
+  consent_ip: string
+  confirmed_at: string | null
+  confirmed_ip: string
+  unsubscribed_at: string | null
+}
+
+interface Campaign {
+  id: number
+  subject: string
+  status: 'sending' | 'sent'
+  recipients: number
+  sent: number
+  failed: number
+  created_by: string
+  created_at: string
+  finished_at: string | null
+}
+
+const statusLabels: Record<Subscriber['status'], string> = {
+  pending: 'Ожидает подтверждения',
+  confirmed: 'Подтверждён',
+  unsubscribed: 'Отписался',
+}
+
+const pageSize = 100
+
+const formatDate = (value: string | null) => (value ? new Date(value).toLocaleString('ru-RU') : '—')
+
+export default function NewsletterPage() {
+  const [subscribers, setSubscribers] = useState<Subscriber[]>([])
+  const [total, setTotal] = useState(0)
+  const [offset, setOffset] = useState(0)
+  const [statusFilter, setStatusFilter] = useState('')
+  const [search, setSearch] = useState('')
+  const [loading, setLoading] = useState(true)
+
+  const [campaigns, setCampaigns] = useState<Campaign[]>([])
+  const [subject, setSubject] = useState('')
+  const [body, setBody] = useState('')
+  const [testEmail, setTestEmail] = useState('')
+  const [sending, setSending] = useState(false)
+
+  const filterQuery = useCallback(() => {
+    const params = new URLSearchParams()
+    if (statusFilter) params.set('status', statusFilter)
+    if (search.trim()) params.set('q', search.trim())
+    return params
+  }, [statusFilter, search])
+
+  const fetchSubscribers = useCallback(async () => {
+    try {
+      const params = filterQuery()
+      params.set('limit', String(pageSize))
+      params.set('offset', String(offset))
+      const res = await adminFetch(`${API_URL}/admin/newsletter/subscribers?${params}`)
+      const data = await res.json()
+      setSubscribers(data.subscribers || [])
+      setTotal(data.total || 0)
+    } catch (error) {
+      console.error('Error fetching subscribers:', error)
+    } finally {
+      setLoading(false)
+    }
+  }, [filterQuery, offset])
+
+  const fetchCampaigns = useCallback(async () => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/newsletter/campaigns`)
+      setCampaigns(await res.json())
+    } catch (error) {
+      console.error('Error fetching campaigns:', error)
+    }
+  }, [])
+
+  useEffect(() => {
+    fetchSubscribers()
+  }, [fetchSubscribers])
+
+  useEffect(() => {
+    fetchCampaigns()
+  }, [fetchCampaigns])
+
+  // Refresh the progress while a campaign is being sent
+  useEffect(() => {
+    if (!campaigns.some(c => c.status === 'sending')) return
+    const timer = setTimeout(fetchCampaigns, 5000)
+    return () => clearTimeout(timer)
+  }, [campaigns, fetchCampaigns])
+
+  const handleDelete = async (subscriber: Subscriber) => {
+    if (!confirm(`Удалить ${subscriber.email} вместе с записью о согласии? Для обычной отписки удалять не нужно.`)) return
+
+    try {
+      const res = await adminFetch(`${API_URL}/admin/newsletter/subscribers/${subscriber.id}`, { method: 'DELETE' })
+      if (res.ok) {
+        fetchSubscribers()
+      }
+    } catch (error) {
+      console.error('Error deleting subscriber:', error)
+      alert('Ошибка при удалении подписчика')
+    }
+  }
+
+  const sendCampaign = async (test: boolean) => {
+    if (!test && !confirm('Отправить рассылку всем подтверждённым подписчикам?')) return
+
+    setSending(true)
+    try {
+      const res = await adminFetch(`${API_URL}/admin/newsletter/campaigns`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ subject, body, test_email: test ? testEmail : '' }),
+      })
+      if (!res.ok) {
+        alert(`Ошибка при отправке: ${await res.text()}`)
+        return
+      }
+      if (test) {
+        alert(`Тестовое письмо отправлено на ${testEmail}`)
+      } else {
+        setSubject('')
+        setBody('')
+        fetchCampaigns()
+      }
+    } catch (error) {
+      console.error('Error sending campaign:', error)
+      alert('Ошибка при отправке рассылки')
+    } finally {
+      setSending(false)
+    }
+  }
+
+  // The export needs the admin session, so it is downloaded through fetch
+  const exportCSV = async () => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/newsletter/subscribers/export?${filterQuery()}`)
+      if (!res.ok) {
+        alert(`Ошибка при экспорте: ${await res.text()}`)
+        return
+      }
+      const filename = res.headers.get('Content-Disposition')?.match(/filename="?([^"]+)"?/)?.[1] || 'subscribers.csv'
+      const url = URL.createObjectURL(await res.blob())
+      const link = document.createElement('a')
+      link.href = url
+      link.download = filename
+      link.click()
+      URL.revokeObjectURL(url)
+    } catch (error) {
+      console.error('Error exporting subscribers:', error)
+      alert('Ошибка при экспорте подписчиков')
+    }
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-2">
+          <ArrowLeft className="w-4 h-4" />
+          Назад к панели
+        </Link>
+        <h1 className="text-4xl font-serif font-bold text-foreground mb-8">Рассылка</h1>
+
+        <div className="bg-card border border-border rounded-lg p-6 mb-8 space-y-4">
+          <h2 className="text-xl font-semibold text-foreground">Новая рассылка</h2>
+          <input
+            type="text"
+            placeholder="Тема письма"
+            value={subject}
+            onChange={(e) => setSubject(e.target.value)}
+            className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+          <textarea
+            rows={8}
+            placeholder="Текст письма. Ссылка для отписки добавляется автоматически."
+            value={body}
+            onChange={(e) => setBody(e.target.value)}
+            className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+          <div className="flex flex-wrap items-center gap-3">
+            <input
+              type="email"
+              placeholder="Адрес для проверки"
+              value={testEmail}
+              onChange={(e) => setTestEmail(e.target.value)}
+              className="px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+            />
+            <button
+              onClick={() => sendCampaign(true)}
+              disabled={sending || !subject || !body || !testEmail}
+              className="px-4 py-2 border border-border rounded-lg hover:bg-muted transition disabled:opacity-50"
+            >
+              Отправить тест
+            </button>
+            <button
+              onClick={() => sendCampaign(false)}
+              disabled={sending || !subject || !body}
+              className="flex items-center gap-2 px-6 py-2 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition disabled:opacity-50"
+            >
+              <Send className="w-4 h-4" />
+              Отправить подписчикам
+            </button>
+          </div>
+
+          {campaigns.length > 0 && (
+            <div className="pt-4 border-t border-border space-y-2">
+              <h3 className="text-sm font-semibold text-muted-foreground uppercase">Отправленные рассылки</h3>
+              {campaigns.map((c) => (
+                <div key={c.id} className="flex flex-wrap items-center justify-between gap-2 text-sm">
+                  <span className="text-foreground">{c.subject}</span>
+                  <span className="text-muted-foreground">
+                    {formatDate(c.created_at)} · {c.created_by} ·{' '}
+                    {c.status === 'sending' ? `отправляется: ${c.sent + c.failed} из ${c.recipients}` : `отправлено ${c.sent}`}
+                    {c.failed > 0 && `, ошибок ${c.failed}`}
+                  </span>
+                </div>
+              ))}
+            </div>
+          )}
+        </div>
+
+        <div className="bg-card border border-border rounded-lg p-6">
+          <div className="flex flex-wrap items-center gap-3 mb-6">
+            <h2 className="text-xl font-semibold text-foreground mr-auto">Подписчики ({total})</h2>
+            <input
+              type="search"
+              placeholder="Поиск по email"
+              value={search}
+              onChange={(e) => {
+                setSearch(e.target.value)
+                setOffset(0)
+              }}
+              className="px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+            />
+            <select
+              value={statusFilter}
+              onChange={(e) => {
+                setStatusFilter(e.target.value)
+                setOffset(0)
+              }}
+              className="px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+            >
+              <option value="">Все</option>
+              {Object.entries(statusLabels).map(([value, label]) => (
+                <option key={value} value={value}>{label}</option>
+              ))}
+            </select>
+            <button
+              onClick={exportCSV}
+              className="flex items-center gap-2 px-4 py-2 border border-border rounded-lg hover:bg-muted transition"
+            >
+              <Download className="w-4 h-4" />
+              CSV
+            </button>
+          </div>
+
+          {loading ? (
+            <p className="text-muted-foreground">Загрузка...</p>
+          ) : subscribers.length === 0 ? (
+            <p className="text-muted-foreground">Подписчиков нет</p>
+          ) : (
+            <div className="overflow-x-auto">
+              <table className="w-full text-sm">
+                <thead>
+                  <tr className="text-left text-muted-foreground border-b border-border">
+                    <th className="py-2 pr-4">Email</th>
+                    <th className="py-2 pr-4">Статус</th>
+                    <th className="py-2 pr-4">Согласие</th>
+                    <th className="py-2 pr-4">Подтверждение</th>
+                    <th className="py-2 pr-4">Источник</th>
+                    <th className="py-2"></th>
+                  </tr>
+                </thead>
+                <tbody>
+                  {subscribers.map((s) => (
+                    <tr key={s.id} className="border-b border-border last:border-0">
+                      <td className="py-2 pr-4 text-foreground">{s.email}</td>
+                      <td className="py-2 pr-4">{statusLabels[s.status]}</td>
+                      <td className="py-2 pr-4 text-muted-foreground">{formatDate(s.consent_at)}<br />{s.consent_ip}</td>
+                      <td className="py-2 pr-4 text-muted-foreground">{formatDate(s.confirmed_at)}<br />{s.confirmed_ip}</td>
+                      <td className="py-2 pr-4 text-muted-foreground">{s.source || '—'}</td>
+                      <td className="py-2 text-right">
+                        <button
+                          onClick={() => handleDelete(s)}
+                          className="p-2 text-destructive hover:bg-destructive/10 rounded transition"
+                        >
+                          <Trash2 className="w-4 h-4" />
+                        </button>
+                      </td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            </div>
+          )}
+
+          {total > pageSize && (
+            <div className="flex items-center justify-end gap-3 mt-4 text-sm">
+              <button
+                onClick={() => setOffset(Math.max(0, offset - pageSize))}
+                disabled={offset === 0}
+                className="px-3 py-1 border border-border rounded-lg disabled:opacity-50"
+              >
+                Назад
+              </button>
+              <span className="text-muted-foreground">
+                {offset + 1}–{Math.min(offset + pageSize, total)} из {total}
+              </span>
+              <button
+                onClick={() => setOffset(offset + pageSize)}
+                disabled={offset + pageSize >= total}
+                className="px-3 py-1 border border-border rounded-lg disabled:opacity-50"
+              >
+                Вперёд
+              </button>
+            </div>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
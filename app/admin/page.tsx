@@ -2,40 +2,76 @@
 
 import { useState, useEffect } from 'react'
 import Link from 'next/link'
-import { Package, FolderTree, Layers, Plus, Edit, Trash2, MessageSquare, AlertCircle, Database, HelpCircle } from 'lucide-react'
+import { useRouter } from 'next/navigation'
+import { Package, FolderTree, Layers, Plus, Edit, Trash2, MessageSquare, AlertCircle, Database, HelpCircle, Trash, Languages, Coins, Briefcase, Star, BarChart3, ClipboardCheck, Settings, ArrowRightLeft, LayoutTemplate, Building2, Mail, Users, LogOut } from 'lucide-react'
+import { adminFetch, getAdminUsername, setAdminSession } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
-export default function AdminPage() {
-  const [stats, setStats] = useState({
-    products: 0,
-    categories: 0,
-    collections: 0,
-  })
+interface DashboardStats {
+  counts: { products: number; categories: number; collections: number }
+  unhandled_leads: number
+  contacts: {
+    per_day: { start: string; count: number }[]
+    today: number
+    this_week: number
+    week_trend: number | null
+  }
+  last_backup: { file: string; created_at: string; size: number } | null
+  uploads_bytes: number
+  uploads_files: number
+  products_missing_images: { id: number; name: string }[]
+  products_missing_description: { id: number; name: string }[]
+}
 
-  useEffect(() => {
-    fetch(`${API_URL}/products`)
-      .then(res => res.json())
-      .then(data => setStats(prev => ({ ...prev, products: data.length })))
-      .catch(console.error)
+const formatSize = (bytes: number) => {
+  if (bytes < 1024 * 1024) return `${(bytes / 1024).toFixed(0)} КБ`
+  if (bytes < 1024 * 1024 * 1024) return `${(bytes / 1024 / 1024).toFixed(1)} МБ`
+  return `${(bytes / 1024 / 1024 / 1024).toFixed(2)} ГБ`
+}
 
-    fetch(`${API_URL}/categories`)
-      .then(res => res.json())
-      .then(data => setStats(prev => ({ ...prev, categories: data.length })))
-      .catch(console.error)
+export default function AdminPage() {
+  const router = useRouter()
+  const [dashboard, setDashboard] = useState<DashboardStats | null>(null)
+  const [username, setUsername] = useState<string | null>(null)
+  const stats = dashboard?.counts || { products: 0, categories: 0, collections: 0 }
 
-    fetch(`${API_URL}/collections`)
+  useEffect(() => {
+    adminFetch(`${API_URL}/admin/stats`)
       .then(res => res.json())
-      .then(data => setStats(prev => ({ ...prev, collections: data.length })))
+      .then(setDashboard)
       .catch(console.error)
+    setUsername(getAdminUsername())
   }, [])
 
+  const handleLogout = async () => {
+    try {
+      await adminFetch(`${API_URL}/admin/logout`, { method: 'POST' })
+    } catch (error) {
+      console.error('Error logging out:', error)
+    }
+    setAdminSession(null)
+    router.replace('/admin/login')
+  }
+
   return (
     <div className="min-h-screen bg-background">
       <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
-        <div className="mb-8">
-          <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Панель администратора</h1>
-          <p className="text-muted-foreground">Управление содержимым сайта</p>
+        <div className="mb-8 flex flex-wrap items-start justify-between gap-4">
+          <div>
+            <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Панель администратора</h1>
+            <p className="text-muted-foreground">Управление содержимым сайта</p>
+          </div>
+          <div className="flex items-center gap-3">
+            {username && <span className="text-sm text-muted-foreground">{username}</span>}
+            <button
+              onClick={handleLogout}
+              className="flex items-center gap-2 px-4 py-2 border border-border rounded-lg hover:bg-muted transition"
+            >
+              <LogOut className="w-4 h-4" />
+              Выйти
+            </button>
+          </div>
         </div>
 
         <div className="grid md:grid-cols-3 gap-6 mb-12">
@@ -81,6 +117,9 @@ export default function AdminPage() {
           >
             <div className="flex items-center justify-between mb-4">
               <MessageSquare className="w-8 h-8 text-primary" />
+              {dashboard && dashboard.unhandled_leads > 0 && (
+                <span className="text-sm font-semibold text-primary">{dashboard.unhandled_leads} новых</span>
+              )}
             </div>
             <h3 className="font-semibold text-foreground mb-1">Контакты</h3>
             <p className="text-sm text-muted-foreground">Просмотр сообщений</p>
@@ -118,8 +157,212 @@ export default function AdminPage() {
             <h3 className="font-semibold text-foreground mb-1">FAQ</h3>
             <p className="text-sm text-muted-foreground">Часто задаваемые вопросы</p>
           </Link>
+
+          <Link
+            href="/admin/trash"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Trash className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Корзина</h3>
+            <p className="text-sm text-muted-foreground">Восстановление удалённых записей</p>
+          </Link>
+
+          <Link
+            href="/admin/translations"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Languages className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Переводы</h3>
+            <p className="text-sm text-muted-foreground">Непереведённые тексты</p>
+          </Link>
+
+          <Link
+            href="/admin/exchange-rates"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Coins className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Курсы валют</h3>
+            <p className="text-sm text-muted-foreground">Пересчёт цен в RUB, EUR, USD, AED</p>
+          </Link>
+
+          <Link
+            href="/admin/b2b"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Briefcase className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">B2B-клиенты</h3>
+            <p className="text-sm text-muted-foreground">Прайс-листы и индивидуальные цены</p>
+          </Link>
+
+          <Link
+            href="/admin/reviews"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Star className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Отзывы</h3>
+            <p className="text-sm text-muted-foreground">Модерация отзывов клиентов</p>
+          </Link>
+
+          <Link
+            href="/admin/analytics"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <BarChart3 className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Аналитика</h3>
+            <p className="text-sm text-muted-foreground">Просмотры, поиск и обращения</p>
+          </Link>
+
+          <Link
+            href="/admin/quality"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <ClipboardCheck className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Качество контента</h3>
+            <p className="text-sm text-muted-foreground">Пустые поля, битые изображения, дубли</p>
+          </Link>
+
+          <Link
+            href="/admin/settings"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Settings className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">SEO и настройки</h3>
+            <p className="text-sm text-muted-foreground">robots.txt, карта сайта и режим обслуживания</p>
+          </Link>
+
+          <Link
+            href="/admin/redirects"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <ArrowRightLeft className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Редиректы</h3>
+            <p className="text-sm text-muted-foreground">Перенаправления со старых адресов</p>
+          </Link>
+
+          <Link
+            href="/admin/content"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <LayoutTemplate className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Контент страниц</h3>
+            <p className="text-sm text-muted-foreground">Тексты и блоки главной страницы</p>
+          </Link>
+
+          <Link
+            href="/admin/projects"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Building2 className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Портфолио</h3>
+            <p className="text-sm text-muted-foreground">Реализованные проекты отелей</p>
+          </Link>
+
+          <Link
+            href="/admin/newsletter"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Mail className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Рассылка</h3>
+            <p className="text-sm text-muted-foreground">Подписчики и отправка писем</p>
+          </Link>
+
+          <Link
+            href="/admin/users"
+            className="bg-card border border-border rounded-lg p-6 hover:shadow-lg transition"
+          >
+            <div className="flex items-center justify-between mb-4">
+              <Users className="w-8 h-8 text-primary" />
+            </div>
+            <h3 className="font-semibold text-foreground mb-1">Администраторы</h3>
+            <p className="text-sm text-muted-foreground">Доступ к панели управления</p>
+          </Link>
         </div>
 
+        {dashboard && (
+          <div className="grid md:grid-cols-3 gap-6 mb-12">
+            <div className="bg-card border border-border rounded-lg p-6">
+              <h2 className="font-semibold text-foreground mb-4">Обращения</h2>
+              <div className="flex items-end gap-1 h-16 mb-4">
+                {dashboard.contacts.per_day.map((day) => {
+                  const max = Math.max(1, ...dashboard.contacts.per_day.map(d => d.count))
+                  return (
+                    <div
+                      key={day.start}
+                      title={`${day.start}: ${day.count}`}
+                      className="flex-1 bg-primary/60 rounded-t"
+                      style={{ height: `${(day.count / max) * 100}%`, minHeight: 2 }}
+                    />
+                  )
+                })}
+              </div>
+              <p className="text-sm text-muted-foreground">
+                Сегодня: {dashboard.contacts.today} · за неделю: {dashboard.contacts.this_week}
+                {dashboard.contacts.week_trend !== null && (
+                  <> · {dashboard.contacts.week_trend >= 0 ? '+' : ''}{dashboard.contacts.week_trend}% к прошлой неделе</>
+                )}
+              </p>
+            </div>
+
+            <div className="bg-card border border-border rounded-lg p-6">
+              <h2 className="font-semibold text-foreground mb-4">Хранилище</h2>
+              <p className="text-sm text-muted-foreground mb-2">
+                Последний бэкап: {dashboard.last_backup
+                  ? `${new Date(dashboard.last_backup.created_at).toLocaleString('ru-RU')} (${formatSize(dashboard.last_backup.size)})`
+                  : 'не создавался'}
+              </p>
+              <p className="text-sm text-muted-foreground">
+                Загрузки: {formatSize(dashboard.uploads_bytes)}, файлов: {dashboard.uploads_files}
+              </p>
+            </div>
+
+            <div className="bg-card border border-border rounded-lg p-6">
+              <h2 className="font-semibold text-foreground mb-4">Требуют внимания</h2>
+              {dashboard.products_missing_images.length === 0 && dashboard.products_missing_description.length === 0 ? (
+                <p className="text-sm text-muted-foreground">Все товары заполнены</p>
+              ) : (
+                <ul className="text-sm space-y-1">
+                  {dashboard.products_missing_images.map((p) => (
+                    <li key={`image-${p.id}`}>
+                      <Link href={`/admin/products/${p.id}/edit`} className="text-foreground hover:text-primary">{p.name}</Link>
+                      <span className="text-muted-foreground"> — нет фото</span>
+                    </li>
+                  ))}
+                  {dashboard.products_missing_description.map((p) => (
+                    <li key={`description-${p.id}`}>
+                      <Link href={`/admin/products/${p.id}/edit`} className="text-foreground hover:text-primary">{p.name}</Link>
+                      <span className="text-muted-foreground"> — нет описания</span>
+                    </li>
+                  ))}
+                </ul>
+              )}
+            </div>
+          </div>
+        )}
+
         <div className="bg-card border border-border rounded-lg p-6">
           <h2 className="text-xl font-semibold text-foreground mb-4">Быстрые действия</h2>
           <div className="grid md:grid-cols-3 gap-4">
@@ -4,6 +4,8 @@ import { useState, useEffect } from 'react'
 import { useRouter, useParams } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Save } from 'lucide-react'
+import { PlaceholderRuleFields, defaultPlaceholderRule, placeholderRuleFromEntity, placeholderRulePayload } from '@/components/placeholder-rule-fields'
+import { adminFetch } from '@/lib/admin-auth'
 
 // Get API URL - in browser, always use localhost, in Docker use environment variable
 const getApiUrl = () => {
@@ -22,12 +24,14 @@ export default function EditPlaceholderPage() {
   const params = useParams()
   const [loading, setLoading] = useState(true)
   const [saving, setSaving] = useState(false)
+  const [version, setVersion] = useState(0)
   const [formData, setFormData] = useState({
     path: '',
     title: '',
     message: '',
     is_active: true,
   })
+  const [rule, setRule] = useState(defaultPlaceholderRule)
 
   useEffect(() => {
     fetchPlaceholder()
@@ -36,15 +40,17 @@ export default function EditPlaceholderPage() {
   const fetchPlaceholder = async () => {
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/placeholders/${params.id}`)
+      const res = await adminFetch(`${apiUrl}/admin/placeholders/${params.id}`)
       if (res.ok) {
         const placeholder = await res.json()
+        setVersion(placeholder.version)
         setFormData({
           path: placeholder.path,
           title: placeholder.title,
           message: placeholder.message || '',
           is_active: placeholder.is_active,
         })
+        setRule(placeholderRuleFromEntity(placeholder))
       } else {
         alert('Заглушка не найдена')
         router.push('/admin/placeholders')
@@ -63,12 +69,20 @@ export default function EditPlaceholderPage() {
 
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/placeholders/${params.id}`, {
+      const res = await adminFetch(`${apiUrl}/admin/placeholders/${params.id}`, {
         method: 'PUT',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify(formData),
+        headers: {
+          'Content-Type': 'application/json',
+          'If-Match': `"${version}"`,
+        },
+        body: JSON.stringify({ ...formData, ...placeholderRulePayload(rule) }),
       })
 
+      if (res.status === 412) {
+        alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+        return
+      }
+
       if (res.ok) {
         router.push('/admin/placeholders')
       } else {
@@ -110,12 +124,13 @@ export default function EditPlaceholderPage() {
               type="text"
               value={formData.path}
               onChange={(e) => setFormData({ ...formData, path: e.target.value })}
-              placeholder="/new или /portfolio"
+              placeholder="/new, /portfolio или /portfolio/*"
               className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
               required
             />
             <p className="mt-1 text-xs text-muted-foreground">
-              Укажите путь страницы, для которой нужно показать заглушку (например: /new, /portfolio, /sale)
+              Укажите путь страницы, для которой нужно показать заглушку (например: /new, /portfolio, /sale).
+              Звёздочка заменяет часть адреса: /portfolio/* закрывает все страницы внутри раздела, /products/sofa-* — все адреса с этим началом
             </p>
           </div>
 
@@ -144,6 +159,8 @@ export default function EditPlaceholderPage() {
             />
           </div>
 
+          <PlaceholderRuleFields value={rule} onChange={setRule} />
+
           <div className="flex items-center gap-3">
             <input
               type="checkbox"
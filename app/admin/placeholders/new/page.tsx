@@ -4,6 +4,8 @@ import { useState } from 'react'
 import { useRouter } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Save } from 'lucide-react'
+import { PlaceholderRuleFields, defaultPlaceholderRule, placeholderRulePayload } from '@/components/placeholder-rule-fields'
+import { adminFetch } from '@/lib/admin-auth'
 
 // Get API URL - in browser, always use localhost, in Docker use environment variable
 const getApiUrl = () => {
@@ -26,6 +28,7 @@ export default function NewPlaceholderPage() {
     message: 'Мы работаем над этой страницей. Скоро она будет доступна!',
     is_active: true,
   })
+  const [rule, setRule] = useState(defaultPlaceholderRule)
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
@@ -33,10 +36,10 @@ export default function NewPlaceholderPage() {
 
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/placeholders`, {
+      const res = await adminFetch(`${apiUrl}/admin/placeholders`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify(formData),
+        body: JSON.stringify({ ...formData, ...placeholderRulePayload(rule) }),
       })
 
       if (res.ok) {
@@ -72,12 +75,13 @@ export default function NewPlaceholderPage() {
               type="text"
               value={formData.path}
               onChange={(e) => setFormData({ ...formData, path: e.target.value })}
-              placeholder="/new или /portfolio"
+              placeholder="/new, /portfolio или /portfolio/*"
               className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
               required
             />
             <p className="mt-1 text-xs text-muted-foreground">
-              Укажите путь страницы, для которой нужно показать заглушку (например: /new, /portfolio, /sale)
+              Укажите путь страницы, для которой нужно показать заглушку (например: /new, /portfolio, /sale).
+              Звёздочка заменяет часть адреса: /portfolio/* закрывает все страницы внутри раздела, /products/sofa-* — все адреса с этим началом
             </p>
           </div>
 
@@ -106,6 +110,8 @@ export default function NewPlaceholderPage() {
             />
           </div>
 
+          <PlaceholderRuleFields value={rule} onChange={setRule} />
+
           <div className="flex items-center gap-3">
             <input
               type="checkbox"
@@ -3,6 +3,7 @@
 import { useState, useEffect } from 'react'
 import Link from 'next/link'
 import { Plus, Edit, Trash2, CheckCircle, XCircle, ArrowLeft } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
 
 // Get API URL - in browser, always use localhost, in Docker use environment variable
 const getApiUrl = () => {
@@ -18,13 +19,25 @@ const getApiUrl = () => {
 
 interface Placeholder {
   id: number
+  version: number
   path: string
   title: string
   message: string
   is_active: boolean
+  priority: number
+  starts_at?: string | null
+  ends_at?: string | null
   created_at: string
 }
 
+function formatSchedule(placeholder: Placeholder) {
+  const format = (value: string) => new Date(value).toLocaleString('ru-RU', { dateStyle: 'short', timeStyle: 'short' })
+  if (placeholder.starts_at && placeholder.ends_at) return `${format(placeholder.starts_at)} — ${format(placeholder.ends_at)}`
+  if (placeholder.starts_at) return `с ${format(placeholder.starts_at)}`
+  if (placeholder.ends_at) return `до ${format(placeholder.ends_at)}`
+  return ''
+}
+
 export default function PlaceholdersPage() {
   const [placeholders, setPlaceholders] = useState<Placeholder[]>([])
   const [loading, setLoading] = useState(true)
@@ -38,7 +51,7 @@ export default function PlaceholdersPage() {
     try {
       setError(null)
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/placeholders`)
+      const res = await adminFetch(`${apiUrl}/admin/placeholders`)
       if (res.ok) {
         const data = await res.json()
         // Ensure we have an array, even if API returns null
@@ -58,13 +71,14 @@ export default function PlaceholdersPage() {
     }
   }
 
-  const handleDelete = async (id: number) => {
+  const handleDelete = async (id: number, version: number) => {
     if (!confirm('Вы уверены, что хотите удалить эту заглушку?')) return
 
     try {
       const apiUrl = getApiUrl()
-      const res = await fetch(`${apiUrl}/admin/placeholders/${id}`, {
+      const res = await adminFetch(`${apiUrl}/admin/placeholders/${id}`, {
         method: 'DELETE',
+        headers: { 'If-Match': `"${version}"` },
       })
 
       if (res.ok) {
@@ -147,6 +161,9 @@ export default function PlaceholdersPage() {
                     <code className="text-sm font-mono text-foreground bg-muted px-2 py-1 rounded">
                       {placeholder.path}
                     </code>
+                    {placeholder.priority !== 0 && (
+                      <div className="text-xs text-muted-foreground mt-1">Приоритет: {placeholder.priority}</div>
+                    )}
                   </td>
                   <td className="px-6 py-4">
                     <div className="text-sm font-medium text-foreground">{placeholder.title}</div>
@@ -166,6 +183,9 @@ export default function PlaceholdersPage() {
                         Неактивна
                       </span>
                     )}
+                    {formatSchedule(placeholder) && (
+                      <div className="text-xs text-muted-foreground mt-1">{formatSchedule(placeholder)}</div>
+                    )}
                   </td>
                   <td className="px-6 py-4 whitespace-nowrap text-sm text-muted-foreground">
                     {new Date(placeholder.created_at).toLocaleDateString('ru-RU')}
@@ -179,7 +199,7 @@ export default function PlaceholdersPage() {
                         <Edit className="w-4 h-4" />
                       </Link>
                       <button
-                        onClick={() => handleDelete(placeholder.id)}
+                        onClick={() => handleDelete(placeholder.id, placeholder.version)}
                         className="p-2 text-destructive hover:bg-destructive/10 rounded-lg transition"
                       >
                         <Trash2 className="w-4 h-4" />
@@ -4,6 +4,12 @@ import { useState, useEffect } from 'react'
 import { useRouter, useParams } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Save } from 'lucide-react'
+import { PublicationFields, Publication, defaultPublication, publicationFromEntity, publicationPayload } from '@/components/publication-fields'
+import { PricingFields, Pricing, defaultPricing, pricingFromEntity, pricingPayload } from '@/components/pricing-fields'
+import { SEOFields, SEO, defaultSEO, seoFromEntity } from '@/components/seo-fields'
+import { ProductRelationsEditor } from '@/components/product-relations-editor'
+import { PreviewButton } from '@/components/preview-button'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -12,15 +18,17 @@ export default function EditProductPage() {
   const params = useParams()
   const [loading, setLoading] = useState(true)
   const [saving, setSaving] = useState(false)
+  const [version, setVersion] = useState(0)
   const [uploading, setUploading] = useState(false)
   const [images, setImages] = useState<string[]>([])
   const [featured, setFeatured] = useState(false)
+  const [publication, setPublication] = useState<Publication>(defaultPublication)
+  const [pricing, setPricing] = useState<Pricing>(defaultPricing)
+  const [seo, setSeo] = useState<SEO>(defaultSEO)
   const [formData, setFormData] = useState({
     name: '',
     category: '',
     price: '',
-    rating: '',
-    reviews: '',
     description: '',
     color: '',
     dimensions: '',
@@ -55,7 +63,7 @@ export default function EditProductPage() {
         const formDataUpload = new FormData()
         formDataUpload.append('image', file)
 
-        const res = await fetch(`${API_URL}/admin/upload`, {
+        const res = await adminFetch(`${API_URL}/admin/upload`, {
           method: 'POST',
           body: formDataUpload,
         })
@@ -90,14 +98,13 @@ export default function EditProductPage() {
 
   const fetchProduct = async () => {
     try {
-      const res = await fetch(`${API_URL}/products/${params.id}`)
+      const res = await adminFetch(`${API_URL}/admin/products/${params.id}`)
       const product = await res.json()
+      setVersion(product.version)
       setFormData({
         name: product.name,
         category: product.category,
         price: product.price.toString(),
-        rating: product.rating.toString(),
-        reviews: product.reviews.toString(),
         description: product.description,
         color: product.color,
         dimensions: product.dimensions,
@@ -112,6 +119,9 @@ export default function EditProductPage() {
       }
       // Set featured flag
       setFeatured(product.featured || false)
+      setPublication(publicationFromEntity(product))
+      setPricing(pricingFromEntity(product))
+      setSeo(seoFromEntity(product))
     } catch (error) {
       console.error('Error fetching product:', error)
     } finally {
@@ -128,8 +138,6 @@ export default function EditProductPage() {
         name: formData.name,
         category: formData.category,
         price: parseFloat(formData.price),
-        rating: parseFloat(formData.rating),
-        reviews: parseInt(formData.reviews),
         description: formData.description,
         image: images[0] || '', // Main image (first one)
         images: images,         // All images
@@ -138,14 +146,25 @@ export default function EditProductPage() {
         material: formData.material,
         features: formData.features.split(',').map(f => f.trim()).filter(f => f),
         featured: featured, // Recommended product flag
+        ...publicationPayload(publication),
+        ...pricingPayload(pricing),
+        ...seo,
       }
 
-      const res = await fetch(`${API_URL}/admin/products/${params.id}`, {
+      const res = await adminFetch(`${API_URL}/admin/products/${params.id}`, {
         method: 'PUT',
-        headers: { 'Content-Type': 'application/json' },
+        headers: {
+          'Content-Type': 'application/json',
+          'If-Match': `"${version}"`,
+        },
         body: JSON.stringify(product),
       })
 
+      if (res.status === 412) {
+        alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+        return
+      }
+
       if (res.ok) {
         router.push('/admin/products')
       } else {
@@ -175,7 +194,10 @@ export default function EditProductPage() {
           Назад к товарам
         </Link>
 
-        <h1 className="text-4xl font-serif font-bold text-foreground mb-8">Редактировать товар</h1>
+        <div className="flex flex-wrap items-center justify-between gap-4 mb-8">
+          <h1 className="text-4xl font-serif font-bold text-foreground">Редактировать товар</h1>
+          <PreviewButton path={`/products/${params.id}`} />
+        </div>
 
         <form onSubmit={handleSubmit} className="bg-card border border-border rounded-lg p-8 space-y-6">
           <div className="grid md:grid-cols-2 gap-6">
@@ -210,27 +232,6 @@ export default function EditProductPage() {
                 className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
               />
             </div>
-            <div>
-              <label className="block text-sm font-medium text-foreground mb-2">Рейтинг</label>
-              <input
-                type="number"
-                step="0.1"
-                min="0"
-                max="5"
-                value={formData.rating}
-                onChange={(e) => setFormData({ ...formData, rating: e.target.value })}
-                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
-              />
-            </div>
-            <div>
-              <label className="block text-sm font-medium text-foreground mb-2">Отзывы</label>
-              <input
-                type="number"
-                value={formData.reviews}
-                onChange={(e) => setFormData({ ...formData, reviews: e.target.value })}
-                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
-              />
-            </div>
             <div>
               <label className="block text-sm font-medium text-foreground mb-2">Цвет</label>
               <input
@@ -331,6 +332,12 @@ export default function EditProductPage() {
             </div>
           </div>
 
+          <PricingFields value={pricing} onChange={setPricing} />
+
+          <PublicationFields value={publication} onChange={setPublication} />
+
+          <SEOFields value={seo} onChange={setSeo} />
+
           <div className="flex gap-4 pt-6">
             <button
               type="submit"
@@ -348,6 +355,8 @@ export default function EditProductPage() {
             </Link>
           </div>
         </form>
+
+        <ProductRelationsEditor productId={Number(params.id)} />
       </div>
     </div>
   )
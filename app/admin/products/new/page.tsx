@@ -4,6 +4,10 @@ import { useState } from 'react'
 import { useRouter } from 'next/navigation'
 import Link from 'next/link'
 import { ArrowLeft, Save } from 'lucide-react'
+import { PublicationFields, Publication, defaultPublication, publicationPayload } from '@/components/publication-fields'
+import { PricingFields, Pricing, defaultPricing, pricingPayload } from '@/components/pricing-fields'
+import { SEOFields, SEO, defaultSEO } from '@/components/seo-fields'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -13,12 +17,13 @@ export default function NewProductPage() {
   const [uploading, setUploading] = useState(false)
   const [images, setImages] = useState<string[]>([])
   const [featured, setFeatured] = useState(false)
+  const [publication, setPublication] = useState<Publication>(defaultPublication)
+  const [pricing, setPricing] = useState<Pricing>(defaultPricing)
+  const [seo, setSeo] = useState<SEO>(defaultSEO)
   const [formData, setFormData] = useState({
     name: '',
     category: '',
     price: '',
-    rating: '4.5',
-    reviews: '0',
     description: '',
     color: '',
     dimensions: '',
@@ -55,7 +60,7 @@ export default function NewProductPage() {
         const formDataUpload = new FormData()
         formDataUpload.append('image', file)
 
-        const res = await fetch(`${API_URL}/admin/upload`, {
+        const res = await adminFetch(`${API_URL}/admin/upload`, {
           method: 'POST',
           body: formDataUpload,
         })
@@ -100,8 +105,6 @@ export default function NewProductPage() {
         name: formData.name,
         category: formData.category,
         price: parseFloat(formData.price),
-        rating: parseFloat(formData.rating),
-        reviews: parseInt(formData.reviews),
         description: formData.description,
         image: images[0], // Main image (first one)
         images: images,   // All images
@@ -110,9 +113,12 @@ export default function NewProductPage() {
         material: formData.material,
         features: formData.features.split(',').map(f => f.trim()).filter(f => f),
         featured: featured, // Recommended product flag
+        ...publicationPayload(publication),
+        ...pricingPayload(pricing),
+        ...seo,
       }
 
-      const res = await fetch(`${API_URL}/admin/products`, {
+      const res = await adminFetch(`${API_URL}/admin/products`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify(product),
@@ -174,27 +180,6 @@ export default function NewProductPage() {
                 className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
               />
             </div>
-            <div>
-              <label className="block text-sm font-medium text-foreground mb-2">Рейтинг</label>
-              <input
-                type="number"
-                step="0.1"
-                min="0"
-                max="5"
-                value={formData.rating}
-                onChange={(e) => setFormData({ ...formData, rating: e.target.value })}
-                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
-              />
-            </div>
-            <div>
-              <label className="block text-sm font-medium text-foreground mb-2">Отзывы</label>
-              <input
-                type="number"
-                value={formData.reviews}
-                onChange={(e) => setFormData({ ...formData, reviews: e.target.value })}
-                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
-              />
-            </div>
             <div>
               <label className="block text-sm font-medium text-foreground mb-2">Цвет</label>
               <input
@@ -295,6 +280,12 @@ export default function NewProductPage() {
             </div>
           </div>
 
+          <PricingFields value={pricing} onChange={setPricing} />
+
+          <PublicationFields value={publication} onChange={setPublication} />
+
+          <SEOFields value={seo} onChange={setSeo} />
+
           <div className="flex gap-4 pt-6">
             <button
               type="submit"
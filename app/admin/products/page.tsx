@@ -3,11 +3,13 @@
 import { useState, useEffect } from 'react'
 import Link from 'next/link'
 import { Plus, Edit, Trash2, ArrowLeft, Star } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
 interface Product {
   id: number
+  version: number
   name: string
   category: string
   price: number
@@ -32,7 +34,7 @@ export default function ProductsPage() {
 
   const fetchProducts = async () => {
     try {
-      const res = await fetch(`${API_URL}/products`)
+      const res = await adminFetch(`${API_URL}/admin/products`)
       const data = await res.json()
       setProducts(data)
     } catch (error) {
@@ -42,12 +44,13 @@ export default function ProductsPage() {
     }
   }
 
-  const handleDelete = async (id: number) => {
+  const handleDelete = async (id: number, version: number) => {
     if (!confirm('Вы уверены, что хотите удалить этот товар?')) return
 
     try {
-      const res = await fetch(`${API_URL}/admin/products/${id}`, {
+      const res = await adminFetch(`${API_URL}/admin/products/${id}`, {
         method: 'DELETE',
+        headers: { 'If-Match': `"${version}"` },
       })
       if (res.ok) {
         setProducts(products.filter(p => p.id !== id))
@@ -127,7 +130,7 @@ export default function ProductsPage() {
                           <Edit className="w-4 h-4" />
                         </Link>
                         <button
-                          onClick={() => handleDelete(product.id)}
+                          onClick={() => handleDelete(product.id, product.version)}
                           className="p-2 text-destructive hover:bg-destructive/10 rounded transition"
                         >
                           <Trash2 className="w-4 h-4" />
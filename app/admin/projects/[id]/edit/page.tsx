@@ -0,0 +1,76 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import { useRouter, useParams } from 'next/navigation'
+import Link from 'next/link'
+import { ArrowLeft } from 'lucide-react'
+import { ProjectForm, ProjectFormValue, projectFormFromEntity, projectPayload } from '@/components/project-form'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export default function EditProjectPage() {
+  const router = useRouter()
+  const params = useParams()
+  const id = params.id as string
+  const [project, setProject] = useState<ProjectFormValue | null>(null)
+  const [version, setVersion] = useState(0)
+  const [saving, setSaving] = useState(false)
+
+  useEffect(() => {
+    adminFetch(`${API_URL}/admin/projects/${id}`)
+      .then(res => res.json())
+      .then(data => {
+        setProject(projectFormFromEntity(data))
+        setVersion(data.version)
+      })
+      .catch(error => console.error('Error fetching project:', error))
+  }, [id])
+
+  const handleSubmit = async () => {
+    if (!project) return
+    setSaving(true)
+    try {
+      const res = await adminFetch(`${API_URL}/admin/projects/${id}`, {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json', 'If-Match': `"${version}"` },
+        body: JSON.stringify(projectPayload(project)),
+      })
+      if (res.ok) {
+        router.push('/admin/projects')
+      } else if (res.status === 412) {
+        alert('Запись была изменена другим пользователем. Обновите страницу и повторите изменения.')
+      } else {
+        alert(`Ошибка при сохранении проекта: ${await res.text()}`)
+      }
+    } catch (error) {
+      console.error('Error updating project:', error)
+      alert('Ошибка при сохранении проекта')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  if (!project) {
+    return (
+      <div className="min-h-screen bg-background flex items-center justify-center">
+        <p className="text-muted-foreground">Загрузка...</p>
+      </div>
+    )
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-4xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin/projects" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад к проектам
+        </Link>
+
+        <h1 className="text-3xl font-serif font-bold text-foreground mb-8">Редактировать проект</h1>
+
+        <ProjectForm value={project} onChange={setProject} onSubmit={handleSubmit} saving={saving} />
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,52 @@
+'use client'
+
+import { useState } from 'react'
+import { useRouter } from 'next/navigation'
+import Link from 'next/link'
+import { ArrowLeft } from 'lucide-react'
+import { ProjectForm, defaultProjectForm, projectPayload } from '@/components/project-form'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export default function NewProjectPage() {
+  const router = useRouter()
+  const [saving, setSaving] = useState(false)
+  const [project, setProject] = useState(defaultProjectForm)
+
+  const handleSubmit = async () => {
+    setSaving(true)
+    try {
+      const res = await adminFetch(`${API_URL}/admin/projects`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(projectPayload(project)),
+      })
+      if (res.ok) {
+        router.push('/admin/projects')
+      } else {
+        alert(`Ошибка при создании проекта: ${await res.text()}`)
+      }
+    } catch (error) {
+      console.error('Error creating project:', error)
+      alert('Ошибка при создании проекта')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-4xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin/projects" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад к проектам
+        </Link>
+
+        <h1 className="text-3xl font-serif font-bold text-foreground mb-8">Новый проект</h1>
+
+        <ProjectForm value={project} onChange={setProject} onSubmit={handleSubmit} saving={saving} />
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,127 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { Plus, Edit, Trash2, ArrowLeft, MapPin } from 'lucide-react'
+import { statusLabels, PublicationStatus } from '@/components/publication-fields'
+import { Project, projectImageUrl } from '@/lib/projects'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export default function ProjectsPage() {
+  const [projects, setProjects] = useState<Project[]>([])
+  const [loading, setLoading] = useState(true)
+
+  useEffect(() => {
+    fetchProjects()
+  }, [])
+
+  const fetchProjects = async () => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/projects?limit=200`)
+      const data = await res.json()
+      setProjects(data)
+    } catch (error) {
+      console.error('Error fetching projects:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleDelete = async (id: number, version: number) => {
+    if (!confirm('Вы уверены, что хотите удалить этот проект?')) return
+
+    try {
+      const res = await adminFetch(`${API_URL}/admin/projects/${id}`, {
+        method: 'DELETE',
+        headers: { 'If-Match': `"${version}"` },
+      })
+      if (res.ok) {
+        setProjects(projects.filter(p => p.id !== id))
+      }
+    } catch (error) {
+      console.error('Error deleting project:', error)
+      alert('Ошибка при удалении проекта')
+    }
+  }
+
+  if (loading) {
+    return (
+      <div className="min-h-screen bg-background flex items-center justify-center">
+        <p className="text-muted-foreground">Загрузка...</p>
+      </div>
+    )
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <div className="flex items-center justify-between mb-8">
+          <div>
+            <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-2">
+              <ArrowLeft className="w-4 h-4" />
+              Назад к панели
+            </Link>
+            <h1 className="text-4xl font-serif font-bold text-foreground">Портфолио</h1>
+          </div>
+          <Link
+            href="/admin/projects/new"
+            className="flex items-center gap-2 px-6 py-3 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition"
+          >
+            <Plus className="w-5 h-5" />
+            Добавить проект
+          </Link>
+        </div>
+
+        {projects.length === 0 && (
+          <p className="text-muted-foreground">Проектов пока нет</p>
+        )}
+
+        <div className="grid md:grid-cols-2 lg:grid-cols-3 gap-6">
+          {projects.map((project) => (
+            <div key={project.id} className="bg-card border border-border rounded-lg overflow-hidden">
+              <div className="h-48 bg-muted overflow-hidden">
+                <img
+                  src={projectImageUrl(project.gallery[0])}
+                  alt={project.hotel_name}
+                  className="w-full h-full object-cover"
+                />
+              </div>
+              <div className="p-6">
+                <div className="flex items-start justify-between mb-2">
+                  <div>
+                    <h3 className="font-semibold text-foreground text-lg mb-1">{project.hotel_name}</h3>
+                    <p className="flex items-center gap-1 text-sm text-muted-foreground">
+                      <MapPin className="w-3 h-3" />
+                      {project.city}, {project.year}
+                    </p>
+                  </div>
+                  <div className="flex items-center gap-2">
+                    <Link
+                      href={`/admin/projects/${project.id}/edit`}
+                      className="p-2 text-primary hover:bg-primary/10 rounded transition"
+                    >
+                      <Edit className="w-4 h-4" />
+                    </Link>
+                    <button
+                      onClick={() => handleDelete(project.id, project.version)}
+                      className="p-2 text-destructive hover:bg-destructive/10 rounded transition"
+                    >
+                      <Trash2 className="w-4 h-4" />
+                    </button>
+                  </div>
+                </div>
+                <div className="flex items-center gap-3 text-xs text-muted-foreground">
+                  <span className="px-2 py-0.5 rounded bg-muted">{statusLabels[project.status as PublicationStatus] || project.status}</span>
+                  <span>Товаров: {project.product_ids.length}</span>
+                  <span>Коллекций: {project.collection_ids.length}</span>
+                </div>
+              </div>
+            </div>
+          ))}
+        </div>
+      </div>
+    </div>
+  )
+}
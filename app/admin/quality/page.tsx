@@ -0,0 +1,131 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, RefreshCw, CheckCircle2 } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface QualityIssue {
+  field: string
+  rule: string
+  severity: 'error' | 'warning'
+  message: string
+}
+
+interface QualityEntity {
+  type: 'products' | 'categories' | 'collections' | 'faqs'
+  id: number
+  name: string
+  issues: QualityIssue[]
+}
+
+interface QualityReport {
+  checked_at: string
+  errors: number
+  warnings: number
+  entities: QualityEntity[]
+}
+
+const typeLabels: Record<QualityEntity['type'], string> = {
+  products: 'Товар',
+  categories: 'Категория',
+  collections: 'Коллекция',
+  faqs: 'Вопрос FAQ',
+}
+
+export default function QualityPage() {
+  const [report, setReport] = useState<QualityReport | null>(null)
+  const [severity, setSeverity] = useState('')
+  const [loading, setLoading] = useState(true)
+
+  useEffect(() => {
+    fetchReport(false)
+  }, [severity])
+
+  const fetchReport = async (refresh: boolean) => {
+    setLoading(true)
+    try {
+      const params = new URLSearchParams()
+      if (refresh) params.set('refresh', 'true')
+      if (severity) params.set('severity', severity)
+      const res = await adminFetch(`${API_URL}/admin/quality?${params}`)
+      setReport(await res.json())
+    } catch (error) {
+      console.error('Error fetching quality report:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="mb-8 flex flex-wrap items-end justify-between gap-4">
+          <div>
+            <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Качество контента</h1>
+            {report && (
+              <p className="text-muted-foreground">
+                Ошибок: {report.errors} · предупреждений: {report.warnings} · проверено {new Date(report.checked_at).toLocaleString('ru-RU')}
+              </p>
+            )}
+          </div>
+          <div className="flex gap-4">
+            <select value={severity} onChange={(e) => setSeverity(e.target.value)} className="px-4 py-2 border border-border rounded-lg bg-background text-foreground">
+              <option value="">Все</option>
+              <option value="error">Только ошибки</option>
+              <option value="warning">Только предупреждения</option>
+            </select>
+            <button
+              onClick={() => fetchReport(true)}
+              className="flex items-center gap-2 px-4 py-2 border border-border rounded-lg hover:bg-muted transition"
+            >
+              <RefreshCw className="w-4 h-4" />
+              Проверить сейчас
+            </button>
+          </div>
+        </div>
+
+        {loading ? (
+          <p className="text-muted-foreground">Загрузка...</p>
+        ) : !report || report.entities.length === 0 ? (
+          <div className="bg-card border border-border rounded-lg p-12 text-center">
+            <CheckCircle2 className="w-16 h-16 text-green-600 mx-auto mb-4" />
+            <p className="text-muted-foreground">Проблем не найдено</p>
+          </div>
+        ) : (
+          <div className="space-y-4">
+            {report.entities.map((entity) => (
+              <div key={`${entity.type}-${entity.id}`} className="bg-card border border-border rounded-lg p-6">
+                <div className="flex justify-between gap-4 mb-3">
+                  <div>
+                    <p className="text-sm text-muted-foreground">{typeLabels[entity.type]} #{entity.id}</p>
+                    <p className="font-semibold text-foreground">{entity.name || 'Без названия'}</p>
+                  </div>
+                  <Link href={`/admin/${entity.type}/${entity.id}/edit`} className="text-primary hover:underline h-fit">
+                    Исправить
+                  </Link>
+                </div>
+                <ul className="space-y-1">
+                  {entity.issues.map((issue, idx) => (
+                    <li key={idx} className="text-sm">
+                      <span className={issue.severity === 'error' ? 'text-red-600' : 'text-yellow-600'}>●</span>{' '}
+                      <span className="text-muted-foreground">{issue.field}:</span>{' '}
+                      <span className="text-foreground">{issue.message}</span>
+                    </li>
+                  ))}
+                </ul>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,217 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Plus, Trash2, Search } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface Redirect {
+  id: number
+  from_path: string
+  to_path: string
+  status_code: number
+  auto: boolean
+  hits: number
+  last_hit_at?: string
+  created_at: string
+  version: number
+}
+
+const emptyForm = { from_path: '', to_path: '', status_code: 301 }
+
+export default function RedirectsPage() {
+  const [redirects, setRedirects] = useState<Redirect[]>([])
+  const [query, setQuery] = useState('')
+  const [loading, setLoading] = useState(true)
+  const [form, setForm] = useState(emptyForm)
+  const [saving, setSaving] = useState(false)
+  const [error, setError] = useState('')
+
+  useEffect(() => {
+    const timeout = setTimeout(fetchRedirects, 300)
+    return () => clearTimeout(timeout)
+  }, [query])
+
+  const fetchRedirects = async () => {
+    try {
+      const params = new URLSearchParams()
+      if (query) params.set('q', query)
+      const res = await adminFetch(`${API_URL}/admin/redirects?${params}`)
+      setRedirects(await res.json())
+    } catch (error) {
+      console.error('Error fetching redirects:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setSaving(true)
+    setError('')
+    try {
+      const res = await adminFetch(`${API_URL}/admin/redirects`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(form),
+      })
+      if (!res.ok) {
+        setError(await res.text())
+        return
+      }
+      setForm(emptyForm)
+      fetchRedirects()
+    } catch (error) {
+      console.error('Error creating redirect:', error)
+      setError('Не удалось сохранить')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  const handleDelete = async (redirect: Redirect) => {
+    if (!confirm(`Удалить редирект ${redirect.from_path}?`)) return
+    try {
+      const res = await adminFetch(`${API_URL}/admin/redirects/${redirect.id}`, {
+        method: 'DELETE',
+        headers: { 'If-Match': `"${redirect.version}"` },
+      })
+      if (res.status === 412) {
+        alert('Редирект был изменён. Обновите страницу.')
+      }
+      fetchRedirects()
+    } catch (error) {
+      console.error('Error deleting redirect:', error)
+    }
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="mb-8">
+          <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Редиректы</h1>
+          <p className="text-muted-foreground">
+            При смене адреса товара или коллекции редирект со старого адреса создаётся автоматически
+          </p>
+        </div>
+
+        <form onSubmit={handleCreate} className="bg-card border border-border rounded-lg p-6 mb-6">
+          <h2 className="text-xl font-semibold text-foreground mb-4">Новый редирект</h2>
+          <div className="grid md:grid-cols-[1fr_1fr_auto_auto] gap-4 items-end">
+            <div>
+              <label className="block text-sm font-medium text-foreground mb-2">Старый адрес</label>
+              <input
+                type="text"
+                required
+                value={form.from_path}
+                onChange={(e) => setForm({ ...form, from_path: e.target.value })}
+                placeholder="/old-page"
+                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+              />
+            </div>
+            <div>
+              <label className="block text-sm font-medium text-foreground mb-2">Новый адрес</label>
+              <input
+                type="text"
+                required
+                value={form.to_path}
+                onChange={(e) => setForm({ ...form, to_path: e.target.value })}
+                placeholder="/catalog"
+                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+              />
+            </div>
+            <div>
+              <label className="block text-sm font-medium text-foreground mb-2">Код</label>
+              <select
+                value={form.status_code}
+                onChange={(e) => setForm({ ...form, status_code: parseInt(e.target.value) })}
+                className="px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+              >
+                <option value={301}>301 — навсегда</option>
+                <option value={302}>302 — временно</option>
+                <option value={307}>307 — временно</option>
+                <option value={308}>308 — навсегда</option>
+              </select>
+            </div>
+            <button
+              type="submit"
+              disabled={saving}
+              className="flex items-center gap-2 px-6 py-2 bg-primary text-primary-foreground rounded-lg hover:bg-primary/90 transition disabled:opacity-50"
+            >
+              <Plus className="w-4 h-4" />
+              Добавить
+            </button>
+          </div>
+          {error && <p className="text-sm text-red-600 mt-4">{error}</p>}
+        </form>
+
+        <div className="relative mb-4">
+          <Search className="w-4 h-4 absolute left-3 top-1/2 -translate-y-1/2 text-muted-foreground" />
+          <input
+            type="text"
+            value={query}
+            onChange={(e) => setQuery(e.target.value)}
+            placeholder="Поиск по адресу"
+            className="w-full pl-10 pr-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+        </div>
+
+        <div className="bg-card border border-border rounded-lg overflow-hidden">
+          {loading ? (
+            <p className="p-6 text-muted-foreground">Загрузка...</p>
+          ) : redirects.length === 0 ? (
+            <p className="p-6 text-muted-foreground">Редиректов нет</p>
+          ) : (
+            <table className="w-full text-sm">
+              <thead className="bg-muted/50">
+                <tr>
+                  <th className="text-left p-4 font-medium text-foreground">Откуда</th>
+                  <th className="text-left p-4 font-medium text-foreground">Куда</th>
+                  <th className="text-left p-4 font-medium text-foreground">Код</th>
+                  <th className="text-left p-4 font-medium text-foreground">Переходы</th>
+                  <th className="p-4"></th>
+                </tr>
+              </thead>
+              <tbody>
+                {redirects.map((redirect) => (
+                  <tr key={redirect.id} className="border-t border-border">
+                    <td className="p-4 font-mono break-all">
+                      {redirect.from_path}
+                      {redirect.auto && (
+                        <span className="ml-2 px-2 py-0.5 text-xs rounded bg-muted text-muted-foreground font-sans">авто</span>
+                      )}
+                    </td>
+                    <td className="p-4 font-mono break-all">{redirect.to_path}</td>
+                    <td className="p-4">{redirect.status_code}</td>
+                    <td className="p-4 text-muted-foreground">
+                      {redirect.hits}
+                      {redirect.last_hit_at && (
+                        <span className="block text-xs">{new Date(redirect.last_hit_at).toLocaleDateString('ru-RU')}</span>
+                      )}
+                    </td>
+                    <td className="p-4 text-right">
+                      <button
+                        onClick={() => handleDelete(redirect)}
+                        className="p-2 text-muted-foreground hover:text-red-600 transition"
+                        title="Удалить"
+                      >
+                        <Trash2 className="w-4 h-4" />
+                      </button>
+                    </td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
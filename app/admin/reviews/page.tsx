@@ -0,0 +1,165 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Check, X, Trash2, Star } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface Review {
+  id: number
+  product_id: number
+  author: string
+  hotel_name: string
+  rating: number
+  text: string
+  photos: string[]
+  status: 'pending' | 'approved' | 'rejected'
+  moderation_note?: string
+  created_at: string
+  version: number
+}
+
+const statusLabels: Record<Review['status'], string> = {
+  pending: 'На модерации',
+  approved: 'Опубликованные',
+  rejected: 'Отклонённые',
+}
+
+export default function ReviewsPage() {
+  const [reviews, setReviews] = useState<Review[]>([])
+  const [status, setStatus] = useState<Review['status']>('pending')
+  const [loading, setLoading] = useState(true)
+
+  useEffect(() => {
+    fetchReviews()
+  }, [status])
+
+  const fetchReviews = async () => {
+    setLoading(true)
+    try {
+      const res = await adminFetch(`${API_URL}/admin/reviews?status=${status}`)
+      setReviews(await res.json())
+    } catch (error) {
+      console.error('Error fetching reviews:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const moderate = async (review: Review, newStatus: Review['status']) => {
+    const patch: Record<string, string> = { status: newStatus }
+    if (newStatus === 'rejected') {
+      const note = prompt('Причина отклонения (необязательно)')
+      if (note === null) return
+      patch.moderation_note = note
+    }
+
+    const res = await adminFetch(`${API_URL}/admin/reviews/${review.id}`, {
+      method: 'PATCH',
+      headers: {
+        'Content-Type': 'application/merge-patch+json',
+        'If-Match': `"${review.version}"`,
+      },
+      body: JSON.stringify(patch),
+    })
+    if (res.ok) {
+      setReviews(reviews.filter(r => r.id !== review.id))
+    } else if (res.status === 412) {
+      alert('Отзыв был изменён другим пользователем. Обновите страницу.')
+    } else {
+      alert('Ошибка при сохранении')
+    }
+  }
+
+  const handleDelete = async (review: Review) => {
+    if (!confirm('Удалить отзыв навсегда?')) return
+    const res = await adminFetch(`${API_URL}/admin/reviews/${review.id}`, {
+      method: 'DELETE',
+      headers: { 'If-Match': `"${review.version}"` },
+    })
+    if (res.ok) {
+      setReviews(reviews.filter(r => r.id !== review.id))
+    } else {
+      alert('Ошибка при удалении')
+    }
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="mb-8">
+          <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Отзывы</h1>
+          <p className="text-muted-foreground">Рейтинг товара считается только по опубликованным отзывам</p>
+        </div>
+
+        <div className="flex gap-4 mb-6">
+          {(Object.keys(statusLabels) as Review['status'][]).map((s) => (
+            <button
+              key={s}
+              onClick={() => setStatus(s)}
+              className={s === status ? 'font-semibold text-primary' : 'text-muted-foreground hover:text-foreground'}
+            >
+              {statusLabels[s]}
+            </button>
+          ))}
+        </div>
+
+        {loading ? (
+          <p className="text-muted-foreground">Загрузка...</p>
+        ) : reviews.length === 0 ? (
+          <div className="bg-card border border-border rounded-lg p-12 text-center">
+            <Star className="w-16 h-16 text-muted-foreground mx-auto mb-4" />
+            <p className="text-muted-foreground">Отзывов нет</p>
+          </div>
+        ) : (
+          <div className="space-y-4">
+            {reviews.map((review) => (
+              <div key={review.id} className="bg-card border border-border rounded-lg p-6 flex justify-between gap-6">
+                <div className="space-y-2">
+                  <p className="text-sm text-muted-foreground">
+                    <Link href={`/products/${review.product_id}`} className="hover:text-foreground">Товар #{review.product_id}</Link>
+                    {' · '}{new Date(review.created_at).toLocaleString('ru-RU')}
+                  </p>
+                  <p className="font-semibold text-foreground">
+                    {review.author}{review.hotel_name && `, ${review.hotel_name}`} · {'★'.repeat(review.rating)}
+                  </p>
+                  <p className="text-foreground whitespace-pre-line">{review.text}</p>
+                  {review.photos.length > 0 && (
+                    <div className="flex gap-2 flex-wrap">
+                      {review.photos.map((photo) => (
+                        <img key={photo} src={`${API_URL.replace('/api', '')}${photo}`} alt="" className="w-20 h-20 object-cover rounded" />
+                      ))}
+                    </div>
+                  )}
+                  {review.moderation_note && <p className="text-sm text-muted-foreground">Причина: {review.moderation_note}</p>}
+                </div>
+                <div className="flex gap-2 items-start">
+                  {review.status !== 'approved' && (
+                    <button onClick={() => moderate(review, 'approved')} className="p-2 text-green-700 hover:bg-muted rounded" title="Опубликовать">
+                      <Check className="w-5 h-5" />
+                    </button>
+                  )}
+                  {review.status !== 'rejected' && (
+                    <button onClick={() => moderate(review, 'rejected')} className="p-2 text-red-600 hover:bg-muted rounded" title="Отклонить">
+                      <X className="w-5 h-5" />
+                    </button>
+                  )}
+                  <button onClick={() => handleDelete(review)} className="p-2 text-muted-foreground hover:bg-muted rounded" title="Удалить">
+                    <Trash2 className="w-5 h-5" />
+                  </button>
+                </div>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,259 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Save, RotateCcw, ExternalLink, Wrench } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+const BACKEND_URL = API_URL.replace(/\/api$/, '')
+
+interface Setting {
+  key: string
+  value: string
+  default: string
+  is_default: boolean
+  updated_at?: string
+}
+
+export default function SettingsPage() {
+  const [robots, setRobots] = useState<Setting | null>(null)
+  const [value, setValue] = useState('')
+  const [loading, setLoading] = useState(true)
+  const [saving, setSaving] = useState(false)
+  const [message, setMessage] = useState('')
+  const [maintenance, setMaintenance] = useState({ mode: 'off', message: '', retry_after: '3600' })
+  const [maintenanceSaving, setMaintenanceSaving] = useState(false)
+  const [maintenanceMessage, setMaintenanceMessage] = useState('')
+
+  useEffect(() => {
+    fetchSettings()
+  }, [])
+
+  const fetchSettings = async () => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/settings`)
+      const settings: Setting[] = await res.json()
+      const setting = settings.find((s) => s.key === 'robots_txt') || null
+      setRobots(setting)
+      setValue(setting?.value || '')
+      const valueOf = (key: string) => settings.find((s) => s.key === key)?.value || ''
+      setMaintenance({
+        mode: valueOf('maintenance_mode') || 'off',
+        message: valueOf('maintenance_message'),
+        retry_after: valueOf('maintenance_retry_after') || '3600',
+      })
+    } catch (error) {
+      console.error('Error fetching settings:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const save = async (newValue: string | null) => {
+    setSaving(true)
+    setMessage('')
+    try {
+      const res = await adminFetch(`${API_URL}/admin/settings/robots_txt`, {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ value: newValue }),
+      })
+      if (!res.ok) {
+        setMessage(await res.text())
+        return
+      }
+      const setting: Setting = await res.json()
+      setRobots(setting)
+      setValue(setting.value)
+      setMessage(newValue === null ? 'Восстановлено значение по умолчанию' : 'Сохранено')
+    } catch (error) {
+      console.error('Error saving robots.txt:', error)
+      setMessage('Не удалось сохранить')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  const saveMaintenance = async (mode: string) => {
+    setMaintenanceSaving(true)
+    setMaintenanceMessage('')
+    try {
+      // The mode goes last so the site closes with the new message already in place
+      const values: [string, string][] = [
+        ['maintenance_message', maintenance.message],
+        ['maintenance_retry_after', maintenance.retry_after],
+        ['maintenance_mode', mode],
+      ]
+      for (const [key, value] of values) {
+        const res = await adminFetch(`${API_URL}/admin/settings/${key}`, {
+          method: 'PUT',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ value }),
+        })
+        if (!res.ok) {
+          setMaintenanceMessage(await res.text())
+          return
+        }
+      }
+      setMaintenance({ ...maintenance, mode })
+      setMaintenanceMessage(mode === 'on' ? 'Сайт закрыт на обслуживание' : 'Сохранено')
+    } catch (error) {
+      console.error('Error saving maintenance mode:', error)
+      setMaintenanceMessage('Не удалось сохранить')
+    } finally {
+      setMaintenanceSaving(false)
+    }
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="mb-8">
+          <h1 className="text-4xl font-serif font-bold text-foreground mb-2">SEO и настройки</h1>
+          <p className="text-muted-foreground">Карта сайта строится автоматически из опубликованных товаров, коллекций и категорий</p>
+        </div>
+
+        <div className="bg-card border border-border rounded-lg p-6 mb-6">
+          <div className="flex flex-wrap items-center justify-between gap-4 mb-2">
+            <h2 className="text-xl font-semibold text-foreground">Режим обслуживания</h2>
+            {maintenance.mode === 'on' && (
+              <span className="inline-flex items-center gap-1 px-2 py-1 rounded-full text-xs font-medium bg-red-100 text-red-800 dark:bg-red-900 dark:text-red-200">
+                <Wrench className="w-3 h-3" />
+                Сайт закрыт
+              </span>
+            )}
+          </div>
+          <p className="text-sm text-muted-foreground mb-4">
+            Посетители видят сообщение вместо страниц сайта, публичный API отвечает 503. Админ-панель продолжает работать,
+            а вошедшие администраторы видят закрытый сайт как обычно и могут проверить его перед открытием.
+          </p>
+          <div className="grid md:grid-cols-[1fr_auto] gap-4 mb-4">
+            <div>
+              <label className="block text-sm font-medium text-foreground mb-2">Сообщение</label>
+              <textarea
+                value={maintenance.message}
+                onChange={(e) => setMaintenance({ ...maintenance, message: e.target.value })}
+                rows={3}
+                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+              />
+            </div>
+            <div>
+              <label className="block text-sm font-medium text-foreground mb-2">Повторить через, сек</label>
+              <input
+                type="number"
+                min={1}
+                value={maintenance.retry_after}
+                onChange={(e) => setMaintenance({ ...maintenance, retry_after: e.target.value })}
+                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+              />
+            </div>
+          </div>
+          <div className="flex flex-wrap items-center gap-4">
+            {maintenance.mode === 'on' ? (
+              <button
+                onClick={() => saveMaintenance('off')}
+                disabled={maintenanceSaving}
+                className="flex items-center gap-2 px-6 py-2 bg-primary text-primary-foreground rounded-lg hover:bg-primary/90 transition disabled:opacity-50"
+              >
+                Открыть сайт
+              </button>
+            ) : (
+              <button
+                onClick={() => confirm('Закрыть сайт для посетителей?') && saveMaintenance('on')}
+                disabled={maintenanceSaving}
+                className="flex items-center gap-2 px-6 py-2 bg-destructive text-white rounded-lg hover:opacity-90 transition disabled:opacity-50"
+              >
+                <Wrench className="w-4 h-4" />
+                Включить режим обслуживания
+              </button>
+            )}
+            <button
+              onClick={() => saveMaintenance(maintenance.mode)}
+              disabled={maintenanceSaving}
+              className="flex items-center gap-2 px-4 py-2 border border-border rounded-lg hover:bg-muted transition disabled:opacity-50"
+            >
+              <Save className="w-4 h-4" />
+              Сохранить сообщение
+            </button>
+            {maintenance.mode === 'on' && (
+              <a href="/" target="_blank" rel="noopener noreferrer" className="inline-flex items-center gap-2 text-primary hover:underline">
+                <ExternalLink className="w-4 h-4" />
+                Открыть сайт в новой вкладке
+              </a>
+            )}
+            {maintenanceMessage && <p className="text-sm text-muted-foreground">{maintenanceMessage}</p>}
+          </div>
+        </div>
+
+        <div className="bg-card border border-border rounded-lg p-6 mb-6">
+          <h2 className="text-xl font-semibold text-foreground mb-2">Карта сайта</h2>
+          <p className="text-sm text-muted-foreground mb-4">
+            Страницы, закрытые активной заглушкой, в карту сайта не попадают.
+          </p>
+          <a href={`${BACKEND_URL}/sitemap.xml`} target="_blank" rel="noopener noreferrer" className="inline-flex items-center gap-2 text-primary hover:underline">
+            <ExternalLink className="w-4 h-4" />
+            Открыть sitemap.xml
+          </a>
+        </div>
+
+        <div className="bg-card border border-border rounded-lg p-6">
+          <div className="flex flex-wrap justify-between gap-4 mb-4">
+            <div>
+              <h2 className="text-xl font-semibold text-foreground mb-1">robots.txt</h2>
+              {robots && (
+                <p className="text-sm text-muted-foreground">
+                  {robots.is_default
+                    ? 'Используется значение по умолчанию'
+                    : `Изменено ${new Date(robots.updated_at!).toLocaleString('ru-RU')}`}
+                </p>
+              )}
+            </div>
+            <a href={`${BACKEND_URL}/robots.txt`} target="_blank" rel="noopener noreferrer" className="inline-flex items-center gap-2 text-primary hover:underline h-fit">
+              <ExternalLink className="w-4 h-4" />
+              Открыть
+            </a>
+          </div>
+
+          {loading ? (
+            <p className="text-muted-foreground">Загрузка...</p>
+          ) : (
+            <>
+              <textarea
+                value={value}
+                onChange={(e) => setValue(e.target.value)}
+                rows={14}
+                spellCheck={false}
+                className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground font-mono text-sm"
+              />
+              <div className="flex flex-wrap items-center gap-4 mt-4">
+                <button
+                  onClick={() => save(value)}
+                  disabled={saving}
+                  className="flex items-center gap-2 px-6 py-2 bg-primary text-primary-foreground rounded-lg hover:bg-primary/90 transition disabled:opacity-50"
+                >
+                  <Save className="w-4 h-4" />
+                  {saving ? 'Сохранение...' : 'Сохранить'}
+                </button>
+                <button
+                  onClick={() => save(null)}
+                  disabled={saving || robots?.is_default}
+                  className="flex items-center gap-2 px-4 py-2 border border-border rounded-lg hover:bg-muted transition disabled:opacity-50"
+                >
+                  <RotateCcw className="w-4 h-4" />
+                  По умолчанию
+                </button>
+                {message && <p className="text-sm text-muted-foreground">{message}</p>}
+              </div>
+            </>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
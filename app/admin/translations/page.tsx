@@ -0,0 +1,262 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Languages, Save, X } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+type EntityType = 'products' | 'categories' | 'collections' | 'faqs' | 'placeholders' | 'projects'
+
+interface MissingTranslation {
+  type: EntityType
+  id: number
+  label: string
+  locale: string
+  missing: string[]
+}
+
+const typeLabels: Record<EntityType, string> = {
+  products: 'Товар',
+  categories: 'Категория',
+  collections: 'Коллекция',
+  faqs: 'FAQ',
+  placeholders: 'Заглушка',
+  projects: 'Проект',
+}
+
+const translatableFields: Record<EntityType, string[]> = {
+  products: ['name', 'category', 'description', 'color', 'dimensions', 'material', 'features', 'meta_title', 'meta_description'],
+  categories: ['name', 'description', 'meta_title', 'meta_description'],
+  collections: ['name', 'description', 'meta_title', 'meta_description'],
+  faqs: ['question', 'answer'],
+  placeholders: ['title', 'message'],
+  projects: ['hotel_name', 'description', 'client_quote', 'client_name'],
+}
+
+const fieldLabels: Record<string, string> = {
+  name: 'Название',
+  category: 'Категория',
+  description: 'Описание',
+  color: 'Цвет',
+  dimensions: 'Размеры',
+  material: 'Материал',
+  features: 'Особенности (по одной на строку)',
+  question: 'Вопрос',
+  answer: 'Ответ',
+  title: 'Заголовок',
+  message: 'Сообщение',
+  meta_title: 'SEO-заголовок',
+  meta_description: 'SEO-описание',
+  hotel_name: 'Отель',
+  client_quote: 'Отзыв клиента',
+  client_name: 'Автор отзыва',
+}
+
+const multilineFields = ['description', 'features', 'answer', 'message', 'meta_description', 'client_quote']
+
+export default function TranslationsPage() {
+  const [items, setItems] = useState<MissingTranslation[]>([])
+  const [loading, setLoading] = useState(true)
+  const [typeFilter, setTypeFilter] = useState('')
+  const [localeFilter, setLocaleFilter] = useState('')
+  const [editing, setEditing] = useState<MissingTranslation | null>(null)
+  const [values, setValues] = useState<Record<string, string>>({})
+  const [saving, setSaving] = useState(false)
+
+  useEffect(() => {
+    fetchMissing()
+  }, [typeFilter, localeFilter])
+
+  const fetchMissing = async () => {
+    try {
+      const params = new URLSearchParams()
+      if (typeFilter) params.set('type', typeFilter)
+      if (localeFilter) params.set('locale', localeFilter)
+      const res = await adminFetch(`${API_URL}/admin/translations/missing?${params}`)
+      const data = await res.json()
+      setItems(data)
+    } catch (error) {
+      console.error('Error fetching missing translations:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const locales = Array.from(new Set(items.map(i => i.locale)))
+
+  const startEditing = async (item: MissingTranslation) => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/translations/${item.type}/${item.id}`)
+      const data = await res.json()
+      const current = data[item.locale] || {}
+      const initial: Record<string, string> = {}
+      for (const field of translatableFields[item.type]) {
+        const value = current[field]
+        initial[field] = Array.isArray(value) ? value.join('\n') : value || ''
+      }
+      setValues(initial)
+      setEditing(item)
+    } catch (error) {
+      console.error('Error fetching translations:', error)
+    }
+  }
+
+  const handleSave = async (e: React.FormEvent) => {
+    e.preventDefault()
+    if (!editing) return
+    setSaving(true)
+
+    const payload: Record<string, string | string[]> = {}
+    for (const [field, value] of Object.entries(values)) {
+      payload[field] = field === 'features'
+        ? value.split('\n').map(f => f.trim()).filter(f => f)
+        : value
+    }
+
+    try {
+      const res = await adminFetch(`${API_URL}/admin/translations/${editing.type}/${editing.id}/${editing.locale}`, {
+        method: 'PUT',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(payload),
+      })
+
+      if (res.ok) {
+        setEditing(null)
+        fetchMissing()
+      } else {
+        alert('Ошибка при сохранении перевода')
+      }
+    } catch (error) {
+      console.error('Error saving translation:', error)
+      alert('Ошибка при сохранении перевода')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  if (loading) {
+    return (
+      <div className="min-h-screen bg-background">
+        <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+          <p className="text-muted-foreground">Загрузка...</p>
+        </div>
+      </div>
+    )
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="mb-8">
+          <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Переводы</h1>
+          <p className="text-muted-foreground">
+            Записи с непереведёнными полями. Всего: {items.length}
+          </p>
+        </div>
+
+        <div className="flex gap-4 mb-6">
+          <select
+            value={typeFilter}
+            onChange={(e) => setTypeFilter(e.target.value)}
+            className="px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          >
+            <option value="">Все типы</option>
+            {Object.entries(typeLabels).map(([type, label]) => (
+              <option key={type} value={type}>{label}</option>
+            ))}
+          </select>
+          <select
+            value={localeFilter}
+            onChange={(e) => setLocaleFilter(e.target.value)}
+            className="px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          >
+            <option value="">Все языки</option>
+            {(localeFilter ? [localeFilter] : locales).map(locale => (
+              <option key={locale} value={locale}>{locale}</option>
+            ))}
+          </select>
+        </div>
+
+        {editing && (
+          <form onSubmit={handleSave} className="bg-card border border-border rounded-lg p-6 mb-6 space-y-4">
+            <div className="flex items-center justify-between">
+              <h2 className="text-xl font-semibold text-foreground">
+                {typeLabels[editing.type]} «{editing.label}» · {editing.locale}
+              </h2>
+              <button type="button" onClick={() => setEditing(null)} className="p-2 text-muted-foreground hover:text-foreground">
+                <X className="w-5 h-5" />
+              </button>
+            </div>
+            {translatableFields[editing.type].map(field => (
+              <div key={field}>
+                <label className="block text-sm font-medium text-foreground mb-2">{fieldLabels[field]}</label>
+                {multilineFields.includes(field) ? (
+                  <textarea
+                    value={values[field] || ''}
+                    onChange={(e) => setValues({ ...values, [field]: e.target.value })}
+                    rows={4}
+                    className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+                  />
+                ) : (
+                  <input
+                    type="text"
+                    value={values[field] || ''}
+                    onChange={(e) => setValues({ ...values, [field]: e.target.value })}
+                    className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+                  />
+                )}
+              </div>
+            ))}
+            <button
+              type="submit"
+              disabled={saving}
+              className="flex items-center gap-2 px-6 py-2 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition disabled:opacity-50"
+            >
+              <Save className="w-4 h-4" />
+              {saving ? 'Сохранение...' : 'Сохранить'}
+            </button>
+          </form>
+        )}
+
+        {items.length === 0 ? (
+          <div className="bg-card border border-border rounded-lg p-12 text-center">
+            <Languages className="w-16 h-16 text-muted-foreground mx-auto mb-4" />
+            <p className="text-muted-foreground">Все тексты переведены</p>
+          </div>
+        ) : (
+          <div className="space-y-4">
+            {items.map((item) => (
+              <div
+                key={`${item.type}-${item.id}-${item.locale}`}
+                className="bg-card border border-border rounded-lg p-6 flex items-center justify-between gap-4"
+              >
+                <div>
+                  <p className="text-sm text-muted-foreground">{typeLabels[item.type]} · {item.locale}</p>
+                  <p className="font-semibold text-foreground">{item.label}</p>
+                  <p className="text-sm text-muted-foreground">
+                    Не переведено: {item.missing.map(f => fieldLabels[f] || f).join(', ')}
+                  </p>
+                </div>
+                <button
+                  onClick={() => startEditing(item)}
+                  className="px-4 py-2 text-primary hover:bg-primary/10 rounded-lg transition"
+                >
+                  Перевести
+                </button>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  )
+}
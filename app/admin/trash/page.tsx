@@ -0,0 +1,164 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Trash2, RotateCcw } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface TrashItem {
+  type: 'products' | 'categories' | 'collections'
+  id: number
+  name: string
+  deleted_at: string
+  purge_at: string
+}
+
+const typeLabels: Record<TrashItem['type'], string> = {
+  products: 'Товар',
+  categories: 'Категория',
+  collections: 'Коллекция',
+}
+
+export default function TrashPage() {
+  const [items, setItems] = useState<TrashItem[]>([])
+  const [loading, setLoading] = useState(true)
+
+  useEffect(() => {
+    fetchTrash()
+  }, [])
+
+  const fetchTrash = async () => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/trash`)
+      const data = await res.json()
+      setItems(data)
+    } catch (error) {
+      console.error('Error fetching trash:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const removeFromList = (item: TrashItem) => {
+    setItems(items.filter(i => !(i.type === item.type && i.id === item.id)))
+  }
+
+  const handleRestore = async (item: TrashItem) => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/trash/${item.type}/${item.id}/restore`, {
+        method: 'POST',
+      })
+
+      if (res.ok) {
+        removeFromList(item)
+      } else {
+        alert('Ошибка при восстановлении')
+      }
+    } catch (error) {
+      console.error('Error restoring item:', error)
+      alert('Ошибка при восстановлении')
+    }
+  }
+
+  const handlePurge = async (item: TrashItem) => {
+    if (!confirm(`Удалить «${item.name}» навсегда? Это действие нельзя отменить.`)) {
+      return
+    }
+
+    try {
+      const res = await adminFetch(`${API_URL}/admin/trash/${item.type}/${item.id}`, {
+        method: 'DELETE',
+      })
+
+      if (res.ok) {
+        removeFromList(item)
+      } else {
+        alert('Ошибка при удалении')
+      }
+    } catch (error) {
+      console.error('Error purging item:', error)
+      alert('Ошибка при удалении')
+    }
+  }
+
+  const formatDate = (dateString: string) => {
+    const date = new Date(dateString)
+    return date.toLocaleString('ru-RU', {
+      year: 'numeric',
+      month: 'long',
+      day: 'numeric',
+      hour: '2-digit',
+      minute: '2-digit',
+    })
+  }
+
+  if (loading) {
+    return (
+      <div className="min-h-screen bg-background">
+        <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+          <p className="text-muted-foreground">Загрузка...</p>
+        </div>
+      </div>
+    )
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-6">
+          <ArrowLeft className="w-4 h-4" />
+          Назад в админ-панель
+        </Link>
+
+        <div className="mb-8">
+          <h1 className="text-4xl font-serif font-bold text-foreground mb-2">Корзина</h1>
+          <p className="text-muted-foreground">
+            Удалённые товары, категории и коллекции. Элементов: {items.length}
+          </p>
+        </div>
+
+        {items.length === 0 ? (
+          <div className="bg-card border border-border rounded-lg p-12 text-center">
+            <Trash2 className="w-16 h-16 text-muted-foreground mx-auto mb-4" />
+            <p className="text-muted-foreground">Корзина пуста</p>
+          </div>
+        ) : (
+          <div className="space-y-4">
+            {items.map((item) => (
+              <div
+                key={`${item.type}-${item.id}`}
+                className="bg-card border border-border rounded-lg p-6 flex items-center justify-between gap-4"
+              >
+                <div>
+                  <p className="text-sm text-muted-foreground">{typeLabels[item.type]}</p>
+                  <p className="font-semibold text-foreground">{item.name}</p>
+                  <p className="text-sm text-muted-foreground">
+                    Удалено {formatDate(item.deleted_at)} · будет удалено навсегда {formatDate(item.purge_at)}
+                  </p>
+                </div>
+                <div className="flex gap-2">
+                  <button
+                    onClick={() => handleRestore(item)}
+                    className="p-2 text-primary hover:bg-primary/10 rounded-lg transition"
+                    title="Восстановить"
+                  >
+                    <RotateCcw className="w-5 h-5" />
+                  </button>
+                  <button
+                    onClick={() => handlePurge(item)}
+                    className="p-2 text-red-500 hover:bg-red-50 dark:hover:bg-red-950 rounded-lg transition"
+                    title="Удалить навсегда"
+                  >
+                    <Trash2 className="w-5 h-5" />
+                  </button>
+                </div>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,151 @@
+'use client'
+
+import { useState, useEffect, useCallback } from 'react'
+import Link from 'next/link'
+import { ArrowLeft, Plus, Trash2 } from 'lucide-react'
+import { adminFetch, getAdminUsername } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface AdminUser {
+  id: number
+  username: string
+  created_at: string
+}
+
+export default function AdminUsersPage() {
+  const [admins, setAdmins] = useState<AdminUser[]>([])
+  const [loading, setLoading] = useState(true)
+  const [form, setForm] = useState({ username: '', password: '' })
+  const [saving, setSaving] = useState(false)
+
+  const fetchAdmins = useCallback(async () => {
+    try {
+      const res = await adminFetch(`${API_URL}/admin/users`)
+      setAdmins(await res.json())
+    } catch (error) {
+      console.error('Error fetching admins:', error)
+    } finally {
+      setLoading(false)
+    }
+  }, [])
+
+  useEffect(() => {
+    fetchAdmins()
+  }, [fetchAdmins])
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setSaving(true)
+    try {
+      const res = await adminFetch(`${API_URL}/admin/users`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(form),
+      })
+      if (!res.ok) {
+        alert(`Ошибка при создании: ${await res.text()}`)
+        return
+      }
+      setForm({ username: '', password: '' })
+      fetchAdmins()
+    } catch (error) {
+      console.error('Error creating admin:', error)
+      alert('Ошибка при создании администратора')
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  const handleDelete = async (admin: AdminUser) => {
+    if (!confirm(`Удалить администратора ${admin.username}?`)) return
+
+    try {
+      const res = await adminFetch(`${API_URL}/admin/users/${admin.id}`, { method: 'DELETE' })
+      if (!res.ok) {
+        alert(`Ошибка при удалении: ${await res.text()}`)
+        return
+      }
+      fetchAdmins()
+    } catch (error) {
+      console.error('Error deleting admin:', error)
+      alert('Ошибка при удалении администратора')
+    }
+  }
+
+  const current = getAdminUsername()
+
+  return (
+    <div className="min-h-screen bg-background">
+      <div className="max-w-4xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
+        <Link href="/admin" className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition mb-2">
+          <ArrowLeft className="w-4 h-4" />
+          Назад к панели
+        </Link>
+        <h1 className="text-4xl font-serif font-bold text-foreground mb-8">Администраторы</h1>
+
+        <form onSubmit={handleCreate} className="bg-card border border-border rounded-lg p-6 mb-8 flex flex-wrap items-center gap-3">
+          <input
+            required
+            placeholder="Имя пользователя"
+            value={form.username}
+            onChange={(e) => setForm({ ...form, username: e.target.value })}
+            className="px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+          <input
+            type="password"
+            required
+            minLength={8}
+            autoComplete="new-password"
+            placeholder="Пароль (не короче 8 символов)"
+            value={form.password}
+            onChange={(e) => setForm({ ...form, password: e.target.value })}
+            className="px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+          <button
+            type="submit"
+            disabled={saving}
+            className="flex items-center gap-2 px-6 py-2 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition disabled:opacity-50"
+          >
+            <Plus className="w-4 h-4" />
+            Добавить
+          </button>
+        </form>
+
+        <div className="bg-card border border-border rounded-lg p-6">
+          {loading ? (
+            <p className="text-muted-foreground">Загрузка...</p>
+          ) : (
+            <table className="w-full text-sm">
+              <thead>
+                <tr className="text-left text-muted-foreground border-b border-border">
+                  <th className="py-2 pr-4">Имя пользователя</th>
+                  <th className="py-2 pr-4">Создан</th>
+                  <th className="py-2"></th>
+                </tr>
+              </thead>
+              <tbody>
+                {admins.map((admin) => (
+                  <tr key={admin.id} className="border-b border-border last:border-0">
+                    <td className="py-2 pr-4 text-foreground">{admin.username}</td>
+                    <td className="py-2 pr-4 text-muted-foreground">{new Date(admin.created_at).toLocaleString('ru-RU')}</td>
+                    <td className="py-2 text-right">
+                      {admin.username !== current && (
+                        <button
+                          onClick={() => handleDelete(admin)}
+                          className="p-2 text-destructive hover:bg-destructive/10 rounded transition"
+                        >
+                          <Trash2 className="w-4 h-4" />
+                        </button>
+                      )}
+                    </td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          )}
+        </div>
+      </div>
+    </div>
+  )
+}
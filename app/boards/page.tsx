@@ -0,0 +1,238 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import Header from '@/components/header'
+import Footer from '@/components/footer'
+import { Trash2, Share2, Send, FileText } from 'lucide-react'
+import { boardHeaders, getCustomerToken } from '@/lib/customer-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface BoardItem {
+  product_id: number
+  quantity: number
+  notes: string
+  product?: {
+    name: string
+    price: number
+    display_price?: { amount: number; currency: string }
+  }
+}
+
+interface Board {
+  id: number
+  name: string
+  share_token: string | null
+  items: BoardItem[]
+  version: number
+}
+
+const inputClass = 'w-full px-4 py-3 border border-border rounded-lg bg-background text-foreground placeholder-muted-foreground focus:outline-none focus:ring-2 focus:ring-primary'
+
+export default function BoardsPage() {
+  const [boards, setBoards] = useState<Board[]>([])
+  const [loading, setLoading] = useState(true)
+  const [contact, setContact] = useState({ name: '', email: '', phone: '', message: '' })
+  const [result, setResult] = useState('')
+  const loggedIn = typeof window !== 'undefined' && !!getCustomerToken()
+
+  useEffect(() => {
+    fetchBoards()
+  }, [])
+
+  const fetchBoards = async () => {
+    try {
+      const res = await fetch(`${API_URL}/boards`, { headers: boardHeaders() })
+      if (res.ok) {
+        setBoards(await res.json())
+      }
+    } catch (error) {
+      console.error('Error fetching boards:', error)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  const replaceBoard = (board: Board) => {
+    setBoards(boards.map(b => (b.id === board.id ? board : b)))
+  }
+
+  const updateItem = async (board: Board, item: BoardItem) => {
+    const res = await fetch(`${API_URL}/boards/${board.id}/items/${item.product_id}`, {
+      method: 'PUT',
+      headers: {
+        'Content-Type': 'application/json',
+        ...boardHeaders(),
+      },
+      body: JSON.stringify({ quantity: item.quantity, notes: item.notes }),
+    })
+    if (res.ok) {
+      replaceBoard(await res.json())
+    }
+  }
+
+  const removeItem = async (board: Board, productId: number) => {
+    const res = await fetch(`${API_URL}/boards/${board.id}/items/${productId}`, {
+      method: 'DELETE',
+      headers: boardHeaders(),
+    })
+    if (res.ok) {
+      replaceBoard(await res.json())
+    }
+  }
+
+  const deleteBoard = async (board: Board) => {
+    if (!confirm(`Удалить подборку «${board.name}»?`)) return
+    const res = await fetch(`${API_URL}/boards/${board.id}`, {
+      method: 'DELETE',
+      headers: {
+        'If-Match': `"${board.version}"`,
+        ...boardHeaders(),
+      },
+    })
+    if (res.ok) {
+      setBoards(boards.filter(b => b.id !== board.id))
+    }
+  }
+
+  const toggleShare = async (board: Board) => {
+    const res = await fetch(`${API_URL}/boards/${board.id}/share`, {
+      method: board.share_token ? 'DELETE' : 'POST',
+      headers: boardHeaders(),
+    })
+    if (res.ok) {
+      replaceBoard(await res.json())
+    }
+  }
+
+  const convert = async (board: Board, target: 'contact' | 'quote') => {
+    setResult('')
+    const res = await fetch(`${API_URL}/boards/${board.id}/${target}`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...boardHeaders(),
+      },
+      body: JSON.stringify(contact),
+    })
+    if (!res.ok) {
+      alert(await res.text())
+      return
+    }
+    const data = await res.json()
+    setResult(data.quote
+      ? `Запрос на коммерческое предложение ${data.quote.number} отправлен. Менеджер свяжется с вами.`
+      : 'Запрос отправлен. Менеджер свяжется с вами.')
+  }
+
+  const shareUrl = (token: string) => `${window.location.origin}/boards/shared/${token}`
+
+  return (
+    <div className="min-h-screen bg-background">
+      <Header />
+
+      <main className="max-w-5xl mx-auto px-4 sm:px-6 lg:px-8 py-12 sm:py-16">
+        <h1 className="text-3xl sm:text-4xl font-serif font-bold text-foreground mb-8 text-center">Мои подборки</h1>
+
+        {result && <p className="text-green-700 text-center mb-8">{result}</p>}
+
+        {loading ? (
+          <p className="text-muted-foreground text-center">Загрузка...</p>
+        ) : boards.length === 0 ? (
+          <p className="text-muted-foreground text-center">
+            Подборок пока нет. Добавляйте товары из <Link href="/catalog" className="text-primary hover:underline">каталога</Link>.
+          </p>
+        ) : (
+          <div className="space-y-12">
+            {!loggedIn && (
+              <section className="bg-card border border-border rounded-lg p-6 grid md:grid-cols-2 gap-4">
+                <p className="md:col-span-2 text-muted-foreground">
+                  Контактные данные для запроса. <Link href="/account" className="text-primary hover:underline">Войдите</Link>, чтобы сохранить подборки в личном кабинете.
+                </p>
+                <input placeholder="Имя" value={contact.name} onChange={(e) => setContact({ ...contact, name: e.target.value })} className={inputClass} />
+                <input type="email" placeholder="Email" value={contact.email} onChange={(e) => setContact({ ...contact, email: e.target.value })} className={inputClass} />
+                <input placeholder="Телефон" value={contact.phone} onChange={(e) => setContact({ ...contact, phone: e.target.value })} className={inputClass} />
+              </section>
+            )}
+
+            {boards.map((board) => (
+              <section key={board.id} className="bg-card border border-border rounded-lg p-6 sm:p-8">
+                <div className="flex flex-wrap items-center justify-between gap-4 mb-6">
+                  <h2 className="text-2xl font-serif font-bold text-foreground">{board.name}</h2>
+                  <div className="flex gap-4">
+                    <button onClick={() => toggleShare(board)} className="flex items-center gap-2 text-muted-foreground hover:text-foreground">
+                      <Share2 className="w-4 h-4" />
+                      {board.share_token ? 'Закрыть доступ' : 'Поделиться'}
+                    </button>
+                    <button onClick={() => deleteBoard(board)} className="flex items-center gap-2 text-red-600 hover:opacity-80">
+                      <Trash2 className="w-4 h-4" />
+                      Удалить
+                    </button>
+                  </div>
+                </div>
+
+                {board.share_token && (
+                  <p className="text-sm text-muted-foreground mb-6 break-all">Ссылка для просмотра: {shareUrl(board.share_token)}</p>
+                )}
+
+                {board.items.length === 0 ? (
+                  <p className="text-muted-foreground">В подборке нет товаров</p>
+                ) : (
+                  <div className="space-y-4 mb-6">
+                    {board.items.map((item) => (
+                      <div key={item.product_id} className="grid sm:grid-cols-[1fr_6rem_1fr_auto] gap-4 items-center">
+                        <Link href={`/products/${item.product_id}`} className="font-semibold text-foreground hover:text-primary">
+                          {item.product?.name || 'Товар недоступен'}
+                        </Link>
+                        <input
+                          type="number"
+                          min={1}
+                          value={item.quantity}
+                          onChange={(e) => replaceBoard({ ...board, items: board.items.map(i => (i.product_id === item.product_id ? { ...i, quantity: parseInt(e.target.value) || 1 } : i)) })}
+                          onBlur={() => updateItem(board, item)}
+                          className="px-3 py-2 border border-border rounded bg-background text-foreground"
+                        />
+                        <input
+                          placeholder="Заметка"
+                          value={item.notes}
+                          onChange={(e) => replaceBoard({ ...board, items: board.items.map(i => (i.product_id === item.product_id ? { ...i, notes: e.target.value } : i)) })}
+                          onBlur={() => updateItem(board, item)}
+                          className="px-3 py-2 border border-border rounded bg-background text-foreground"
+                        />
+                        <button onClick={() => removeItem(board, item.product_id)} className="text-muted-foreground hover:text-red-600">
+                          <Trash2 className="w-4 h-4" />
+                        </button>
+                      </div>
+                    ))}
+                  </div>
+                )}
+
+                {board.items.length > 0 && (
+                  <div className="flex flex-wrap gap-4">
+                    <button
+                      onClick={() => convert(board, 'contact')}
+                      className="flex items-center gap-2 px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition"
+                    >
+                      <Send className="w-5 h-5" />
+                      Отправить запрос
+                    </button>
+                    <button
+                      onClick={() => convert(board, 'quote')}
+                      className="flex items-center gap-2 px-6 py-3 border border-border rounded-lg text-foreground hover:bg-muted transition"
+                    >
+                      <FileText className="w-5 h-5" />
+                      Запросить КП
+                    </button>
+                  </div>
+                )}
+              </section>
+            ))}
+          </div>
+        )}
+      </main>
+
+      <Footer />
+    </div>
+  )
+}
@@ -0,0 +1,73 @@
+import Link from 'next/link'
+import Header from '@/components/header'
+import Footer from '@/components/footer'
+import { notFound } from 'next/navigation'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface SharedBoard {
+  name: string
+  items: {
+    product_id: number
+    quantity: number
+    notes: string
+    product?: { name: string; price: number; image: string }
+  }[]
+  updated_at: string
+}
+
+async function getSharedBoard(token: string): Promise<SharedBoard | null> {
+  try {
+    const res = await fetch(`${API_URL}/boards/shared/${encodeURIComponent(token)}`, { cache: 'no-store' })
+    if (!res.ok) return null
+    return res.json()
+  } catch (error) {
+    console.error('Error fetching shared board:', error)
+    return null
+  }
+}
+
+export default async function SharedBoardPage({ params }: { params: Promise<{ token: string }> }) {
+  const { token } = await params
+  const board = await getSharedBoard(token)
+
+  if (!board) {
+    notFound()
+  }
+
+  return (
+    <div className="min-h-screen bg-background">
+      <Header />
+
+      <main className="max-w-5xl mx-auto px-4 sm:px-6 lg:px-8 py-12 sm:py-16">
+        <h1 className="text-3xl sm:text-4xl font-serif font-bold text-foreground mb-2 text-center">{board.name}</h1>
+        <p className="text-muted-foreground text-center mb-8">
+          Обновлено {new Date(board.updated_at).toLocaleDateString('ru-RU')}
+        </p>
+
+        <div className="space-y-4">
+          {board.items.map((item) => (
+            <div key={item.product_id} className="bg-card border border-border rounded-lg p-6 flex justify-between gap-4">
+              <div>
+                {item.product ? (
+                  <Link href={`/products/${item.product_id}`} className="font-semibold text-foreground hover:text-primary">
+                    {item.product.name}
+                  </Link>
+                ) : (
+                  <p className="font-semibold text-muted-foreground">Товар недоступен</p>
+                )}
+                {item.notes && <p className="text-sm text-muted-foreground mt-1">{item.notes}</p>}
+              </div>
+              <div className="text-right">
+                <p className="text-foreground">× {item.quantity}</p>
+                {item.product && <p className="font-bold text-primary">{item.product.price} ₽</p>}
+              </div>
+            </div>
+          ))}
+        </div>
+      </main>
+
+      <Footer />
+    </div>
+  )
+}
@@ -5,11 +5,14 @@ import Header from '@/components/header'
 import Footer from '@/components/footer'
 import { Filter, X, ChevronDown, ChevronLeft, ChevronRight } from 'lucide-react'
 import Link from 'next/link'
+import { track } from '@/lib/analytics'
+import { browserPreviewHeaders } from '@/lib/preview'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
 interface Product {
   id: number
+  slug?: string
   name: string
   category: string
   price: number
@@ -53,7 +56,7 @@ function ProductCard({ product }: { product: Product }) {
 
   return (
     <Link
-      href={`/products/${product.id}`}
+      href={`/products/${product.slug || product.id}`}
       className="group bg-card border border-border rounded-lg overflow-hidden hover:shadow-lg transition"
     >
       <div className="relative h-64 bg-muted overflow-hidden">
@@ -113,6 +116,7 @@ function ProductCard({ product }: { product: Product }) {
 
 interface Category {
   id: number
+  slug?: string
   name: string
   description: string
   icon: string
@@ -127,6 +131,7 @@ export default function CatalogPage() {
   const [selectedCategory, setSelectedCategory] = useState('Все')
   const [sortBy, setSortBy] = useState('featured')
   const [showFilters, setShowFilters] = useState(false)
+  const [search, setSearch] = useState('')
 
   useEffect(() => {
     fetchProducts()
@@ -135,7 +140,7 @@ export default function CatalogPage() {
 
   const fetchProducts = async () => {
     try {
-      const res = await fetch(`${API_URL}/products`)
+      const res = await fetch(`${API_URL}/products`, { headers: browserPreviewHeaders() })
       const data = await res.json()
       setAllProducts(data)
     } catch (error) {
@@ -147,10 +152,14 @@ export default function CatalogPage() {
 
   const fetchCategories = async () => {
     try {
-      const res = await fetch(`${API_URL}/categories`)
+      const res = await fetch(`${API_URL}/categories`, { headers: browserPreviewHeaders() })
       if (res.ok) {
-        const data = await res.json()
+        const data: Category[] = await res.json()
         setCategories(data)
+        // Category links point here as /catalog?category=<slug>
+        const wanted = new URLSearchParams(window.location.search).get('category')
+        const match = wanted && data.find(c => c.slug === wanted || c.name === wanted)
+        if (match) setSelectedCategory(match.name)
       }
     } catch (error) {
       console.error('Error fetching categories:', error)
@@ -165,9 +174,17 @@ export default function CatalogPage() {
   // Filter products
   let filtered = allProducts.filter(product => {
     if (selectedCategory !== 'Все' && product.category !== selectedCategory) return false
+    if (search.trim() && !`${product.name} ${product.category}`.toLowerCase().includes(search.trim().toLowerCase())) return false
     return true
   })
 
+  // Report the search once the user stops typing, with the number of results
+  useEffect(() => {
+    if (!search.trim() || loading) return
+    const timer = setTimeout(() => track('search', { query: search, results: filtered.length }), 1000)
+    return () => clearTimeout(timer)
+  }, [search, loading])
+
   // Sort products
   if (sortBy === 'price-low') {
     filtered = [...filtered].sort((a, b) => a.price - b.price)
@@ -207,6 +224,15 @@ export default function CatalogPage() {
           <div className={`lg:block space-y-6 bg-card border border-border p-6 rounded-lg h-fit lg:sticky lg:top-20 ${showFilters ? 'block' : 'hidden'}`}>
             <div>
               <div className="space-y-4">
+                {/* Search */}
+                <input
+                  type="search"
+                  placeholder="Поиск"
+                  value={search}
+                  onChange={(e) => setSearch(e.target.value)}
+                  className="w-full px-4 py-2 border border-border rounded-lg text-sm bg-background text-foreground focus:outline-none focus:ring-2 focus:ring-primary"
+                />
+
                 {/* Category Filter */}
                 <div>
                   <h3 className="font-semibold text-foreground mb-3">Категория</h3>
@@ -229,10 +255,11 @@ export default function CatalogPage() {
                 </div>
 
                 {/* Reset Filters */}
-                {selectedCategory !== 'Все' && (
+                {(selectedCategory !== 'Все' || search) && (
                   <button
                     onClick={() => {
                       setSelectedCategory('Все')
+                      setSearch('')
                     }}
                     className="w-full px-4 py-2 border border-border text-sm font-medium rounded-lg hover:bg-muted transition flex items-center justify-center gap-2"
                   >
@@ -281,6 +308,7 @@ export default function CatalogPage() {
                   <button
                     onClick={() => {
                       setSelectedCategory('Все')
+                      setSearch('')
                     }}
                     className="mt-4 px-4 py-2 border border-primary text-primary rounded-lg hover:bg-primary hover:text-primary-foreground transition"
                   >
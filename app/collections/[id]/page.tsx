@@ -2,12 +2,15 @@ import Link from 'next/link'
 import Header from '@/components/header'
 import Footer from '@/components/footer'
 import { ArrowRight } from 'lucide-react'
-import { notFound } from 'next/navigation'
+import { notFound, permanentRedirect } from 'next/navigation'
+import { getSEO, seoMetadata, jsonLd } from '@/lib/seo'
+import { requestPreviewHeaders } from '@/lib/preview-server'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
 interface Product {
   id: number
+  slug?: string
   name: string
   price: number
   image: string
@@ -16,6 +19,7 @@ interface Product {
 
 interface Collection {
   id: number
+  slug?: string
   name: string
   description: string
   image: string
@@ -35,9 +39,12 @@ function getImageUrl(image: string | undefined, images?: string[]): string {
   return img
 }
 
-async function getCollection(id: number): Promise<Collection | null> {
+async function getCollection(idOrSlug: string): Promise<Collection | null> {
   try {
-    const res = await fetch(`${API_URL}/collections/${id}`, { cache: 'no-store' })
+    const res = await fetch(`${API_URL}/collections/${encodeURIComponent(idOrSlug)}`, {
+      cache: 'no-store',
+      headers: await requestPreviewHeaders(),
+    })
     if (!res.ok) return null
     return await res.json()
   } catch (error) {
@@ -46,23 +53,31 @@ async function getCollection(id: number): Promise<Collection | null> {
   }
 }
 
+export async function generateMetadata({ params }: { params: Promise<{ id: string }> }) {
+  const { id } = await params
+  return seoMetadata(await getSEO(`/collections/${encodeURIComponent(id)}`))
+}
+
 export default async function CollectionPage({ params }: { params: Promise<{ id: string }> }) {
   const { id } = await params
-  const collectionId = parseInt(id)
-  
-  if (isNaN(collectionId)) {
-    notFound()
-  }
+  const collection = await getCollection(decodeURIComponent(id))
 
-  const collection = await getCollection(collectionId)
-  
   if (!collection) {
     notFound()
   }
+  if (collection.slug && decodeURIComponent(id) !== collection.slug) {
+    permanentRedirect(`/collections/${collection.slug}`)
+  }
+  const collectionId = collection.id
+
+  const seo = await getSEO(`/collections/${collectionId}`)
 
   return (
     <div className="min-h-screen bg-background">
       <Header />
+      {seo?.json_ld.map((data, idx) => (
+        <script key={idx} type="application/ld+json" dangerouslySetInnerHTML={{ __html: jsonLd(data) }} />
+      ))}
 
       <main>
         {/* Hero Section */}
@@ -93,7 +108,7 @@ export default async function CollectionPage({ params }: { params: Promise<{ id:
                   {collection.products.map((product) => (
                     <Link
                       key={product.id}
-                      href={`/products/${product.id}`}
+                      href={`/products/${product.slug || product.id}`}
                       className="group bg-card border border-border rounded-lg overflow-hidden hover:shadow-lg transition"
                     >
                       <div className="relative h-48 sm:h-56 md:h-64 bg-muted overflow-hidden">
@@ -1,9 +1,11 @@
 'use client'
 
-import { useState } from 'react'
+import { useState, useEffect } from 'react'
 import Header from '@/components/header'
 import Footer from '@/components/footer'
 import { Phone, Mail, MapPin, Send } from 'lucide-react'
+import { customerAuthHeaders } from '@/lib/customer-auth'
+import { track } from '@/lib/analytics'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
@@ -17,6 +19,15 @@ function ContactForm() {
   const [submitted, setSubmitted] = useState(false)
   const [loading, setLoading] = useState(false)
   const [error, setError] = useState('')
+  const [productId, setProductId] = useState<number | undefined>()
+
+  useEffect(() => {
+    // Product pages link here with ?product= so the funnel can attribute the request
+    const product = parseInt(new URLSearchParams(window.location.search).get('product') || '')
+    const id = isNaN(product) ? undefined : product
+    setProductId(id)
+    track('contact_open', { product_id: id })
+  }, [])
 
   const handleChange = (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement>) => {
     const { name, value } = e.target
@@ -33,12 +44,14 @@ function ContactForm() {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
+          ...customerAuthHeaders(),
         },
         body: JSON.stringify(formData),
       })
 
       if (res.ok) {
         setSubmitted(true)
+        track('contact_submit', { product_id: productId })
         setFormData({ name: '', email: '', phone: '', message: '' })
         setTimeout(() => setSubmitted(false), 5000)
       } else {
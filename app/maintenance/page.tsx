@@ -0,0 +1,37 @@
+import { Wrench } from 'lucide-react'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+const defaultMessage = 'Сайт временно закрыт на техническое обслуживание. Пожалуйста, зайдите позже.'
+
+async function getMessage(): Promise<string> {
+  try {
+    const res = await fetch(`${API_URL}/maintenance`, { cache: 'no-store' })
+    if (!res.ok) return defaultMessage
+    const status = await res.json()
+    return status.message || defaultMessage
+  } catch (error) {
+    console.error('Error fetching maintenance status:', error)
+    return defaultMessage
+  }
+}
+
+// Shown by proxy.ts instead of every page while the maintenance mode is on.
+// Header and footer are left out: every page they link to is closed too.
+export default async function MaintenancePage() {
+  const message = await getMessage()
+
+  return (
+    <div className="min-h-screen bg-background flex items-center justify-center py-16">
+      <div className="max-w-2xl mx-auto px-4 sm:px-6 lg:px-8 text-center">
+        <div className="w-24 h-24 mx-auto bg-primary/10 rounded-full flex items-center justify-center mb-6">
+          <Wrench className="w-12 h-12 text-primary" />
+        </div>
+        <h1 className="text-4xl md:text-5xl font-serif font-bold text-foreground mb-4">
+          Технические работы
+        </h1>
+        <p className="text-lg text-muted-foreground">{message}</p>
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,54 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import Header from '@/components/header'
+import Footer from '@/components/footer'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export default function ConfirmSubscriptionPage() {
+  const [status, setStatus] = useState<'pending' | 'confirmed' | 'failed'>('pending')
+
+  useEffect(() => {
+    const token = new URLSearchParams(window.location.search).get('token')
+    if (!token) {
+      setStatus('failed')
+      return
+    }
+
+    fetch(`${API_URL}/newsletter/confirm`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ token }),
+    })
+      .then(res => setStatus(res.ok ? 'confirmed' : 'failed'))
+      .catch(() => setStatus('failed'))
+  }, [])
+
+  return (
+    <div className="min-h-screen bg-background">
+      <Header />
+
+      <main className="max-w-xl mx-auto px-4 sm:px-6 lg:px-8 py-16 text-center space-y-6">
+        <h1 className="text-3xl font-serif font-bold text-foreground">Подписка на рассылку</h1>
+        {status === 'pending' && <p className="text-muted-foreground">Проверяем ссылку...</p>}
+        {status === 'confirmed' && (
+          <>
+            <p className="text-foreground">Подписка подтверждена. Спасибо, что остаётесь с нами!</p>
+            <Link href="/catalog" className="inline-block px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition">
+              Перейти в каталог
+            </Link>
+          </>
+        )}
+        {status === 'failed' && (
+          <p className="text-muted-foreground">Ссылка недействительна или устарела. Подпишитесь ещё раз, чтобы получить новое письмо.</p>
+        )}
+      </main>
+
+      <Footer />
+    </div>
+  )
+}
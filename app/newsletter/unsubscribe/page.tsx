@@ -0,0 +1,48 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Header from '@/components/header'
+import Footer from '@/components/footer'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export default function UnsubscribePage() {
+  const [status, setStatus] = useState<'pending' | 'unsubscribed' | 'failed'>('pending')
+
+  useEffect(() => {
+    const token = new URLSearchParams(window.location.search).get('token')
+    if (!token) {
+      setStatus('failed')
+      return
+    }
+
+    fetch(`${API_URL}/newsletter/unsubscribe`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ token }),
+    })
+      .then(res => setStatus(res.ok ? 'unsubscribed' : 'failed'))
+      .catch(() => setStatus('failed'))
+  }, [])
+
+  return (
+    <div className="min-h-screen bg-background">
+      <Header />
+
+      <main className="max-w-xl mx-auto px-4 sm:px-6 lg:px-8 py-16 text-center space-y-6">
+        <h1 className="text-3xl font-serif font-bold text-foreground">Отписка от рассылки</h1>
+        {status === 'pending' && <p className="text-muted-foreground">Обрабатываем запрос...</p>}
+        {status === 'unsubscribed' && (
+          <p className="text-foreground">Вы отписались от рассылки и больше не будете получать наши письма.</p>
+        )}
+        {status === 'failed' && (
+          <p className="text-muted-foreground">Ссылка недействительна. Если письма продолжают приходить, свяжитесь с нами.</p>
+        )}
+      </main>
+
+      <Footer />
+    </div>
+  )
+}
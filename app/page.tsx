@@ -1,3 +1,4 @@
+import type { ReactNode } from 'react'
 import Header from '@/components/header'
 import Hero from '@/components/hero'
 import Categories from '@/components/categories'
@@ -5,27 +6,53 @@ import FeaturedProducts from '@/components/featured-products'
 import Collections from '@/components/collections'
 import AboutSection from '@/components/about-section'
 import FAQ from '@/components/faq'
+import Newsletter from '@/components/newsletter'
 import Footer from '@/components/footer'
 import SectionDivider from '@/components/section-divider'
 import ChaosFacts from '@/components/chaos-facts'
+import InteriorsCarousel from '@/components/interiors-carousel'
+import { ContentSection } from '@/components/content-blocks'
+import { getPageLayout } from '@/lib/content'
+
+// Homepage sections that can be replaced from the admin (page "home");
+// until a section has published blocks the built-in version is shown.
+const editableSections = ['hero', 'chaos-facts', 'interiors', 'about', 'newsletter']
+
+export default async function Home() {
+  const layout = await getPageLayout('home')
+  const sections = layout?.sections || []
+
+  const section = (name: string, fallback: ReactNode) => {
+    const content = sections.find((s) => s.name === name)
+    return content ? <ContentSection section={content} /> : fallback
+  }
+  const extraSections = sections.filter((s) => !editableSections.includes(s.name))
 
-export default function Home() {
   return (
     <div className="min-h-screen bg-background">
       <Header />
       <main>
-        <Hero />
+        {section('hero', <Hero />)}
         <SectionDivider />
         <Categories />
         <SectionDivider />
         <FeaturedProducts />
-        <ChaosFacts />
+        {section('chaos-facts', <ChaosFacts />)}
         <SectionDivider />
         <Collections />
         <SectionDivider />
-        <AboutSection />
+        {section('interiors', <InteriorsCarousel />)}
+        <SectionDivider />
+        {section('about', <AboutSection />)}
+        {extraSections.map((s) => (
+          <div key={s.name}>
+            <SectionDivider />
+            <ContentSection section={s} />
+          </div>
+        ))}
         <SectionDivider />
         <FAQ />
+        {section('newsletter', <Newsletter />)}
       </main>
       <Footer />
     </div>
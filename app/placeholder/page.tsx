@@ -0,0 +1,8 @@
+import PlaceholderPage from '@/components/placeholder-page'
+
+// Pages covered by a placeholder rule are rewritten here by proxy.ts; the
+// browser keeps the original address.
+export default async function PlaceholderRoute({ searchParams }: { searchParams: Promise<{ path?: string }> }) {
+  const { path } = await searchParams
+  return <PlaceholderPage path={path || '/'} />
+}
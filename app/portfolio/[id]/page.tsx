@@ -0,0 +1,148 @@
+import Link from 'next/link'
+import Header from '@/components/header'
+import Footer from '@/components/footer'
+import { MapPin, Calendar, Quote } from 'lucide-react'
+import { notFound, permanentRedirect } from 'next/navigation'
+import { getProject, projectImageUrl } from '@/lib/projects'
+
+export async function generateMetadata({ params }: { params: Promise<{ id: string }> }) {
+  const { id } = await params
+  const project = await getProject(decodeURIComponent(id))
+  if (!project) return {}
+  return {
+    title: `${project.hotel_name}, ${project.city}`,
+    description: project.description.slice(0, 160),
+  }
+}
+
+export default async function ProjectPage({ params }: { params: Promise<{ id: string }> }) {
+  const { id } = await params
+  const project = await getProject(decodeURIComponent(id))
+
+  if (!project) {
+    notFound()
+  }
+  if (project.slug && decodeURIComponent(id) !== project.slug) {
+    permanentRedirect(`/portfolio/${project.slug}`)
+  }
+
+  const [cover, ...gallery] = project.gallery
+  const products = project.products || []
+  const collections = project.collections || []
+
+  return (
+    <div className="min-h-screen bg-background">
+      <Header />
+
+      <main>
+        {/* Hero Section */}
+        <section className="relative h-64 sm:h-80 md:h-96 lg:h-[500px] bg-muted overflow-hidden flex items-center justify-center w-full">
+          <img src={projectImageUrl(cover)} alt={project.hotel_name} className="absolute inset-0 w-full h-full object-cover" />
+          <div className="absolute inset-0 bg-black/50 dark:bg-black/60"></div>
+          <div className="relative z-10 text-center px-4 space-y-4">
+            <h1 className="text-3xl sm:text-4xl md:text-5xl lg:text-6xl font-serif font-bold text-white">{project.hotel_name}</h1>
+            <div className="flex items-center justify-center gap-6 text-white/90">
+              <span className="flex items-center gap-2">
+                <MapPin className="w-5 h-5" />
+                {project.city}
+              </span>
+              <span className="flex items-center gap-2">
+                <Calendar className="w-5 h-5" />
+                {project.year}
+              </span>
+            </div>
+          </div>
+        </section>
+
+        <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12 space-y-16">
+          {/* Breadcrumb */}
+          <div className="flex items-center gap-2 text-sm">
+            <Link href="/" className="text-muted-foreground hover:text-foreground transition">Главная</Link>
+            <span className="text-muted-foreground">/</span>
+            <Link href="/portfolio" className="text-muted-foreground hover:text-foreground transition">Портфолио</Link>
+            <span className="text-muted-foreground">/</span>
+            <span className="text-foreground">{project.hotel_name}</span>
+          </div>
+
+          {project.description && (
+            <div className="max-w-3xl mx-auto space-y-4">
+              {project.description.split(/\n\s*\n/).map((paragraph, idx) => (
+                <p key={idx} className="text-base sm:text-lg text-foreground leading-relaxed whitespace-pre-line">{paragraph.trim()}</p>
+              ))}
+            </div>
+          )}
+
+          {gallery.length > 0 && (
+            <div className="grid sm:grid-cols-2 lg:grid-cols-3 gap-4">
+              {gallery.map((image, idx) => (
+                <div key={idx} className="aspect-[4/3] bg-muted rounded-lg overflow-hidden">
+                  <img src={projectImageUrl(image)} alt={`${project.hotel_name} — фото ${idx + 2}`} className="w-full h-full object-cover" />
+                </div>
+              ))}
+            </div>
+          )}
+
+          {project.client_quote && (
+            <figure className="max-w-3xl mx-auto bg-card border border-border rounded-lg p-6 sm:p-10 text-center space-y-4">
+              <Quote className="w-8 h-8 text-primary mx-auto" />
+              <blockquote className="text-lg sm:text-xl font-serif text-foreground leading-relaxed">
+                {project.client_quote}
+              </blockquote>
+              {project.client_name && (
+                <figcaption className="text-sm text-muted-foreground">{project.client_name}</figcaption>
+              )}
+            </figure>
+          )}
+
+          {collections.length > 0 && (
+            <div>
+              <h2 className="text-xl sm:text-2xl font-serif font-bold text-foreground mb-4 sm:mb-6">Коллекции в проекте</h2>
+              <div className="flex flex-wrap gap-3">
+                {collections.map((collection) => (
+                  <Link
+                    key={collection.id}
+                    href={`/collections/${collection.slug || collection.id}`}
+                    className="px-4 py-2 border border-border rounded-lg text-foreground hover:bg-muted transition"
+                  >
+                    {collection.name}
+                  </Link>
+                ))}
+              </div>
+            </div>
+          )}
+
+          {products.length > 0 && (
+            <div>
+              <h2 className="text-xl sm:text-2xl font-serif font-bold text-foreground mb-4 sm:mb-6">Мебель в проекте</h2>
+              <div className="grid grid-cols-2 md:grid-cols-3 lg:grid-cols-4 gap-4 sm:gap-6">
+                {products.map((product) => (
+                  <Link
+                    key={product.id}
+                    href={`/products/${product.slug || product.id}`}
+                    className="group bg-card border border-border rounded-lg overflow-hidden hover:shadow-lg transition"
+                  >
+                    <div className="aspect-square bg-muted overflow-hidden">
+                      <img
+                        src={projectImageUrl(product.images?.[0] || product.image)}
+                        alt={product.name}
+                        className="w-full h-full object-cover group-hover:scale-105 transition duration-300"
+                      />
+                    </div>
+                    <div className="p-3 sm:p-4">
+                      <h3 className="text-sm sm:text-base font-semibold text-foreground group-hover:text-primary transition mb-1 sm:mb-2 line-clamp-2">
+                        {product.name}
+                      </h3>
+                      <p className="text-sm sm:text-base font-bold text-primary">{product.price} ₽</p>
+                    </div>
+                  </Link>
+                ))}
+              </div>
+            </div>
+          )}
+        </div>
+      </main>
+
+      <Footer />
+    </div>
+  )
+}
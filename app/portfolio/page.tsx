@@ -1,112 +1,33 @@
-'use client'
-
-import { useState, useEffect } from 'react'
+import Link from 'next/link'
 import Header from '@/components/header'
 import Footer from '@/components/footer'
-import PlaceholderPage from '@/components/placeholder-page'
-import { MapPin, Star } from 'lucide-react'
-
-const projects = [
-  {
-    id: 1,
-    name: 'Grand Plaza Hotel',
-    location: 'New York, USA',
-    image: '/placeholder.svg?key=proj1',
-    description: 'Furnished 250-room luxury hotel with contemporary design',
-    items: 'Beds, Seating, Dining Furniture',
-    rating: 4.9,
-  },
-  {
-    id: 2,
-    name: 'Côte d\'Azur Resort',
-    location: 'Cannes, France',
-    image: '/placeholder.svg?key=proj2',
-    description: 'Mediterranean-inspired boutique resort with 80 rooms',
-    items: 'Resort Furniture, Decor, Accessories',
-    rating: 4.8,
-  },
-  {
-    id: 3,
-    name: 'Asia Pacific Tower',
-    location: 'Singapore',
-    image: '/placeholder.svg?key=proj3',
-    description: 'Modern 5-star hotel with 500 guest rooms',
-    items: 'Full Suite of Hospitality Furniture',
-    rating: 4.9,
-  },
-  {
-    id: 4,
-    name: 'Desert Oasis Resort',
-    location: 'Dubai, UAE',
-    image: '/placeholder.svg?key=proj4',
-    description: 'Luxury desert resort featuring 150 rooms',
-    items: 'Premium Beds, Executive Seating',
-    rating: 4.7,
-  },
-  {
-    id: 5,
-    name: 'Alpine Lodge',
-    location: 'Swiss Alps',
-    image: '/placeholder.svg?key=proj5',
-    description: 'Boutique mountain hotel with rustic charm',
-    items: 'Custom Furniture, Decor Elements',
-    rating: 4.8,
-  },
-  {
-    id: 6,
-    name: 'Tokyo Metropolitan',
-    location: 'Tokyo, Japan',
-    image: '/placeholder.svg?key=proj6',
-    description: 'Ultra-modern 600-room luxury hotel',
-    items: 'Contemporary Furniture Suite',
-    rating: 4.9,
-  },
-]
-
-export default function PortfolioPage() {
-  const [showPlaceholder, setShowPlaceholder] = useState<boolean | null>(null)
-
-  useEffect(() => {
-    checkPlaceholder()
-  }, [])
+import ProjectCard from '@/components/project-card'
+import { getProjects } from '@/lib/projects'
 
-  const checkPlaceholder = async () => {
-    try {
-      // Get API URL - in browser, always use localhost, in Docker use environment variable
-      let apiUrl = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
-      if (typeof window !== 'undefined' && apiUrl.includes('backend:')) {
-        apiUrl = 'http://localhost:8080/api'
-      }
-      const res = await fetch(`${apiUrl}/placeholder/check?path=/portfolio`, {
-        cache: 'no-store',
-      })
-      if (res.ok) {
-        const data = await res.json()
-        setShowPlaceholder(data.exists === true)
-      } else {
-        setShowPlaceholder(false)
-      }
-    } catch (error) {
-      console.error('Error checking placeholder:', error)
-      setShowPlaceholder(false)
-    }
-  }
+// Placeholders for /portfolio are applied by proxy.ts before this page runs
+export default async function PortfolioPage({ searchParams }: { searchParams: Promise<{ city?: string; year?: string }> }) {
+  const { city, year } = await searchParams
+  const [allProjects, projects] = await Promise.all([
+    getProjects(),
+    city || year ? getProjects({ city, year }) : null,
+  ])
+  const shown = projects || allProjects
 
-  if (showPlaceholder === null) {
-    return (
-      <div className="min-h-screen bg-background">
-        <Header />
-        <main className="flex items-center justify-center min-h-[60vh]">
-          <p className="text-muted-foreground">Загрузка...</p>
-        </main>
-        <Footer />
-      </div>
-    )
-  }
+  // Filter options come from every published project, not just the shown ones
+  const cities = Array.from(new Set(allProjects.map((p) => p.city))).sort((a, b) => a.localeCompare(b, 'ru'))
+  const years = Array.from(new Set(allProjects.map((p) => p.year))).sort((a, b) => b - a)
 
-  if (showPlaceholder) {
-    return <PlaceholderPage path="/portfolio" />
+  const filterHref = (next: { city?: string; year?: string }) => {
+    const params = new URLSearchParams()
+    if (next.city) params.set('city', next.city)
+    if (next.year) params.set('year', next.year)
+    const query = params.toString()
+    return `/portfolio${query ? `?${query}` : ''}`
   }
+  const chip = (active: boolean) =>
+    `px-4 py-2 text-sm rounded-lg border transition ${
+      active ? 'bg-primary text-primary-foreground border-primary' : 'border-border text-foreground hover:bg-muted'
+    }`
 
   return (
     <div className="min-h-screen bg-background">
@@ -128,53 +49,36 @@ export default function PortfolioPage() {
         {/* Projects Grid */}
         <section className="py-16 md:py-24 bg-background">
           <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8">
-            <div className="grid md:grid-cols-2 lg:grid-cols-3 gap-6">
-              {projects.map((project) => (
-                <div
-                  key={project.id}
-                  className="group bg-card border border-border rounded-lg overflow-hidden hover:shadow-lg transition"
-                >
-                  <div className="relative h-64 bg-muted overflow-hidden">
-                    <img
-                      src={project.image || "/placeholder.svg"}
-                      alt={project.name}
-                      className="w-full h-full object-cover group-hover:scale-105 transition duration-300"
-                    />
-                  </div>
-                  <div className="p-6 space-y-4">
-                    <div>
-                      <h3 className="text-xl font-semibold text-foreground mb-2">
-                        {project.name}
-                      </h3>
-                      <div className="flex items-center gap-2 text-sm text-muted-foreground mb-3">
-                        <MapPin className="w-4 h-4" />
-                        {project.location}
-                      </div>
-                    </div>
-                    <p className="text-muted-foreground">
-                      {project.description}
-                    </p>
-                    <div className="space-y-2">
-                      <p className="text-xs font-semibold text-muted-foreground uppercase">
-                        Предоставленная мебель
-                      </p>
-                      <p className="text-sm text-foreground">{project.items}</p>
-                    </div>
-                    <div className="flex items-center justify-between pt-4 border-t border-border">
-                      <div className="flex items-center gap-1">
-                        <Star className="w-4 h-4 fill-accent text-accent" />
-                        <span className="text-sm font-semibold text-foreground">
-                          {project.rating}
-                        </span>
-                      </div>
-                      <button className="px-4 py-2 text-sm font-medium text-primary border border-primary rounded-lg hover:bg-primary hover:text-primary-foreground transition">
-                        Подробнее
-                      </button>
-                    </div>
-                  </div>
+            {allProjects.length > 0 && (
+              <div className="space-y-4 mb-10">
+                <div className="flex flex-wrap items-center gap-2">
+                  <span className="text-sm text-muted-foreground w-16">Город</span>
+                  <Link href={filterHref({ year })} className={chip(!city)}>Все</Link>
+                  {cities.map((c) => (
+                    <Link key={c} href={filterHref({ city: c, year })} className={chip(city === c)}>{c}</Link>
+                  ))}
                 </div>
-              ))}
-            </div>
+                <div className="flex flex-wrap items-center gap-2">
+                  <span className="text-sm text-muted-foreground w-16">Год</span>
+                  <Link href={filterHref({ city })} className={chip(!year)}>Все</Link>
+                  {years.map((y) => (
+                    <Link key={y} href={filterHref({ city, year: String(y) })} className={chip(year === String(y))}>{y}</Link>
+                  ))}
+                </div>
+              </div>
+            )}
+
+            {shown.length === 0 ? (
+              <p className="text-center text-muted-foreground py-12">
+                {allProjects.length === 0 ? 'Проекты скоро появятся' : 'Нет проектов по выбранным условиям'}
+              </p>
+            ) : (
+              <div className="grid md:grid-cols-2 lg:grid-cols-3 gap-6">
+                {shown.map((project) => (
+                  <ProjectCard key={project.id} project={project} />
+                ))}
+              </div>
+            )}
           </div>
         </section>
       </main>
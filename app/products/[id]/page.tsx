@@ -2,13 +2,22 @@ import Link from 'next/link'
 import Header from '@/components/header'
 import Footer from '@/components/footer'
 import { Shield, RotateCcw } from 'lucide-react'
-import { notFound } from 'next/navigation'
+import { notFound, permanentRedirect } from 'next/navigation'
+import AddToBoard from '@/components/add-to-board'
 import ProductImageCarousel from '@/components/product-image-carousel'
+import ProductReviews from '@/components/product-reviews'
+import ProductViewTracker from '@/components/product-view-tracker'
+import ProjectCard from '@/components/project-card'
+import FAQ from '@/components/faq'
+import { getProductProjects } from '@/lib/projects'
+import { getSEO, seoMetadata, jsonLd } from '@/lib/seo'
+import { requestPreviewHeaders } from '@/lib/preview-server'
 
 const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
 interface Product {
   id: number
+  slug?: string
   name: string
   category: string
   price: number
@@ -23,10 +32,12 @@ interface Product {
   features: string[]
 }
 
-async function getProduct(id: number): Promise<Product | null> {
+// Products are addressed by slug; numeric ids keep working for old links
+async function getProduct(idOrSlug: string): Promise<Product | null> {
   try {
-    const res = await fetch(`${API_URL}/products/${id}`, {
+    const res = await fetch(`${API_URL}/products/${encodeURIComponent(idOrSlug)}`, {
       cache: 'no-store',
+      headers: await requestPreviewHeaders(),
     })
     if (!res.ok) {
       return null
@@ -38,17 +49,18 @@ async function getProduct(id: number): Promise<Product | null> {
   }
 }
 
-async function getProducts(): Promise<Product[]> {
+async function getRelatedProducts(id: number): Promise<Product[]> {
   try {
-    const res = await fetch(`${API_URL}/products`, {
+    const res = await fetch(`${API_URL}/products/${id}/related?limit=3`, {
       cache: 'no-store',
+      headers: await requestPreviewHeaders(),
     })
     if (!res.ok) {
       return []
     }
     return await res.json()
   } catch (error) {
-    console.error('Error fetching products:', error)
+    console.error('Error fetching related products:', error)
     return []
   }
 }
@@ -63,30 +75,29 @@ function getImageUrl(image: string | undefined): string {
   return image
 }
 
+export async function generateMetadata({ params }: { params: Promise<{ id: string }> }) {
+  const { id } = await params
+  return seoMetadata(await getSEO(`/products/${encodeURIComponent(id)}`))
+}
+
 export default async function ProductPage({ params }: { params: Promise<{ id: string }> }) {
   const { id } = await params
-  const productId = parseInt(id)
-  
-  if (isNaN(productId)) {
-    notFound()
-  }
+  const product = await getProduct(decodeURIComponent(id))
 
-  const product = await getProduct(productId)
-  
   if (!product) {
     notFound()
   }
+  if (product.slug && decodeURIComponent(id) !== product.slug) {
+    permanentRedirect(`/products/${product.slug}`)
+  }
+  const productId = product.id
 
-  // Get related products (other products from the same category, excluding current)
-  const allProducts = await getProducts()
-  const relatedProducts = allProducts
-    .filter(p => p.id !== productId && p.category === product.category)
-    .slice(0, 3)
-
-  // If no related products in same category, get any other products
-  const finalRelatedProducts = relatedProducts.length > 0 
-    ? relatedProducts 
-    : allProducts.filter(p => p.id !== productId).slice(0, 3)
+  // Curated accessories and matching items first, then similar products
+  const [relatedProducts, projects] = await Promise.all([
+    getRelatedProducts(productId),
+    getProductProjects(productId),
+  ])
+  const seo = await getSEO(`/products/${productId}`)
 
   // Build specifications from product data
   const specifications: Record<string, string> = {
@@ -103,6 +114,10 @@ export default async function ProductPage({ params }: { params: Promise<{ id: st
   return (
     <div className="min-h-screen bg-background">
       <Header />
+      <ProductViewTracker productId={product.id} />
+      {seo?.json_ld.map((data, idx) => (
+        <script key={idx} type="application/ld+json" dangerouslySetInnerHTML={{ __html: jsonLd(data) }} />
+      ))}
 
       <main className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 py-12">
         {/* Breadcrumb */}
@@ -116,7 +131,7 @@ export default async function ProductPage({ params }: { params: Promise<{ id: st
 
         <div className="grid md:grid-cols-2 gap-8 md:gap-12 mb-12 md:mb-16">
           {/* Image Gallery */}
-          <ProductImageCarousel images={images} productName={product.name} />
+          <ProductImageCarousel images={images} productName={product.name} productId={product.id} />
 
           {/* Product Details */}
           <div className="space-y-6">
@@ -136,6 +151,16 @@ export default async function ProductPage({ params }: { params: Promise<{ id: st
               <p className="text-sm sm:text-base text-muted-foreground">В наличии</p>
             </div>
 
+            <div className="flex flex-wrap gap-4">
+              <Link
+                href={`/contact?product=${product.id}`}
+                className="px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition"
+              >
+                Запросить цену
+              </Link>
+              <AddToBoard productId={product.id} />
+            </div>
+
             <p className="text-base sm:text-lg text-foreground leading-relaxed">{product.description}</p>
 
             {/* Key Features */}
@@ -204,17 +229,33 @@ export default async function ProductPage({ params }: { params: Promise<{ id: st
           </div>
         </div>
 
+        <ProductReviews productId={product.id} />
+
+        <FAQ productId={product.id} />
+
+        {/* Hotels furnished with this product */}
+        {projects.length > 0 && (
+          <div className="mb-12 sm:mb-16">
+            <h2 className="text-xl sm:text-2xl font-serif font-bold text-foreground mb-4 sm:mb-6">Используется в проектах</h2>
+            <div className="grid sm:grid-cols-2 md:grid-cols-3 gap-4 sm:gap-6">
+              {projects.map((project) => (
+                <ProjectCard key={project.id} project={project} />
+              ))}
+            </div>
+          </div>
+        )}
+
         {/* Related Products */}
-        {finalRelatedProducts.length > 0 && (
+        {relatedProducts.length > 0 && (
         <div>
             <h2 className="text-xl sm:text-2xl font-serif font-bold text-foreground mb-4 sm:mb-6">Вам также может понравиться</h2>
           <div className="grid sm:grid-cols-2 md:grid-cols-3 gap-4 sm:gap-6">
-              {finalRelatedProducts.map((related) => {
+              {relatedProducts.map((related) => {
                 const relatedImageUrl = getImageUrl(related.image)
                 return (
               <Link
                 key={related.id}
-                href={`/products/${related.id}`}
+                href={`/products/${related.slug || related.id}`}
                 className="group bg-card border border-border rounded-lg overflow-hidden hover:shadow-lg transition"
               >
                 <div className="aspect-square bg-muted overflow-hidden">
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Purposes of customer_tokens rows.
+const (
+	tokenSession = "session"
+	tokenVerify  = "verify"
+	tokenReset   = "reset"
+)
+
+const (
+	sessionTTL       = 30 * 24 * time.Hour
+	verificationTTL  = 72 * time.Hour
+	passwordResetTTL = 2 * time.Hour
+
+	passwordIterations = 120000
+	minPasswordLength  = 8
+)
+
+// hashPassword returns "pbkdf2-sha256$<iterations>$<salt>$<hash>".
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	hash := pbkdf2.Key([]byte(password), salt, passwordIterations, 32, sha256.New)
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+func checkPassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	got := pbkdf2.Key([]byte(password), salt, iterations, len(want), sha256.New)
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+func validatePassword(password string) error {
+	if len([]rune(password)) < minPasswordLength {
+		return &validationError{msg: fmt.Sprintf("Password must be at least %d characters long", minPasswordLength)}
+	}
+	return nil
+}
+
+// issueToken stores a new single-purpose token for a customer and returns it.
+// Expired tokens of every customer are removed on the way.
+func issueToken(customerID int, purpose string, ttl time.Duration) (string, time.Time, error) {
+	token, err := newAPIKey()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	db.Exec("DELETE FROM customer_tokens WHERE expires_at <= CURRENT_TIMESTAMP")
+	_, err = db.Exec(
+		"INSERT INTO customer_tokens (token_hash, customer_id, purpose, expires_at) VALUES ($1, $2, $3, $4)",
+		hashToken(token), customerID, purpose, expiresAt,
+	)
+	return token, expiresAt, err
+}
+
+// consumeToken deletes a valid token and returns its customer. It returns
+// sql.ErrNoRows for unknown, used or expired tokens.
+func consumeToken(token, purpose string) (int, error) {
+	var customerID int
+	err := db.QueryRow(
+		"DELETE FROM customer_tokens WHERE token_hash = $1 AND purpose = $2 AND expires_at > CURRENT_TIMESTAMP RETURNING customer_id",
+		hashToken(token), purpose,
+	).Scan(&customerID)
+	return customerID, err
+}
+
+func sendVerificationEmail(c Customer) {
+	token, _, err := issueToken(c.ID, tokenVerify, verificationTTL)
+	if err != nil {
+		log.Printf("Error issuing verification token: %v", err)
+		return
+	}
+	body := fmt.Sprintf("Здравствуйте!\n\nПодтвердите адрес электронной почты, перейдя по ссылке:\n%s/account/verify?token=%s\n\nСсылка действительна %d часа.",
+		siteURL, token, int(verificationTTL.Hours()))
+	if err := sendMail(c.Email, "Подтверждение регистрации", body); err != nil {
+		log.Printf("Error sending verification email: %v", err)
+	}
+}
+
+func sendPasswordResetEmail(c Customer) {
+	token, _, err := issueToken(c.ID, tokenReset, passwordResetTTL)
+	if err != nil {
+		log.Printf("Error issuing password reset token: %v", err)
+		return
+	}
+	body := fmt.Sprintf("Здравствуйте!\n\nЧтобы задать новый пароль, перейдите по ссылке:\n%s/account/reset-password?token=%s\n\nСсылка действительна %d часа. Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.",
+		siteURL, token, int(passwordResetTTL.Hours()))
+	if err := sendMail(c.Email, "Сброс пароля", body); err != nil {
+		log.Printf("Error sending password reset email: %v", err)
+	}
+}
+
+// requireCustomer returns the authenticated customer or writes 401.
+func requireCustomer(w http.ResponseWriter, r *http.Request) (*Customer, bool) {
+	c, err := customerFromRequest(r)
+	if err != nil && err != errInvalidCredentials {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if c == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+	return c, true
+}
+
+func registerCustomer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Customer
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c := req.Customer
+	// Price lists are assigned by admins only
+	c.PriceListID = nil
+	if err := validateCustomer(&c); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = db.QueryRow(
+		"INSERT INTO customers (email, name, company, phone, legal_name, tax_id, address, password_hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, email_verified_at, created_at, version, updated_at",
+		c.Email, c.Name, c.Company, c.Phone, c.LegalName, c.TaxID, c.Address, hash,
+	).Scan(&c.ID, &c.EmailVerifiedAt, &c.CreatedAt, &c.Version, &c.UpdatedAt)
+	if err != nil {
+		writeSaveError(w, uniqueViolation(err, "This email is already registered"), "")
+		return
+	}
+
+	sendVerificationEmail(c)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+func verifyCustomerEmail(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	customerID, err := consumeToken(req.Token, tokenVerify)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid or expired token", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := db.Exec("UPDATE customers SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP) WHERE id = $1", customerID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "verified"})
+}
+
+// emailRequest handles endpoints that take an email and must not reveal
+// whether it is registered: they always answer 202, and the mail is sent in
+// the background so the response time does not tell either. Requests are
+// limited per client and address and per client.
+func emailRequest(w http.ResponseWriter, r *http.Request, send func(c Customer)) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if emailRequestLimiter.blocked(r, email) {
+		writeTooManyAttempts(w, emailRequestLimiter.pair.window)
+		return
+	}
+	emailRequestLimiter.record(r, email)
+
+	c, err := scanCustomer(db.QueryRow("SELECT "+customerColumns+" FROM customers WHERE email = $1", email))
+	if err == nil {
+		go send(c)
+	} else if err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "accepted"})
+}
+
+func resendVerification(w http.ResponseWriter, r *http.Request) {
+	emailRequest(w, r, func(c Customer) {
+		if c.EmailVerifiedAt == nil {
+			sendVerificationEmail(c)
+		}
+	})
+}
+
+func requestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	emailRequest(w, r, sendPasswordResetEmail)
+}
+
+// confirmPasswordReset sets a new password and ends all sessions. Following
+// the emailed link also proves ownership of the address.
+func confirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+
+	customerID, err := consumeToken(req.Token, tokenReset)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid or expired token", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := setCustomerPassword(customerID, req.Password); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "password_reset"})
+}
+
+func setCustomerPassword(customerID int, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(
+		"UPDATE customers SET password_hash = $1, email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP) WHERE id = $2",
+		hash, customerID,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DELETE FROM customer_tokens WHERE customer_id = $1 AND purpose = $2", customerID, tokenSession)
+	return err
+}
+
+func loginCustomer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if customerLoginLimiter.blocked(r, email) {
+		writeTooManyAttempts(w, customerLoginLimiter.pair.window)
+		return
+	}
+
+	var passwordHash sql.NullString
+	c, err := scanCustomer(db.QueryRow("SELECT "+customerColumns+" FROM customers WHERE email = $1", email))
+	if err == nil {
+		err = db.QueryRow("SELECT password_hash FROM customers WHERE id = $1", c.ID).Scan(&passwordHash)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err == sql.ErrNoRows || !passwordHash.Valid || !checkPassword(passwordHash.String, req.Password) {
+		customerLoginLimiter.record(r, email)
+		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+		return
+	}
+	customerLoginLimiter.clear(r, email)
+	if c.EmailVerifiedAt == nil {
+		http.Error(w, "Email address is not verified", http.StatusForbidden)
+		return
+	}
+
+	token, expiresAt, err := issueToken(c.ID, tokenSession, sessionTTL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":      token,
+		"expires_at": expiresAt,
+		"customer":   c,
+	})
+}
+
+func logoutCustomer(w http.ResponseWriter, r *http.Request) {
+	if token := bearerToken(r); token != "" {
+		if _, err := db.Exec("DELETE FROM customer_tokens WHERE token_hash = $1 AND purpose = $2", hashToken(token), tokenSession); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getAccount(w http.ResponseWriter, r *http.Request) {
+	c, ok := requireCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	setETag(w, c.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// patchAccount lets customers edit their own profile with a merge patch.
+// The email, price list and bookkeeping fields are read-only.
+func patchAccount(w http.ResponseWriter, r *http.Request) {
+	c, ok := requireCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+	if version != anyVersion && c.Version != version {
+		writeSaveError(w, errVersionMismatch, "Customer not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, c, customerRequiredFields, "id", "email", "price_list_id", "email_verified_at", "created_at", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := saveCustomer(c, c.Version); err != nil {
+		writeSaveError(w, err, "Customer not found")
+		return
+	}
+
+	setETag(w, c.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+func changeAccountPassword(w http.ResponseWriter, r *http.Request) {
+	c, ok := requireCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var passwordHash sql.NullString
+	if err := db.QueryRow("SELECT password_hash FROM customers WHERE id = $1", c.ID).Scan(&passwordHash); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if passwordHash.Valid && !checkPassword(passwordHash.String, req.CurrentPassword) {
+		http.Error(w, "Current password is incorrect", http.StatusForbidden)
+		return
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+
+	// Ends every session, the client has to log in again
+	if err := setCustomerPassword(c.ID, req.NewPassword); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getAccountRequests lists the contact requests the customer sent while
+// logged in or from their email address.
+func getAccountRequests(w http.ResponseWriter, r *http.Request) {
+	c, ok := requireCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.Query(
+		"SELECT "+contactColumns+" FROM contacts WHERE customer_id = $1 OR LOWER(email) = $2 ORDER BY created_at DESC",
+		c.ID, c.Email,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	contacts := []Contact{}
+	for rows.Next() {
+		contact, err := scanContact(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		contacts = append(contacts, contact)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contacts)
+}
+
+func getAccountQuotes(w http.ResponseWriter, r *http.Request) {
+	c, ok := requireCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	quotes, err := queryQuotes("SELECT "+quoteColumns+" FROM quotes WHERE customer_id = $1 AND status <> $2 ORDER BY created_at DESC", c.ID, quoteDraft)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quotes)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPassword(t *testing.T) {
+	encoded, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, _ := hashPassword("correct horse"); other == encoded {
+		t.Error("hashes of the same password share a salt")
+	}
+	parts := strings.Split(encoded, "$")
+
+	tests := []struct {
+		name     string
+		encoded  string
+		password string
+		want     bool
+	}{
+		{name: "right password", encoded: encoded, password: "correct horse", want: true},
+		{name: "wrong password", encoded: encoded, password: "correct horsE"},
+		{name: "empty password", encoded: encoded, password: ""},
+		{name: "empty hash", encoded: "", password: "correct horse"},
+		{name: "other scheme", encoded: "bcrypt$" + strings.Join(parts[1:], "$"), password: "correct horse"},
+		{name: "bad iterations", encoded: strings.Join([]string{parts[0], "0", parts[2], parts[3]}, "$"), password: "correct horse"},
+		{name: "fewer iterations", encoded: strings.Join([]string{parts[0], "1000", parts[2], parts[3]}, "$"), password: "correct horse"},
+		{name: "bad salt", encoded: strings.Join([]string{parts[0], parts[1], "!!", parts[3]}, "$"), password: "correct horse"},
+		{name: "missing part", encoded: strings.Join(parts[:3], "$"), password: "correct horse"},
+	}
+	for _, tt := range tests {
+		if got := checkPassword(tt.encoded, tt.password); got != tt.want {
+			t.Errorf("%s: checkPassword = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminUser is an account of the admin panel. Every admin API request is
+// authenticated as one, and the audit log names it as the actor.
+type AdminUser struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const adminSessionTTL = 12 * time.Hour
+
+const maxAdminUsernameLength = 100
+
+const adminUserColumns = "id, username, created_at"
+
+type adminContextKey struct{}
+
+func scanAdminUser(s rowScanner) (AdminUser, error) {
+	var a AdminUser
+	err := s.Scan(&a.ID, &a.Username, &a.CreatedAt)
+	return a, err
+}
+
+// ensureAdminUser creates the account named by ADMIN_USERNAME with
+// ADMIN_PASSWORD if it does not exist yet, so that a fresh installation can
+// be logged into. Further admins are added in the admin panel.
+func ensureAdminUser() {
+	username := strings.TrimSpace(os.Getenv("ADMIN_USERNAME"))
+	password := os.Getenv("ADMIN_PASSWORD")
+	if username == "" || password == "" {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM admin_users").Scan(&count); err == nil && count == 0 {
+			log.Println("No admin users yet: set ADMIN_USERNAME and ADMIN_PASSWORD to create the first one")
+		}
+		return
+	}
+	if err := validatePassword(password); err != nil {
+		log.Printf("Not creating admin user %q: %v", username, err)
+		return
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		log.Printf("Error hashing admin password: %v", err)
+		return
+	}
+	res, err := db.Exec("INSERT INTO admin_users (username, password_hash) VALUES ($1, $2) ON CONFLICT (username) DO NOTHING", username, hash)
+	if err != nil {
+		log.Printf("Error creating admin user: %v", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("Created admin user %q", username)
+	}
+}
+
+// adminFromRequest returns the admin authenticated by the session token of
+// r, or by basic auth for scripted access. It returns nil for anonymous
+// requests and wrong credentials. Failed basic auth counts towards the login
+// limit like the login form does.
+func adminFromRequest(r *http.Request) (*AdminUser, error) {
+	if token := bearerToken(r); token != "" {
+		return adminFromSession(token)
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		username = strings.TrimSpace(username)
+		if adminLoginLimiter.blocked(r, username) {
+			return nil, errTooManyAttempts
+		}
+		a, err := checkAdminCredentials(username, password)
+		if err == nil && a == nil {
+			adminLoginLimiter.record(r, username)
+		}
+		return a, err
+	}
+	return nil, nil
+}
+
+// adminFromSession returns the admin signed in with the session token, or
+// nil when the session is unknown or expired.
+func adminFromSession(token string) (*AdminUser, error) {
+	a, err := scanAdminUser(db.QueryRow(
+		"SELECT "+qualifyColumns("a", adminUserColumns)+` FROM admin_users a
+		JOIN admin_sessions s ON s.admin_id = a.id
+		WHERE s.token_hash = $1 AND s.expires_at > CURRENT_TIMESTAMP`,
+		hashToken(token),
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// hasAdminSession reports whether a public request comes from a signed-in
+// admin. The frontend forwards the admin session cookie in the
+// X-Admin-Session header.
+func hasAdminSession(r *http.Request) bool {
+	token := r.Header.Get("X-Admin-Session")
+	if token == "" {
+		return false
+	}
+	a, err := adminFromSession(token)
+	if err != nil {
+		log.Printf("Error checking admin session: %v", err)
+	}
+	return a != nil
+}
+
+// checkAdminCredentials returns the admin with username if password is
+// right, nil otherwise.
+func checkAdminCredentials(username, password string) (*AdminUser, error) {
+	var a AdminUser
+	var passwordHash string
+	err := db.QueryRow(
+		"SELECT "+adminUserColumns+", password_hash FROM admin_users WHERE username = $1",
+		strings.TrimSpace(username),
+	).Scan(&a.ID, &a.Username, &a.CreatedAt, &passwordHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !checkPassword(passwordHash, password) {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+// adminAuthMiddleware rejects admin API requests that are not authenticated
+// as an admin user and passes the user on to auditActor.
+func adminAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a, err := adminFromRequest(r)
+		if err == errTooManyAttempts {
+			writeTooManyAttempts(w, adminLoginLimiter.pair.window)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if a == nil {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminContextKey{}, a)))
+	})
+}
+
+// currentAdmin returns the admin authenticated by adminAuthMiddleware.
+func currentAdmin(r *http.Request) *AdminUser {
+	a, _ := r.Context().Value(adminContextKey{}).(*AdminUser)
+	return a
+}
+
+func loginAdmin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := strings.TrimSpace(req.Username)
+	if adminLoginLimiter.blocked(r, username) {
+		writeTooManyAttempts(w, adminLoginLimiter.pair.window)
+		return
+	}
+
+	a, err := checkAdminCredentials(req.Username, req.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if a == nil {
+		adminLoginLimiter.record(r, username)
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+	adminLoginLimiter.clear(r, username)
+
+	token, err := newAPIKey()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	expiresAt := time.Now().Add(adminSessionTTL)
+	db.Exec("DELETE FROM admin_sessions WHERE expires_at <= CURRENT_TIMESTAMP")
+	if _, err := db.Exec("INSERT INTO admin_sessions (token_hash, admin_id, expires_at) VALUES ($1, $2, $3)", hashToken(token), a.ID, expiresAt); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":      token,
+		"expires_at": expiresAt,
+		"admin":      a,
+	})
+}
+
+func logoutAdmin(w http.ResponseWriter, r *http.Request) {
+	if token := bearerToken(r); token != "" {
+		if _, err := db.Exec("DELETE FROM admin_sessions WHERE token_hash = $1", hashToken(token)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getCurrentAdmin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(currentAdmin(r))
+}
+
+func getAdminUsers(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.Query("SELECT " + adminUserColumns + " FROM admin_users ORDER BY username")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	admins := []AdminUser{}
+	for rows.Next() {
+		a, err := scanAdminUser(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		admins = append(admins, a)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(admins)
+}
+
+func createAdminUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" || len(req.Username) > maxAdminUsernameLength {
+		writeSaveError(w, &validationError{msg: "Field \"username\" must be 1 to " + strconv.Itoa(maxAdminUsernameLength) + " characters"}, "")
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	a, err := scanAdminUser(db.QueryRow(
+		"INSERT INTO admin_users (username, password_hash) VALUES ($1, $2) RETURNING "+adminUserColumns,
+		req.Username, hash,
+	))
+	if err != nil {
+		writeSaveError(w, uniqueViolation(err, "This username is taken"), "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(a)
+}
+
+// deleteAdminUser removes another admin together with their sessions. Admins
+// cannot delete themselves, so there is always one left to log in with.
+func deleteAdminUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid admin ID", http.StatusBadRequest)
+		return
+	}
+	if a := currentAdmin(r); a != nil && a.ID == id {
+		writeSaveError(w, &validationError{msg: "You cannot delete your own account"}, "")
+		return
+	}
+
+	res, err := db.Exec("DELETE FROM admin_users WHERE id = $1", id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "Admin not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
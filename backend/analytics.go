@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event types accepted by the /events beacon.
+const (
+	eventProductView   = "product_view"
+	eventGallery       = "gallery_interaction"
+	eventContactOpen   = "contact_open"
+	eventContactSubmit = "contact_submit"
+	eventSearch        = "search"
+)
+
+var eventTypes = []string{eventProductView, eventGallery, eventContactOpen, eventContactSubmit, eventSearch}
+
+const maxEventsPerBeacon = 50
+
+// Event is a single interaction reported by the frontend. The session ID is
+// a random value the browser keeps for the visit; no cookies or IP addresses
+// are stored.
+type Event struct {
+	Type      string          `json:"type"`
+	SessionID string          `json:"session_id"`
+	ProductID *int            `json:"product_id,omitempty"`
+	Query     string          `json:"query,omitempty"`
+	Results   *int            `json:"results,omitempty"` // number of search results
+	Path      string          `json:"path,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+func validateEvent(e *Event) error {
+	valid := false
+	for _, t := range eventTypes {
+		if e.Type == t {
+			valid = true
+		}
+	}
+	if !valid {
+		return &validationError{msg: fmt.Sprintf("Field \"type\" must be one of %s", strings.Join(eventTypes, ", "))}
+	}
+	e.SessionID = strings.TrimSpace(e.SessionID)
+	if e.SessionID == "" || len(e.SessionID) > 64 {
+		return &validationError{msg: "Field \"session_id\" must be 1 to 64 characters"}
+	}
+	if e.Type == eventProductView && e.ProductID == nil {
+		return &validationError{msg: "product_view events need a product_id"}
+	}
+	e.Query = strings.ToLower(strings.Join(strings.Fields(e.Query), " "))
+	if e.Type == eventSearch && (e.Query == "" || e.Results == nil) {
+		return &validationError{msg: "search events need a query and results"}
+	}
+	e.Query = truncateUTF8(e.Query, 200)
+	e.Path = truncateUTF8(e.Path, 500)
+	return nil
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a character,
+// which Postgres would reject as invalid UTF-8.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// recordEvents is the analytics beacon. It takes one event or an array of
+// them and answers 204. Bodies are parsed regardless of Content-Type, since
+// navigator.sendBeacon sends text/plain.
+func recordEvents(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var events []Event
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(body, &events)
+	} else {
+		var e Event
+		err = json.Unmarshal(body, &e)
+		events = []Event{e}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(events) > maxEventsPerBeacon {
+		http.Error(w, fmt.Sprintf("At most %d events per request", maxEventsPerBeacon), http.StatusRequestEntityTooLarge)
+		return
+	}
+	for i := range events {
+		if err := validateEvent(&events[i]); err != nil {
+			writeSaveError(w, err, "")
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+	for _, e := range events {
+		var data interface{}
+		if len(e.Data) > 0 {
+			data = string(e.Data)
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO events (type, session_id, product_id, query, results, path, data) VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7)",
+			e.Type, e.SessionID, e.ProductID, e.Query, e.Results, e.Path, data,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// aggregateAnalytics rebuilds the daily session, product and search rollups
+// for day from the raw events. It is idempotent, so the current day can be
+// refreshed repeatedly.
+func aggregateAnalytics(day time.Time) error {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	next := day.AddDate(0, 0, 1)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	queries := []string{
+		"DELETE FROM analytics_sessions_daily WHERE day = $1",
+		"DELETE FROM analytics_products_daily WHERE day = $1",
+		"DELETE FROM analytics_searches_daily WHERE day = $1",
+		`INSERT INTO analytics_sessions_daily (day, session_id, events, product_views, gallery_interactions, contact_opens, contact_submits, searches, first_seen, last_seen)
+		SELECT $1, session_id, COUNT(*),
+			COUNT(*) FILTER (WHERE type = 'product_view'),
+			COUNT(*) FILTER (WHERE type = 'gallery_interaction'),
+			COUNT(*) FILTER (WHERE type = 'contact_open'),
+			COUNT(*) FILTER (WHERE type = 'contact_submit'),
+			COUNT(*) FILTER (WHERE type = 'search'),
+			MIN(created_at), MAX(created_at)
+		FROM events WHERE created_at >= $1 AND created_at < $2
+		GROUP BY session_id`,
+		`INSERT INTO analytics_products_daily (day, product_id, views, sessions, gallery_interactions, contact_opens)
+		SELECT $1, product_id,
+			COUNT(*) FILTER (WHERE type = 'product_view'),
+			COUNT(DISTINCT session_id) FILTER (WHERE type = 'product_view'),
+			COUNT(*) FILTER (WHERE type = 'gallery_interaction'),
+			COUNT(*) FILTER (WHERE type = 'contact_open')
+		FROM events WHERE created_at >= $1 AND created_at < $2 AND product_id IS NOT NULL
+		GROUP BY product_id`,
+		`INSERT INTO analytics_searches_daily (day, query, searches, zero_results)
+		SELECT $1, query, COUNT(*), COUNT(*) FILTER (WHERE results = 0)
+		FROM events WHERE created_at >= $1 AND created_at < $2 AND type = 'search'
+		GROUP BY query`,
+	}
+	for _, q := range queries {
+		args := []interface{}{day}
+		if strings.Contains(q, "$2") {
+			args = append(args, next)
+		}
+		if _, err := tx.Exec(q, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// startAnalyticsAggregator refreshes the rollups of yesterday and today every
+// hour, so reports lag the raw events by at most an hour.
+func startAnalyticsAggregator() {
+	go func() {
+		for {
+			now := time.Now().UTC()
+			for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+				if err := aggregateAnalytics(day); err != nil {
+					log.Printf("Failed to aggregate analytics for %s: %v", day.Format("2006-01-02"), err)
+				}
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+}
+
+// reportRange reads ?from= and ?to= (inclusive days), defaulting to the last
+// 30 days.
+func reportRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -29)
+	for name, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := r.URL.Query().Get(name); v != "" {
+			t, err := parseDateParam(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid %s date", name), http.StatusBadRequest)
+				return from, to, false
+			}
+			*dst = t
+		}
+	}
+	return from.Truncate(24 * time.Hour), to.Truncate(24 * time.Hour), true
+}
+
+type ProductReport struct {
+	ProductID           int    `json:"product_id"`
+	Name                string `json:"name"`
+	Views               int    `json:"views"`
+	Sessions            int    `json:"sessions"`
+	GalleryInteractions int    `json:"gallery_interactions"`
+	ContactOpens        int    `json:"contact_opens"`
+}
+
+// getTopProducts ranks products by views in the date range.
+func getTopProducts(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := pageParams(r, 20, 100)
+
+	rows, err := db.Query(`SELECT a.product_id, COALESCE(p.name, ''), SUM(a.views), SUM(a.sessions), SUM(a.gallery_interactions), SUM(a.contact_opens)
+		FROM analytics_products_daily a LEFT JOIN products p ON p.id = a.product_id
+		WHERE a.day BETWEEN $1 AND $2
+		GROUP BY a.product_id, p.name
+		ORDER BY SUM(a.views) DESC, a.product_id
+		LIMIT `+strconv.Itoa(limit), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	report := []ProductReport{}
+	for rows.Next() {
+		var p ProductReport
+		if err := rows.Scan(&p.ProductID, &p.Name, &p.Views, &p.Sessions, &p.GalleryInteractions, &p.ContactOpens); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		report = append(report, p)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+type SearchReport struct {
+	Query       string `json:"query"`
+	Searches    int    `json:"searches"`
+	ZeroResults int    `json:"zero_results"`
+}
+
+// getZeroResultSearches lists the queries that found nothing, most frequent first.
+func getZeroResultSearches(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := pageParams(r, 50, 500)
+
+	rows, err := db.Query(`SELECT query, SUM(searches), SUM(zero_results)
+		FROM analytics_searches_daily
+		WHERE day BETWEEN $1 AND $2
+		GROUP BY query
+		HAVING SUM(zero_results) > 0
+		ORDER BY SUM(zero_results) DESC, query
+		LIMIT `+strconv.Itoa(limit), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	report := []SearchReport{}
+	for rows.Next() {
+		var s SearchReport
+		if err := rows.Scan(&s.Query, &s.Searches, &s.ZeroResults); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		report = append(report, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// getFunnel counts daily sessions at each step from product view to contact.
+// Later steps only count sessions that also viewed a product.
+func getFunnel(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := reportRange(w, r)
+	if !ok {
+		return
+	}
+
+	var sessions, viewed, opened, submitted int
+	err := db.QueryRow(`SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE product_views > 0),
+			COUNT(*) FILTER (WHERE product_views > 0 AND contact_opens > 0),
+			COUNT(*) FILTER (WHERE product_views > 0 AND contact_submits > 0)
+		FROM analytics_sessions_daily WHERE day BETWEEN $1 AND $2`, from, to,
+	).Scan(&sessions, &viewed, &opened, &submitted)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rate := func(n, of int) float64 {
+		if of == 0 {
+			return 0
+		}
+		return float64(n) / float64(of)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":                 from.Format("2006-01-02"),
+		"to":                   to.Format("2006-01-02"),
+		"sessions":             sessions,
+		"viewed_product":       viewed,
+		"opened_contact":       opened,
+		"submitted_contact":    submitted,
+		"view_to_open_rate":    rate(opened, viewed),
+		"view_to_contact_rate": rate(submitted, viewed),
+	})
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "chair", n: 10, want: "chair"},
+		{s: "chair", n: 5, want: "chair"},
+		{s: "chair", n: 3, want: "cha"},
+		{s: "стул", n: 8, want: "стул"},
+		{s: "стул", n: 5, want: "ст"},
+		{s: "стул", n: 4, want: "ст"},
+		{s: "стул", n: 1, want: ""},
+		{s: "a€b", n: 3, want: "a"},
+		{s: "", n: 0, want: ""},
+	}
+	for _, tt := range tests {
+		got := truncateUTF8(tt.s, tt.n)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("truncateUTF8(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Actor      string          `json:"actor"`
+	IP         string          `json:"ip"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   *int            `json:"entity_id"`
+	Status     int             `json:"status"`
+	Diff       json.RawMessage `json:"diff"`
+}
+
+const auditLogTable = `CREATE TABLE IF NOT EXISTS audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	actor VARCHAR(255) NOT NULL,
+	ip VARCHAR(64),
+	action VARCHAR(255) NOT NULL,
+	entity_type VARCHAR(50),
+	entity_id INTEGER,
+	status INTEGER NOT NULL,
+	diff JSONB
+)`
+
+// auditLoaders fetch the current state of an entity for the before/after
+// diff, keyed by the first path segment under /api/admin.
+var auditLoaders = map[string]func(id int) (interface{}, error){
+	"products":         func(id int) (interface{}, error) { return fetchProduct(id) },
+	"categories":       func(id int) (interface{}, error) { return fetchCategory(id) },
+	"collections":      func(id int) (interface{}, error) { return fetchCollection(id) },
+	"placeholders":     func(id int) (interface{}, error) { return fetchPlaceholder(id) },
+	"faqs":             func(id int) (interface{}, error) { return fetchFAQ(id) },
+	"contacts":         func(id int) (interface{}, error) { return fetchContact(id) },
+	"price-lists":      func(id int) (interface{}, error) { return fetchPriceList(id) },
+	"customers":        func(id int) (interface{}, error) { return fetchCustomer(id) },
+	"quotes":           func(id int) (interface{}, error) { return fetchQuote(id) },
+	"reviews":          func(id int) (interface{}, error) { return fetchReview(id) },
+	"redirects":        func(id int) (interface{}, error) { return fetchRedirect(id) },
+	"content-sections": func(id int) (interface{}, error) { return fetchContentSection(id) },
+	"content-blocks":   func(id int) (interface{}, error) { return fetchContentBlock(id) },
+	"projects":         func(id int) (interface{}, error) { return fetchProject(id) },
+}
+
+// auditActor identifies who performed an admin request: the admin user
+// authenticated by adminAuthMiddleware, by session or basic auth.
+func auditActor(r *http.Request) string {
+	if a := currentAdmin(r); a != nil {
+		return a.Username
+	}
+	return "anonymous"
+}
+
+// clientIP returns the address of the client, honouring the headers set by nginx.
+func clientIP(r *http.Request) string {
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// auditRecorder passes the response through while keeping the status and body
+// so the middleware can learn the id of created entities.
+type auditRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *auditRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *auditRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if rec.body.Len() < 1<<20 {
+		rec.body.Write(b)
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// auditMiddleware writes an audit_log entry for every mutating admin request.
+func auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		template := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if t, err := route.GetPathTemplate(); err == nil {
+				template = t
+			}
+		}
+		tail := strings.TrimPrefix(template, "/api/admin")
+		entityType := strings.Split(strings.TrimPrefix(tail, "/"), "/")[0]
+		action := auditAction(r.Method, entityType, tail)
+		// Trash endpoints act on the entity named in the route
+		if t := mux.Vars(r)["type"]; t != "" {
+			entityType = t
+		}
+
+		var entityID *int
+		if id, err := strconv.Atoi(mux.Vars(r)["id"]); err == nil {
+			entityID = &id
+		}
+
+		loader := auditLoaders[entityType]
+		var before interface{}
+		if loader != nil && entityID != nil {
+			if v, err := loader(*entityID); err == nil {
+				before = v
+			}
+		}
+
+		rec := &auditRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		var after interface{}
+		if rec.status < 400 && loader != nil {
+			if entityID == nil {
+				var created struct {
+					ID *int `json:"id"`
+				}
+				if json.Unmarshal(rec.body.Bytes(), &created) == nil {
+					entityID = created.ID
+				}
+			}
+			if entityID != nil {
+				if v, err := loader(*entityID); err == nil {
+					after = v
+				}
+			}
+		}
+
+		actor := auditActor(r)
+		var diff []byte
+		if rec.status < 400 {
+			diff = auditDiff(before, after)
+			if entityID != nil {
+				// Keep the state being replaced too, in case it predates revision history
+				recordRevision(entityType, *entityID, before, "")
+				recordRevision(entityType, *entityID, after, actor)
+			}
+		}
+
+		writeAuditEntry(actor, clientIP(r), action, entityType, entityID, rec.status, diff)
+	})
+}
+
+func writeAuditEntry(actor, ip, action, entityType string, entityID *int, status int, diff []byte) {
+	_, err := db.Exec(
+		"INSERT INTO audit_log (actor, ip, action, entity_type, entity_id, status, diff) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		actor, ip, action, entityType, entityID, status, nullableJSON(diff),
+	)
+	if err != nil {
+		log.Printf("Error writing audit entry: %v", err)
+	}
+}
+
+// auditAction names the operation: plain CRUD routes get a verb, anything
+// else (collection membership, dumps, ...) is described by method and route.
+func auditAction(method, entityType, tail string) string {
+	switch tail {
+	case "/" + entityType:
+		if method == http.MethodPost {
+			return "create"
+		}
+	case "/" + entityType + "/{id}":
+		switch method {
+		case http.MethodPut:
+			return "update"
+		case http.MethodPatch:
+			return "patch"
+		case http.MethodDelete:
+			return "delete"
+		}
+	}
+	return method + " " + tail
+}
+
+// auditDiff returns the changed top-level fields as {"field": {"before": x, "after": y}}.
+func auditDiff(before, after interface{}) []byte {
+	b, a := toJSONMap(before), toJSONMap(after)
+
+	diff := map[string]map[string]interface{}{}
+	for _, key := range unionKeys(b, a) {
+		if !reflect.DeepEqual(b[key], a[key]) {
+			diff[key] = map[string]interface{}{"before": b[key], "after": a[key]}
+		}
+	}
+	if len(diff) == 0 {
+		return nil
+	}
+
+	data, _ := json.Marshal(diff)
+	return data
+}
+
+// toJSONMap returns the top-level fields of v as they appear in the API.
+func toJSONMap(v interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	if v == nil {
+		return m
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		json.Unmarshal(data, &m)
+	}
+	return m
+}
+
+// unionKeys returns the keys present in either map, sorted.
+func unionKeys(a, b map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]interface{}{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nullableJSON maps an empty document to SQL NULL.
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+func getAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var conditions []string
+	var args []interface{}
+	addCondition := func(cond string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, strings.Replace(cond, "?", "$"+strconv.Itoa(len(args)), 1))
+	}
+
+	if v := query.Get("entity_type"); v != "" {
+		addCondition("entity_type = ?", v)
+	}
+	if v := query.Get("entity_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid entity_id", http.StatusBadRequest)
+			return
+		}
+		addCondition("entity_id = ?", id)
+	}
+	if v := query.Get("actor"); v != "" {
+		addCondition("actor = ?", v)
+	}
+	if v := query.Get("action"); v != "" {
+		addCondition("action = ?", v)
+	}
+	if v := query.Get("from"); v != "" {
+		from, err := parseDateParam(v)
+		if err != nil {
+			http.Error(w, "Invalid from date", http.StatusBadRequest)
+			return
+		}
+		addCondition("created_at >= ?", from)
+	}
+	if v := query.Get("to"); v != "" {
+		to, err := parseDateParam(v)
+		if err != nil {
+			http.Error(w, "Invalid to date", http.StatusBadRequest)
+			return
+		}
+		// A bare date includes the whole day
+		if len(v) == len("2006-01-02") {
+			to = to.AddDate(0, 0, 1)
+		}
+		addCondition("created_at < ?", to)
+	}
+
+	limit, offset := pageParams(r, 100, 500)
+
+	sqlQuery := "SELECT id, created_at, actor, COALESCE(ip, ''), action, COALESCE(entity_type, ''), entity_id, status, diff FROM audit_log"
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	sqlQuery += " ORDER BY id DESC LIMIT " + strconv.Itoa(limit) + " OFFSET " + strconv.Itoa(offset)
+
+	rows, err := db.Query(sqlQuery, args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		entries = append(entries, e)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func scanAuditEntry(s rowScanner) (AuditEntry, error) {
+	var e AuditEntry
+	var entityID sql.NullInt64
+	var diff []byte
+	err := s.Scan(&e.ID, &e.CreatedAt, &e.Actor, &e.IP, &e.Action, &e.EntityType, &entityID, &e.Status, &diff)
+	if err != nil {
+		return e, err
+	}
+	if entityID.Valid {
+		id := int(entityID.Int64)
+		e.EntityID = &id
+	}
+	if len(diff) > 0 {
+		e.Diff = diff
+	}
+	return e, nil
+}
+
+// snapshotAuditLog reads the whole audit log so it can be put back after a
+// dump restore replaces the database contents.
+func snapshotAuditLog() ([]AuditEntry, error) {
+	rows, err := db.Query("SELECT id, created_at, actor, COALESCE(ip, ''), action, COALESCE(entity_type, ''), entity_id, status, diff FROM audit_log ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// restoreAuditLog replaces the audit log with a snapshot taken before a restore.
+func restoreAuditLog(entries []AuditEntry) error {
+	if _, err := db.Exec(auditLogTable); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("TRUNCATE audit_log"); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		_, err := tx.Exec(
+			"INSERT INTO audit_log (id, created_at, actor, ip, action, entity_type, entity_id, status, diff) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+			e.ID, e.CreatedAt, e.Actor, e.IP, e.Action, e.EntityType, e.EntityID, e.Status, nullableJSON(e.Diff),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("SELECT setval(pg_get_serial_sequence('audit_log', 'id'), COALESCE((SELECT MAX(id) FROM audit_log), 0) + 1, false)"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,619 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Board is a named selection of products ("Lobby", "Standard room") kept by a
+// customer or, for anonymous visitors, by whoever holds its owner token
+// (sent as X-Board-Token). A share token gives read-only access.
+type Board struct {
+	ID         int         `json:"id"`
+	Name       string      `json:"name"`
+	ShareToken *string     `json:"share_token"`
+	Items      []BoardItem `json:"items"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Version    int         `json:"version"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+
+	customerID     *int
+	ownerTokenHash sql.NullString
+}
+
+type BoardItem struct {
+	ProductID int      `json:"product_id"`
+	Quantity  int      `json:"quantity"`
+	Notes     string   `json:"notes"`
+	Product   *Product `json:"product,omitempty"` // nil once the product is no longer published
+}
+
+const boardColumns = "id, name, share_token, created_at, version, updated_at, customer_id, owner_token_hash"
+
+func scanBoard(s rowScanner) (Board, error) {
+	var b Board
+	err := s.Scan(&b.ID, &b.Name, &b.ShareToken, &b.CreatedAt, &b.Version, &b.UpdatedAt, &b.customerID, &b.ownerTokenHash)
+	return b, err
+}
+
+// loadBoardItems fills b.Items with the items and their visible products,
+// priced for customer.
+func loadBoardItems(r *http.Request, b *Board, customer *Customer) error {
+	rows, err := db.Query("SELECT product_id, quantity, notes FROM board_items WHERE board_id = $1 ORDER BY created_at, product_id", b.ID)
+	if err != nil {
+		return err
+	}
+	b.Items = []BoardItem{}
+	for rows.Next() {
+		var item BoardItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.Notes); err != nil {
+			rows.Close()
+			return err
+		}
+		b.Items = append(b.Items, item)
+	}
+	rows.Close()
+
+	for i := range b.Items {
+		p, err := fetchProduct(b.Items[i].ProductID)
+		if err == sql.ErrNoRows || (err == nil && !productVisible(r, p)) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := applyCustomerPrices(customer, &p); err != nil {
+			return err
+		}
+		b.Items[i].Product = &p
+	}
+	return nil
+}
+
+// boardOwner identifies the caller: a customer, an anonymous owner token, or
+// neither. It writes 401 for invalid customer credentials.
+func boardOwner(w http.ResponseWriter, r *http.Request) (*Customer, string, bool) {
+	customer, err := customerFromRequest(r)
+	if err == errInvalidCredentials {
+		http.Error(w, "Invalid customer credentials", http.StatusUnauthorized)
+		return nil, "", false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, "", false
+	}
+	return customer, strings.TrimSpace(r.Header.Get("X-Board-Token")), true
+}
+
+func ownsBoard(b Board, customer *Customer, ownerToken string) bool {
+	if customer != nil && b.customerID != nil && *b.customerID == customer.ID {
+		return true
+	}
+	return ownerToken != "" && b.ownerTokenHash.Valid && b.ownerTokenHash.String == hashToken(ownerToken)
+}
+
+// ownedBoard loads the {id} board if the caller owns it, and writes 404
+// otherwise so that board ids cannot be probed.
+func ownedBoard(w http.ResponseWriter, r *http.Request) (Board, *Customer, bool) {
+	customer, ownerToken, ok := boardOwner(w, r)
+	if !ok {
+		return Board{}, nil, false
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid board ID", http.StatusBadRequest)
+		return Board{}, nil, false
+	}
+
+	b, err := scanBoard(db.QueryRow("SELECT "+boardColumns+" FROM boards WHERE id = $1", id))
+	if err == sql.ErrNoRows || (err == nil && !ownsBoard(b, customer, ownerToken)) {
+		http.Error(w, "Board not found", http.StatusNotFound)
+		return Board{}, nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return Board{}, nil, false
+	}
+	return b, customer, true
+}
+
+func writeBoard(w http.ResponseWriter, r *http.Request, b Board, customer *Customer, status int) {
+	if err := loadBoardItems(r, &b, customer); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	setETag(w, b.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(b)
+}
+
+// touchBoard bumps the version of a board after its items changed.
+func touchBoard(b *Board) error {
+	return db.QueryRow(
+		"UPDATE boards SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING version, updated_at", b.ID,
+	).Scan(&b.Version, &b.UpdatedAt)
+}
+
+func getBoards(w http.ResponseWriter, r *http.Request) {
+	customer, ownerToken, ok := boardOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var customerID interface{}
+	if customer != nil {
+		customerID = customer.ID
+	}
+	var tokenHash interface{}
+	if ownerToken != "" {
+		tokenHash = hashToken(ownerToken)
+	}
+
+	rows, err := db.Query("SELECT "+boardColumns+" FROM boards WHERE customer_id = $1 OR owner_token_hash = $2 ORDER BY updated_at DESC", customerID, tokenHash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var boards []Board
+	for rows.Next() {
+		b, err := scanBoard(rows)
+		if err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		boards = append(boards, b)
+	}
+	rows.Close()
+
+	result := []Board{}
+	for _, b := range boards {
+		if err := loadBoardItems(r, &b, customer); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result = append(result, b)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// createBoard creates a board for the customer, or for the anonymous owner
+// token. Anonymous callers without a token get a new one in owner_token and
+// must send it as X-Board-Token from then on.
+func createBoard(w http.ResponseWriter, r *http.Request) {
+	customer, ownerToken, ok := boardOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Field \"name\" must not be empty", http.StatusUnprocessableEntity)
+		return
+	}
+
+	var customerID *int
+	var tokenHash interface{}
+	newToken := ""
+	if customer != nil {
+		customerID = &customer.ID
+	} else {
+		if ownerToken == "" {
+			token, err := newAPIKey()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			ownerToken, newToken = token, token
+		}
+		tokenHash = hashToken(ownerToken)
+	}
+
+	b, err := scanBoard(db.QueryRow(
+		"INSERT INTO boards (name, customer_id, owner_token_hash) VALUES ($1, $2, $3) RETURNING "+boardColumns,
+		req.Name, customerID, tokenHash,
+	))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.Items = []BoardItem{}
+
+	setETag(w, b.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if newToken != "" {
+		json.NewEncoder(w).Encode(struct {
+			Board
+			OwnerToken string `json:"owner_token"`
+		}{b, newToken})
+		return
+	}
+	json.NewEncoder(w).Encode(b)
+}
+
+func getBoard(w http.ResponseWriter, r *http.Request) {
+	b, customer, ok := ownedBoard(w, r)
+	if !ok {
+		return
+	}
+	writeBoard(w, r, b, customer, http.StatusOK)
+}
+
+// renameBoard changes the name of a board.
+func renameBoard(w http.ResponseWriter, r *http.Request) {
+	b, customer, ok := ownedBoard(w, r)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Field \"name\" must not be empty", http.StatusUnprocessableEntity)
+		return
+	}
+
+	err := db.QueryRow(
+		"UPDATE boards SET name = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND ($3 = -1 OR version = $3) RETURNING version, updated_at",
+		req.Name, b.ID, version,
+	).Scan(&b.Version, &b.UpdatedAt)
+	if err == sql.ErrNoRows {
+		err = versionConflict("boards", b.ID)
+	}
+	if err != nil {
+		writeSaveError(w, err, "Board not found")
+		return
+	}
+	b.Name = req.Name
+
+	writeBoard(w, r, b, customer, http.StatusOK)
+}
+
+func deleteBoard(w http.ResponseWriter, r *http.Request) {
+	b, _, ok := ownedBoard(w, r)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteVersioned("boards", b.ID, version); err != nil {
+		writeSaveError(w, err, "Board not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// putBoardItem adds a product to a board or updates its quantity and notes.
+func putBoardItem(w http.ResponseWriter, r *http.Request) {
+	b, customer, ok := ownedBoard(w, r)
+	if !ok {
+		return
+	}
+
+	var item BoardItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if v, ok := mux.Vars(r)["product_id"]; ok {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid product ID", http.StatusBadRequest)
+			return
+		}
+		item.ProductID = id
+	}
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+	if item.Quantity < 0 {
+		http.Error(w, "Field \"quantity\" must be positive", http.StatusUnprocessableEntity)
+		return
+	}
+
+	p, err := fetchProduct(item.ProductID)
+	if err == sql.ErrNoRows || (err == nil && !productVisible(r, p)) {
+		http.Error(w, "Product not found", http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO board_items (board_id, product_id, quantity, notes) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (board_id, product_id) DO UPDATE SET quantity = EXCLUDED.quantity, notes = EXCLUDED.notes`,
+		b.ID, item.ProductID, item.Quantity, item.Notes,
+	)
+	if err == nil {
+		err = touchBoard(&b)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeBoard(w, r, b, customer, http.StatusOK)
+}
+
+func deleteBoardItem(w http.ResponseWriter, r *http.Request) {
+	b, customer, ok := ownedBoard(w, r)
+	if !ok {
+		return
+	}
+	productID, err := strconv.Atoi(mux.Vars(r)["product_id"])
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM board_items WHERE board_id = $1 AND product_id = $2", b.ID, productID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "Item not found", http.StatusNotFound)
+		return
+	}
+	if err := touchBoard(&b); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeBoard(w, r, b, customer, http.StatusOK)
+}
+
+// shareBoard creates the read-only share token of a board if it has none.
+func shareBoard(w http.ResponseWriter, r *http.Request) {
+	b, customer, ok := ownedBoard(w, r)
+	if !ok {
+		return
+	}
+
+	if b.ShareToken == nil {
+		token, err := newAPIKey()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, err := db.Exec("UPDATE boards SET share_token = $1 WHERE id = $2", token, b.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b.ShareToken = &token
+	}
+
+	writeBoard(w, r, b, customer, http.StatusOK)
+}
+
+// unshareBoard revokes the share link; a later share creates a new one.
+func unshareBoard(w http.ResponseWriter, r *http.Request) {
+	b, customer, ok := ownedBoard(w, r)
+	if !ok {
+		return
+	}
+	if _, err := db.Exec("UPDATE boards SET share_token = NULL WHERE id = $1", b.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.ShareToken = nil
+
+	writeBoard(w, r, b, customer, http.StatusOK)
+}
+
+// getSharedBoard is the read-only view behind a share link. Items are shown at
+// retail prices.
+func getSharedBoard(w http.ResponseWriter, r *http.Request) {
+	b, err := scanBoard(db.QueryRow("SELECT "+boardColumns+" FROM boards WHERE share_token = $1", mux.Vars(r)["token"]))
+	if err == sql.ErrNoRows {
+		http.Error(w, "Board not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := loadBoardItems(r, &b, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"name":       b.Name,
+		"items":      b.Items,
+		"updated_at": b.UpdatedAt,
+	})
+}
+
+// claimBoards moves the boards of an anonymous owner token to the logged-in
+// customer, e.g. right after login.
+func claimBoards(w http.ResponseWriter, r *http.Request) {
+	customer, ownerToken, ok := boardOwner(w, r)
+	if !ok {
+		return
+	}
+	if customer == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if ownerToken == "" {
+		http.Error(w, "X-Board-Token header is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.Exec(
+		"UPDATE boards SET customer_id = $1, owner_token_hash = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE owner_token_hash = $2",
+		customer.ID, hashToken(ownerToken),
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	n, _ := result.RowsAffected()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"claimed": n})
+}
+
+// boardSummary renders the items of a board as the text of a contact request.
+func boardSummary(b Board) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Подборка «%s»:\n", b.Name)
+	for _, item := range b.Items {
+		name := fmt.Sprintf("Товар #%d", item.ProductID)
+		if item.Product != nil {
+			name = item.Product.Name
+		}
+		fmt.Fprintf(&sb, "- %s × %d", name, item.Quantity)
+		if item.Notes != "" {
+			fmt.Fprintf(&sb, " (%s)", item.Notes)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// convertBoard turns a board into a contact request, and for
+// /boards/{id}/quote also into a draft quote priced for the customer.
+// Anonymous owners must send name and email; customers' details are filled in.
+func convertBoard(w http.ResponseWriter, r *http.Request) {
+	b, customer, ok := ownedBoard(w, r)
+	if !ok {
+		return
+	}
+	if err := loadBoardItems(r, &b, customer); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(b.Items) == 0 {
+		http.Error(w, "Board is empty", http.StatusUnprocessableEntity)
+		return
+	}
+
+	var contact Contact
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&contact); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if customer != nil {
+		contact.CustomerID = &customer.ID
+		if contact.Name == "" {
+			contact.Name = customer.Name
+		}
+		if contact.Email == "" {
+			contact.Email = customer.Email
+		}
+		if contact.Phone == "" {
+			contact.Phone = customer.Phone
+		}
+	}
+	if contact.Name == "" || contact.Email == "" {
+		http.Error(w, "Missing required fields: name, email", http.StatusUnprocessableEntity)
+		return
+	}
+	message := boardSummary(b)
+	if strings.TrimSpace(contact.Message) != "" {
+		message = contact.Message + "\n\n" + message
+	}
+	contact.Message = message
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO contacts (name, email, phone, message, customer_id) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		contact.Name, contact.Email, contact.Phone, contact.Message, contact.CustomerID,
+	).Scan(&contact.ID, &contact.CreatedAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{"contact": contact}
+	if strings.HasSuffix(r.URL.Path, "/quote") {
+		rates, err := exchangeRates()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		q := Quote{CustomerID: contact.CustomerID, ContactID: &contact.ID, Currency: baseCurrency, Status: quoteDraft, Notes: fmt.Sprintf("Из подборки «%s»", b.Name)}
+		for _, item := range b.Items {
+			qi := QuoteItem{Quantity: item.Quantity, Notes: item.Notes, Name: fmt.Sprintf("Товар #%d", item.ProductID)}
+			if item.Product != nil {
+				productID := item.ProductID
+				qi.ProductID = &productID
+				qi.Name = item.Product.Name
+				// Without a rate the item is left at zero for the sales team to price
+				if price, err := priceIn(*item.Product, q.Currency, rates); err == nil {
+					qi.UnitPrice = price.Amount
+				}
+			}
+			q.Items = append(q.Items, qi)
+		}
+		if err := validateQuote(&q); err != nil {
+			writeSaveError(w, err, "")
+			return
+		}
+		items, _ := json.Marshal(q.Items)
+		err = tx.QueryRow(
+			"INSERT INTO quotes (customer_id, contact_id, items, currency, total, status, notes) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at",
+			q.CustomerID, q.ContactID, string(items), q.Currency, q.Total, q.Status, q.Notes,
+		).Scan(&q.ID, &q.CreatedAt)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The draft is priced by the sales team before the customer sees it
+		response["quote"] = map[string]interface{}{"id": q.ID, "number": quoteNumber(q.ID), "status": q.Status}
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
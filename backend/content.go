@@ -0,0 +1,717 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Content blocks replace the hard-coded texts of site pages. A page (e.g.
+// "home") consists of named, ordered sections; each section holds ordered
+// blocks of one of the block types, written per locale.
+type ContentSection struct {
+	ID        int       `json:"id"`
+	Page      string    `json:"page"`
+	Name      string    `json:"name"`  // key the frontend renders the section by, e.g. "hero"
+	Title     string    `json:"title"` // label in the admin
+	Order     int       `json:"order"`
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ContentBlock struct {
+	ID        int             `json:"id"`
+	SectionID int             `json:"section_id"`
+	Type      string          `json:"type"`
+	Locale    string          `json:"locale"`
+	Order     int             `json:"order"`
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"` // shape depends on Type, see contentBlockTypes
+	CreatedAt time.Time       `json:"created_at"`
+	Version   int             `json:"version"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+const contentSectionColumns = `id, page, name, title, "order", version, updated_at`
+
+const contentBlockColumns = `id, section_id, type, locale, "order", status, data, created_at, version, updated_at`
+
+var contentSectionRequiredFields = []string{"page", "name"}
+
+var contentBlockRequiredFields = []string{"section_id", "type", "data"}
+
+type contentImage struct {
+	URL     string `json:"url"`
+	Alt     string `json:"alt,omitempty"`
+	Caption string `json:"caption,omitempty"`
+	Link    string `json:"link,omitempty"`
+}
+
+// Rich text is plain text with blank lines between paragraphs, **bold**,
+// *italic* and [label](url) links; the frontend renders it without HTML.
+type richTextBlock struct {
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text"`
+}
+
+type carouselBlock struct {
+	Title  string         `json:"title,omitempty"`
+	Images []contentImage `json:"images"`
+}
+
+type keyFact struct {
+	Value string `json:"value,omitempty"` // e.g. "40%"
+	Label string `json:"label"`
+}
+
+type keyFactsBlock struct {
+	Title string    `json:"title,omitempty"`
+	Facts []keyFact `json:"facts"`
+}
+
+type ctaBlock struct {
+	Title       string `json:"title"`
+	Text        string `json:"text,omitempty"`
+	Image       string `json:"image,omitempty"` // background image
+	ButtonLabel string `json:"button_label"`
+	ButtonURL   string `json:"button_url"`
+}
+
+// contentLinkPattern is the allow-list for links in blocks: site paths,
+// http(s), mailto: and tel:. It keeps javascript: and data: URLs, and
+// protocol-relative //host links, out of the page. Rich text links are
+// checked by the same rule when they are rendered.
+var contentLinkPattern = regexp.MustCompile(`^(/([^/]|$)|https?://|mailto:|tel:)`)
+
+func validateContentLink(field, link string) error {
+	if link != "" && !contentLinkPattern.MatchString(link) {
+		return &validationError{msg: fmt.Sprintf("%s must be a path starting with /, an http(s) URL, mailto: or tel:", field)}
+	}
+	return nil
+}
+
+// contentBlockTypes validate the data of each block type.
+var contentBlockTypes = map[string]func(data []byte) (interface{}, error){
+	"rich_text": func(data []byte) (interface{}, error) {
+		var b richTextBlock
+		if err := decodeBlockData(data, &b); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(b.Text) == "" {
+			return nil, &validationError{msg: "Rich text block needs \"text\""}
+		}
+		return b, nil
+	},
+	"image": func(data []byte) (interface{}, error) {
+		var b contentImage
+		if err := decodeBlockData(data, &b); err != nil {
+			return nil, err
+		}
+		if b.URL == "" {
+			return nil, &validationError{msg: "Image block needs \"url\""}
+		}
+		if err := validateContentLink("Field \"link\"", b.Link); err != nil {
+			return nil, err
+		}
+		return b, nil
+	},
+	"carousel": func(data []byte) (interface{}, error) {
+		var b carouselBlock
+		if err := decodeBlockData(data, &b); err != nil {
+			return nil, err
+		}
+		if len(b.Images) == 0 {
+			return nil, &validationError{msg: "Carousel block needs at least one image"}
+		}
+		for i, img := range b.Images {
+			if img.URL == "" {
+				return nil, &validationError{msg: fmt.Sprintf("Image %d of the carousel needs \"url\"", i+1)}
+			}
+			if err := validateContentLink(fmt.Sprintf("Link of image %d", i+1), img.Link); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	},
+	"key_facts": func(data []byte) (interface{}, error) {
+		var b keyFactsBlock
+		if err := decodeBlockData(data, &b); err != nil {
+			return nil, err
+		}
+		if len(b.Facts) == 0 {
+			return nil, &validationError{msg: "Key facts block needs at least one fact"}
+		}
+		for i, f := range b.Facts {
+			if f.Label == "" {
+				return nil, &validationError{msg: fmt.Sprintf("Fact %d needs \"label\"", i+1)}
+			}
+		}
+		return b, nil
+	},
+	"cta": func(data []byte) (interface{}, error) {
+		var b ctaBlock
+		if err := decodeBlockData(data, &b); err != nil {
+			return nil, err
+		}
+		if b.Title == "" || b.ButtonLabel == "" || b.ButtonURL == "" {
+			return nil, &validationError{msg: "CTA block needs \"title\", \"button_label\" and \"button_url\""}
+		}
+		if err := validateContentLink("Field \"button_url\"", b.ButtonURL); err != nil {
+			return nil, err
+		}
+		return b, nil
+	},
+}
+
+func decodeBlockData(data []byte, dst interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return &validationError{msg: "Invalid block data: " + err.Error()}
+	}
+	return nil
+}
+
+func validateContentSection(s *ContentSection) error {
+	s.Page = strings.TrimSpace(s.Page)
+	s.Name = strings.TrimSpace(s.Name)
+	if !slugPattern.MatchString(s.Page) {
+		return &validationError{msg: "Field \"page\" may only contain lowercase latin letters, digits and single hyphens"}
+	}
+	if !slugPattern.MatchString(s.Name) {
+		return &validationError{msg: "Field \"name\" may only contain lowercase latin letters, digits and single hyphens"}
+	}
+	return nil
+}
+
+// validateContentBlock checks the block type and locale and normalises the
+// data to the fields of its type.
+func validateContentBlock(b *ContentBlock) error {
+	if b.Locale == "" {
+		b.Locale = defaultLocale
+	}
+	if !isSupportedLocale(b.Locale) {
+		return &validationError{msg: fmt.Sprintf("Unsupported locale %q", b.Locale)}
+	}
+	if b.Status == "" {
+		b.Status = statusDraft
+	}
+	if err := validatePublication(b.Status, nil, nil); err != nil {
+		return err
+	}
+	validate, ok := contentBlockTypes[b.Type]
+	if !ok {
+		return &validationError{msg: "Field \"type\" must be one of rich_text, image, carousel, key_facts, cta"}
+	}
+	if len(b.Data) == 0 || string(b.Data) == "null" {
+		return &validationError{msg: "Field \"data\" is required"}
+	}
+	data, err := validate(b.Data)
+	if err != nil {
+		return err
+	}
+	if b.Data, err = json.Marshal(data); err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM content_sections WHERE id = $1)", b.SectionID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return &validationError{msg: "Field \"section_id\" refers to an unknown section"}
+	}
+	return nil
+}
+
+func scanContentSection(s rowScanner) (ContentSection, error) {
+	var cs ContentSection
+	err := s.Scan(&cs.ID, &cs.Page, &cs.Name, &cs.Title, &cs.Order, &cs.Version, &cs.UpdatedAt)
+	return cs, err
+}
+
+func fetchContentSection(id int) (ContentSection, error) {
+	return scanContentSection(db.QueryRow("SELECT "+contentSectionColumns+" FROM content_sections WHERE id = $1", id))
+}
+
+func scanContentBlock(s rowScanner) (ContentBlock, error) {
+	var b ContentBlock
+	var data []byte
+	err := s.Scan(&b.ID, &b.SectionID, &b.Type, &b.Locale, &b.Order, &b.Status, &data, &b.CreatedAt, &b.Version, &b.UpdatedAt)
+	b.Data = data
+	return b, err
+}
+
+func fetchContentBlock(id int) (ContentBlock, error) {
+	return scanContentBlock(db.QueryRow("SELECT "+contentBlockColumns+" FROM content_blocks WHERE id = $1", id))
+}
+
+const contentSectionTaken = "This page already has a section with this name"
+
+// saveContentSection writes s if the row is still at version. See saveProduct.
+func saveContentSection(s *ContentSection, version int) error {
+	if err := validateContentSection(s); err != nil {
+		return err
+	}
+	err := db.QueryRow(
+		`UPDATE content_sections SET page=$1, name=$2, title=$3, "order"=$4, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$5 AND ($6 = -1 OR version=$6) RETURNING version, updated_at`,
+		s.Page, s.Name, s.Title, s.Order, s.ID, version,
+	).Scan(&s.Version, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return versionConflict("content_sections", s.ID)
+	}
+	return uniqueViolation(err, contentSectionTaken)
+}
+
+// saveContentBlock writes b if the row is still at version. See saveProduct.
+func saveContentBlock(b *ContentBlock, version int) error {
+	if err := validateContentBlock(b); err != nil {
+		return err
+	}
+	err := db.QueryRow(
+		`UPDATE content_blocks SET section_id=$1, type=$2, locale=$3, "order"=$4, status=$5, data=$6, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$7 AND ($8 = -1 OR version=$8) RETURNING created_at, version, updated_at`,
+		b.SectionID, b.Type, b.Locale, b.Order, b.Status, string(b.Data), b.ID, version,
+	).Scan(&b.CreatedAt, &b.Version, &b.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return versionConflict("content_blocks", b.ID)
+	}
+	return err
+}
+
+// PageSection is a section of a page layout with the blocks to render.
+type PageSection struct {
+	Name   string         `json:"name"`
+	Title  string         `json:"title"`
+	Locale string         `json:"locale"`
+	Blocks []ContentBlock `json:"blocks"`
+}
+
+// getPageLayout returns the sections of a page in order, each with its
+// blocks in the best locale of the request that has any. Sections without
+// blocks are left out. Admin routes and preview tokens also see drafts.
+func getPageLayout(w http.ResponseWriter, r *http.Request) {
+	page := mux.Vars(r)["page"]
+
+	statusFilter := " AND b.status = '" + statusPublished + "'"
+	if showDrafts(r) {
+		statusFilter = " AND b.status <> '" + statusArchived + "'"
+	}
+	locales := requestLocales(r)
+	rows, err := db.Query(
+		`SELECT s.id, s.name, s.title, b.id, b.section_id, b.type, b.locale, b."order", b.status, b.data, b.created_at, b.version, b.updated_at
+		FROM content_sections s JOIN content_blocks b ON b.section_id = s.id
+		WHERE s.page = $1`+statusFilter+`
+		ORDER BY s."order", s.id, b."order", b.id`,
+		page,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type pending struct {
+		section PageSection
+		blocks  map[string][]ContentBlock // by locale
+	}
+	var order []int
+	sections := map[int]*pending{}
+	for rows.Next() {
+		var sectionID int
+		var name, title string
+		var b ContentBlock
+		var data []byte
+		if err := rows.Scan(&sectionID, &name, &title, &b.ID, &b.SectionID, &b.Type, &b.Locale, &b.Order, &b.Status, &data, &b.CreatedAt, &b.Version, &b.UpdatedAt); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b.Data = data
+		s, ok := sections[sectionID]
+		if !ok {
+			s = &pending{section: PageSection{Name: name, Title: title}, blocks: map[string][]ContentBlock{}}
+			sections[sectionID] = s
+			order = append(order, sectionID)
+		}
+		s.blocks[b.Locale] = append(s.blocks[b.Locale], b)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	layout := []PageSection{}
+	for _, id := range order {
+		s := sections[id]
+		for _, locale := range locales {
+			if blocks := s.blocks[locale]; len(blocks) > 0 {
+				s.section.Locale, s.section.Blocks = locale, blocks
+				layout = append(layout, s.section)
+				break
+			}
+		}
+	}
+
+	w.Header().Set("Content-Language", locales[0])
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"page": page, "sections": layout})
+}
+
+// Content sections (admin)
+
+func getContentSections(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + contentSectionColumns + " FROM content_sections"
+	var args []interface{}
+	if page := r.URL.Query().Get("page"); page != "" {
+		query += " WHERE page = $1"
+		args = append(args, page)
+	}
+	rows, err := db.Query(query+` ORDER BY page, "order", id`, args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	sections := []ContentSection{}
+	for rows.Next() {
+		s, err := scanContentSection(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sections = append(sections, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sections)
+}
+
+func getContentSection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	s, err := fetchContentSection(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Section not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, s.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+func createContentSection(w http.ResponseWriter, r *http.Request) {
+	var s ContentSection
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateContentSection(&s); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+
+	err := db.QueryRow(
+		`INSERT INTO content_sections (page, name, title, "order") VALUES ($1, $2, $3, $4) RETURNING id, version, updated_at`,
+		s.Page, s.Name, s.Title, s.Order,
+	).Scan(&s.ID, &s.Version, &s.UpdatedAt)
+	if err != nil {
+		writeSaveError(w, uniqueViolation(err, contentSectionTaken), "")
+		return
+	}
+
+	setETag(w, s.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+func updateContentSection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var s ContentSection
+	if err := decodeFull(r.Body, &s, contentSectionRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	s.ID = id
+	if err := saveContentSection(&s, version); err != nil {
+		writeSaveError(w, err, "Section not found")
+		return
+	}
+
+	setETag(w, s.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+func patchContentSection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := fetchContentSection(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Section not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && s.Version != version {
+		writeSaveError(w, errVersionMismatch, "Section not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &s, contentSectionRequiredFields, "id", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	s.ID = id
+	if err := saveContentSection(&s, s.Version); err != nil {
+		writeSaveError(w, err, "Section not found")
+		return
+	}
+
+	setETag(w, s.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// deleteContentSection deletes a section together with its blocks.
+func deleteContentSection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteVersioned("content_sections", id, version); err != nil {
+		writeSaveError(w, err, "Section not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Content blocks (admin)
+
+// getContentBlocks lists blocks, filtered by ?section_id=, ?locale= and ?type=.
+func getContentBlocks(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + contentBlockColumns + " FROM content_blocks WHERE 1=1"
+	var args []interface{}
+	for _, f := range []struct{ param, column string }{
+		{"section_id", "section_id"},
+		{"locale", "locale"},
+		{"type", "type"},
+	} {
+		if v := r.URL.Query().Get(f.param); v != "" {
+			args = append(args, v)
+			query += " AND " + f.column + " = $" + strconv.Itoa(len(args))
+		}
+	}
+	limit, offset := pageParams(r, 500, 1000)
+	query += ` ORDER BY section_id, locale, "order", id LIMIT ` + strconv.Itoa(limit) + " OFFSET " + strconv.Itoa(offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	blocks := []ContentBlock{}
+	for rows.Next() {
+		b, err := scanContentBlock(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		blocks = append(blocks, b)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blocks)
+}
+
+func getContentBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid block ID", http.StatusBadRequest)
+		return
+	}
+
+	b, err := fetchContentBlock(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Block not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, b.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(b)
+}
+
+func createContentBlock(w http.ResponseWriter, r *http.Request) {
+	var b ContentBlock
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateContentBlock(&b); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+
+	err := db.QueryRow(
+		`INSERT INTO content_blocks (section_id, type, locale, "order", status, data) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, version, updated_at`,
+		b.SectionID, b.Type, b.Locale, b.Order, b.Status, string(b.Data),
+	).Scan(&b.ID, &b.CreatedAt, &b.Version, &b.UpdatedAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, b.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(b)
+}
+
+func updateContentBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid block ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var b ContentBlock
+	if err := decodeFull(r.Body, &b, contentBlockRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	b.ID = id
+	if err := saveContentBlock(&b, version); err != nil {
+		writeSaveError(w, err, "Block not found")
+		return
+	}
+
+	setETag(w, b.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(b)
+}
+
+func patchContentBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid block ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	b, err := fetchContentBlock(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Block not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && b.Version != version {
+		writeSaveError(w, errVersionMismatch, "Block not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &b, contentBlockRequiredFields, "id", "created_at", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	b.ID = id
+	if err := saveContentBlock(&b, b.Version); err != nil {
+		writeSaveError(w, err, "Block not found")
+		return
+	}
+
+	setETag(w, b.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(b)
+}
+
+func deleteContentBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid block ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteVersioned("content_blocks", id, version); err != nil {
+		writeSaveError(w, err, "Block not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
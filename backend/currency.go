@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CurrencyFormat tells clients how to display amounts in a currency.
+type CurrencyFormat struct {
+	Symbol      string `json:"symbol"`
+	Decimals    int    `json:"decimals"`
+	SymbolFirst bool   `json:"symbol_first"`
+	Locale      string `json:"locale"`
+}
+
+// currencyFormats are the currencies prices can be quoted in.
+var currencyFormats = map[string]CurrencyFormat{
+	"RUB": {Symbol: "₽", Decimals: 0, SymbolFirst: false, Locale: "ru-RU"},
+	"EUR": {Symbol: "€", Decimals: 2, SymbolFirst: false, Locale: "de-DE"},
+	"USD": {Symbol: "$", Decimals: 2, SymbolFirst: true, Locale: "en-US"},
+	"AED": {Symbol: "AED", Decimals: 2, SymbolFirst: true, Locale: "en-AE"},
+}
+
+// baseCurrency is the currency exchange rates are expressed in and the
+// default currency of new products.
+var baseCurrency = strings.ToUpper(envOr("BASE_CURRENCY", "RUB"))
+
+type Money struct {
+	Amount    float64        `json:"amount"`
+	Currency  string         `json:"currency"`
+	Converted bool           `json:"converted"` // false for the base price or a manual override
+	Format    CurrencyFormat `json:"format"`
+}
+
+type Currency struct {
+	Code      string         `json:"code"`
+	Rate      *float64       `json:"rate"` // units of baseCurrency per unit, null if unknown
+	Source    string         `json:"source,omitempty"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+	Format    CurrencyFormat `json:"format"`
+}
+
+func currencyCodes() []string {
+	codes := make([]string, 0, len(currencyFormats))
+	for code := range currencyFormats {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// validateProductPricing checks the base currency and manual price overrides
+// of a product. An empty currency means baseCurrency.
+func validateProductPricing(p *Product) error {
+	if p.Currency == "" {
+		p.Currency = baseCurrency
+	}
+	p.Currency = strings.ToUpper(p.Currency)
+	if _, ok := currencyFormats[p.Currency]; !ok {
+		return &validationError{msg: fmt.Sprintf("Field \"currency\" must be one of %s", strings.Join(currencyCodes(), ", "))}
+	}
+	for code, price := range p.Prices {
+		if _, ok := currencyFormats[code]; !ok {
+			return &validationError{msg: fmt.Sprintf("Field \"prices\" has unknown currency %q", code)}
+		}
+		if price < 0 {
+			return &validationError{msg: fmt.Sprintf("Field \"prices\" has a negative %s price", code)}
+		}
+	}
+	return nil
+}
+
+// exchangeRates loads the rate of every currency, the base currency included.
+func exchangeRates() (map[string]float64, error) {
+	rows, err := db.Query("SELECT currency, rate FROM exchange_rates")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := map[string]float64{baseCurrency: 1}
+	for rows.Next() {
+		var code string
+		var rate float64
+		if err := rows.Scan(&code, &rate); err != nil {
+			return nil, err
+		}
+		rates[code] = rate
+	}
+	return rates, rows.Err()
+}
+
+// priceIn returns the price of p in currency: a manual override if there is
+// one, otherwise the base price converted through the exchange rates.
+func priceIn(p Product, currency string, rates map[string]float64) (Money, error) {
+	format := currencyFormats[currency]
+	if currency == p.Currency {
+		return Money{Amount: p.Price, Currency: currency, Format: format}, nil
+	}
+	if price, ok := p.Prices[currency]; ok {
+		return Money{Amount: price, Currency: currency, Format: format}, nil
+	}
+
+	from, ok := rates[p.Currency]
+	if !ok {
+		return Money{}, &validationError{msg: fmt.Sprintf("No exchange rate for %s", p.Currency)}
+	}
+	to, ok := rates[currency]
+	if !ok {
+		return Money{}, &validationError{msg: fmt.Sprintf("No exchange rate for %s", currency)}
+	}
+
+	scale := math.Pow(10, float64(format.Decimals))
+	amount := math.Round(p.Price*from/to*scale) / scale
+	return Money{Amount: amount, Currency: currency, Converted: true, Format: format}, nil
+}
+
+// convertPrices sets DisplayPrice on products when the request asks for a
+// currency with ?currency=. A product that cannot be converted for lack of an
+// exchange rate keeps its own price and currency, so one missing rate does not
+// break a whole listing.
+func convertPrices(r *http.Request, products ...*Product) error {
+	currency := strings.ToUpper(r.URL.Query().Get("currency"))
+	if currency == "" {
+		return nil
+	}
+	if _, ok := currencyFormats[currency]; !ok {
+		return &validationError{msg: fmt.Sprintf("Parameter \"currency\" must be one of %s", strings.Join(currencyCodes(), ", "))}
+	}
+
+	rates, err := exchangeRates()
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		money, err := priceIn(*p, currency, rates)
+		if err != nil {
+			log.Printf("Showing product %d in %s: %v", p.ID, p.Currency, err)
+			money = Money{Amount: p.Price, Currency: p.Currency, Format: currencyFormats[p.Currency]}
+		}
+		p.DisplayPrice = &money
+	}
+	return nil
+}
+
+// productRefs returns pointers to the elements of products.
+func productRefs(products []Product) []*Product {
+	refs := make([]*Product, len(products))
+	for i := range products {
+		refs[i] = &products[i]
+	}
+	return refs
+}
+
+func getCurrencies(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.Query("SELECT currency, rate, source, updated_at FROM exchange_rates")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	known := map[string]Currency{}
+	for rows.Next() {
+		var c Currency
+		var rate float64
+		var updatedAt time.Time
+		if err := rows.Scan(&c.Code, &rate, &c.Source, &updatedAt); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.Rate = &rate
+		c.UpdatedAt = &updatedAt
+		known[c.Code] = c
+	}
+
+	currencies := []Currency{}
+	for _, code := range currencyCodes() {
+		c, ok := known[code]
+		if !ok {
+			c = Currency{Code: code}
+		}
+		if code == baseCurrency {
+			one := 1.0
+			c = Currency{Code: code, Rate: &one}
+		}
+		c.Format = currencyFormats[code]
+		currencies = append(currencies, c)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"base":       baseCurrency,
+		"currencies": currencies,
+	})
+}
+
+// setExchangeRate stores the rate of a currency in units of baseCurrency.
+func setExchangeRate(tx *sql.Tx, code string, rate float64, source string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := currencyFormats[code]; !ok {
+		return &validationError{msg: fmt.Sprintf("Unknown currency %q", code)}
+	}
+	if code == baseCurrency {
+		return &validationError{msg: fmt.Sprintf("%s is the base currency and has no exchange rate", code)}
+	}
+	if rate <= 0 {
+		return &validationError{msg: fmt.Sprintf("Rate of %s must be positive", code)}
+	}
+
+	_, err := tx.Exec(
+		`INSERT INTO exchange_rates (currency, rate, source, updated_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (currency) DO UPDATE SET rate = EXCLUDED.rate, source = EXCLUDED.source, updated_at = EXCLUDED.updated_at`,
+		code, rate, source,
+	)
+	return err
+}
+
+func updateExchangeRate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Rate float64 `json:"rate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	err = setExchangeRate(tx, mux.Vars(r)["currency"], req.Rate, "manual")
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+	getCurrencies(w, r)
+}
+
+// importExchangeRates loads rates from an uploaded file, either a JSON object
+// {"EUR": 98.5, ...} or CSV lines "EUR,98.5". All rates are applied or none.
+func importExchangeRates(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Unable to get file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(file); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rates, err := parseExchangeRates(buf.Bytes())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	for code, rate := range rates {
+		if _, ok := currencyFormats[code]; !ok || code == baseCurrency || rate <= 0 {
+			http.Error(w, fmt.Sprintf("Invalid rate for %q", code), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for code, rate := range rates {
+		if err := setExchangeRate(tx, code, rate, "import"); err != nil {
+			writeSaveError(w, err, "")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	getCurrencies(w, r)
+}
+
+func parseExchangeRates(data []byte) (map[string]float64, error) {
+	rates := map[string]float64{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &rates); err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			fields := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' || r == '\t' })
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("Line %d: expected \"CODE,rate\"", line)
+			}
+			rate, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: invalid rate %q", line, fields[1])
+			}
+			rates[strings.TrimSpace(fields[0])] = rate
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	normalized := map[string]float64{}
+	for code, rate := range rates {
+		normalized[strings.ToUpper(code)] = rate
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("No rates found in file")
+	}
+	return normalized, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPriceIn(t *testing.T) {
+	rates := map[string]float64{"RUB": 1, "EUR": 100, "USD": 90}
+	tests := []struct {
+		name     string
+		product  Product
+		currency string
+		want     Money
+		wantErr  bool
+	}{
+		{
+			name:     "own currency",
+			product:  Product{Price: 1500, Currency: "RUB"},
+			currency: "RUB",
+			want:     Money{Amount: 1500, Currency: "RUB"},
+		},
+		{
+			name:     "manual override",
+			product:  Product{Price: 1500, Currency: "RUB", Prices: map[string]float64{"EUR": 14.99}},
+			currency: "EUR",
+			want:     Money{Amount: 14.99, Currency: "EUR"},
+		},
+		{
+			name:     "converted and rounded to the currency decimals",
+			product:  Product{Price: 1000, Currency: "RUB"},
+			currency: "USD",
+			want:     Money{Amount: 11.11, Currency: "USD", Converted: true},
+		},
+		{
+			name:     "converted into a currency without decimals",
+			product:  Product{Price: 12.34, Currency: "EUR"},
+			currency: "RUB",
+			want:     Money{Amount: 1234, Currency: "RUB", Converted: true},
+		},
+		{
+			name:     "cross rate",
+			product:  Product{Price: 90, Currency: "EUR"},
+			currency: "USD",
+			want:     Money{Amount: 100, Currency: "USD", Converted: true},
+		},
+		{
+			name:     "no rate for the product currency",
+			product:  Product{Price: 10, Currency: "AED"},
+			currency: "RUB",
+			wantErr:  true,
+		},
+		{
+			name:     "no rate for the target currency",
+			product:  Product{Price: 10, Currency: "RUB"},
+			currency: "AED",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := priceIn(tt.product, tt.currency, rates)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.want.Format = currencyFormats[tt.currency]
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExchangeRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{name: "json", data: `{"eur": 98.5, "USD": 90}`, want: map[string]float64{"EUR": 98.5, "USD": 90}},
+		{name: "csv", data: "EUR,98.5\nusd;90\n", want: map[string]float64{"EUR": 98.5, "USD": 90}},
+		{name: "csv with comments and blanks", data: "# rates\n\nAED\t24.5\n", want: map[string]float64{"AED": 24.5}},
+		{name: "csv with spaces", data: " EUR , 98.5 ", want: map[string]float64{"EUR": 98.5}},
+		{name: "bad rate", data: "EUR,abc", wantErr: true},
+		{name: "too many fields", data: "EUR,98.5,x", wantErr: true},
+		{name: "bad json", data: `{"EUR": "x"}`, wantErr: true},
+		{name: "empty", data: "# nothing\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExchangeRates([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
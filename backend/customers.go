@@ -0,0 +1,358 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Customer is a customer account. Customers authenticate on the public API
+// with "Authorization: Bearer <token>", where the token is either an API key
+// issued by an admin or a session token from login; only hashes are stored.
+type Customer struct {
+	ID              int        `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Company         string     `json:"company"`
+	Phone           string     `json:"phone"`
+	LegalName       string     `json:"legal_name"`
+	TaxID           string     `json:"tax_id"`
+	Address         string     `json:"address"`
+	PriceListID     *int       `json:"price_list_id"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Version         int        `json:"version"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+const customerColumns = "id, email, name, company, phone, legal_name, tax_id, address, price_list_id, email_verified_at, created_at, version, updated_at"
+
+var customerRequiredFields = []string{"email"}
+
+var errInvalidCredentials = errors.New("invalid credentials")
+
+func scanCustomer(s rowScanner) (Customer, error) {
+	var c Customer
+	err := s.Scan(&c.ID, &c.Email, &c.Name, &c.Company, &c.Phone, &c.LegalName, &c.TaxID, &c.Address, &c.PriceListID, &c.EmailVerifiedAt, &c.CreatedAt, &c.Version, &c.UpdatedAt)
+	return c, err
+}
+
+func fetchCustomer(id int) (Customer, error) {
+	return scanCustomer(db.QueryRow("SELECT "+customerColumns+" FROM customers WHERE id = $1", id))
+}
+
+// uniqueViolation turns a unique constraint error into a validationError
+// with msg and returns other errors unchanged.
+func uniqueViolation(err error, msg string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return &validationError{msg: msg}
+	}
+	return err
+}
+
+func validateCustomer(c *Customer) error {
+	c.Email = strings.ToLower(strings.TrimSpace(c.Email))
+	if !validEmail(c.Email) {
+		return &validationError{msg: "Field \"email\" must be an email address"}
+	}
+	if c.PriceListID != nil {
+		var exists bool
+		if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM price_lists WHERE id = $1)", *c.PriceListID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return &validationError{msg: fmt.Sprintf("Price list %d does not exist", *c.PriceListID)}
+		}
+	}
+	return nil
+}
+
+// saveCustomer writes c if the row is still at version. See saveProduct.
+// The verification state and credentials are not written.
+func saveCustomer(c *Customer, version int) error {
+	if err := validateCustomer(c); err != nil {
+		return err
+	}
+
+	err := db.QueryRow(
+		"UPDATE customers SET email=$1, name=$2, company=$3, phone=$4, legal_name=$5, tax_id=$6, address=$7, price_list_id=$8, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$9 AND ($10 = -1 OR version=$10) RETURNING version, updated_at",
+		c.Email, c.Name, c.Company, c.Phone, c.LegalName, c.TaxID, c.Address, c.PriceListID, c.ID, version,
+	).Scan(&c.Version, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return versionConflict("customers", c.ID)
+	}
+	return uniqueViolation(err, "A customer with this email already exists")
+}
+
+func newAPIKey() (string, error) {
+	key := make([]byte, 24)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// customerFromRequest returns the customer authenticated by the bearer token
+// of r, nil for anonymous requests, or errInvalidCredentials.
+func customerFromRequest(r *http.Request) (*Customer, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, nil
+	}
+
+	c, err := scanCustomer(db.QueryRow(
+		`SELECT `+customerColumns+` FROM customers WHERE api_key_hash = $1
+		OR id = (SELECT customer_id FROM customer_tokens WHERE token_hash = $1 AND purpose = $2 AND expires_at > CURRENT_TIMESTAMP)`,
+		hashToken(token), tokenSession,
+	))
+	if err == sql.ErrNoRows {
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+}
+
+func getCustomers(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + customerColumns + " FROM customers"
+	var args []interface{}
+	if v := r.URL.Query().Get("price_list_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid price_list_id", http.StatusBadRequest)
+			return
+		}
+		query += " WHERE price_list_id = $1"
+		args = append(args, id)
+	}
+
+	rows, err := db.Query(query+" ORDER BY id", args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	customers := []Customer{}
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		customers = append(customers, c)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customers)
+}
+
+func getCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	c, err := fetchCustomer(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Customer not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, c.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// createCustomer creates an account and returns its API key. The key is only
+// shown once; use the api-key endpoint to issue a new one.
+func createCustomer(w http.ResponseWriter, r *http.Request) {
+	var c Customer
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateCustomer(&c); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+
+	key, err := newAPIKey()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = db.QueryRow(
+		"INSERT INTO customers (email, name, company, phone, legal_name, tax_id, address, price_list_id, api_key_hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, email_verified_at, created_at, version, updated_at",
+		c.Email, c.Name, c.Company, c.Phone, c.LegalName, c.TaxID, c.Address, c.PriceListID, hashToken(key),
+	).Scan(&c.ID, &c.EmailVerifiedAt, &c.CreatedAt, &c.Version, &c.UpdatedAt)
+	if err != nil {
+		writeSaveError(w, uniqueViolation(err, "A customer with this email already exists"), "")
+		return
+	}
+
+	setETag(w, c.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		Customer
+		APIKey string `json:"api_key"`
+	}{c, key})
+}
+
+func updateCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var c Customer
+	if err := decodeFull(r.Body, &c, customerRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	c.ID = id
+	if err := saveCustomer(&c, version); err != nil {
+		writeSaveError(w, err, "Customer not found")
+		return
+	}
+
+	// created_at and email_verified_at are not written by saveCustomer
+	if saved, err := fetchCustomer(id); err == nil {
+		c = saved
+	}
+
+	setETag(w, c.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+func patchCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := fetchCustomer(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Customer not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && c.Version != version {
+		writeSaveError(w, errVersionMismatch, "Customer not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &c, customerRequiredFields, "id", "email_verified_at", "created_at", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	c.ID = id
+	if err := saveCustomer(&c, c.Version); err != nil {
+		writeSaveError(w, err, "Customer not found")
+		return
+	}
+
+	setETag(w, c.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+func deleteCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteVersioned("customers", id, version); err != nil {
+		writeSaveError(w, err, "Customer not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rotateCustomerAPIKey issues a new API key, invalidating the previous one.
+func rotateCustomerAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	key, err := newAPIKey()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := db.Exec("UPDATE customers SET api_key_hash = $1 WHERE id = $2", hashToken(key), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "Customer not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"api_key": key})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// errVersionMismatch is returned by versioned writes when the row exists but
+// its version no longer matches the one the client based its change on.
+var errVersionMismatch = errors.New("version mismatch")
+
+// anyVersion is passed to versioned writes for "If-Match: *".
+const anyVersion = -1
+
+func entityETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", entityETag(version))
+}
+
+// ifMatchVersion extracts the expected row version from the If-Match header.
+// It writes 428 if the header is missing or 400 if it is not one of our
+// ETags, and reports whether the handler should continue.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (int, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		http.Error(w, "If-Match header is required", http.StatusPreconditionRequired)
+		return 0, false
+	}
+	if header == "*" {
+		return anyVersion, true
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || version < 0 {
+		http.Error(w, "Invalid If-Match header", http.StatusBadRequest)
+		return 0, false
+	}
+	return version, true
+}
+
+// versionConflict tells a missing row (sql.ErrNoRows) apart from a row whose
+// version changed (errVersionMismatch) after a versioned write matched nothing.
+func versionConflict(table string, id int) error {
+	query := "SELECT EXISTS(SELECT 1 FROM " + table + " WHERE id = $1"
+	if _, ok := softDeleteTables[table]; ok {
+		// Rows in the trash count as missing
+		query += " AND deleted_at IS NULL"
+	}
+
+	var exists bool
+	err := db.QueryRow(query+")", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errVersionMismatch
+	}
+	return sql.ErrNoRows
+}
+
+// deleteVersioned deletes the row with id if its version matches.
+func deleteVersioned(table string, id, version int) error {
+	result, err := db.Exec("DELETE FROM "+table+" WHERE id = $1 AND ($2 = -1 OR version = $2)", id, version)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return versionConflict(table, id)
+	}
+	return nil
+}
+
+// writeSaveError maps errors from versioned writes to a status code.
+func writeSaveError(w http.ResponseWriter, err error, notFound string) {
+	var ve *validationError
+	switch {
+	case errors.As(err, &ve):
+		http.Error(w, ve.Error(), http.StatusUnprocessableEntity)
+	case err == sql.ErrNoRows:
+		http.Error(w, notFound, http.StatusNotFound)
+	case err == errVersionMismatch:
+		http.Error(w, "Resource was modified by another request", http.StatusPreconditionFailed)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		header  string
+		version int
+		ok      bool
+		status  int
+	}{
+		{header: `"3"`, version: 3, ok: true},
+		{header: ` "0" `, version: 0, ok: true},
+		{header: `W/"7"`, version: 7, ok: true},
+		{header: `12`, version: 12, ok: true},
+		{header: `*`, version: anyVersion, ok: true},
+		{header: ``, status: http.StatusPreconditionRequired},
+		{header: `"abc"`, status: http.StatusBadRequest},
+		{header: `"-1"`, status: http.StatusBadRequest},
+		{header: `"1", "2"`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPut, "/api/admin/products/1", nil)
+		if tt.header != "" {
+			r.Header.Set("If-Match", tt.header)
+		}
+		w := httptest.NewRecorder()
+		version, ok := ifMatchVersion(w, r)
+		if ok != tt.ok || (ok && version != tt.version) {
+			t.Errorf("If-Match %q: got (%d, %v), want (%d, %v)", tt.header, version, ok, tt.version, tt.ok)
+		}
+		if !tt.ok && w.Code != tt.status {
+			t.Errorf("If-Match %q: status %d, want %d", tt.header, w.Code, tt.status)
+		}
+	}
+}
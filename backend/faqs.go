@@ -0,0 +1,313 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// faqGroups are the sections the FAQ is split into on the site. Items
+// without a group are general questions.
+var faqGroups = []string{"delivery", "payment", "warranty"}
+
+func validFAQGroup(group string) bool {
+	for _, g := range faqGroups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+func validateFAQ(faq *FAQ) error {
+	faq.Group = strings.TrimSpace(faq.Group)
+	if faq.Group != "" && !validFAQGroup(faq.Group) {
+		return &validationError{msg: "Field \"group\" must be one of " + strings.Join(faqGroups, ", ") + " or empty"}
+	}
+	var err error
+	if faq.ProductIDs, err = validateLinkedIDs(faq.ProductIDs, "products", "product_ids"); err != nil {
+		return err
+	}
+	if faq.CategoryIDs, err = validateLinkedIDs(faq.CategoryIDs, "categories", "category_ids"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// queryFAQs runs a query selecting faqColumns and loads the linked product
+// and category ids of the result.
+func queryFAQs(query string, args ...interface{}) ([]FAQ, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	faqs := []FAQ{}
+	for rows.Next() {
+		faq, err := scanFAQ(rows)
+		if err != nil {
+			return nil, err
+		}
+		faqs = append(faqs, faq)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return faqs, loadFAQLinks(faqs)
+}
+
+func loadFAQLinks(faqs []FAQ) error {
+	if len(faqs) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(faqs))
+	index := map[int]int{}
+	for i := range faqs {
+		ids[i] = int64(faqs[i].ID)
+		index[faqs[i].ID] = i
+		faqs[i].ProductIDs = []int{}
+		faqs[i].CategoryIDs = []int{}
+	}
+
+	for _, link := range []struct {
+		table, column string
+		field         func(faq *FAQ) *[]int
+	}{
+		{"faq_products", "product_id", func(faq *FAQ) *[]int { return &faq.ProductIDs }},
+		{"faq_categories", "category_id", func(faq *FAQ) *[]int { return &faq.CategoryIDs }},
+	} {
+		rows, err := db.Query("SELECT faq_id, "+link.column+" FROM "+link.table+" WHERE faq_id = ANY($1) ORDER BY "+link.column, pq.Array(ids))
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var faqID, id int
+			if err := rows.Scan(&faqID, &id); err != nil {
+				rows.Close()
+				return err
+			}
+			field := link.field(&faqs[index[faqID]])
+			*field = append(*field, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hideFAQLinks drops the ids of products that are not visible to r and of
+// trashed categories from faqs.
+func hideFAQLinks(r *http.Request, faqs []FAQ) error {
+	products := make([]*[]int, len(faqs))
+	categories := make([]*[]int, len(faqs))
+	for i := range faqs {
+		products[i] = &faqs[i].ProductIDs
+		categories[i] = &faqs[i].CategoryIDs
+	}
+	if err := hideLinks("products", visibleCondition(r, ""), products); err != nil {
+		return err
+	}
+	return hideLinks("categories", "deleted_at IS NULL", categories)
+}
+
+// replaceFAQLinks stores the product and category ids of faq.
+func replaceFAQLinks(tx *sql.Tx, faq *FAQ) error {
+	for _, link := range []struct {
+		table, column string
+		ids           []int
+	}{
+		{"faq_products", "product_id", faq.ProductIDs},
+		{"faq_categories", "category_id", faq.CategoryIDs},
+	} {
+		if _, err := tx.Exec("DELETE FROM "+link.table+" WHERE faq_id = $1", faq.ID); err != nil {
+			return err
+		}
+		for _, id := range link.ids {
+			if _, err := tx.Exec("INSERT INTO "+link.table+" (faq_id, "+link.column+") VALUES ($1, $2)", faq.ID, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// likeEscaper escapes the LIKE wildcards in user input, so that "%" or "_"
+// in a search matches literally; patterns are used with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchFAQs finds FAQ items whose question or answer contains ?q=, in the
+// default language or in a translation of the request locale. Matches in the
+// question come first. Narrowed with ?group=, paginated with ?limit=.
+func searchFAQs(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		http.Error(w, "Missing q parameter", http.StatusBadRequest)
+		return
+	}
+	limit, _ := pageParams(r, 20, 100)
+
+	args := []interface{}{"%" + likeEscaper.Replace(q) + "%", pq.Array(requestLocales(r)), limit}
+	query := "SELECT " + qualifyColumns("f", faqColumns) + ` FROM faqs f
+		WHERE (f.question ILIKE $1 ESCAPE '\' OR f.answer ILIKE $1 ESCAPE '\' OR EXISTS (
+			SELECT 1 FROM translations t
+			WHERE t.entity_type = 'faqs' AND t.entity_id = f.id AND t.locale = ANY($2) AND t.value ILIKE $1 ESCAPE '\'
+		))`
+	if group := r.URL.Query().Get("group"); group != "" {
+		if !validFAQGroup(group) {
+			http.Error(w, "Invalid group", http.StatusBadRequest)
+			return
+		}
+		args = append(args, group)
+		query += " AND f.faq_group = $" + strconv.Itoa(len(args))
+	}
+	query += ` ORDER BY (f.question ILIKE $1 ESCAPE '\') DESC, f."order" ASC, f.id ASC LIMIT $3`
+
+	faqs, err := queryFAQs(query, args...)
+	if err == nil {
+		err = hideFAQLinks(r, faqs)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "faqs", &faqs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(faqs)
+}
+
+// getProductFAQs lists the FAQ items attached to a product, or to the
+// category it belongs to. Products name their category rather than refer to
+// it by id, so categories are matched by name.
+func getProductFAQs(w http.ResponseWriter, r *http.Request) {
+	product, ok := publicProduct(w, r)
+	if !ok {
+		return
+	}
+
+	faqs, err := queryFAQs(
+		"SELECT "+qualifyColumns("f", faqColumns)+` FROM faqs f
+		WHERE EXISTS (SELECT 1 FROM faq_products fp WHERE fp.faq_id = f.id AND fp.product_id = $1)
+		OR EXISTS (
+			SELECT 1 FROM faq_categories fc JOIN categories c ON c.id = fc.category_id
+			WHERE fc.faq_id = f.id AND c.deleted_at IS NULL AND LOWER(c.name) = LOWER($2)
+		)
+		ORDER BY f."order" ASC, f.id ASC`,
+		product.ID, product.Category,
+	)
+	if err == nil {
+		err = hideFAQLinks(r, faqs)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "faqs", &faqs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(faqs)
+}
+
+// validateFAQOrder checks that ids lists every existing FAQ exactly once.
+func validateFAQOrder(ids []int, existing map[int]bool) error {
+	seen := map[int]bool{}
+	for _, id := range ids {
+		if !existing[id] {
+			return &validationError{msg: "FAQ " + strconv.Itoa(id) + " does not exist"}
+		}
+		if seen[id] {
+			return &validationError{msg: "FAQ " + strconv.Itoa(id) + " is listed twice"}
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(existing) {
+		return &validationError{msg: "Field \"ids\" must list every FAQ"}
+	}
+	return nil
+}
+
+// reorderFAQs sets the display order of the whole FAQ at once. The body
+// lists every FAQ id in the new order; anything else is rejected so that a
+// stale list cannot leave items out. Only the rows whose position changed get
+// a new version.
+func reorderFAQs(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs []int `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id FROM faqs ORDER BY id FOR UPDATE")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	existing := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		existing[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := validateFAQOrder(req.IDs, existing); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+
+	ids := make([]int64, len(req.IDs))
+	for i, id := range req.IDs {
+		ids[i] = int64(id)
+	}
+	_, err = tx.Exec(
+		`UPDATE faqs SET "order" = v.position, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		FROM unnest($1::int[]) WITH ORDINALITY AS v(id, position)
+		WHERE faqs.id = v.id AND faqs."order" IS DISTINCT FROM v.position`,
+		pq.Array(ids),
+	)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	faqs, err := queryFAQs(`SELECT ` + faqColumns + ` FROM faqs ORDER BY "order" ASC, id ASC`)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(faqs)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateFAQOrder(t *testing.T) {
+	existing := map[int]bool{1: true, 2: true, 3: true}
+	tests := []struct {
+		name    string
+		ids     []int
+		wantErr string
+	}{
+		{name: "every FAQ", ids: []int{3, 1, 2}},
+		{name: "unknown id", ids: []int{1, 2, 3, 4}, wantErr: "FAQ 4 does not exist"},
+		{name: "listed twice", ids: []int{1, 2, 2, 3}, wantErr: "FAQ 2 is listed twice"},
+		{name: "missing id", ids: []int{1, 3}, wantErr: `Field "ids" must list every FAQ`},
+		{name: "empty list", ids: nil, wantErr: `Field "ids" must list every FAQ`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFAQOrder(tt.ids, existing)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var ve *validationError
+			if !errors.As(err, &ve) || ve.msg != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := validateFAQOrder(nil, map[int]bool{}); err != nil {
+		t.Errorf("empty FAQ: unexpected error: %v", err)
+	}
+}
@@ -7,3 +7,5 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/rs/cors v1.10.1
 )
+
+require golang.org/x/crypto v0.31.0
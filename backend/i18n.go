@@ -0,0 +1,480 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// translatableFields lists, per entity type, the JSON fields that can be
+// translated. The base columns always hold the defaultLocale text.
+var translatableFields = map[string][]string{
+	"products":     {"name", "category", "description", "color", "dimensions", "material", "features", "meta_title", "meta_description"},
+	"categories":   {"name", "description", "meta_title", "meta_description"},
+	"collections":  {"name", "description", "meta_title", "meta_description"},
+	"faqs":         {"question", "answer"},
+	"placeholders": {"title", "message"},
+	"projects":     {"hotel_name", "description", "client_quote", "client_name"},
+}
+
+var defaultLocale = envOr("DEFAULT_LOCALE", "ru")
+
+// supportedLocales is configured with SUPPORTED_LOCALES (comma-separated).
+var supportedLocales = strings.Split(envOr("SUPPORTED_LOCALES", "ru,en,zh"), ",")
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func isSupportedLocale(locale string) bool {
+	for _, l := range supportedLocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+func isTranslatableField(entityType, field string) bool {
+	for _, f := range translatableFields[entityType] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// requestLocales returns the fallback chain for a request: ?lang= first, then
+// Accept-Language in preference order, each followed by its base language,
+// always ending with defaultLocale. Admin reads only honour ?lang= so that
+// edit forms never load translated text into the base columns.
+func requestLocales(r *http.Request) []string {
+	var candidates []string
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		candidates = append(candidates, lang)
+	}
+	if !viaAdminRoute(r) {
+		candidates = append(candidates, parseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+	}
+
+	var chain []string
+	seen := map[string]bool{}
+	add := func(locale string) {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		if locale != "" && !seen[locale] && isSupportedLocale(locale) {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, c := range candidates {
+		add(c)
+		if i := strings.IndexAny(c, "-_"); i > 0 {
+			add(c[:i])
+		}
+	}
+	add(defaultLocale)
+	if len(chain) == 0 {
+		chain = []string{defaultLocale}
+	}
+	return chain
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language header
+// ordered by their q-value.
+func parseAcceptLanguage(header string) []string {
+	type tag struct {
+		name string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.TrimSpace(fields[0])
+		if name == "" || name == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if v := strings.TrimPrefix(strings.TrimSpace(param), "q="); v != param {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, tag{name, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.name
+	}
+	return names
+}
+
+// jsonField finds the struct field carrying the given JSON name.
+func jsonField(v reflect.Value, name string) reflect.Value {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if tag == name {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// localize overlays translations for the request's locale chain onto target,
+// which is a pointer to an entity struct or to a slice of them. It sets the
+// Content-Language of the response to the first locale of the chain.
+func localize(w http.ResponseWriter, r *http.Request, entityType string, target interface{}) error {
+	chain := requestLocales(r)
+	w.Header().Set("Content-Language", chain[0])
+	w.Header().Add("Vary", "Accept-Language")
+	if chain[0] == defaultLocale {
+		return nil
+	}
+
+	v := reflect.ValueOf(target).Elem()
+	var items []reflect.Value
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i))
+		}
+	} else {
+		items = append(items, v)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = jsonField(item, "id").Int()
+	}
+
+	rows, err := db.Query(
+		"SELECT entity_id, locale, field, value FROM translations WHERE entity_type = $1 AND entity_id = ANY($2) AND locale = ANY($3)",
+		entityType, pq.Array(ids), pq.Array(chain),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// translations[id][field][locale] = value
+	translations := map[int64]map[string]map[string]string{}
+	for rows.Next() {
+		var id int64
+		var locale, field, value string
+		if err := rows.Scan(&id, &locale, &field, &value); err != nil {
+			return err
+		}
+		if translations[id] == nil {
+			translations[id] = map[string]map[string]string{}
+		}
+		if translations[id][field] == nil {
+			translations[id][field] = map[string]string{}
+		}
+		translations[id][field][locale] = value
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		for field, byLocale := range translations[ids[i]] {
+			for _, locale := range chain {
+				if locale == defaultLocale {
+					break
+				}
+				value, ok := byLocale[locale]
+				if !ok {
+					continue
+				}
+				setTranslatedField(jsonField(item, field), value)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// setTranslatedField writes a stored translation into a string or []string
+// field; list fields are stored as JSON arrays.
+func setTranslatedField(f reflect.Value, value string) {
+	if !f.IsValid() || !f.CanSet() {
+		return
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Slice:
+		var list []string
+		if json.Unmarshal([]byte(value), &list) == nil {
+			f.Set(reflect.ValueOf(list))
+		}
+	}
+}
+
+// translationTarget validates the {type} and {id} route variables.
+func translationTarget(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	vars := mux.Vars(r)
+	entityType := vars["type"]
+	if _, ok := translatableFields[entityType]; !ok {
+		http.Error(w, "Invalid entity type", http.StatusBadRequest)
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return entityType, id, true
+}
+
+func getTranslations(w http.ResponseWriter, r *http.Request) {
+	entityType, id, ok := translationTarget(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.Query("SELECT locale, field, value FROM translations WHERE entity_type = $1 AND entity_id = $2", entityType, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := map[string]map[string]interface{}{}
+	for rows.Next() {
+		var locale, field, value string
+		if err := rows.Scan(&locale, &field, &value); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if result[locale] == nil {
+			result[locale] = map[string]interface{}{}
+		}
+		var list []string
+		if json.Unmarshal([]byte(value), &list) == nil {
+			result[locale][field] = list
+		} else {
+			result[locale][field] = value
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// updateTranslations replaces the translations of one locale of an entity.
+// Fields sent as null or "" are removed so the locale falls back again.
+func updateTranslations(w http.ResponseWriter, r *http.Request) {
+	entityType, id, ok := translationTarget(w, r)
+	if !ok {
+		return
+	}
+	locale := mux.Vars(r)["locale"]
+	if !isSupportedLocale(locale) || locale == defaultLocale {
+		http.Error(w, fmt.Sprintf("Locale must be one of %s other than %s", strings.Join(supportedLocales, ", "), defaultLocale), http.StatusUnprocessableEntity)
+		return
+	}
+
+	exists, err := auditLoaders[entityType](id)
+	if err == sql.ErrNoRows || exists == nil {
+		http.Error(w, "Entity not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	values := map[string]string{}
+	for field, raw := range fields {
+		if !isTranslatableField(entityType, field) {
+			http.Error(w, fmt.Sprintf("Field %q is not translatable", field), http.StatusUnprocessableEntity)
+			return
+		}
+		var text string
+		var list []string
+		switch {
+		case string(raw) == "null":
+		case json.Unmarshal(raw, &text) == nil:
+			values[field] = text
+		case json.Unmarshal(raw, &list) == nil:
+			if len(list) > 0 {
+				values[field] = string(raw)
+			}
+		default:
+			http.Error(w, fmt.Sprintf("Field %q must be a string or a list of strings", field), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM translations WHERE entity_type = $1 AND entity_id = $2 AND locale = $3", entityType, id, locale); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for field, value := range values {
+		if value == "" {
+			continue
+		}
+		_, err := tx.Exec(
+			"INSERT INTO translations (entity_type, entity_id, locale, field, value) VALUES ($1, $2, $3, $4, $5)",
+			entityType, id, locale, field, value,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	getTranslations(w, r)
+}
+
+type MissingTranslation struct {
+	Type    string   `json:"type"`
+	ID      int      `json:"id"`
+	Label   string   `json:"label"`
+	Locale  string   `json:"locale"`
+	Missing []string `json:"missing"`
+}
+
+// translationSources lists the live rows of each translatable entity type
+// with the base text of their translatable fields.
+var translationSources = map[string]string{
+	"products":     "SELECT id, name, json_build_object('name', name, 'category', category, 'description', description, 'color', color, 'dimensions', dimensions, 'material', material, 'features', features, 'meta_title', meta_title, 'meta_description', meta_description) FROM products WHERE deleted_at IS NULL ORDER BY id",
+	"categories":   "SELECT id, name, json_build_object('name', name, 'description', description, 'meta_title', meta_title, 'meta_description', meta_description) FROM categories WHERE deleted_at IS NULL ORDER BY id",
+	"collections":  "SELECT id, name, json_build_object('name', name, 'description', description, 'meta_title', meta_title, 'meta_description', meta_description) FROM collections WHERE deleted_at IS NULL ORDER BY id",
+	"faqs":         "SELECT id, question, json_build_object('question', question, 'answer', answer) FROM faqs ORDER BY id",
+	"placeholders": "SELECT id, path, json_build_object('title', title, 'message', message) FROM placeholders ORDER BY id",
+	"projects":     "SELECT id, hotel_name, json_build_object('hotel_name', hotel_name, 'description', description, 'client_quote', client_quote, 'client_name', client_name) FROM projects ORDER BY id",
+}
+
+// getMissingTranslations reports, per entity and locale, which translatable
+// fields with base text have no translation. Filter with ?type= and ?locale=.
+func getMissingTranslations(w http.ResponseWriter, r *http.Request) {
+	types := []string{"products", "categories", "collections", "faqs", "placeholders", "projects"}
+	if t := r.URL.Query().Get("type"); t != "" {
+		if _, ok := translatableFields[t]; !ok {
+			http.Error(w, "Invalid entity type", http.StatusBadRequest)
+			return
+		}
+		types = []string{t}
+	}
+
+	var locales []string
+	for _, l := range supportedLocales {
+		if l != defaultLocale {
+			locales = append(locales, l)
+		}
+	}
+	if l := r.URL.Query().Get("locale"); l != "" {
+		if !isSupportedLocale(l) || l == defaultLocale {
+			http.Error(w, "Invalid locale", http.StatusBadRequest)
+			return
+		}
+		locales = []string{l}
+	}
+
+	result := []MissingTranslation{}
+	for _, entityType := range types {
+		existing, err := existingTranslations(entityType)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rows, err := db.Query(translationSources[entityType])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for rows.Next() {
+			var id int
+			var label string
+			var baseJSON []byte
+			if err := rows.Scan(&id, &label, &baseJSON); err != nil {
+				rows.Close()
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			var base map[string]interface{}
+			json.Unmarshal(baseJSON, &base)
+
+			for _, locale := range locales {
+				var missing []string
+				for _, field := range translatableFields[entityType] {
+					text, _ := base[field].(string)
+					if strings.TrimSpace(text) == "" {
+						continue
+					}
+					if !existing[fmt.Sprintf("%d/%s/%s", id, locale, field)] {
+						missing = append(missing, field)
+					}
+				}
+				if len(missing) > 0 {
+					result = append(result, MissingTranslation{Type: entityType, ID: id, Label: label, Locale: locale, Missing: missing})
+				}
+			}
+		}
+		rows.Close()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// existingTranslations returns the set of "id/locale/field" keys translated
+// for an entity type.
+func existingTranslations(entityType string) (map[string]bool, error) {
+	rows, err := db.Query("SELECT entity_id, locale, field FROM translations WHERE entity_type = $1", entityType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := map[string]bool{}
+	for rows.Next() {
+		var id int
+		var locale, field string
+		if err := rows.Scan(&id, &locale, &field); err != nil {
+			return nil, err
+		}
+		existing[fmt.Sprintf("%d/%s/%s", id, locale, field)] = true
+	}
+	return existing, rows.Err()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{header: "", want: []string{}},
+		{header: "en", want: []string{"en"}},
+		{header: "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7", want: []string{"ru-RU", "ru", "en-US", "en"}},
+		{header: "en;q=0.5, zh;q=0.8, ru", want: []string{"ru", "zh", "en"}},
+		{header: "de;q=0, en", want: []string{"en"}},
+		{header: "*, en;q=0.1", want: []string{"en"}},
+		{header: "fr;q=abc", want: []string{"fr"}},
+		{header: "en;level=1;q=0.4,ru", want: []string{"ru", "en"}},
+	}
+	for _, tt := range tests {
+		if got := parseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRequestLocales(t *testing.T) {
+	defer func(locales []string, def string) { supportedLocales, defaultLocale = locales, def }(supportedLocales, defaultLocale)
+	supportedLocales, defaultLocale = []string{"ru", "en", "zh"}, "ru"
+
+	tests := []struct {
+		name           string
+		url            string
+		acceptLanguage string
+		admin          bool
+		want           []string
+	}{
+		{name: "nothing asked", url: "/api/products", want: []string{"ru"}},
+		{name: "lang parameter", url: "/api/products?lang=en", want: []string{"en", "ru"}},
+		{name: "region falls back to base", url: "/api/products?lang=en-GB", want: []string{"en", "ru"}},
+		{name: "accept-language order", url: "/api/products", acceptLanguage: "zh-CN,en;q=0.5", want: []string{"zh", "en", "ru"}},
+		{name: "lang beats header", url: "/api/products?lang=EN", acceptLanguage: "zh", want: []string{"en", "zh", "ru"}},
+		{name: "unsupported skipped", url: "/api/products?lang=de", acceptLanguage: "fr, en;q=0.2", want: []string{"en", "ru"}},
+		{name: "admin ignores header", url: "/api/admin/products", acceptLanguage: "en", admin: true, want: []string{"ru"}},
+		{name: "admin honours lang", url: "/api/admin/products?lang=zh", admin: true, want: []string{"zh", "ru"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			if tt.admin {
+				r = r.WithContext(context.WithValue(r.Context(), draftsContextKey{}, true))
+			}
+			if got := requestLocales(r); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requestLocales = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// siteURL is the public address of the frontend, used for links in emails.
+var siteURL = strings.TrimRight(envOr("SITE_URL", "http://localhost:3000"), "/")
+
+// sendMail sends a plain-text email over SMTP configured with SMTP_HOST,
+// SMTP_PORT (default 587), SMTP_USER, SMTP_PASSWORD and SMTP_FROM. Without
+// SMTP_HOST the message is only logged, which is enough for development.
+func sendMail(to, subject, body string, headers ...string) error {
+	host := os.Getenv("SMTP_HOST")
+	from := envOr("SMTP_FROM", "no-reply@localhost")
+	if host == "" {
+		log.Printf("SMTP_HOST not set, email to %s not sent:\nSubject: %s\n%s", to, subject, body)
+		return nil
+	}
+	port := envOr("SMTP_PORT", "587")
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	for _, h := range headers {
+		msg.WriteString(h + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+	return smtp.SendMail(host+":"+port, auth, from, []string{to}, []byte(msg.String()))
+}
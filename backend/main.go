@@ -22,63 +22,111 @@ import (
 )
 
 type Product struct {
-	ID          int      `json:"id"`
-	Name        string   `json:"name"`
-	Category    string   `json:"category"`
-	Price       float64  `json:"price"`
-	Rating      float64  `json:"rating"`
-	Reviews     int      `json:"reviews"`
-	Description string   `json:"description"`
-	Image       string   `json:"image"`  // Main image (for backward compatibility)
-	Images      []string `json:"images"` // Array of images
-	Color       string   `json:"color"`
-	Dimensions  string   `json:"dimensions"`
-	Material    string   `json:"material"`
-	Features    []string `json:"features"`
-	Featured    bool     `json:"featured"` // Recommended product flag
+	ID              int                `json:"id"`
+	Name            string             `json:"name"`
+	Category        string             `json:"category"`
+	Price           float64            `json:"price"`
+	Currency        string             `json:"currency"`         // currency of Price
+	Prices          map[string]float64 `json:"prices,omitempty"` // manual overrides per currency
+	DisplayPrice    *Money             `json:"display_price,omitempty"`
+	RetailPrice     *float64           `json:"retail_price,omitempty"` // set when a customer price list applies
+	PriceList       string             `json:"price_list,omitempty"`
+	Rating          float64            `json:"rating"`  // average of approved reviews
+	Reviews         int                `json:"reviews"` // number of approved reviews
+	Description     string             `json:"description"`
+	Image           string             `json:"image"`  // Main image (for backward compatibility)
+	Images          []string           `json:"images"` // Array of images
+	Color           string             `json:"color"`
+	Dimensions      string             `json:"dimensions"`
+	Material        string             `json:"material"`
+	Features        []string           `json:"features"`
+	Featured        bool               `json:"featured"` // Recommended product flag
+	Slug            string             `json:"slug"`
+	MetaTitle       string             `json:"meta_title"`
+	MetaDescription string             `json:"meta_description"`
+	CanonicalURL    string             `json:"canonical_url"`
+	OGImage         string             `json:"og_image"`
+	Status          string             `json:"status"` // draft, published or archived
+	PublishAt       *time.Time         `json:"publish_at"`
+	UnpublishAt     *time.Time         `json:"unpublish_at"`
+	Version         int                `json:"version"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	DeletedAt       *time.Time         `json:"deleted_at,omitempty"`
 }
 
 type Category struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Icon        string `json:"icon"`
-	Href        string `json:"href"`
-	Image       string `json:"image"`
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Icon            string     `json:"icon"`
+	Href            string     `json:"href"`
+	Image           string     `json:"image"`
+	Slug            string     `json:"slug"`
+	MetaTitle       string     `json:"meta_title"`
+	MetaDescription string     `json:"meta_description"`
+	CanonicalURL    string     `json:"canonical_url"`
+	OGImage         string     `json:"og_image"`
+	Version         int        `json:"version"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Collection struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Image       string    `json:"image"`
-	Count       int       `json:"count"`
-	Products    []Product `json:"products,omitempty"`
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Image           string     `json:"image"`
+	Count           int        `json:"count"`
+	Products        []Product  `json:"products,omitempty"`
+	Slug            string     `json:"slug"`
+	MetaTitle       string     `json:"meta_title"`
+	MetaDescription string     `json:"meta_description"`
+	CanonicalURL    string     `json:"canonical_url"`
+	OGImage         string     `json:"og_image"`
+	Status          string     `json:"status"` // draft, published or archived
+	PublishAt       *time.Time `json:"publish_at"`
+	UnpublishAt     *time.Time `json:"unpublish_at"`
+	Version         int        `json:"version"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Contact struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Message   string    `json:"message"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Phone      string     `json:"phone"`
+	Message    string     `json:"message"`
+	CustomerID *int       `json:"customer_id,omitempty"` // set when sent by a logged-in customer
+	Handled    bool       `json:"handled"`               // a manager has followed up on the lead
+	HandledAt  *time.Time `json:"handled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 type Placeholder struct {
-	ID          int    `json:"id"`
-	Path        string `json:"path"`
-	Title       string `json:"title"`
-	Message     string `json:"message"`
-	IsActive    bool   `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID        int        `json:"id"`
+	Path      string     `json:"path"` // exact path or pattern, see placeholderMatches
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	IsActive  bool       `json:"is_active"`
+	Priority  int        `json:"priority"` // higher wins when several placeholders match
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	Version   int        `json:"version"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
 
 type FAQ struct {
-	ID       int    `json:"id"`
-	Question string `json:"question"`
-	Answer   string `json:"answer"`
-	Order    int    `json:"order"`
+	ID          int       `json:"id"`
+	Question    string    `json:"question"`
+	Answer      string    `json:"answer"`
+	Order       int       `json:"order"`
+	Group       string    `json:"group"` // delivery, payment, warranty or empty
+	ProductIDs  []int     `json:"product_ids"`
+	CategoryIDs []int     `json:"category_ids"`
+	Version     int       `json:"version"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 var db *sql.DB
@@ -115,6 +163,8 @@ func initDB() {
 	createTables()
 	// Initialize default data if tables are empty
 	initDefaultData()
+	backfillSlugs()
+	ensureAdminUser()
 }
 
 func createTables() {
@@ -181,6 +231,360 @@ func createTables() {
 			answer TEXT NOT NULL,
 			"order" INTEGER DEFAULT 0
 		)`,
+		// Row versions for optimistic concurrency control (exposed as ETags)
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		`ALTER TABLE categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`ALTER TABLE categories ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		`ALTER TABLE collections ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`ALTER TABLE collections ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		`ALTER TABLE placeholders ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`ALTER TABLE placeholders ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		`ALTER TABLE placeholders ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE placeholders ADD COLUMN IF NOT EXISTS starts_at TIMESTAMP`,
+		`ALTER TABLE placeholders ADD COLUMN IF NOT EXISTS ends_at TIMESTAMP`,
+		`ALTER TABLE faqs ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`ALTER TABLE faqs ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		// Soft deletion: trashed rows keep their collection memberships until purged
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP`,
+		`ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP`,
+		`ALTER TABLE collections ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP`,
+		// Publication workflow; rows that existed before it stay visible
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'RUB'`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS prices TEXT`,
+		`CREATE TABLE IF NOT EXISTS exchange_rates (
+			currency VARCHAR(3) PRIMARY KEY,
+			rate DECIMAL(18,6) NOT NULL,
+			source VARCHAR(20) NOT NULL DEFAULT 'manual',
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMP`,
+		`ALTER TABLE collections ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'`,
+		`ALTER TABLE collections ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP`,
+		`ALTER TABLE collections ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMP`,
+		auditLogTable,
+		`CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity_type, entity_id)`,
+		`CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at)`,
+		`CREATE TABLE IF NOT EXISTS revisions (
+			id SERIAL PRIMARY KEY,
+			entity_type VARCHAR(50) NOT NULL,
+			entity_id INTEGER NOT NULL,
+			version INTEGER NOT NULL,
+			data JSONB NOT NULL,
+			actor VARCHAR(255),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (entity_type, entity_id, version)
+		)`,
+		`CREATE TABLE IF NOT EXISTS translations (
+			entity_type VARCHAR(50) NOT NULL,
+			entity_id INTEGER NOT NULL,
+			locale VARCHAR(10) NOT NULL,
+			field VARCHAR(50) NOT NULL,
+			value TEXT NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (entity_type, entity_id, locale, field)
+		)`,
+		`CREATE TABLE IF NOT EXISTS price_lists (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL UNIQUE,
+			description TEXT NOT NULL DEFAULT '',
+			adjustment_percent DECIMAL(6,2) NOT NULL DEFAULT 0,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS price_list_items (
+			price_list_id INTEGER REFERENCES price_lists(id) ON DELETE CASCADE,
+			product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+			price DECIMAL(10,2),
+			adjustment_percent DECIMAL(6,2),
+			PRIMARY KEY (price_list_id, product_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS customers (
+			id SERIAL PRIMARY KEY,
+			email VARCHAR(255) NOT NULL UNIQUE,
+			name VARCHAR(255) NOT NULL DEFAULT '',
+			company VARCHAR(255) NOT NULL DEFAULT '',
+			price_list_id INTEGER REFERENCES price_lists(id) ON DELETE SET NULL,
+			api_key_hash VARCHAR(64) UNIQUE,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`ALTER TABLE customers ADD COLUMN IF NOT EXISTS phone VARCHAR(50) NOT NULL DEFAULT ''`,
+		`ALTER TABLE customers ADD COLUMN IF NOT EXISTS legal_name VARCHAR(255) NOT NULL DEFAULT ''`,
+		`ALTER TABLE customers ADD COLUMN IF NOT EXISTS tax_id VARCHAR(50) NOT NULL DEFAULT ''`,
+		`ALTER TABLE customers ADD COLUMN IF NOT EXISTS address TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE customers ADD COLUMN IF NOT EXISTS password_hash VARCHAR(255)`,
+		`ALTER TABLE customers ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP`,
+		`CREATE TABLE IF NOT EXISTS customer_tokens (
+			token_hash VARCHAR(64) PRIMARY KEY,
+			customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+			purpose VARCHAR(20) NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`ALTER TABLE contacts ADD COLUMN IF NOT EXISTS customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL`,
+		`ALTER TABLE contacts ADD COLUMN IF NOT EXISTS handled BOOLEAN NOT NULL DEFAULT false`,
+		`ALTER TABLE contacts ADD COLUMN IF NOT EXISTS handled_at TIMESTAMP`,
+		`CREATE TABLE IF NOT EXISTS quotes (
+			id SERIAL PRIMARY KEY,
+			customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL,
+			contact_id INTEGER REFERENCES contacts(id) ON DELETE SET NULL,
+			items JSONB NOT NULL DEFAULT '[]',
+			currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+			total DECIMAL(12,2) NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL DEFAULT 'draft',
+			valid_until TIMESTAMP,
+			notes TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Rows from before the currency column were priced in rubles; new rows
+		// name their currency, baseCurrency unless the admin picks another one
+		`ALTER TABLE products ALTER COLUMN currency DROP DEFAULT`,
+		`ALTER TABLE quotes ALTER COLUMN currency DROP DEFAULT`,
+		`CREATE TABLE IF NOT EXISTS boards (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			customer_id INTEGER REFERENCES customers(id) ON DELETE CASCADE,
+			owner_token_hash VARCHAR(64),
+			share_token VARCHAR(64) UNIQUE,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS boards_owner_token_idx ON boards (owner_token_hash)`,
+		`CREATE TABLE IF NOT EXISTS board_items (
+			board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+			product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			quantity INTEGER NOT NULL DEFAULT 1,
+			notes TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (board_id, product_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS reviews (
+			id SERIAL PRIMARY KEY,
+			product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			customer_id INTEGER REFERENCES customers(id) ON DELETE SET NULL,
+			author VARCHAR(255) NOT NULL,
+			hotel_name VARCHAR(255) NOT NULL DEFAULT '',
+			rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+			text TEXT NOT NULL,
+			photos TEXT NOT NULL DEFAULT '[]',
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			moderation_note TEXT NOT NULL DEFAULT '',
+			moderated_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS reviews_product_idx ON reviews (product_id, status, created_at DESC)`,
+		`CREATE TABLE IF NOT EXISTS product_relations (
+			product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			related_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			kind VARCHAR(20) NOT NULL,
+			position INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (product_id, related_id)
+		)`,
+		// Raw analytics events are append-only; reports read the daily rollups
+		`CREATE TABLE IF NOT EXISTS events (
+			id BIGSERIAL PRIMARY KEY,
+			type VARCHAR(30) NOT NULL,
+			session_id VARCHAR(64) NOT NULL,
+			product_id INTEGER,
+			query TEXT,
+			results INTEGER,
+			path TEXT,
+			data JSONB,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS events_created_at_idx ON events (created_at)`,
+		`CREATE TABLE IF NOT EXISTS analytics_sessions_daily (
+			day DATE NOT NULL,
+			session_id VARCHAR(64) NOT NULL,
+			events INTEGER NOT NULL,
+			product_views INTEGER NOT NULL,
+			gallery_interactions INTEGER NOT NULL,
+			contact_opens INTEGER NOT NULL,
+			contact_submits INTEGER NOT NULL,
+			searches INTEGER NOT NULL,
+			first_seen TIMESTAMP NOT NULL,
+			last_seen TIMESTAMP NOT NULL,
+			PRIMARY KEY (day, session_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS analytics_products_daily (
+			day DATE NOT NULL,
+			product_id INTEGER NOT NULL,
+			views INTEGER NOT NULL,
+			sessions INTEGER NOT NULL,
+			gallery_interactions INTEGER NOT NULL,
+			contact_opens INTEGER NOT NULL,
+			PRIMARY KEY (day, product_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS analytics_searches_daily (
+			day DATE NOT NULL,
+			query TEXT NOT NULL,
+			searches INTEGER NOT NULL,
+			zero_results INTEGER NOT NULL,
+			PRIMARY KEY (day, query)
+		)`,
+		`ALTER TABLE products ADD COLUMN IF NOT EXISTS slug VARCHAR(200) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS meta_title VARCHAR(255) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS meta_description TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS canonical_url TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS og_image TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug VARCHAR(200) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS meta_title VARCHAR(255) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS meta_description TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS canonical_url TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS og_image TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE collections ADD COLUMN IF NOT EXISTS slug VARCHAR(200) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS meta_title VARCHAR(255) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS meta_description TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS canonical_url TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS og_image TEXT NOT NULL DEFAULT ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS products_slug_key ON products (slug) WHERE slug <> ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS categories_slug_key ON categories (slug) WHERE slug <> ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS collections_slug_key ON collections (slug) WHERE slug <> ''`,
+		`CREATE TABLE IF NOT EXISTS redirects (
+			id SERIAL PRIMARY KEY,
+			from_path VARCHAR(500) NOT NULL UNIQUE,
+			to_path VARCHAR(500) NOT NULL,
+			status_code INTEGER NOT NULL DEFAULT 301,
+			auto BOOLEAN NOT NULL DEFAULT FALSE,
+			hits INTEGER NOT NULL DEFAULT 0,
+			last_hit_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS redirects_to_path_idx ON redirects (to_path)`,
+		`CREATE TABLE IF NOT EXISTS settings (
+			key VARCHAR(100) PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// CMS content: named sections of a page holding typed blocks per locale
+		`CREATE TABLE IF NOT EXISTS content_sections (
+			id SERIAL PRIMARY KEY,
+			page VARCHAR(100) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			title VARCHAR(255) NOT NULL DEFAULT '',
+			"order" INTEGER NOT NULL DEFAULT 0,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (page, name)
+		)`,
+		`CREATE TABLE IF NOT EXISTS content_blocks (
+			id SERIAL PRIMARY KEY,
+			section_id INTEGER NOT NULL REFERENCES content_sections(id) ON DELETE CASCADE,
+			type VARCHAR(20) NOT NULL,
+			locale VARCHAR(10) NOT NULL,
+			"order" INTEGER NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL DEFAULT 'draft',
+			data JSONB NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS content_blocks_section_idx ON content_blocks (section_id, locale, "order")`,
+		// Portfolio: completed projects and the products and collections used in them
+		`CREATE TABLE IF NOT EXISTS projects (
+			id SERIAL PRIMARY KEY,
+			slug VARCHAR(200) NOT NULL UNIQUE,
+			hotel_name VARCHAR(255) NOT NULL,
+			city VARCHAR(100) NOT NULL,
+			year INTEGER NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			gallery JSONB NOT NULL DEFAULT '[]',
+			client_quote TEXT NOT NULL DEFAULT '',
+			client_name VARCHAR(255) NOT NULL DEFAULT '',
+			status VARCHAR(20) NOT NULL DEFAULT 'draft',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			version INTEGER NOT NULL DEFAULT 1,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS project_products (
+			project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+			product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (project_id, product_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS project_products_product_idx ON project_products (product_id)`,
+		`CREATE TABLE IF NOT EXISTS project_collections (
+			project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+			collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (project_id, collection_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS project_collections_collection_idx ON project_collections (collection_id)`,
+		// Newsletter: double opt-in subscribers with their consent record, and campaigns
+		`CREATE TABLE IF NOT EXISTS newsletter_subscribers (
+			id SERIAL PRIMARY KEY,
+			email VARCHAR(255) NOT NULL UNIQUE,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			source VARCHAR(100) NOT NULL DEFAULT '',
+			consent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			consent_ip VARCHAR(45) NOT NULL DEFAULT '',
+			confirm_token_hash VARCHAR(64),
+			confirm_expires_at TIMESTAMP,
+			confirm_sent_at TIMESTAMP,
+			confirmed_at TIMESTAMP,
+			confirmed_ip VARCHAR(45) NOT NULL DEFAULT '',
+			unsubscribe_token VARCHAR(64) NOT NULL UNIQUE,
+			unsubscribed_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS newsletter_subscribers_confirm_idx ON newsletter_subscribers (confirm_token_hash)`,
+		`CREATE TABLE IF NOT EXISTS newsletter_campaigns (
+			id SERIAL PRIMARY KEY,
+			subject VARCHAR(255) NOT NULL,
+			body TEXT NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'sending',
+			recipients INTEGER NOT NULL DEFAULT 0,
+			sent INTEGER NOT NULL DEFAULT 0,
+			failed INTEGER NOT NULL DEFAULT 0,
+			last_subscriber_id INTEGER NOT NULL DEFAULT 0,
+			created_by VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			finished_at TIMESTAMP
+		)`,
+		// FAQ groups and the products and categories an item is shown on
+		`ALTER TABLE faqs ADD COLUMN IF NOT EXISTS faq_group VARCHAR(20) NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS faqs_group_idx ON faqs (faq_group)`,
+		`CREATE TABLE IF NOT EXISTS faq_products (
+			faq_id INTEGER NOT NULL REFERENCES faqs(id) ON DELETE CASCADE,
+			product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			PRIMARY KEY (faq_id, product_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS faq_products_product_idx ON faq_products (product_id)`,
+		`CREATE TABLE IF NOT EXISTS faq_categories (
+			faq_id INTEGER NOT NULL REFERENCES faqs(id) ON DELETE CASCADE,
+			category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+			PRIMARY KEY (faq_id, category_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS faq_categories_category_idx ON faq_categories (category_id)`,
+		// Admin panel accounts and their login sessions
+		`CREATE TABLE IF NOT EXISTS admin_users (
+			id SERIAL PRIMARY KEY,
+			username VARCHAR(100) NOT NULL UNIQUE,
+			password_hash TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS admin_sessions (
+			token_hash VARCHAR(64) PRIMARY KEY,
+			admin_id INTEGER NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			name VARCHAR(100) PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
 	}
 
 	for _, query := range queries {
@@ -189,6 +593,45 @@ func createTables() {
 			log.Printf("Error creating table: %v", err)
 		}
 	}
+
+	runMigrations()
+}
+
+// migrations change existing data and therefore run only once each, unlike
+// the idempotent statements of createTables. Applied names are recorded in
+// schema_migrations together with the migration itself.
+var migrations = []struct {
+	name  string
+	apply func(tx *sql.Tx) error
+}{
+	{"derive-product-ratings", deriveProductRatings},
+}
+
+func runMigrations() {
+	for _, m := range migrations {
+		tx, err := db.Begin()
+		if err != nil {
+			log.Printf("Error starting migration %s: %v", m.name, err)
+			return
+		}
+		result, err := tx.Exec("INSERT INTO schema_migrations (name) VALUES ($1) ON CONFLICT (name) DO NOTHING", m.name)
+		if err == nil {
+			if n, _ := result.RowsAffected(); n == 0 {
+				tx.Rollback()
+				continue
+			}
+			err = m.apply(tx)
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			tx.Rollback()
+			log.Printf("Error applying migration %s: %v", m.name, err)
+			return
+		}
+		log.Printf("Applied migration %s", m.name)
+	}
 }
 
 func initDefaultData() {
@@ -203,19 +646,19 @@ func initDefaultData() {
 	if count == 0 {
 		log.Println("Initializing default data...")
 		defaultProducts := []Product{
-			{Name: "Роскошная кровать King Size", Category: "Кровати", Price: 2499, Rating: 4.8, Reviews: 124, Description: "Опыт роскоши с нашей кроватью King Size ручной работы", Image: "/luxury-king-bed-frame.jpg", Color: "Коричневый", Dimensions: "210cm × 160cm × 120cm", Material: "Премиальная твердая древесина, высококачественная ткань", Features: []string{"Ручная резьба", "Рама из премиальной древесины", "Включает премиальный матрас", "Настраиваемое изголовье", "Гарантия 5 лет"}},
-			{Name: "Современное кресло для гостиной", Category: "Мебель для сидения", Price: 899, Rating: 4.7, Reviews: 89, Description: "Современный комфорт встречается со стилем", Image: "/modern-lounge-chair.png", Color: "Серый", Dimensions: "85cm × 95cm × 85cm", Material: "Премиальная обивка, деревянная рама", Features: []string{"Эргономичный дизайн", "Прочная обивка", "Легко чистить", "Доступно в нескольких цветах", "Гарантия 3 года"}},
-			{Name: "Конференц-стол Executive", Category: "Столовая мебель", Price: 3200, Rating: 4.9, Reviews: 156, Description: "Впечатлите клиентов и гостей этим потрясающим конференц-столом", Image: "/modern-conference-table.jpg", Color: "Орех", Dimensions: "240cm × 120cm × 75cm", Material: "Премиальный орех, металлическое основание", Features: []string{"Вмещает 12 человек", "Шпон ореха", "Металлическое основание", "Управление кабелями", "Гарантия 7 лет"}},
-			{Name: "Мраморный прикроватный столик", Category: "Декор", Price: 599, Rating: 4.6, Reviews: 67, Description: "Элегантный мраморный столик", Image: "/marble-luxury-side-table.jpg", Color: "Белый", Dimensions: "50cm × 50cm × 60cm", Material: "Мрамор, металл", Features: []string{"Премиальный мрамор", "Металлические ножки", "Легко чистить", "Гарантия 2 года"}},
-			{Name: "Премиальная кровать Queen Size", Category: "Кровати", Price: 1999, Rating: 4.8, Reviews: 98, Description: "Роскошная кровать Queen Size", Image: "/luxury-king-bed-frame.jpg", Color: "Черный", Dimensions: "200cm × 160cm × 120cm", Material: "Твердая древесина", Features: []string{"Прочная конструкция", "Элегантный дизайн", "Гарантия 5 лет"}},
-			{Name: "Современное акцентное кресло", Category: "Мебель для сидения", Price: 749, Rating: 4.5, Reviews: 45, Description: "Стильное акцентное кресло", Image: "/modern-lounge-chair.png", Color: "Бежевый", Dimensions: "80cm × 90cm × 80cm", Material: "Ткань, дерево", Features: []string{"Современный дизайн", "Удобное", "Гарантия 3 года"}},
+			{Name: "Роскошная кровать King Size", Category: "Кровати", Price: 2499, Description: "Опыт роскоши с нашей кроватью King Size ручной работы", Image: "/luxury-king-bed-frame.jpg", Color: "Коричневый", Dimensions: "210cm × 160cm × 120cm", Material: "Премиальная твердая древесина, высококачественная ткань", Features: []string{"Ручная резьба", "Рама из премиальной древесины", "Включает премиальный матрас", "Настраиваемое изголовье", "Гарантия 5 лет"}},
+			{Name: "Современное кресло для гостиной", Category: "Мебель для сидения", Price: 899, Description: "Современный комфорт встречается со стилем", Image: "/modern-lounge-chair.png", Color: "Серый", Dimensions: "85cm × 95cm × 85cm", Material: "Премиальная обивка, деревянная рама", Features: []string{"Эргономичный дизайн", "Прочная обивка", "Легко чистить", "Доступно в нескольких цветах", "Гарантия 3 года"}},
+			{Name: "Конференц-стол Executive", Category: "Столовая мебель", Price: 3200, Description: "Впечатлите клиентов и гостей этим потрясающим конференц-столом", Image: "/modern-conference-table.jpg", Color: "Орех", Dimensions: "240cm × 120cm × 75cm", Material: "Премиальный орех, металлическое основание", Features: []string{"Вмещает 12 человек", "Шпон ореха", "Металлическое основание", "Управление кабелями", "Гарантия 7 лет"}},
+			{Name: "Мраморный прикроватный столик", Category: "Декор", Price: 599, Description: "Элегантный мраморный столик", Image: "/marble-luxury-side-table.jpg", Color: "Белый", Dimensions: "50cm × 50cm × 60cm", Material: "Мрамор, металл", Features: []string{"Премиальный мрамор", "Металлические ножки", "Легко чистить", "Гарантия 2 года"}},
+			{Name: "Премиальная кровать Queen Size", Category: "Кровати", Price: 1999, Description: "Роскошная кровать Queen Size", Image: "/luxury-king-bed-frame.jpg", Color: "Черный", Dimensions: "200cm × 160cm × 120cm", Material: "Твердая древесина", Features: []string{"Прочная конструкция", "Элегантный дизайн", "Гарантия 5 лет"}},
+			{Name: "Современное акцентное кресло", Category: "Мебель для сидения", Price: 749, Description: "Стильное акцентное кресло", Image: "/modern-lounge-chair.png", Color: "Бежевый", Dimensions: "80cm × 90cm × 80cm", Material: "Ткань, дерево", Features: []string{"Современный дизайн", "Удобное", "Гарантия 3 года"}},
 		}
 
 		for _, p := range defaultProducts {
 			featuresStr := strings.Join(p.Features, ",")
 			_, err := db.Exec(
-				"INSERT INTO products (name, category, price, rating, reviews, description, image, color, dimensions, material, features) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
-				p.Name, p.Category, p.Price, p.Rating, p.Reviews, p.Description, p.Image, p.Color, p.Dimensions, p.Material, featuresStr,
+				"INSERT INTO products (name, category, price, description, image, color, dimensions, material, features, currency) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)",
+				p.Name, p.Category, p.Price, p.Description, p.Image, p.Color, p.Dimensions, p.Material, featuresStr, baseCurrency,
 			)
 			if err != nil {
 				log.Printf("Error inserting default product: %v", err)
@@ -277,92 +720,183 @@ func initDefaultData() {
 }
 
 // Products CRUD
-func getProducts(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT id, name, category, price, rating, reviews, description, image, images, color, dimensions, material, features, featured FROM products ORDER BY id")
+const productColumns = "id, name, category, price, currency, prices, rating, reviews, description, image, images, color, dimensions, material, features, featured, slug, meta_title, meta_description, canonical_url, og_image, status, publish_at, unpublish_at, version, updated_at, deleted_at"
+
+// productRequiredFields are the fields a full (PUT) product representation must contain.
+var productRequiredFields = []string{"name", "category", "price", "status"}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// qualifyColumns prefixes every column in a comma-separated list with a table alias.
+func qualifyColumns(alias, columns string) string {
+	parts := strings.Split(columns, ", ")
+	for i, part := range parts {
+		parts[i] = alias + "." + part
+	}
+	return strings.Join(parts, ", ")
+}
+
+func scanProduct(s rowScanner) (Product, error) {
+	var p Product
+	var featuresStr, imagesStr, pricesStr sql.NullString
+	err := s.Scan(&p.ID, &p.Name, &p.Category, &p.Price, &p.Currency, &pricesStr, &p.Rating, &p.Reviews, &p.Description, &p.Image, &imagesStr, &p.Color, &p.Dimensions, &p.Material, &featuresStr, &p.Featured, &p.Slug, &p.MetaTitle, &p.MetaDescription, &p.CanonicalURL, &p.OGImage, &p.Status, &p.PublishAt, &p.UnpublishAt, &p.Version, &p.UpdatedAt, &p.DeletedAt)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return p, err
+	}
+	if featuresStr.Valid && featuresStr.String != "" {
+		p.Features = strings.Split(featuresStr.String, ",")
+	}
+	// Parse images JSON array
+	if imagesStr.Valid && imagesStr.String != "" {
+		json.Unmarshal([]byte(imagesStr.String), &p.Images)
+	}
+	// If no images array but has main image, use it
+	if len(p.Images) == 0 && p.Image != "" {
+		p.Images = []string{p.Image}
+	}
+	if pricesStr.Valid && pricesStr.String != "" {
+		json.Unmarshal([]byte(pricesStr.String), &p.Prices)
+	}
+	return p, nil
+}
+
+func queryProducts(query string, args ...interface{}) ([]Product, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
 	}
 	defer rows.Close()
 
 	var products []Product
 	for rows.Next() {
-		var p Product
-		var featuresStr, imagesStr sql.NullString
-		err := rows.Scan(&p.ID, &p.Name, &p.Category, &p.Price, &p.Rating, &p.Reviews, &p.Description, &p.Image, &imagesStr, &p.Color, &p.Dimensions, &p.Material, &featuresStr, &p.Featured)
+		p, err := scanProduct(rows)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		if featuresStr.Valid && featuresStr.String != "" {
-			p.Features = strings.Split(featuresStr.String, ",")
-		}
-		// Parse images JSON array
-		if imagesStr.Valid && imagesStr.String != "" {
-			json.Unmarshal([]byte(imagesStr.String), &p.Images)
-		}
-		// If no images array but has main image, use it
-		if len(p.Images) == 0 && p.Image != "" {
-			p.Images = []string{p.Image}
+			return nil, err
 		}
 		products = append(products, p)
 	}
+	return products, rows.Err()
+}
+
+func fetchProduct(id int) (Product, error) {
+	return scanProduct(db.QueryRow("SELECT "+productColumns+" FROM products WHERE id = $1", id))
+}
+
+// productVisible is the in-memory counterpart of visibleCondition.
+func productVisible(r *http.Request, p Product) bool {
+	return p.DeletedAt == nil && (p.Status == statusPublished || showDrafts(r))
+}
+
+// saveProduct writes every column of p to the row with p.ID if the row is
+// still at version (or anyVersion) and bumps its version.
+// It returns sql.ErrNoRows if there is no such product and errVersionMismatch
+// if it has been changed since.
+func saveProduct(p *Product, version int) error {
+	if err := validatePublication(p.Status, p.PublishAt, p.UnpublishAt); err != nil {
+		return err
+	}
+	if err := validateProductPricing(p); err != nil {
+		return err
+	}
+	if err := validateSEO(&p.Slug, &p.MetaTitle, &p.CanonicalURL); err != nil {
+		return err
+	}
+	if err := assignSlug("products", p.ID, p.Name, &p.Slug); err != nil {
+		return err
+	}
+
+	// Set main image from images array if not set
+	if p.Image == "" && len(p.Images) > 0 {
+		p.Image = p.Images[0]
+	}
+
+	featuresStr := strings.Join(p.Features, ",")
+	// Convert images array to JSON
+	imagesJSON, _ := json.Marshal(p.Images)
+	pricesJSON, _ := json.Marshal(p.Prices)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	oldSlug, err := currentSlug(tx, "products", p.ID)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow(
+		"UPDATE products SET name=$1, category=$2, price=$3, description=$4, image=$5, images=$6, color=$7, dimensions=$8, material=$9, features=$10, featured=$11, status=$12, publish_at=$13, unpublish_at=$14, currency=$15, prices=$16, slug=$17, meta_title=$18, meta_description=$19, canonical_url=$20, og_image=$21, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$22 AND deleted_at IS NULL AND ($23 = -1 OR version=$23) RETURNING rating, reviews, version, updated_at",
+		p.Name, p.Category, p.Price, p.Description, p.Image, string(imagesJSON), p.Color, p.Dimensions, p.Material, featuresStr, p.Featured, p.Status, p.PublishAt, p.UnpublishAt, p.Currency, string(pricesJSON), p.Slug, p.MetaTitle, p.MetaDescription, p.CanonicalURL, p.OGImage, p.ID, version,
+	).Scan(&p.Rating, &p.Reviews, &p.Version, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return versionConflict("products", p.ID)
+	}
+	if err != nil {
+		return uniqueViolation(err, slugTaken("products"))
+	}
+	if err := trackSlugChange(tx, "/products/", oldSlug, p.Slug); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func getProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := queryProducts("SELECT " + productColumns + " FROM products WHERE " + visibleCondition(r, "") + " ORDER BY id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := localize(w, r, "products", &products); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !priceProducts(w, r, productRefs(products)...) {
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(products)
 }
 
 func getFeaturedProducts(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT id, name, category, price, rating, reviews, description, image, images, color, dimensions, material, features, featured FROM products WHERE featured = TRUE ORDER BY id LIMIT 8")
+	products, err := queryProducts("SELECT " + productColumns + " FROM products WHERE featured = TRUE AND " + visibleCondition(r, "") + " ORDER BY id LIMIT 8")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
 
-	var products []Product
-	for rows.Next() {
-		var p Product
-		var featuresStr, imagesStr sql.NullString
-		err := rows.Scan(&p.ID, &p.Name, &p.Category, &p.Price, &p.Rating, &p.Reviews, &p.Description, &p.Image, &imagesStr, &p.Color, &p.Dimensions, &p.Material, &featuresStr, &p.Featured)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		if featuresStr.Valid && featuresStr.String != "" {
-			p.Features = strings.Split(featuresStr.String, ",")
-		}
-		// Parse images JSON array
-		if imagesStr.Valid && imagesStr.String != "" {
-			json.Unmarshal([]byte(imagesStr.String), &p.Images)
-		}
-		// If no images array but has main image, use it
-		if len(p.Images) == 0 && p.Image != "" {
-			p.Images = []string{p.Image}
-		}
-		products = append(products, p)
+	if err := localize(w, r, "products", &products); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !priceProducts(w, r, productRefs(products)...) {
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(products)
 }
 
+// getProduct looks a product up by id or slug.
 func getProduct(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := routeEntityID(r, "products")
+	if err == sql.ErrNoRows {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var p Product
-	var featuresStr, imagesStr sql.NullString
-	err = db.QueryRow(
-		"SELECT id, name, category, price, rating, reviews, description, image, images, color, dimensions, material, features, featured FROM products WHERE id = $1",
-		id,
-	).Scan(&p.ID, &p.Name, &p.Category, &p.Price, &p.Rating, &p.Reviews, &p.Description, &p.Image, &imagesStr, &p.Color, &p.Dimensions, &p.Material, &featuresStr, &p.Featured)
-
-	if err == sql.ErrNoRows {
+	p, err := fetchProduct(id)
+	if err == sql.ErrNoRows || (err == nil && !productVisible(r, p)) {
 		http.Error(w, "Product not found", http.StatusNotFound)
 		return
 	}
@@ -371,18 +905,15 @@ func getProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if featuresStr.Valid && featuresStr.String != "" {
-		p.Features = strings.Split(featuresStr.String, ",")
-	}
-	// Parse images JSON array
-	if imagesStr.Valid && imagesStr.String != "" {
-		json.Unmarshal([]byte(imagesStr.String), &p.Images)
+	if err := localize(w, r, "products", &p); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	// If no images array but has main image, use it
-	if len(p.Images) == 0 && p.Image != "" {
-		p.Images = []string{p.Image}
+	if !priceProducts(w, r, &p) {
+		return
 	}
 
+	setETag(w, p.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(p)
 }
@@ -394,6 +925,27 @@ func createProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// New products stay hidden until they are published
+	if product.Status == "" {
+		product.Status = statusDraft
+	}
+	if err := validatePublication(product.Status, product.PublishAt, product.UnpublishAt); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := validateProductPricing(&product); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := validateSEO(&product.Slug, &product.MetaTitle, &product.CanonicalURL); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := assignSlug("products", 0, product.Name, &product.Slug); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	featuresStr := strings.Join(product.Features, ",")
 
 	// Set main image from images array if not set
@@ -404,17 +956,19 @@ func createProduct(w http.ResponseWriter, r *http.Request) {
 	// Convert images array to JSON
 	imagesJSON, _ := json.Marshal(product.Images)
 	imagesStr := string(imagesJSON)
+	pricesJSON, _ := json.Marshal(product.Prices)
 
 	err := db.QueryRow(
-		"INSERT INTO products (name, category, price, rating, reviews, description, image, images, color, dimensions, material, features, featured) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id",
-		product.Name, product.Category, product.Price, product.Rating, product.Reviews, product.Description, product.Image, imagesStr, product.Color, product.Dimensions, product.Material, featuresStr, product.Featured,
-	).Scan(&product.ID)
+		"INSERT INTO products (name, category, price, description, image, images, color, dimensions, material, features, featured, status, publish_at, unpublish_at, currency, prices, slug, meta_title, meta_description, canonical_url, og_image) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21) RETURNING id, rating, reviews, version, updated_at",
+		product.Name, product.Category, product.Price, product.Description, product.Image, imagesStr, product.Color, product.Dimensions, product.Material, featuresStr, product.Featured, product.Status, product.PublishAt, product.UnpublishAt, product.Currency, string(pricesJSON), product.Slug, product.MetaTitle, product.MetaDescription, product.CanonicalURL, product.OGImage,
+	).Scan(&product.ID, &product.Rating, &product.Reviews, &product.Version, &product.UpdatedAt)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, uniqueViolation(err, slugTaken("products")), "")
 		return
 	}
 
+	setETag(w, product.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(product)
@@ -428,40 +982,68 @@ func updateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var product Product
-	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	featuresStr := strings.Join(product.Features, ",")
+	var product Product
+	if err := decodeFull(r.Body, &product, productRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
 
-	// Set main image from images array if not set
-	if product.Image == "" && len(product.Images) > 0 {
-		product.Image = product.Images[0]
+	product.ID = id
+	if err := saveProduct(&product, version); err != nil {
+		writeSaveError(w, err, "Product not found")
+		return
 	}
 
-	// Convert images array to JSON
-	imagesJSON, _ := json.Marshal(product.Images)
-	imagesStr := string(imagesJSON)
+	setETag(w, product.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
 
-	result, err := db.Exec(
-		"UPDATE products SET name=$1, category=$2, price=$3, rating=$4, reviews=$5, description=$6, image=$7, images=$8, color=$9, dimensions=$10, material=$11, features=$12, featured=$13 WHERE id=$14",
-		product.Name, product.Category, product.Price, product.Rating, product.Reviews, product.Description, product.Image, imagesStr, product.Color, product.Dimensions, product.Material, featuresStr, product.Featured, id,
-	)
+func patchProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
 
+	product, err := fetchProduct(id)
+	if err == sql.ErrNoRows || (err == nil && product.DeletedAt != nil) {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Product not found", http.StatusNotFound)
+	if version != anyVersion && product.Version != version {
+		writeSaveError(w, errVersionMismatch, "Product not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &product, productRequiredFields, "id", "rating", "reviews", "version", "updated_at", "deleted_at"); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
 	product.ID = id
+	if err := saveProduct(&product, product.Version); err != nil {
+		writeSaveError(w, err, "Product not found")
+		return
+	}
+
+	setETag(w, product.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(product)
 }
@@ -474,15 +1056,13 @@ func deleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM products WHERE id = $1", id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Product not found", http.StatusNotFound)
+	if err := softDeleteVersioned("products", id, version); err != nil {
+		writeSaveError(w, err, "Product not found")
 		return
 	}
 
@@ -490,8 +1070,70 @@ func deleteProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 // Categories CRUD
+const categoryColumns = "id, name, description, icon, href, image, slug, meta_title, meta_description, canonical_url, og_image, version, updated_at, deleted_at"
+
+var categoryRequiredFields = []string{"name"}
+
+func scanCategory(s rowScanner) (Category, error) {
+	var c Category
+	var href, image sql.NullString
+	err := s.Scan(&c.ID, &c.Name, &c.Description, &c.Icon, &href, &image, &c.Slug, &c.MetaTitle, &c.MetaDescription, &c.CanonicalURL, &c.OGImage, &c.Version, &c.UpdatedAt, &c.DeletedAt)
+	if err != nil {
+		return c, err
+	}
+	if href.Valid {
+		c.Href = href.String
+	}
+	if image.Valid {
+		c.Image = image.String
+	}
+	return c, nil
+}
+
+func fetchCategory(id int) (Category, error) {
+	return scanCategory(db.QueryRow("SELECT "+categoryColumns+" FROM categories WHERE id = $1", id))
+}
+
+// saveCategory writes every column of c to the row with c.ID if the row is
+// still at version. See saveProduct.
+func saveCategory(c *Category, version int) error {
+	if err := validateSEO(&c.Slug, &c.MetaTitle, &c.CanonicalURL); err != nil {
+		return err
+	}
+	if err := assignSlug("categories", c.ID, c.Name, &c.Slug); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	oldSlug, err := currentSlug(tx, "categories", c.ID)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow(
+		"UPDATE categories SET name=$1, description=$2, icon=$3, href=$4, image=$5, slug=$6, meta_title=$7, meta_description=$8, canonical_url=$9, og_image=$10, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$11 AND deleted_at IS NULL AND ($12 = -1 OR version=$12) RETURNING version, updated_at",
+		c.Name, c.Description, c.Icon, c.Href, c.Image, c.Slug, c.MetaTitle, c.MetaDescription, c.CanonicalURL, c.OGImage, c.ID, version,
+	).Scan(&c.Version, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return versionConflict("categories", c.ID)
+	}
+	if err != nil {
+		return uniqueViolation(err, slugTaken("categories"))
+	}
+	// Categories have no page of their own; the catalog selects them by slug
+	if err := trackSlugChange(tx, "/catalog?category=", oldSlug, c.Slug); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func getCategories(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT id, name, description, icon, href, image FROM categories ORDER BY id")
+	rows, err := db.Query("SELECT " + categoryColumns + " FROM categories WHERE deleted_at IS NULL ORDER BY id")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -500,43 +1142,80 @@ func getCategories(w http.ResponseWriter, r *http.Request) {
 
 	var categories []Category
 	for rows.Next() {
-		var c Category
-		var href, image sql.NullString
-		err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.Icon, &href, &image)
+		c, err := scanCategory(rows)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		if href.Valid {
-			c.Href = href.String
-		}
-		if image.Valid {
-			c.Image = image.String
-		}
 		categories = append(categories, c)
 	}
 
+	if err := localize(w, r, "categories", &categories); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(categories)
 }
 
+// getCategory looks a category up by id or slug.
+func getCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := routeEntityID(r, "categories")
+	if err == sql.ErrNoRows {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	category, err := fetchCategory(id)
+	if err == sql.ErrNoRows || (err == nil && category.DeletedAt != nil) {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "categories", &category); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, category.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
 func createCategory(w http.ResponseWriter, r *http.Request) {
 	var category Category
 	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := validateSEO(&category.Slug, &category.MetaTitle, &category.CanonicalURL); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := assignSlug("categories", 0, category.Name, &category.Slug); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	err := db.QueryRow(
-		"INSERT INTO categories (name, description, icon, href, image) VALUES ($1, $2, $3, $4, $5) RETURNING id",
-		category.Name, category.Description, category.Icon, category.Href, category.Image,
-	).Scan(&category.ID)
+		"INSERT INTO categories (name, description, icon, href, image, slug, meta_title, meta_description, canonical_url, og_image) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, version, updated_at",
+		category.Name, category.Description, category.Icon, category.Href, category.Image, category.Slug, category.MetaTitle, category.MetaDescription, category.CanonicalURL, category.OGImage,
+	).Scan(&category.ID, &category.Version, &category.UpdatedAt)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, uniqueViolation(err, slugTaken("categories")), "")
 		return
 	}
 
+	setETag(w, category.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(category)
@@ -550,29 +1229,68 @@ func updateCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	var category Category
-	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := decodeFull(r.Body, &category, categoryRequiredFields...); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
-	result, err := db.Exec(
-		"UPDATE categories SET name=$1, description=$2, icon=$3, href=$4, image=$5 WHERE id=$6",
-		category.Name, category.Description, category.Icon, category.Href, category.Image, id,
-	)
+	category.ID = id
+	if err := saveCategory(&category, version); err != nil {
+		writeSaveError(w, err, "Category not found")
+		return
+	}
 
+	setETag(w, category.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+func patchCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	category, err := fetchCategory(id)
+	if err == sql.ErrNoRows || (err == nil && category.DeletedAt != nil) {
 		http.Error(w, "Category not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && category.Version != version {
+		writeSaveError(w, errVersionMismatch, "Category not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &category, categoryRequiredFields, "id", "version", "updated_at", "deleted_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
 
 	category.ID = id
+	if err := saveCategory(&category, category.Version); err != nil {
+		writeSaveError(w, err, "Category not found")
+		return
+	}
+
+	setETag(w, category.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(category)
 }
@@ -585,15 +1303,13 @@ func deleteCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM categories WHERE id = $1", id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Category not found", http.StatusNotFound)
+	if err := softDeleteVersioned("categories", id, version); err != nil {
+		writeSaveError(w, err, "Category not found")
 		return
 	}
 
@@ -601,8 +1317,67 @@ func deleteCategory(w http.ResponseWriter, r *http.Request) {
 }
 
 // Collections CRUD
+const collectionColumns = "id, name, description, image, count, slug, meta_title, meta_description, canonical_url, og_image, status, publish_at, unpublish_at, version, updated_at, deleted_at"
+
+var collectionRequiredFields = []string{"name", "status"}
+
+func scanCollection(s rowScanner) (Collection, error) {
+	var c Collection
+	err := s.Scan(&c.ID, &c.Name, &c.Description, &c.Image, &c.Count, &c.Slug, &c.MetaTitle, &c.MetaDescription, &c.CanonicalURL, &c.OGImage, &c.Status, &c.PublishAt, &c.UnpublishAt, &c.Version, &c.UpdatedAt, &c.DeletedAt)
+	return c, err
+}
+
+func fetchCollection(id int) (Collection, error) {
+	return scanCollection(db.QueryRow("SELECT "+collectionColumns+" FROM collections WHERE id = $1", id))
+}
+
+func collectionVisible(r *http.Request, c Collection) bool {
+	return c.DeletedAt == nil && (c.Status == statusPublished || showDrafts(r))
+}
+
+// saveCollection writes the editable columns of c to the row with c.ID if
+// the row is still at version. See saveProduct.
+func saveCollection(c *Collection, version int) error {
+	if err := validatePublication(c.Status, c.PublishAt, c.UnpublishAt); err != nil {
+		return err
+	}
+	if err := validateSEO(&c.Slug, &c.MetaTitle, &c.CanonicalURL); err != nil {
+		return err
+	}
+	if err := assignSlug("collections", c.ID, c.Name, &c.Slug); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	oldSlug, err := currentSlug(tx, "collections", c.ID)
+	if err != nil {
+		return err
+	}
+	// Don't update count manually - it's calculated from collection_products
+	err = tx.QueryRow(
+		"UPDATE collections SET name=$1, description=$2, image=$3, status=$4, publish_at=$5, unpublish_at=$6, slug=$7, meta_title=$8, meta_description=$9, canonical_url=$10, og_image=$11, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$12 AND deleted_at IS NULL AND ($13 = -1 OR version=$13) RETURNING version, updated_at",
+		c.Name, c.Description, c.Image, c.Status, c.PublishAt, c.UnpublishAt, c.Slug, c.MetaTitle, c.MetaDescription, c.CanonicalURL, c.OGImage, c.ID, version,
+	).Scan(&c.Version, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return versionConflict("collections", c.ID)
+	}
+	if err != nil {
+		return uniqueViolation(err, slugTaken("collections"))
+	}
+	if err := trackSlugChange(tx, "/collections/", oldSlug, c.Slug); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func getCollections(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT id, name, description, image FROM collections ORDER BY id")
+	rows, err := db.Query("SELECT " + collectionColumns + " FROM collections WHERE " + visibleCondition(r, "") + " ORDER BY id")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -611,38 +1386,43 @@ func getCollections(w http.ResponseWriter, r *http.Request) {
 
 	var collections []Collection
 	for rows.Next() {
-		var c Collection
-		err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.Image)
+		c, err := scanCollection(rows)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		// Count products in collection
 		var count int
-		db.QueryRow("SELECT COUNT(*) FROM collection_products WHERE collection_id = $1", c.ID).Scan(&count)
+		db.QueryRow(`SELECT COUNT(*) FROM collection_products cp
+			INNER JOIN products p ON p.id = cp.product_id
+			WHERE cp.collection_id = $1 AND `+visibleCondition(r, "p"), c.ID).Scan(&count)
 		c.Count = count
 		collections = append(collections, c)
 	}
 
+	if err := localize(w, r, "collections", &collections); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(collections)
 }
 
+// getCollection looks a collection up by id or slug.
 func getCollection(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := routeEntityID(r, "collections")
+	if err == sql.ErrNoRows {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var collection Collection
-	err = db.QueryRow(
-		"SELECT id, name, description, image, count FROM collections WHERE id = $1",
-		id,
-	).Scan(&collection.ID, &collection.Name, &collection.Description, &collection.Image, &collection.Count)
-
-	if err == sql.ErrNoRows {
+	collection, err := fetchCollection(id)
+	if err == sql.ErrNoRows || (err == nil && !collectionVisible(r, collection)) {
 		http.Error(w, "Collection not found", http.StatusNotFound)
 		return
 	}
@@ -652,39 +1432,32 @@ func getCollection(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get products in this collection
-	productRows, err := db.Query(`
-		SELECT p.id, p.name, p.category, p.price, p.rating, p.reviews, p.description, 
-		       p.image, p.images, p.color, p.dimensions, p.material, p.features, p.featured
+	products, err := queryProducts(`
+		SELECT `+qualifyColumns("p", productColumns)+`
 		FROM products p
 		INNER JOIN collection_products cp ON p.id = cp.product_id
-		WHERE cp.collection_id = $1
+		WHERE cp.collection_id = $1 AND `+visibleCondition(r, "p")+`
 		ORDER BY p.id
 	`, id)
 	if err == nil {
-		defer productRows.Close()
-		var products []Product
-		for productRows.Next() {
-			var p Product
-			var featuresStr, imagesStr sql.NullString
-			err := productRows.Scan(&p.ID, &p.Name, &p.Category, &p.Price, &p.Rating, &p.Reviews, &p.Description,
-				&p.Image, &imagesStr, &p.Color, &p.Dimensions, &p.Material, &featuresStr, &p.Featured)
-			if err == nil {
-				if featuresStr.Valid && featuresStr.String != "" {
-					p.Features = strings.Split(featuresStr.String, ",")
-				}
-				if imagesStr.Valid && imagesStr.String != "" {
-					json.Unmarshal([]byte(imagesStr.String), &p.Images)
-				}
-				if len(p.Images) == 0 && p.Image != "" {
-					p.Images = []string{p.Image}
-				}
-				products = append(products, p)
-			}
-		}
 		collection.Products = products
 		collection.Count = len(products)
 	}
 
+	if err := localize(w, r, "collections", &collection); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := localize(w, r, "products", &collection.Products); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !priceProducts(w, r, productRefs(collection.Products)...) {
+		return
+	}
+
+	setETag(w, collection.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(collection)
 }
@@ -696,17 +1469,35 @@ func createCollection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// New collections stay hidden until they are published
+	if collection.Status == "" {
+		collection.Status = statusDraft
+	}
+	if err := validatePublication(collection.Status, collection.PublishAt, collection.UnpublishAt); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := validateSEO(&collection.Slug, &collection.MetaTitle, &collection.CanonicalURL); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if err := assignSlug("collections", 0, collection.Name, &collection.Slug); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	err := db.QueryRow(
-		"INSERT INTO collections (name, description, image, count) VALUES ($1, $2, $3, 0) RETURNING id",
-		collection.Name, collection.Description, collection.Image,
-	).Scan(&collection.ID)
+		"INSERT INTO collections (name, description, image, count, status, publish_at, unpublish_at, slug, meta_title, meta_description, canonical_url, og_image) VALUES ($1, $2, $3, 0, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, version, updated_at",
+		collection.Name, collection.Description, collection.Image, collection.Status, collection.PublishAt, collection.UnpublishAt, collection.Slug, collection.MetaTitle, collection.MetaDescription, collection.CanonicalURL, collection.OGImage,
+	).Scan(&collection.ID, &collection.Version, &collection.UpdatedAt)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, uniqueViolation(err, slugTaken("collections")), "")
 		return
 	}
 
 	collection.Count = 0
+	setETag(w, collection.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(collection)
@@ -720,30 +1511,68 @@ func updateCollection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var collection Collection
-	if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var collection Collection
+	if err := decodeFull(r.Body, &collection, collectionRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	collection.ID = id
+	if err := saveCollection(&collection, version); err != nil {
+		writeSaveError(w, err, "Collection not found")
+		return
+	}
+
+	setETag(w, collection.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collection)
+}
+
+func patchCollection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	// Don't update count manually - it's calculated from collection_products
-	result, err := db.Exec(
-		"UPDATE collections SET name=$1, description=$2, image=$3 WHERE id=$4",
-		collection.Name, collection.Description, collection.Image, id,
-	)
-
+	collection, err := fetchCollection(id)
+	if err == sql.ErrNoRows || (err == nil && collection.DeletedAt != nil) {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Collection not found", http.StatusNotFound)
+	if version != anyVersion && collection.Version != version {
+		writeSaveError(w, errVersionMismatch, "Collection not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &collection, collectionRequiredFields, "id", "version", "updated_at", "deleted_at"); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
 	collection.ID = id
+	if err := saveCollection(&collection, collection.Version); err != nil {
+		writeSaveError(w, err, "Collection not found")
+		return
+	}
+
+	setETag(w, collection.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(collection)
 }
@@ -756,15 +1585,13 @@ func deleteCollection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM collections WHERE id = $1", id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Collection not found", http.StatusNotFound)
+	if err := softDeleteVersioned("collections", id, version); err != nil {
+		writeSaveError(w, err, "Collection not found")
 		return
 	}
 
@@ -798,7 +1625,7 @@ func addProductToCollection(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update count
-	db.Exec("UPDATE collections SET count = (SELECT COUNT(*) FROM collection_products WHERE collection_id = $1) WHERE id = $1", collectionID)
+	refreshCollectionCount(collectionID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
@@ -828,7 +1655,7 @@ func removeProductFromCollection(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update count
-	db.Exec("UPDATE collections SET count = (SELECT COUNT(*) FROM collection_products WHERE collection_id = $1) WHERE id = $1", collectionID)
+	refreshCollectionCount(collectionID)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -870,6 +1697,8 @@ func uploadImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	invalidateStatsCache()
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"url":      fmt.Sprintf("/uploads/%s", filename),
@@ -878,6 +1707,16 @@ func uploadImage(w http.ResponseWriter, r *http.Request) {
 }
 
 // Contacts CRUD
+const contactColumns = "id, name, email, phone, message, customer_id, handled, handled_at, created_at"
+
+func scanContact(s rowScanner) (Contact, error) {
+	var c Contact
+	var phone sql.NullString
+	err := s.Scan(&c.ID, &c.Name, &c.Email, &phone, &c.Message, &c.CustomerID, &c.Handled, &c.HandledAt, &c.CreatedAt)
+	c.Phone = phone.String
+	return c, err
+}
+
 func createContact(w http.ResponseWriter, r *http.Request) {
 	var contact Contact
 	if err := json.NewDecoder(r.Body).Decode(&contact); err != nil {
@@ -885,9 +1724,24 @@ func createContact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Logged-in customers don't have to retype their details
+	contact.CustomerID = nil
+	if customer, err := customerFromRequest(r); err == nil && customer != nil {
+		contact.CustomerID = &customer.ID
+		if contact.Name == "" {
+			contact.Name = customer.Name
+		}
+		if contact.Email == "" {
+			contact.Email = customer.Email
+		}
+		if contact.Phone == "" {
+			contact.Phone = customer.Phone
+		}
+	}
+
 	err := db.QueryRow(
-		"INSERT INTO contacts (name, email, phone, message) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
-		contact.Name, contact.Email, contact.Phone, contact.Message,
+		"INSERT INTO contacts (name, email, phone, message, customer_id) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		contact.Name, contact.Email, contact.Phone, contact.Message, contact.CustomerID,
 	).Scan(&contact.ID, &contact.CreatedAt)
 
 	if err != nil {
@@ -900,8 +1754,25 @@ func createContact(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(contact)
 }
 
+func fetchContact(id int) (Contact, error) {
+	return scanContact(db.QueryRow("SELECT "+contactColumns+" FROM contacts WHERE id = $1", id))
+}
+
+// getContacts lists contact requests, newest first. ?handled=false returns
+// only the leads nobody has followed up on yet.
 func getContacts(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT id, name, email, phone, message, created_at FROM contacts ORDER BY created_at DESC")
+	query := "SELECT " + contactColumns + " FROM contacts"
+	var args []interface{}
+	if v := r.URL.Query().Get("handled"); v != "" {
+		handled, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Invalid handled filter", http.StatusBadRequest)
+			return
+		}
+		query += " WHERE handled = $1"
+		args = append(args, handled)
+	}
+	rows, err := db.Query(query+" ORDER BY created_at DESC", args...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -910,8 +1781,7 @@ func getContacts(w http.ResponseWriter, r *http.Request) {
 
 	var contacts []Contact
 	for rows.Next() {
-		var c Contact
-		err := rows.Scan(&c.ID, &c.Name, &c.Email, &c.Phone, &c.Message, &c.CreatedAt)
+		c, err := scanContact(rows)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -923,6 +1793,48 @@ func getContacts(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(contacts)
 }
 
+// patchContact marks a lead as handled or reopens it. The request itself is
+// what the visitor sent, so "handled" is the only writable field.
+func patchContact(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid contact ID", http.StatusBadRequest)
+		return
+	}
+
+	c, err := fetchContact(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Contact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &c, nil, "id", "name", "email", "phone", "message", "customer_id", "handled_at", "created_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	c, err = scanContact(db.QueryRow(
+		"UPDATE contacts SET handled = $1, handled_at = CASE WHEN $1 THEN COALESCE(handled_at, CURRENT_TIMESTAMP) END WHERE id = $2 RETURNING "+contactColumns,
+		c.Handled, id,
+	))
+	if err == sql.ErrNoRows {
+		http.Error(w, "Contact not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
 func deleteContact(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
@@ -947,8 +1859,42 @@ func deleteContact(w http.ResponseWriter, r *http.Request) {
 }
 
 // Placeholders Management
+const placeholderColumns = "id, path, title, message, is_active, priority, starts_at, ends_at, created_at, version, updated_at"
+
+var placeholderRequiredFields = []string{"path", "title"}
+
+func scanPlaceholder(s rowScanner) (Placeholder, error) {
+	var p Placeholder
+	err := s.Scan(&p.ID, &p.Path, &p.Title, &p.Message, &p.IsActive, &p.Priority, &p.StartsAt, &p.EndsAt, &p.CreatedAt, &p.Version, &p.UpdatedAt)
+	return p, err
+}
+
+func fetchPlaceholder(id int) (Placeholder, error) {
+	return scanPlaceholder(db.QueryRow("SELECT "+placeholderColumns+" FROM placeholders WHERE id = $1", id))
+}
+
+// savePlaceholder writes the editable columns of p to the row with p.ID if
+// the row is still at version. See saveProduct.
+func savePlaceholder(p *Placeholder, version int) error {
+	if err := validatePlaceholder(p); err != nil {
+		return err
+	}
+	err := db.QueryRow(
+		"UPDATE placeholders SET path=$1, title=$2, message=$3, is_active=$4, priority=$5, starts_at=$6, ends_at=$7, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$8 AND ($9 = -1 OR version=$9) RETURNING created_at, version, updated_at",
+		p.Path, p.Title, p.Message, p.IsActive, p.Priority, p.StartsAt, p.EndsAt, p.ID, version,
+	).Scan(&p.CreatedAt, &p.Version, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return versionConflict("placeholders", p.ID)
+	}
+	if err != nil {
+		return uniqueViolation(err, placeholderPathTaken)
+	}
+	invalidatePlaceholderCache()
+	return nil
+}
+
 func getPlaceholders(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT id, path, title, message, is_active, created_at FROM placeholders ORDER BY created_at DESC")
+	rows, err := db.Query("SELECT " + placeholderColumns + " FROM placeholders ORDER BY priority DESC, created_at DESC")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -957,8 +1903,7 @@ func getPlaceholders(w http.ResponseWriter, r *http.Request) {
 
 	var placeholders []Placeholder
 	for rows.Next() {
-		var p Placeholder
-		err := rows.Scan(&p.ID, &p.Path, &p.Title, &p.Message, &p.IsActive, &p.CreatedAt)
+		p, err := scanPlaceholder(rows)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -983,12 +1928,7 @@ func getPlaceholder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var placeholder Placeholder
-	err = db.QueryRow(
-		"SELECT id, path, title, message, is_active, created_at FROM placeholders WHERE id = $1",
-		id,
-	).Scan(&placeholder.ID, &placeholder.Path, &placeholder.Title, &placeholder.Message, &placeholder.IsActive, &placeholder.CreatedAt)
-
+	placeholder, err := fetchPlaceholder(id)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Placeholder not found", http.StatusNotFound)
 		return
@@ -998,6 +1938,7 @@ func getPlaceholder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, placeholder.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(placeholder)
 }
@@ -1009,24 +1950,25 @@ func checkPlaceholder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var placeholder Placeholder
-	err := db.QueryRow(
-		"SELECT id, path, title, message, is_active, created_at FROM placeholders WHERE path = $1 AND is_active = TRUE",
-		path,
-	).Scan(&placeholder.ID, &placeholder.Path, &placeholder.Title, &placeholder.Message, &placeholder.IsActive, &placeholder.CreatedAt)
-
-	if err == sql.ErrNoRows {
+	placeholders, version, err := cachedPlaceholders()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	placeholder, ok := matchPlaceholder(placeholders, path)
+	if !ok {
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{"exists": false})
+		json.NewEncoder(w).Encode(map[string]interface{}{"exists": false, "version": version})
 		return
 	}
-	if err != nil {
+
+	if err := localize(w, r, "placeholders", &placeholder); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"exists": true, "placeholder": placeholder})
+	json.NewEncoder(w).Encode(map[string]interface{}{"exists": true, "placeholder": placeholder, "version": version})
 }
 
 func createPlaceholder(w http.ResponseWriter, r *http.Request) {
@@ -1035,17 +1977,23 @@ func createPlaceholder(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := validatePlaceholder(&placeholder); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
 
 	err := db.QueryRow(
-		"INSERT INTO placeholders (path, title, message, is_active) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
-		placeholder.Path, placeholder.Title, placeholder.Message, placeholder.IsActive,
-	).Scan(&placeholder.ID, &placeholder.CreatedAt)
+		"INSERT INTO placeholders (path, title, message, is_active, priority, starts_at, ends_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, version, updated_at",
+		placeholder.Path, placeholder.Title, placeholder.Message, placeholder.IsActive, placeholder.Priority, placeholder.StartsAt, placeholder.EndsAt,
+	).Scan(&placeholder.ID, &placeholder.CreatedAt, &placeholder.Version, &placeholder.UpdatedAt)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, uniqueViolation(err, placeholderPathTaken), "")
 		return
 	}
+	invalidatePlaceholderCache()
 
+	setETag(w, placeholder.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(placeholder)
@@ -1059,29 +2007,68 @@ func updatePlaceholder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	var placeholder Placeholder
-	if err := json.NewDecoder(r.Body).Decode(&placeholder); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := decodeFull(r.Body, &placeholder, placeholderRequiredFields...); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
-	result, err := db.Exec(
-		"UPDATE placeholders SET path=$1, title=$2, message=$3, is_active=$4 WHERE id=$5",
-		placeholder.Path, placeholder.Title, placeholder.Message, placeholder.IsActive, id,
-	)
+	placeholder.ID = id
+	if err := savePlaceholder(&placeholder, version); err != nil {
+		writeSaveError(w, err, "Placeholder not found")
+		return
+	}
+
+	setETag(w, placeholder.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(placeholder)
+}
 
+func patchPlaceholder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Invalid placeholder ID", http.StatusBadRequest)
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	placeholder, err := fetchPlaceholder(id)
+	if err == sql.ErrNoRows {
 		http.Error(w, "Placeholder not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && placeholder.Version != version {
+		writeSaveError(w, errVersionMismatch, "Placeholder not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &placeholder, placeholderRequiredFields, "id", "created_at", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
 
 	placeholder.ID = id
+	if err := savePlaceholder(&placeholder, placeholder.Version); err != nil {
+		writeSaveError(w, err, "Placeholder not found")
+		return
+	}
+
+	setETag(w, placeholder.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(placeholder)
 }
@@ -1094,17 +2081,16 @@ func deletePlaceholder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM placeholders WHERE id = $1", id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Placeholder not found", http.StatusNotFound)
+	if err := deleteVersioned("placeholders", id, version); err != nil {
+		writeSaveError(w, err, "Placeholder not found")
 		return
 	}
+	invalidatePlaceholderCache()
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -1184,13 +2170,15 @@ func createDump(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	invalidateStatsCache()
+
 	// Return dump info
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":       "success",
-		"filename":     dumpFilename,
-		"path":         fmt.Sprintf("/dumps/%s", dumpFilename),
-		"size":         getFileSize(dumpPath),
+		"status":        "success",
+		"filename":      dumpFilename,
+		"path":          fmt.Sprintf("/api/admin/db/dumps/%s", dumpFilename),
+		"size":          getFileSize(dumpPath),
 		"telegram_sent": telegramSent,
 	})
 }
@@ -1251,6 +2239,25 @@ func sendDumpToTelegram(botToken, chatID, filePath, filename string) error {
 	return nil
 }
 
+// downloadDump sends one file from ./dumps. Dumps hold password and token
+// hashes, so they are only served here, to admins, and never listed.
+func downloadDump(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		http.Error(w, "Dump not found", http.StatusNotFound)
+		return
+	}
+	dumpPath := filepath.Join("./dumps", name)
+	info, err := os.Stat(dumpPath)
+	if err != nil || !info.Mode().IsRegular() {
+		http.Error(w, "Dump not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeFile(w, r, dumpPath)
+}
+
 func restoreDump(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form
 	err := r.ParseMultipartForm(100 << 20) // 100MB max
@@ -1292,6 +2299,13 @@ func restoreDump(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The audit log must outlive the restore, so keep a copy to put back
+	auditEntries, err := snapshotAuditLog()
+	if err != nil {
+		http.Error(w, "Error saving audit log", http.StatusInternalServerError)
+		return
+	}
+
 	timestamp := time.Now().Format("20060102_150405")
 	tempDumpPath := filepath.Join(dumpDir, fmt.Sprintf("restore_%s_%s", timestamp, handler.Filename))
 
@@ -1336,9 +2350,17 @@ func restoreDump(w http.ResponseWriter, r *http.Request) {
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	// The restore may have replaced placeholders and settings even when it
+	// failed halfway
+	invalidatePlaceholderCache()
+	invalidateMaintenanceCache()
+	if err != nil {
 		log.Printf("Restore error: %s", stderr.String())
 		os.Remove(tempDumpPath) // Clean up
+		if err := restoreAuditLog(auditEntries); err != nil {
+			log.Printf("Error restoring audit log: %v", err)
+		}
 		http.Error(w, fmt.Sprintf("Error restoring dump: %s", stderr.String()), http.StatusInternalServerError)
 		return
 	}
@@ -1350,6 +2372,10 @@ func restoreDump(w http.ResponseWriter, r *http.Request) {
 	db.Close()
 	initDB()
 
+	if err := restoreAuditLog(auditEntries); err != nil {
+		log.Printf("Error restoring audit log: %v", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
@@ -1366,31 +2392,103 @@ func getFileSize(filePath string) int64 {
 }
 
 // FAQ CRUD
-func getFAQs(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.Query("SELECT id, question, answer, \"order\" FROM faqs ORDER BY \"order\" ASC, id ASC")
+const faqColumns = `id, question, answer, "order", faq_group, version, updated_at`
+
+var faqRequiredFields = []string{"question", "answer"}
+
+func scanFAQ(s rowScanner) (FAQ, error) {
+	var faq FAQ
+	err := s.Scan(&faq.ID, &faq.Question, &faq.Answer, &faq.Order, &faq.Group, &faq.Version, &faq.UpdatedAt)
+	return faq, err
+}
+
+func fetchFAQ(id int) (FAQ, error) {
+	faq, err := scanFAQ(db.QueryRow("SELECT "+faqColumns+" FROM faqs WHERE id = $1", id))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return faq, err
 	}
-	defer rows.Close()
+	faqs := []FAQ{faq}
+	err = loadFAQLinks(faqs)
+	return faqs[0], err
+}
 
-	var faqs []FAQ
-	for rows.Next() {
-		var faq FAQ
-		err := rows.Scan(&faq.ID, &faq.Question, &faq.Answer, &faq.Order)
+// saveFAQ writes every column of faq and its links to the row with faq.ID
+// if the row is still at version. See saveProduct.
+func saveFAQ(faq *FAQ, version int) error {
+	if err := validateFAQ(faq); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"UPDATE faqs SET question=$1, answer=$2, \"order\"=$3, faq_group=$4, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$5 AND ($6 = -1 OR version=$6) RETURNING version, updated_at",
+		faq.Question, faq.Answer, faq.Order, faq.Group, faq.ID, version,
+	).Scan(&faq.Version, &faq.UpdatedAt)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return versionConflict("faqs", faq.ID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := replaceFAQLinks(tx, faq); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// getFAQs lists the FAQ in display order, filtered with ?group=,
+// ?product_id= and ?category_id=. Public requests only get the links to
+// visible products and categories.
+func getFAQs(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + qualifyColumns("f", faqColumns) + " FROM faqs f WHERE 1=1"
+	var args []interface{}
+	if group := r.URL.Query().Get("group"); group != "" {
+		if !validFAQGroup(group) {
+			http.Error(w, "Invalid group", http.StatusBadRequest)
+			return
+		}
+		args = append(args, group)
+		query += " AND f.faq_group = $" + strconv.Itoa(len(args))
+	}
+	for _, link := range []struct{ param, table, column string }{
+		{"product_id", "faq_products", "product_id"},
+		{"category_id", "faq_categories", "category_id"},
+	} {
+		v := r.URL.Query().Get(link.param)
+		if v == "" {
+			continue
+		}
+		id, err := strconv.Atoi(v)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, "Invalid "+link.param+" parameter", http.StatusBadRequest)
 			return
 		}
-		faqs = append(faqs, faq)
+		args = append(args, id)
+		query += " AND EXISTS (SELECT 1 FROM " + link.table + " l WHERE l.faq_id = f.id AND l." + link.column + " = $" + strconv.Itoa(len(args)) + ")"
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if faqs == nil {
-		json.NewEncoder(w).Encode([]FAQ{})
-	} else {
-		json.NewEncoder(w).Encode(faqs)
+	faqs, err := queryFAQs(query+` ORDER BY f."order" ASC, f.id ASC`, args...)
+	if err == nil && !viaAdminRoute(r) {
+		err = hideFAQLinks(r, faqs)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := localize(w, r, "faqs", &faqs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(faqs)
 }
 
 func getFAQ(w http.ResponseWriter, r *http.Request) {
@@ -1401,8 +2499,7 @@ func getFAQ(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var faq FAQ
-	err = db.QueryRow("SELECT id, question, answer, \"order\" FROM faqs WHERE id = $1", id).Scan(&faq.ID, &faq.Question, &faq.Answer, &faq.Order)
+	faq, err := fetchFAQ(id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			http.Error(w, "FAQ not found", http.StatusNotFound)
@@ -1412,6 +2509,7 @@ func getFAQ(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, faq.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(faq)
 }
@@ -1422,17 +2520,34 @@ func createFAQ(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := validateFAQ(&faq); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
 
-	err := db.QueryRow(
-		"INSERT INTO faqs (question, answer, \"order\") VALUES ($1, $2, $3) RETURNING id",
-		faq.Question, faq.Answer, faq.Order,
-	).Scan(&faq.ID)
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
 
+	err = tx.QueryRow(
+		"INSERT INTO faqs (question, answer, \"order\", faq_group) VALUES ($1, $2, $3, $4) RETURNING id, version, updated_at",
+		faq.Question, faq.Answer, faq.Order, faq.Group,
+	).Scan(&faq.ID, &faq.Version, &faq.UpdatedAt)
+	if err == nil {
+		err = replaceFAQLinks(tx, &faq)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	setETag(w, faq.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(faq)
@@ -1446,29 +2561,68 @@ func updateFAQ(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	var faq FAQ
-	if err := json.NewDecoder(r.Body).Decode(&faq); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := decodeFull(r.Body, &faq, faqRequiredFields...); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
-	result, err := db.Exec(
-		"UPDATE faqs SET question=$1, answer=$2, \"order\"=$3 WHERE id=$4",
-		faq.Question, faq.Answer, faq.Order, id,
-	)
+	faq.ID = id
+	if err := saveFAQ(&faq, version); err != nil {
+		writeSaveError(w, err, "FAQ not found")
+		return
+	}
+
+	setETag(w, faq.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(faq)
+}
 
+func patchFAQ(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Invalid FAQ ID", http.StatusBadRequest)
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	faq, err := fetchFAQ(id)
+	if err == sql.ErrNoRows {
 		http.Error(w, "FAQ not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && faq.Version != version {
+		writeSaveError(w, errVersionMismatch, "FAQ not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &faq, faqRequiredFields, "id", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
 
 	faq.ID = id
+	if err := saveFAQ(&faq, faq.Version); err != nil {
+		writeSaveError(w, err, "FAQ not found")
+		return
+	}
+
+	setETag(w, faq.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(faq)
 }
@@ -1481,15 +2635,13 @@ func deleteFAQ(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM faqs WHERE id = $1", id)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "FAQ not found", http.StatusNotFound)
+	if err := deleteVersioned("faqs", id, version); err != nil {
+		writeSaveError(w, err, "FAQ not found")
 		return
 	}
 
@@ -1500,41 +2652,121 @@ func main() {
 	initDB()
 	defer db.Close()
 
+	startTrashPurger()
+	startPublicationScheduler()
+	startAnalyticsAggregator()
+	startQualityChecker()
+	startReviewPhotoPurger()
+	resumeCampaigns()
+
 	r := mux.NewRouter()
 
 	// Serve static files (uploads)
 	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads/"))))
-	// Serve dump files
-	r.PathPrefix("/dumps/").Handler(http.StripPrefix("/dumps/", http.FileServer(http.Dir("./dumps/"))))
+	// Crawler files live at the site root; nginx routes them to the backend
+	r.HandleFunc("/sitemap.xml", getSitemap).Methods("GET")
+	r.HandleFunc("/sitemap-{n:[0-9]+}.xml", getSitemapChunk).Methods("GET")
+	r.HandleFunc("/robots.txt", getRobotsTxt).Methods("GET")
 
 	// Public API routes
 	api := r.PathPrefix("/api").Subrouter()
+	api.Use(maintenanceMiddleware)
 	api.HandleFunc("/products", getProducts).Methods("GET")
 	api.HandleFunc("/products/featured", getFeaturedProducts).Methods("GET")
 	api.HandleFunc("/products/{id}", getProduct).Methods("GET")
+	api.HandleFunc("/products/{id}/related", getRelatedProducts).Methods("GET")
+	api.HandleFunc("/products/{id}/seo", getProductSEO).Methods("GET")
+	api.HandleFunc("/products/{id}/reviews", getProductReviews).Methods("GET")
+	api.HandleFunc("/products/{id}/projects", getProductProjects).Methods("GET")
+	api.HandleFunc("/products/{id}/faqs", getProductFAQs).Methods("GET")
+	api.HandleFunc("/products/{id}/reviews", createReview).Methods("POST")
+	api.HandleFunc("/reviews/photos", uploadReviewPhoto).Methods("POST")
 	api.HandleFunc("/categories", getCategories).Methods("GET")
+	api.HandleFunc("/categories/{id}", getCategory).Methods("GET")
+	api.HandleFunc("/categories/{id}/seo", getCategorySEO).Methods("GET")
 	api.HandleFunc("/collections", getCollections).Methods("GET")
 	api.HandleFunc("/collections/{id}", getCollection).Methods("GET")
+	api.HandleFunc("/collections/{id}/seo", getCollectionSEO).Methods("GET")
 	api.HandleFunc("/contacts", createContact).Methods("POST")
+	api.HandleFunc("/newsletter/subscribe", subscribeNewsletter).Methods("POST")
+	api.HandleFunc("/newsletter/confirm", confirmNewsletter).Methods("POST")
+	api.HandleFunc("/newsletter/unsubscribe", unsubscribeNewsletter).Methods("POST")
 	api.HandleFunc("/placeholder/check", checkPlaceholder).Methods("GET")
+	api.HandleFunc("/placeholder/check", checkPlaceholders).Methods("POST")
+	api.HandleFunc("/placeholder/version", getPlaceholderVersion).Methods("GET")
+	api.HandleFunc("/redirects/resolve", resolvePath).Methods("GET")
 	api.HandleFunc("/faqs", getFAQs).Methods("GET")
+	api.HandleFunc("/faqs/search", searchFAQs).Methods("GET")
+	api.HandleFunc("/pages/{page}", getPageLayout).Methods("GET")
+	api.HandleFunc("/projects", getProjects).Methods("GET")
+	api.HandleFunc("/projects/{id}", getProject).Methods("GET")
+	api.HandleFunc("/currencies", getCurrencies).Methods("GET")
+	api.HandleFunc("/events", recordEvents).Methods("POST")
+	// Customer accounts
+	api.HandleFunc("/account/register", registerCustomer).Methods("POST")
+	api.HandleFunc("/account/verify", verifyCustomerEmail).Methods("POST")
+	api.HandleFunc("/account/verify/resend", resendVerification).Methods("POST")
+	api.HandleFunc("/account/login", loginCustomer).Methods("POST")
+	api.HandleFunc("/account/logout", logoutCustomer).Methods("POST")
+	api.HandleFunc("/account/password-reset", requestPasswordReset).Methods("POST")
+	api.HandleFunc("/account/password-reset/confirm", confirmPasswordReset).Methods("POST")
+	api.HandleFunc("/account", getAccount).Methods("GET")
+	api.HandleFunc("/account", patchAccount).Methods("PATCH")
+	api.HandleFunc("/account/password", changeAccountPassword).Methods("POST")
+	api.HandleFunc("/account/requests", getAccountRequests).Methods("GET")
+	api.HandleFunc("/account/quotes", getAccountQuotes).Methods("GET")
+	// Project boards
+	api.HandleFunc("/boards", getBoards).Methods("GET")
+	api.HandleFunc("/boards", createBoard).Methods("POST")
+	api.HandleFunc("/boards/claim", claimBoards).Methods("POST")
+	api.HandleFunc("/boards/shared/{token}", getSharedBoard).Methods("GET")
+	api.HandleFunc("/boards/{id}", getBoard).Methods("GET")
+	api.HandleFunc("/boards/{id}", renameBoard).Methods("PATCH")
+	api.HandleFunc("/boards/{id}", deleteBoard).Methods("DELETE")
+	api.HandleFunc("/boards/{id}/items", putBoardItem).Methods("POST")
+	api.HandleFunc("/boards/{id}/items/{product_id}", putBoardItem).Methods("PUT")
+	api.HandleFunc("/boards/{id}/items/{product_id}", deleteBoardItem).Methods("DELETE")
+	api.HandleFunc("/boards/{id}/share", shareBoard).Methods("POST")
+	api.HandleFunc("/boards/{id}/share", unshareBoard).Methods("DELETE")
+	api.HandleFunc("/boards/{id}/contact", convertBoard).Methods("POST")
+	api.HandleFunc("/boards/{id}/quote", convertBoard).Methods("POST")
 	api.HandleFunc("/health", healthCheck).Methods("GET")
+	api.HandleFunc("/maintenance", getMaintenance).Methods("GET")
 
-	// Admin API routes (CRUD)
+	// Admin API routes (CRUD), available to logged-in admin users only
+	api.HandleFunc("/admin/login", loginAdmin).Methods("POST")
 	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(adminAuthMiddleware)
+	admin.Use(auditMiddleware)
+	admin.HandleFunc("/logout", logoutAdmin).Methods("POST")
+	admin.HandleFunc("/me", getCurrentAdmin).Methods("GET")
+	admin.HandleFunc("/users", getAdminUsers).Methods("GET")
+	admin.HandleFunc("/users", createAdminUser).Methods("POST")
+	admin.HandleFunc("/users/{id}", deleteAdminUser).Methods("DELETE")
 	// Upload
 	admin.HandleFunc("/upload", uploadImage).Methods("POST")
 	// Products
+	admin.HandleFunc("/products", withDrafts(getProducts)).Methods("GET")
 	admin.HandleFunc("/products", createProduct).Methods("POST")
+	admin.HandleFunc("/products/{id}", withDrafts(getProduct)).Methods("GET")
 	admin.HandleFunc("/products/{id}", updateProduct).Methods("PUT")
+	admin.HandleFunc("/products/{id}", patchProduct).Methods("PATCH")
 	admin.HandleFunc("/products/{id}", deleteProduct).Methods("DELETE")
+	admin.HandleFunc("/products/{id}/relations", getProductRelations).Methods("GET")
+	admin.HandleFunc("/products/{id}/relations", updateProductRelations).Methods("PUT")
 	// Categories
+	admin.HandleFunc("/categories", withDrafts(getCategories)).Methods("GET")
 	admin.HandleFunc("/categories", createCategory).Methods("POST")
+	admin.HandleFunc("/categories/{id}", getCategory).Methods("GET")
 	admin.HandleFunc("/categories/{id}", updateCategory).Methods("PUT")
+	admin.HandleFunc("/categories/{id}", patchCategory).Methods("PATCH")
 	admin.HandleFunc("/categories/{id}", deleteCategory).Methods("DELETE")
 	// Collections
+	admin.HandleFunc("/collections", withDrafts(getCollections)).Methods("GET")
 	admin.HandleFunc("/collections", createCollection).Methods("POST")
+	admin.HandleFunc("/collections/{id}", withDrafts(getCollection)).Methods("GET")
 	admin.HandleFunc("/collections/{id}", updateCollection).Methods("PUT")
+	admin.HandleFunc("/collections/{id}", patchCollection).Methods("PATCH")
 	admin.HandleFunc("/collections/{id}", deleteCollection).Methods("DELETE")
 	admin.HandleFunc("/collections/{id}/products", addProductToCollection).Methods("POST")
 	admin.HandleFunc("/collections/{id}/products/{product_id}", removeProductFromCollection).Methods("DELETE")
@@ -1543,24 +2775,123 @@ func main() {
 	admin.HandleFunc("/placeholders", createPlaceholder).Methods("POST")
 	admin.HandleFunc("/placeholders/{id}", getPlaceholder).Methods("GET")
 	admin.HandleFunc("/placeholders/{id}", updatePlaceholder).Methods("PUT")
+	admin.HandleFunc("/placeholders/{id}", patchPlaceholder).Methods("PATCH")
 	admin.HandleFunc("/placeholders/{id}", deletePlaceholder).Methods("DELETE")
+	// Redirects
+	admin.HandleFunc("/redirects", getRedirects).Methods("GET")
+	admin.HandleFunc("/redirects", createRedirect).Methods("POST")
+	admin.HandleFunc("/redirects/{id}", getRedirect).Methods("GET")
+	admin.HandleFunc("/redirects/{id}", updateRedirect).Methods("PUT")
+	admin.HandleFunc("/redirects/{id}", patchRedirect).Methods("PATCH")
+	admin.HandleFunc("/redirects/{id}", deleteRedirect).Methods("DELETE")
+	// Content blocks
+	admin.HandleFunc("/pages/{page}", withDrafts(getPageLayout)).Methods("GET")
+	admin.HandleFunc("/content-sections", getContentSections).Methods("GET")
+	admin.HandleFunc("/content-sections", createContentSection).Methods("POST")
+	admin.HandleFunc("/content-sections/{id}", getContentSection).Methods("GET")
+	admin.HandleFunc("/content-sections/{id}", updateContentSection).Methods("PUT")
+	admin.HandleFunc("/content-sections/{id}", patchContentSection).Methods("PATCH")
+	admin.HandleFunc("/content-sections/{id}", deleteContentSection).Methods("DELETE")
+	admin.HandleFunc("/content-blocks", getContentBlocks).Methods("GET")
+	admin.HandleFunc("/content-blocks", createContentBlock).Methods("POST")
+	admin.HandleFunc("/content-blocks/{id}", getContentBlock).Methods("GET")
+	admin.HandleFunc("/content-blocks/{id}", updateContentBlock).Methods("PUT")
+	admin.HandleFunc("/content-blocks/{id}", patchContentBlock).Methods("PATCH")
+	admin.HandleFunc("/content-blocks/{id}", deleteContentBlock).Methods("DELETE")
+	// Portfolio projects
+	admin.HandleFunc("/projects", withDrafts(getProjects)).Methods("GET")
+	admin.HandleFunc("/projects", createProject).Methods("POST")
+	admin.HandleFunc("/projects/{id}", withDrafts(getProject)).Methods("GET")
+	admin.HandleFunc("/projects/{id}", updateProject).Methods("PUT")
+	admin.HandleFunc("/projects/{id}", patchProject).Methods("PATCH")
+	admin.HandleFunc("/projects/{id}", deleteProject).Methods("DELETE")
+	// Newsletter
+	admin.HandleFunc("/newsletter/subscribers", getSubscribers).Methods("GET")
+	admin.HandleFunc("/newsletter/subscribers/export", exportSubscribers).Methods("GET")
+	admin.HandleFunc("/newsletter/subscribers/{id}", deleteSubscriber).Methods("DELETE")
+	admin.HandleFunc("/newsletter/campaigns", getCampaigns).Methods("GET")
+	admin.HandleFunc("/newsletter/campaigns", createCampaign).Methods("POST")
+	admin.HandleFunc("/newsletter/campaigns/{id}", getCampaign).Methods("GET")
 	// Contacts
 	admin.HandleFunc("/contacts", getContacts).Methods("GET")
+	admin.HandleFunc("/contacts/{id}", patchContact).Methods("PATCH")
 	admin.HandleFunc("/contacts/{id}", deleteContact).Methods("DELETE")
 	// FAQs
+	admin.HandleFunc("/faqs", getFAQs).Methods("GET")
 	admin.HandleFunc("/faqs", createFAQ).Methods("POST")
+	admin.HandleFunc("/faqs/order", reorderFAQs).Methods("PUT")
 	admin.HandleFunc("/faqs/{id}", getFAQ).Methods("GET")
 	admin.HandleFunc("/faqs/{id}", updateFAQ).Methods("PUT")
+	admin.HandleFunc("/faqs/{id}", patchFAQ).Methods("PATCH")
 	admin.HandleFunc("/faqs/{id}", deleteFAQ).Methods("DELETE")
+
+	admin.HandleFunc("/reviews", getReviews).Methods("GET")
+	admin.HandleFunc("/reviews/{id}", getReview).Methods("GET")
+	admin.HandleFunc("/reviews/{id}", patchReview).Methods("PATCH")
+	admin.HandleFunc("/reviews/{id}", deleteReview).Methods("DELETE")
 	// Database dumps
 	admin.HandleFunc("/db/dump", createDump).Methods("POST")
+	admin.HandleFunc("/db/dumps/{name}", downloadDump).Methods("GET")
 	admin.HandleFunc("/db/restore", restoreDump).Methods("POST")
+	// Preview links for unpublished content
+	admin.HandleFunc("/preview-token", createPreviewToken).Methods("POST")
+	// Trash
+	admin.HandleFunc("/trash", getTrash).Methods("GET")
+	admin.HandleFunc("/trash/{type}/{id}/restore", restoreFromTrash).Methods("POST")
+	admin.HandleFunc("/trash/{type}/{id}", purgeFromTrash).Methods("DELETE")
+	// Revision history
+	admin.HandleFunc("/revisions/{type}/{id}", getRevisions).Methods("GET")
+	admin.HandleFunc("/revisions/{type}/{id}/diff", diffRevisions).Methods("GET")
+	admin.HandleFunc("/revisions/{type}/{id}/{revision}", getRevision).Methods("GET")
+	admin.HandleFunc("/revisions/{type}/{id}/{revision}/restore", restoreRevision).Methods("POST")
+	// Site settings
+	admin.HandleFunc("/settings", getSettings).Methods("GET")
+	admin.HandleFunc("/settings/{key}", updateSetting).Methods("PUT")
+	// Dashboard statistics
+	admin.HandleFunc("/stats", getStats).Methods("GET")
+	// Content quality
+	admin.HandleFunc("/quality", getQualityReport).Methods("GET")
+	// Audit log
+	admin.HandleFunc("/audit", getAuditLog).Methods("GET")
+
+	admin.HandleFunc("/analytics/top-products", getTopProducts).Methods("GET")
+	admin.HandleFunc("/analytics/zero-result-searches", getZeroResultSearches).Methods("GET")
+	admin.HandleFunc("/analytics/funnel", getFunnel).Methods("GET")
+	// Price lists and B2B customers
+	admin.HandleFunc("/price-lists", getPriceLists).Methods("GET")
+	admin.HandleFunc("/price-lists", createPriceList).Methods("POST")
+	admin.HandleFunc("/price-lists/{id}", getPriceList).Methods("GET")
+	admin.HandleFunc("/price-lists/{id}", updatePriceList).Methods("PUT")
+	admin.HandleFunc("/price-lists/{id}", patchPriceList).Methods("PATCH")
+	admin.HandleFunc("/price-lists/{id}", deletePriceList).Methods("DELETE")
+	admin.HandleFunc("/customers", getCustomers).Methods("GET")
+	admin.HandleFunc("/customers", createCustomer).Methods("POST")
+	admin.HandleFunc("/customers/{id}", getCustomer).Methods("GET")
+	admin.HandleFunc("/customers/{id}", updateCustomer).Methods("PUT")
+	admin.HandleFunc("/customers/{id}", patchCustomer).Methods("PATCH")
+	admin.HandleFunc("/customers/{id}", deleteCustomer).Methods("DELETE")
+	admin.HandleFunc("/customers/{id}/api-key", rotateCustomerAPIKey).Methods("POST")
+	admin.HandleFunc("/quotes", getQuotes).Methods("GET")
+	admin.HandleFunc("/quotes", createQuote).Methods("POST")
+	admin.HandleFunc("/quotes/{id}", getQuote).Methods("GET")
+	admin.HandleFunc("/quotes/{id}", updateQuote).Methods("PUT")
+	admin.HandleFunc("/quotes/{id}", patchQuote).Methods("PATCH")
+	admin.HandleFunc("/quotes/{id}", deleteQuote).Methods("DELETE")
+	// Exchange rates
+	admin.HandleFunc("/exchange-rates", getCurrencies).Methods("GET")
+	admin.HandleFunc("/exchange-rates/import", importExchangeRates).Methods("POST")
+	admin.HandleFunc("/exchange-rates/{currency}", updateExchangeRate).Methods("PUT")
+	// Translations
+	admin.HandleFunc("/translations/missing", getMissingTranslations).Methods("GET")
+	admin.HandleFunc("/translations/{type}/{id}", getTranslations).Methods("GET")
+	admin.HandleFunc("/translations/{type}/{id}/{locale}", updateTranslations).Methods("PUT")
 
 	// CORS middleware
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"http://localhost:3000", "http://frontend:3000"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders: []string{"*"},
+		ExposedHeaders: []string{"ETag", "Content-Language", "Content-Disposition"},
 	})
 
 	handler := c.Handler(r)
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Maintenance mode settings. While maintenance_mode is "on" the public API
+// answers 503 with the message and a Retry-After of maintenance_retry_after
+// seconds.
+const (
+	settingMaintenanceMode       = "maintenance_mode"
+	settingMaintenanceMessage    = "maintenance_message"
+	settingMaintenanceRetryAfter = "maintenance_retry_after"
+)
+
+const defaultMaintenanceMessage = "Сайт временно закрыт на техническое обслуживание. Пожалуйста, зайдите позже."
+
+func validateMaintenanceMode(value string) error {
+	if value != "on" && value != "off" {
+		return &validationError{msg: "Value must be \"on\" or \"off\""}
+	}
+	return nil
+}
+
+func validateRetryAfter(value string) error {
+	if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+		return &validationError{msg: "Value must be a positive number of seconds"}
+	}
+	return nil
+}
+
+// maintenanceState is the maintenance mode as the public API sees it.
+type maintenanceState struct {
+	enabled    bool
+	message    string
+	retryAfter string
+}
+
+// maintenanceCache keeps the maintenance settings in memory, since every
+// public request checks them. updateSetting and restores invalidate it.
+var maintenanceCache struct {
+	sync.Mutex
+	state  maintenanceState
+	loaded bool
+}
+
+// invalidateMaintenanceCache makes the next request reload the settings.
+func invalidateMaintenanceCache() {
+	maintenanceCache.Lock()
+	maintenanceCache.loaded = false
+	maintenanceCache.Unlock()
+}
+
+// currentMaintenance returns the cached maintenance settings, loading them
+// when needed. The message and retry delay are only read while the mode is
+// on.
+func currentMaintenance() (maintenanceState, error) {
+	maintenanceCache.Lock()
+	defer maintenanceCache.Unlock()
+	if maintenanceCache.loaded {
+		return maintenanceCache.state, nil
+	}
+
+	mode, err := getSetting(settingMaintenanceMode)
+	if err != nil {
+		return maintenanceState{}, err
+	}
+	state := maintenanceState{enabled: mode == "on"}
+	if state.enabled {
+		if state.message, err = getSetting(settingMaintenanceMessage); err != nil {
+			return maintenanceState{}, err
+		}
+		if state.retryAfter, err = getSetting(settingMaintenanceRetryAfter); err != nil {
+			return maintenanceState{}, err
+		}
+	}
+	maintenanceCache.state, maintenanceCache.loaded = state, true
+	return state, nil
+}
+
+// maintenanceExempt reports whether a request is served during maintenance:
+// the admin API, the health check, the maintenance status itself, and
+// requests from signed-in admins so they can check the site before reopening
+// it.
+func maintenanceExempt(r *http.Request) bool {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/admin/"),
+		r.URL.Path == "/api/health",
+		r.URL.Path == "/api/maintenance":
+		return true
+	}
+	return hasAdminSession(r)
+}
+
+// maintenanceMiddleware answers public API requests with 503 while the
+// maintenance mode is on.
+func maintenanceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, err := currentMaintenance()
+		if err != nil {
+			// Keep serving; the handler reports database trouble itself
+			log.Printf("Error reading maintenance mode: %v", err)
+		}
+		if !state.enabled || maintenanceExempt(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", state.retryAfter)
+		http.Error(w, state.message, http.StatusServiceUnavailable)
+	})
+}
+
+// getMaintenance reports the maintenance mode to the frontend, which shows
+// the message instead of the pages while it is on. "bypass" tells it that the
+// request comes from a signed-in admin, who can look around the closed site.
+func getMaintenance(w http.ResponseWriter, r *http.Request) {
+	state, err := currentMaintenance()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	status := map[string]interface{}{"enabled": state.enabled}
+	if state.enabled {
+		seconds, _ := strconv.Atoi(state.retryAfter)
+		status["message"] = state.message
+		status["retry_after"] = seconds
+		status["bypass"] = hasAdminSession(r)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,594 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// States of a newsletter subscriber. Subscribers are pending until they
+// follow the link in the confirmation email (double opt-in).
+const (
+	subscriberPending      = "pending"
+	subscriberConfirmed    = "confirmed"
+	subscriberUnsubscribed = "unsubscribed"
+)
+
+const (
+	newsletterConfirmTTL = 72 * time.Hour
+	// A new confirmation email for the same address is sent at most this often
+	newsletterResendInterval = 10 * time.Minute
+)
+
+// newsletterBatchSize is how many campaign emails are sent in one go,
+// configured with NEWSLETTER_BATCH_SIZE.
+func newsletterBatchSize() int {
+	n, err := strconv.Atoi(os.Getenv("NEWSLETTER_BATCH_SIZE"))
+	if err != nil || n <= 0 {
+		n = 50
+	}
+	return n
+}
+
+// newsletterBatchPause is the wait between batches, configured with
+// NEWSLETTER_BATCH_PAUSE_SECONDS, to stay within the SMTP provider's limits.
+func newsletterBatchPause() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("NEWSLETTER_BATCH_PAUSE_SECONDS"))
+	if err != nil || seconds < 0 {
+		seconds = 5
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Subscriber records the consent of a newsletter subscriber: when and from
+// which address the form was submitted and the confirmation link followed.
+type Subscriber struct {
+	ID             int        `json:"id"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	Source         string     `json:"source"` // form the subscription came from, e.g. "footer"
+	ConsentAt      time.Time  `json:"consent_at"`
+	ConsentIP      string     `json:"consent_ip"`
+	ConfirmedAt    *time.Time `json:"confirmed_at"`
+	ConfirmedIP    string     `json:"confirmed_ip"`
+	UnsubscribedAt *time.Time `json:"unsubscribed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+const subscriberColumns = "id, email, status, source, consent_at, consent_ip, confirmed_at, confirmed_ip, unsubscribed_at, created_at"
+
+func scanSubscriber(s rowScanner) (Subscriber, error) {
+	var sub Subscriber
+	err := s.Scan(&sub.ID, &sub.Email, &sub.Status, &sub.Source, &sub.ConsentAt, &sub.ConsentIP, &sub.ConfirmedAt, &sub.ConfirmedIP, &sub.UnsubscribedAt, &sub.CreatedAt)
+	return sub, err
+}
+
+func newsletterUnsubscribeURL(token string) string {
+	return siteURL + "/newsletter/unsubscribe?token=" + token
+}
+
+func sendNewsletterConfirmation(email, token string) {
+	body := fmt.Sprintf("Здравствуйте!\n\nВы подписались на рассылку. Чтобы начать получать письма, подтвердите подписку по ссылке:\n%s/newsletter/confirm?token=%s\n\nСсылка действительна %d часа. Если вы не подписывались, просто проигнорируйте это письмо.",
+		siteURL, token, int(newsletterConfirmTTL.Hours()))
+	if err := sendMail(email, "Подтверждение подписки на рассылку", body); err != nil {
+		log.Printf("Error sending newsletter confirmation: %v", err)
+	}
+}
+
+// recordSubscription records the consent for email and, unless the address
+// is already confirmed or was sent a link moments ago, issues a new
+// confirmation token. send reports whether the token should be emailed.
+func recordSubscription(email, source, ip string) (send bool, token string, err error) {
+	token, err = newAPIKey()
+	if err != nil {
+		return false, "", err
+	}
+	expiresAt := time.Now().Add(newsletterConfirmTTL)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, "", err
+	}
+	defer tx.Rollback()
+
+	var id int
+	var status string
+	var sentAt *time.Time
+	err = tx.QueryRow("SELECT id, status, confirm_sent_at FROM newsletter_subscribers WHERE email = $1 FOR UPDATE", email).Scan(&id, &status, &sentAt)
+	switch {
+	case err == sql.ErrNoRows:
+		unsubscribeToken, err := newAPIKey()
+		if err != nil {
+			return false, "", err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO newsletter_subscribers (email, status, source, consent_ip, confirm_token_hash, confirm_expires_at, confirm_sent_at, unsubscribe_token)
+			VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, $7)`,
+			email, subscriberPending, source, ip, hashToken(token), expiresAt, unsubscribeToken,
+		)
+		if _, duplicate := uniqueViolation(err, "").(*validationError); duplicate {
+			// Submitted twice at once; the other request sends the email
+			return false, "", nil
+		}
+		if err != nil {
+			return false, "", err
+		}
+		send = true
+	case err != nil:
+		return false, "", err
+	case status == subscriberConfirmed:
+		return false, "", nil
+	default:
+		send = sentAt == nil || time.Since(*sentAt) >= newsletterResendInterval
+		_, err = tx.Exec(
+			`UPDATE newsletter_subscribers SET status = $1, source = $2, consent_at = CURRENT_TIMESTAMP, consent_ip = $3,
+				confirm_token_hash = CASE WHEN $4 THEN $5 ELSE confirm_token_hash END,
+				confirm_expires_at = CASE WHEN $4 THEN $6 ELSE confirm_expires_at END,
+				confirm_sent_at = CASE WHEN $4 THEN CURRENT_TIMESTAMP ELSE confirm_sent_at END,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $7`,
+			subscriberPending, source, ip, send, hashToken(token), expiresAt, id,
+		)
+		if err != nil {
+			return false, "", err
+		}
+	}
+	return send, token, tx.Commit()
+}
+
+// validEmail reports whether email is a bare address such as
+// "anna@example.com", without a display name or angle brackets.
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+// subscribeNewsletter starts a subscription and emails a confirmation link.
+// It always answers 202 so that it does not reveal who is subscribed.
+func subscribeNewsletter(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email  string `json:"email"`
+		Source string `json:"source"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !validEmail(email) || len(email) > 255 {
+		writeSaveError(w, &validationError{msg: "Field \"email\" must be an email address"}, "")
+		return
+	}
+	req.Source = truncateUTF8(req.Source, 100)
+
+	send, token, err := recordSubscription(email, req.Source, clientIP(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if send {
+		go sendNewsletterConfirmation(email, token)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": subscriberPending})
+}
+
+// confirmNewsletter completes the double opt-in with the emailed token.
+func confirmNewsletter(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var id int
+	err := db.QueryRow(
+		`UPDATE newsletter_subscribers SET status = $1, confirmed_at = CURRENT_TIMESTAMP, confirmed_ip = $2, unsubscribed_at = NULL,
+			confirm_token_hash = NULL, confirm_expires_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE confirm_token_hash = $3 AND confirm_expires_at > CURRENT_TIMESTAMP RETURNING id`,
+		subscriberConfirmed, clientIP(r), hashToken(req.Token),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid or expired token", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": subscriberConfirmed})
+}
+
+// unsubscribeNewsletter handles the link in every newsletter email. The
+// subscriber is kept, so the consent history stays on record.
+func unsubscribeNewsletter(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var id int
+	err := db.QueryRow(
+		`UPDATE newsletter_subscribers SET status = $1, unsubscribed_at = COALESCE(unsubscribed_at, CURRENT_TIMESTAMP),
+			confirm_token_hash = NULL, confirm_expires_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE unsubscribe_token = $2 AND $2 <> '' RETURNING id`,
+		subscriberUnsubscribed, req.Token,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid token", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": subscriberUnsubscribed})
+}
+
+// Subscribers (admin)
+
+// subscriberFilter builds the WHERE clause for ?status= and ?q= (part of the
+// email).
+func subscriberFilter(r *http.Request) (string, []interface{}, error) {
+	where := " WHERE 1=1"
+	var args []interface{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		switch status {
+		case subscriberPending, subscriberConfirmed, subscriberUnsubscribed:
+		default:
+			return "", nil, fmt.Errorf("status must be one of %s, %s, %s", subscriberPending, subscriberConfirmed, subscriberUnsubscribed)
+		}
+		args = append(args, status)
+		where += " AND status = $" + strconv.Itoa(len(args))
+	}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		args = append(args, "%"+strings.ToLower(q)+"%")
+		where += " AND email LIKE $" + strconv.Itoa(len(args))
+	}
+	return where, args, nil
+}
+
+// getSubscribers lists subscribers, newest first, with the total matching
+// count for pagination.
+func getSubscribers(w http.ResponseWriter, r *http.Request) {
+	where, args, err := subscriberFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset := pageParams(r, 100, 1000)
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM newsletter_subscribers"+where, args...).Scan(&total); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := db.Query("SELECT "+subscriberColumns+" FROM newsletter_subscribers"+where+" ORDER BY created_at DESC, id DESC LIMIT "+strconv.Itoa(limit)+" OFFSET "+strconv.Itoa(offset), args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	subscribers := []Subscriber{}
+	for rows.Next() {
+		sub, err := scanSubscriber(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		subscribers = append(subscribers, sub)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"subscribers": subscribers,
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// exportSubscribers downloads the subscribers matching the list filters as
+// CSV, including the consent records.
+func exportSubscribers(w http.ResponseWriter, r *http.Request) {
+	where, args, err := subscriberFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rows, err := db.Query("SELECT "+subscriberColumns+" FROM newsletter_subscribers"+where+" ORDER BY id", args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"subscribers-%s.csv\"", time.Now().Format("2006-01-02")))
+	out := csv.NewWriter(w)
+	out.Write([]string{"id", "email", "status", "source", "consent_at", "consent_ip", "confirmed_at", "confirmed_ip", "unsubscribed_at", "created_at"})
+	for rows.Next() {
+		sub, err := scanSubscriber(rows)
+		if err != nil {
+			log.Printf("Error exporting subscribers: %v", err)
+			break
+		}
+		out.Write([]string{
+			strconv.Itoa(sub.ID), sub.Email, sub.Status, sub.Source,
+			csvTime(&sub.ConsentAt), sub.ConsentIP, csvTime(sub.ConfirmedAt), sub.ConfirmedIP,
+			csvTime(sub.UnsubscribedAt), csvTime(&sub.CreatedAt),
+		})
+	}
+	out.Flush()
+}
+
+// deleteSubscriber erases a subscriber with their consent record, e.g. on a
+// data removal request. Unsubscribing keeps the record instead.
+func deleteSubscriber(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid subscriber ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM newsletter_subscribers WHERE id = $1", id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "Subscriber not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Campaigns (admin)
+
+// States of a campaign.
+const (
+	campaignSending = "sending"
+	campaignSent    = "sent"
+)
+
+// Campaign is a plain-text email sent to every confirmed subscriber. Sent
+// and Failed count the emails so far while it is sending.
+type Campaign struct {
+	ID         int        `json:"id"`
+	Subject    string     `json:"subject"`
+	Body       string     `json:"body"`
+	Status     string     `json:"status"`
+	Recipients int        `json:"recipients"` // confirmed subscribers when it was started
+	Sent       int        `json:"sent"`
+	Failed     int        `json:"failed"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+const campaignColumns = "id, subject, body, status, recipients, sent, failed, created_by, created_at, finished_at"
+
+func scanCampaign(s rowScanner) (Campaign, error) {
+	var c Campaign
+	err := s.Scan(&c.ID, &c.Subject, &c.Body, &c.Status, &c.Recipients, &c.Sent, &c.Failed, &c.CreatedBy, &c.CreatedAt, &c.FinishedAt)
+	return c, err
+}
+
+func fetchCampaign(id int) (Campaign, error) {
+	return scanCampaign(db.QueryRow("SELECT "+campaignColumns+" FROM newsletter_campaigns WHERE id = $1", id))
+}
+
+// campaignBody appends the unsubscribe link every newsletter must carry.
+func campaignBody(body, unsubscribeToken string) string {
+	return body + "\n\n--\nВы получили это письмо, потому что подписались на рассылку " + siteURL + ".\nОтписаться: " + newsletterUnsubscribeURL(unsubscribeToken)
+}
+
+// sendCampaign delivers a campaign batch by batch. Progress is stored after
+// every recipient, so after a restart it resumes right after the last
+// subscriber it reached and nobody gets the email twice.
+func sendCampaign(id int) {
+	for {
+		var subject, body string
+		var lastSubscriberID int
+		err := db.QueryRow("SELECT subject, body, last_subscriber_id FROM newsletter_campaigns WHERE id = $1 AND status = $2", id, campaignSending).
+			Scan(&subject, &body, &lastSubscriberID)
+		if err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("Error loading campaign %d: %v", id, err)
+			}
+			return
+		}
+
+		rows, err := db.Query(
+			"SELECT id, email, unsubscribe_token FROM newsletter_subscribers WHERE status = $1 AND id > $2 ORDER BY id LIMIT $3",
+			subscriberConfirmed, lastSubscriberID, newsletterBatchSize(),
+		)
+		if err != nil {
+			log.Printf("Error loading recipients of campaign %d: %v", id, err)
+			return
+		}
+		type recipient struct {
+			id           int
+			email, token string
+		}
+		var batch []recipient
+		for rows.Next() {
+			var rc recipient
+			if err := rows.Scan(&rc.id, &rc.email, &rc.token); err != nil {
+				log.Printf("Error loading recipients of campaign %d: %v", id, err)
+				break
+			}
+			batch = append(batch, rc)
+		}
+		rows.Close()
+
+		if len(batch) == 0 {
+			if _, err := db.Exec("UPDATE newsletter_campaigns SET status = $1, finished_at = CURRENT_TIMESTAMP WHERE id = $2", campaignSent, id); err != nil {
+				log.Printf("Error finishing campaign %d: %v", id, err)
+			}
+			return
+		}
+
+		for _, rc := range batch {
+			sent, failed := 1, 0
+			err := sendMail(rc.email, subject, campaignBody(body, rc.token),
+				"List-Unsubscribe: <"+newsletterUnsubscribeURL(rc.token)+">")
+			if err != nil {
+				log.Printf("Error sending campaign %d to subscriber %d: %v", id, rc.id, err)
+				sent, failed = 0, 1
+			}
+			if _, err := db.Exec(
+				"UPDATE newsletter_campaigns SET sent = sent + $1, failed = failed + $2, last_subscriber_id = $3 WHERE id = $4",
+				sent, failed, rc.id, id,
+			); err != nil {
+				log.Printf("Error recording progress of campaign %d: %v", id, err)
+				return
+			}
+		}
+		time.Sleep(newsletterBatchPause())
+	}
+}
+
+// resumeCampaigns continues the campaigns interrupted by a restart.
+func resumeCampaigns() {
+	rows, err := db.Query("SELECT id FROM newsletter_campaigns WHERE status = $1 ORDER BY id", campaignSending)
+	if err != nil {
+		log.Printf("Error loading unfinished campaigns: %v", err)
+		return
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if rows.Scan(&id) == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	for _, id := range ids {
+		log.Printf("Resuming newsletter campaign %d", id)
+		go sendCampaign(id)
+	}
+}
+
+// createCampaign starts sending a campaign to the confirmed subscribers and
+// answers 202 right away; poll GET /api/admin/newsletter/campaigns/{id} for
+// progress. With "test_email" the email is only sent to that address, with
+// a dummy unsubscribe link.
+func createCampaign(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Subject   string `json:"subject"`
+		Body      string `json:"body"`
+		TestEmail string `json:"test_email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Subject = strings.TrimSpace(req.Subject)
+	if req.Subject == "" || strings.TrimSpace(req.Body) == "" {
+		writeSaveError(w, &validationError{msg: "Fields \"subject\" and \"body\" are required"}, "")
+		return
+	}
+
+	if req.TestEmail != "" {
+		if err := sendMail(req.TestEmail, req.Subject, campaignBody(req.Body, "test")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "test_sent"})
+		return
+	}
+
+	var c Campaign
+	err := db.QueryRow(
+		`INSERT INTO newsletter_campaigns (subject, body, status, recipients, created_by)
+		VALUES ($1, $2, $3, (SELECT COUNT(*) FROM newsletter_subscribers WHERE status = $4), $5)
+		RETURNING `+campaignColumns,
+		req.Subject, req.Body, campaignSending, subscriberConfirmed, auditActor(r),
+	).Scan(&c.ID, &c.Subject, &c.Body, &c.Status, &c.Recipients, &c.Sent, &c.Failed, &c.CreatedBy, &c.CreatedAt, &c.FinishedAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	go sendCampaign(c.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(c)
+}
+
+func getCampaigns(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r, 50, 500)
+	rows, err := db.Query("SELECT "+campaignColumns+" FROM newsletter_campaigns ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	campaigns := []Campaign{}
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		campaigns = append(campaigns, c)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(campaigns)
+}
+
+func getCampaign(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+		return
+	}
+
+	c, err := fetchCampaign(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Campaign not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
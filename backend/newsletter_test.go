@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestValidEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{email: "anna@example.com", want: true},
+		{email: "anna.petrova+news@mail.example.ru", want: true},
+		{email: "", want: false},
+		{email: "anna", want: false},
+		{email: "anna@", want: false},
+		{email: "@example.com", want: false},
+		{email: "Anna <anna@example.com>", want: false},
+		{email: "<anna@example.com>", want: false},
+		{email: "anna@example.com, boris@example.com", want: false},
+		{email: "anna @example.com", want: false},
+	}
+	for _, tt := range tests {
+		if got := validEmail(tt.email); got != tt.want {
+			t.Errorf("validEmail(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
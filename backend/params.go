@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// pageParams reads limit/offset query parameters, falling back to def and
+// capping limit at max.
+func pageParams(r *http.Request, def, max int) (int, int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = def
+	}
+	if limit > max {
+		limit = max
+	}
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// parseDateParam accepts either a full RFC 3339 timestamp or a bare date.
+func parseDateParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
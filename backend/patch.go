@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// validationError is returned when a request body is well-formed JSON but
+// does not describe a valid entity. Handlers answer it with 422.
+type validationError struct {
+	msg string
+}
+
+func (e *validationError) Error() string {
+	return e.msg
+}
+
+// writeDecodeError maps errors from decodeFull/applyMergePatch to a status code.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var ve *validationError
+	if errors.As(err, &ve) {
+		http.Error(w, ve.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// decodeFull decodes a full entity representation (PUT) into dst and checks
+// that every required field is present and not null. Unknown fields are
+// rejected, so that a misspelt field fails instead of clearing the real one.
+func decodeFull(body io.Reader, dst interface{}, required ...string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, name := range required {
+		raw, ok := fields[name]
+		if !ok || string(raw) == "null" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return &validationError{msg: "Missing required fields: " + strings.Join(missing, ", ")}
+	}
+
+	return decodeStrict(data, dst)
+}
+
+// applyMergePatch applies a JSON Merge Patch (RFC 7386) from body onto dst.
+// Fields absent from the patch keep their current value, null resets a field
+// to its zero value. Unknown fields, read-only fields, null for a required
+// field and values of the wrong type are reported as validation errors, as
+// decodeFull does for PUT.
+func applyMergePatch(body io.Reader, dst interface{}, required []string, readOnly ...string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	var patch map[string]interface{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return err
+	}
+	if patch == nil {
+		return &validationError{msg: "Patch must be a JSON object"}
+	}
+	for _, name := range readOnly {
+		if _, ok := patch[name]; ok {
+			return &validationError{msg: fmt.Sprintf("Field %q is read-only", name)}
+		}
+	}
+	var cleared []string
+	for _, name := range required {
+		if value, ok := patch[name]; ok && value == nil {
+			cleared = append(cleared, name)
+		}
+	}
+	if len(cleared) > 0 {
+		return &validationError{msg: "Required fields cannot be null: " + strings.Join(cleared, ", ")}
+	}
+
+	currentJSON, err := json.Marshal(dst)
+	if err != nil {
+		return err
+	}
+	var current map[string]interface{}
+	if err := json.Unmarshal(currentJSON, &current); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(current, patch))
+	if err != nil {
+		return err
+	}
+
+	return decodeStrict(merged, dst)
+}
+
+// mergePatch implements the RFC 7386 merge algorithm on decoded JSON values.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// decodeStrict decodes data into a freshly zeroed dst so that type mismatches
+// and unknown fields surface as validation errors.
+func decodeStrict(data []byte, dst interface{}) error {
+	v := reflect.ValueOf(dst).Elem()
+	v.Set(reflect.Zero(v.Type()))
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return &validationError{msg: fmt.Sprintf("Field %q must be of type %s", typeErr.Field, typeErr.Type)}
+		}
+		if strings.HasPrefix(err.Error(), "json: unknown field") {
+			return &validationError{msg: strings.TrimPrefix(err.Error(), "json: ")}
+		}
+		return err
+	}
+	return nil
+}
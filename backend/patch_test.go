@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type patchTarget struct {
+	Name   string   `json:"name"`
+	Price  float64  `json:"price"`
+	Tags   []string `json:"tags"`
+	Nested struct {
+		A string `json:"a"`
+		B string `json:"b"`
+	} `json:"nested"`
+	Version int `json:"version"`
+}
+
+func TestDecodeFull(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    patchTarget
+		invalid bool // validation error (422) rather than malformed JSON (400)
+		wantErr bool
+	}{
+		{name: "complete", body: `{"name":"Chair","price":10}`, want: patchTarget{Name: "Chair", Price: 10}},
+		{name: "missing required", body: `{"price":10}`, invalid: true},
+		{name: "null required", body: `{"name":null,"price":10}`, invalid: true},
+		{name: "wrong type", body: `{"name":"Chair","price":"ten"}`, invalid: true},
+		{name: "unknown field", body: `{"name":"Chair","price":10,"prise":5}`, invalid: true},
+		{name: "malformed", body: `{"name":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := patchTarget{Name: "stale"}
+			err := decodeFull(strings.NewReader(tt.body), &got, "name", "price")
+			var ve *validationError
+			switch {
+			case tt.invalid:
+				if !errors.As(err, &ve) {
+					t.Fatalf("err = %v, want a validation error", err)
+				}
+			case tt.wantErr:
+				if err == nil || errors.As(err, &ve) {
+					t.Fatalf("err = %v, want a decode error", err)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case !reflect.DeepEqual(got, tt.want):
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	current := func() patchTarget {
+		p := patchTarget{Name: "Chair", Price: 10, Tags: []string{"oak"}, Version: 3}
+		p.Nested.A, p.Nested.B = "a", "b"
+		return p
+	}
+	tests := []struct {
+		name    string
+		patch   string
+		want    func(p *patchTarget)
+		invalid bool
+	}{
+		{name: "empty patch keeps everything", patch: `{}`, want: func(p *patchTarget) {}},
+		{name: "replaces a field", patch: `{"price":12.5}`, want: func(p *patchTarget) { p.Price = 12.5 }},
+		{name: "null clears an optional field", patch: `{"tags":null}`, want: func(p *patchTarget) { p.Tags = nil }},
+		{name: "merges nested objects", patch: `{"nested":{"b":"c"}}`, want: func(p *patchTarget) { p.Nested.B = "c" }},
+		{name: "null removes a nested field", patch: `{"nested":{"a":null}}`, want: func(p *patchTarget) { p.Nested.A = "" }},
+		{name: "arrays are replaced", patch: `{"tags":["ash"]}`, want: func(p *patchTarget) { p.Tags = []string{"ash"} }},
+		{name: "null for required field", patch: `{"name":null}`, invalid: true},
+		{name: "read-only field", patch: `{"version":4}`, invalid: true},
+		{name: "unknown field", patch: `{"colour":"red"}`, invalid: true},
+		{name: "wrong type", patch: `{"price":"ten"}`, invalid: true},
+		{name: "not an object", patch: `null`, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := current()
+			err := applyMergePatch(strings.NewReader(tt.patch), &got, []string{"name"}, "version")
+			if tt.invalid {
+				var ve *validationError
+				if !errors.As(err, &ve) {
+					t.Fatalf("err = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := current()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const placeholderPathTaken = "Field \"path\" is already used by another placeholder"
+
+// validatePlaceholder normalises the path pattern and checks the schedule.
+func validatePlaceholder(p *Placeholder) error {
+	p.Path = strings.TrimSpace(p.Path)
+	if !strings.HasPrefix(p.Path, "/") {
+		return &validationError{msg: "Field \"path\" must start with /"}
+	}
+	if _, err := path.Match(p.Path, "/"); err != nil {
+		return &validationError{msg: "Field \"path\" is not a valid pattern"}
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return &validationError{msg: "Field \"ends_at\" must be after \"starts_at\""}
+	}
+	return nil
+}
+
+// placeholderMatches reports whether a placeholder path covers a page path.
+// Besides exact paths, patterns use path.Match syntax, where * and ? stay
+// within one segment, and a trailing /* also covers every deeper path:
+// /portfolio/* matches /portfolio/hotel and /portfolio/hotel/photos, but not
+// /portfolio itself.
+func placeholderMatches(pattern, pagePath string) bool {
+	if pattern == pagePath {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		prefixSegments := strings.Count(prefix, "/") + 1
+		segments := strings.SplitN(pagePath, "/", prefixSegments+1)
+		if len(segments) <= prefixSegments || segments[prefixSegments] == "" {
+			return false
+		}
+		matched, _ := path.Match(prefix, strings.Join(segments[:prefixSegments], "/"))
+		return matched
+	}
+	matched, _ := path.Match(pattern, pagePath)
+	return matched
+}
+
+func isPlaceholderPattern(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// activePlaceholders returns the enabled placeholders whose schedule covers
+// the current time, best match first: by priority, then exact paths before
+// patterns, then longer (more specific) patterns.
+func activePlaceholders() ([]Placeholder, error) {
+	rows, err := db.Query("SELECT " + placeholderColumns + " FROM placeholders WHERE is_active = TRUE AND (starts_at IS NULL OR starts_at <= CURRENT_TIMESTAMP) AND (ends_at IS NULL OR ends_at > CURRENT_TIMESTAMP)")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var placeholders []Placeholder
+	for rows.Next() {
+		p, err := scanPlaceholder(rows)
+		if err != nil {
+			return nil, err
+		}
+		placeholders = append(placeholders, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(placeholders, func(i, j int) bool {
+		a, b := placeholders[i], placeholders[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if isPlaceholderPattern(a.Path) != isPlaceholderPattern(b.Path) {
+			return !isPlaceholderPattern(a.Path)
+		}
+		if len(a.Path) != len(b.Path) {
+			return len(a.Path) > len(b.Path)
+		}
+		return a.ID < b.ID
+	})
+	return placeholders, nil
+}
+
+// nextPlaceholderChange returns when the earliest upcoming starts_at or
+// ends_at of an enabled placeholder passes, or the zero time if none is
+// scheduled. The database compares the times, so its time zone applies.
+func nextPlaceholderChange() (time.Time, error) {
+	var seconds sql.NullFloat64
+	err := db.QueryRow(`SELECT MIN(EXTRACT(EPOCH FROM (boundary - CURRENT_TIMESTAMP))) FROM (
+		SELECT starts_at AS boundary FROM placeholders WHERE is_active = TRUE AND starts_at > CURRENT_TIMESTAMP
+		UNION ALL
+		SELECT ends_at FROM placeholders WHERE is_active = TRUE AND ends_at > CURRENT_TIMESTAMP
+	) boundaries`).Scan(&seconds)
+	if err != nil || !seconds.Valid {
+		return time.Time{}, err
+	}
+	return time.Now().Add(time.Duration(seconds.Float64 * float64(time.Second))), nil
+}
+
+// placeholderCache keeps the active placeholders in memory for the public
+// checks. It is reloaded after placeholders are written and when the next
+// scheduled window opens or closes. version changes whenever the active set
+// does; it starts from the startup time so it never repeats across restarts.
+var placeholderCache = struct {
+	sync.Mutex
+	placeholders []Placeholder
+	fingerprint  string
+	loaded       bool
+	validUntil   time.Time // zero when no schedule boundary is pending
+	version      int64
+}{version: time.Now().Unix()}
+
+// invalidatePlaceholderCache makes the next check reload the placeholders.
+func invalidatePlaceholderCache() {
+	placeholderCache.Lock()
+	placeholderCache.loaded = false
+	placeholderCache.Unlock()
+}
+
+// cachedPlaceholders returns the active placeholders in match order and the
+// current placeholder version.
+func cachedPlaceholders() ([]Placeholder, int64, error) {
+	placeholderCache.Lock()
+	defer placeholderCache.Unlock()
+	c := &placeholderCache
+	if c.loaded && (c.validUntil.IsZero() || time.Now().Before(c.validUntil)) {
+		return c.placeholders, c.version, nil
+	}
+
+	placeholders, err := activePlaceholders()
+	if err != nil {
+		return nil, c.version, err
+	}
+	validUntil, err := nextPlaceholderChange()
+	if err != nil {
+		return nil, c.version, err
+	}
+
+	var fingerprint strings.Builder
+	for _, p := range placeholders {
+		fmt.Fprintf(&fingerprint, "%d:%d,", p.ID, p.Version)
+	}
+	if fingerprint.String() != c.fingerprint {
+		c.version++
+		c.fingerprint = fingerprint.String()
+	}
+	c.placeholders, c.validUntil, c.loaded = placeholders, validUntil, true
+	return placeholders, c.version, nil
+}
+
+// normalizePagePath drops the query string and a trailing slash.
+func normalizePagePath(p string) string {
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	if len(p) > 1 {
+		p = strings.TrimRight(p, "/")
+	}
+	return p
+}
+
+// matchPlaceholder returns the first of the ordered placeholders covering
+// pagePath.
+func matchPlaceholder(placeholders []Placeholder, pagePath string) (Placeholder, bool) {
+	pagePath = normalizePagePath(pagePath)
+	for _, p := range placeholders {
+		if placeholderMatches(p.Path, pagePath) {
+			return p, true
+		}
+	}
+	return Placeholder{}, false
+}
+
+// Most paths accepted by one batch check.
+const maxPlaceholderBatch = 200
+
+// checkPlaceholders is the batch form of checkPlaceholder: POST
+// {"paths": [...]} returns the matching placeholder (or null) per path.
+func checkPlaceholders(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Paths []string `json:"paths"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if len(req.Paths) == 0 {
+		http.Error(w, "Field \"paths\" is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Paths) > maxPlaceholderBatch {
+		http.Error(w, fmt.Sprintf("At most %d paths can be checked at once", maxPlaceholderBatch), http.StatusBadRequest)
+		return
+	}
+
+	placeholders, version, err := cachedPlaceholders()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Paths often share a placeholder; each is localized once
+	matched := []Placeholder{}
+	index := map[int]int{}
+	matchedIndex := make(map[string]int, len(req.Paths))
+	for _, p := range req.Paths {
+		placeholder, ok := matchPlaceholder(placeholders, p)
+		if !ok {
+			matchedIndex[p] = -1
+			continue
+		}
+		i, seen := index[placeholder.ID]
+		if !seen {
+			i = len(matched)
+			index[placeholder.ID] = i
+			matched = append(matched, placeholder)
+		}
+		matchedIndex[p] = i
+	}
+	if err := localize(w, r, "placeholders", &matched); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	results := make(map[string]*Placeholder, len(req.Paths))
+	for p, i := range matchedIndex {
+		if i < 0 {
+			results[p] = nil
+		} else {
+			results[p] = &matched[i]
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"version": version, "placeholders": results})
+}
+
+// getPlaceholderVersion lets clients that keep placeholder results poll for
+// changes instead of checking every path again.
+func getPlaceholderVersion(w http.ResponseWriter, r *http.Request) {
+	_, version, err := cachedPlaceholders()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"version": version})
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestPlaceholderMatches(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		pagePath string
+		want     bool
+	}{
+		{pattern: "/about", pagePath: "/about", want: true},
+		{pattern: "/about", pagePath: "/about/team", want: false},
+		{pattern: "/portfolio/*", pagePath: "/portfolio/hotel", want: true},
+		{pattern: "/portfolio/*", pagePath: "/portfolio/hotel/photos", want: true},
+		{pattern: "/portfolio/*", pagePath: "/portfolio", want: false},
+		{pattern: "/portfolio/*", pagePath: "/portfolio/", want: false},
+		{pattern: "/portfolio/*", pagePath: "/portfolios/hotel", want: false},
+		{pattern: "/catalog/*/reviews", pagePath: "/catalog/chair/reviews", want: true},
+		{pattern: "/catalog/*/reviews", pagePath: "/catalog/chair/oak/reviews", want: false},
+		{pattern: "/*/gallery/*", pagePath: "/hotel/gallery/1/2", want: true},
+		{pattern: "/blog/202?", pagePath: "/blog/2024", want: true},
+		{pattern: "/blog/202?", pagePath: "/blog/20245", want: false},
+		{pattern: "/shop/[ab]*", pagePath: "/shop/bags", want: true},
+		{pattern: "/shop/[ab]*", pagePath: "/shop/cups", want: false},
+		{pattern: "/shop/[", pagePath: "/shop/x", want: false},
+	}
+	for _, tt := range tests {
+		if got := placeholderMatches(tt.pattern, tt.pagePath); got != tt.want {
+			t.Errorf("placeholderMatches(%q, %q) = %v, want %v", tt.pattern, tt.pagePath, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// PriceList holds negotiated prices. A product's price is its item price if
+// the list has one, otherwise retail adjusted by the item's or the list's
+// percentage (e.g. -15 for "Partner -15%").
+type PriceList struct {
+	ID                int             `json:"id"`
+	Name              string          `json:"name"`
+	Description       string          `json:"description"`
+	AdjustmentPercent float64         `json:"adjustment_percent"`
+	Items             []PriceListItem `json:"items"`
+	Version           int             `json:"version"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// PriceListItem overrides the price of one product: either a fixed price in
+// the product's currency or a percentage of retail.
+type PriceListItem struct {
+	ProductID         int      `json:"product_id"`
+	Price             *float64 `json:"price,omitempty"`
+	AdjustmentPercent *float64 `json:"adjustment_percent,omitempty"`
+}
+
+var priceListRequiredFields = []string{"name"}
+
+func validatePriceList(l *PriceList) error {
+	l.Name = strings.TrimSpace(l.Name)
+	if l.Name == "" {
+		return &validationError{msg: "Field \"name\" must not be empty"}
+	}
+	if l.AdjustmentPercent <= -100 {
+		return &validationError{msg: "Field \"adjustment_percent\" must be greater than -100"}
+	}
+
+	seen := map[int]bool{}
+	for _, item := range l.Items {
+		if seen[item.ProductID] {
+			return &validationError{msg: fmt.Sprintf("Product %d is listed twice", item.ProductID)}
+		}
+		seen[item.ProductID] = true
+		if (item.Price == nil) == (item.AdjustmentPercent == nil) {
+			return &validationError{msg: fmt.Sprintf("Item for product %d must have either \"price\" or \"adjustment_percent\"", item.ProductID)}
+		}
+		if item.Price != nil && *item.Price < 0 {
+			return &validationError{msg: fmt.Sprintf("Item for product %d has a negative price", item.ProductID)}
+		}
+		if item.AdjustmentPercent != nil && *item.AdjustmentPercent <= -100 {
+			return &validationError{msg: fmt.Sprintf("Item for product %d must have \"adjustment_percent\" greater than -100", item.ProductID)}
+		}
+		var exists bool
+		if err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", item.ProductID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return &validationError{msg: fmt.Sprintf("Product %d does not exist", item.ProductID)}
+		}
+	}
+	return nil
+}
+
+func fetchPriceList(id int) (PriceList, error) {
+	l := PriceList{Items: []PriceListItem{}}
+	err := db.QueryRow(
+		"SELECT id, name, description, adjustment_percent, version, updated_at FROM price_lists WHERE id = $1", id,
+	).Scan(&l.ID, &l.Name, &l.Description, &l.AdjustmentPercent, &l.Version, &l.UpdatedAt)
+	if err != nil {
+		return l, err
+	}
+
+	rows, err := db.Query("SELECT product_id, price, adjustment_percent FROM price_list_items WHERE price_list_id = $1 ORDER BY product_id", id)
+	if err != nil {
+		return l, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item PriceListItem
+		if err := rows.Scan(&item.ProductID, &item.Price, &item.AdjustmentPercent); err != nil {
+			return l, err
+		}
+		l.Items = append(l.Items, item)
+	}
+	return l, rows.Err()
+}
+
+// writePriceListItems replaces the items of a price list inside tx.
+func writePriceListItems(tx *sql.Tx, l *PriceList) error {
+	if _, err := tx.Exec("DELETE FROM price_list_items WHERE price_list_id = $1", l.ID); err != nil {
+		return err
+	}
+	for _, item := range l.Items {
+		_, err := tx.Exec(
+			"INSERT INTO price_list_items (price_list_id, product_id, price, adjustment_percent) VALUES ($1, $2, $3, $4)",
+			l.ID, item.ProductID, item.Price, item.AdjustmentPercent,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// savePriceList writes l and its items if the row is still at version. See
+// saveProduct.
+func savePriceList(l *PriceList, version int) error {
+	if l.Items == nil {
+		l.Items = []PriceListItem{}
+	}
+	if err := validatePriceList(l); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"UPDATE price_lists SET name=$1, description=$2, adjustment_percent=$3, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$4 AND ($5 = -1 OR version=$5) RETURNING version, updated_at",
+		l.Name, l.Description, l.AdjustmentPercent, l.ID, version,
+	).Scan(&l.Version, &l.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return versionConflict("price_lists", l.ID)
+	}
+	if err != nil {
+		return uniqueViolation(err, "A price list with this name already exists")
+	}
+	if err := writePriceListItems(tx, l); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func getPriceLists(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.Query("SELECT id FROM price_lists ORDER BY name")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	lists := []PriceList{}
+	for _, id := range ids {
+		l, err := fetchPriceList(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		lists = append(lists, l)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lists)
+}
+
+func getPriceList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	l, err := fetchPriceList(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Price list not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, l.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+func createPriceList(w http.ResponseWriter, r *http.Request) {
+	var l PriceList
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if l.Items == nil {
+		l.Items = []PriceListItem{}
+	}
+	if err := validatePriceList(&l); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO price_lists (name, description, adjustment_percent) VALUES ($1, $2, $3) RETURNING id, version, updated_at",
+		l.Name, l.Description, l.AdjustmentPercent,
+	).Scan(&l.ID, &l.Version, &l.UpdatedAt)
+	if err == nil {
+		err = writePriceListItems(tx, &l)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		writeSaveError(w, uniqueViolation(err, "A price list with this name already exists"), "")
+		return
+	}
+
+	setETag(w, l.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(l)
+}
+
+func updatePriceList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var l PriceList
+	if err := decodeFull(r.Body, &l, priceListRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	l.ID = id
+	if err := savePriceList(&l, version); err != nil {
+		writeSaveError(w, err, "Price list not found")
+		return
+	}
+
+	setETag(w, l.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+func patchPriceList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	l, err := fetchPriceList(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Price list not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && l.Version != version {
+		writeSaveError(w, errVersionMismatch, "Price list not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &l, priceListRequiredFields, "id", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	l.ID = id
+	if err := savePriceList(&l, l.Version); err != nil {
+		writeSaveError(w, err, "Price list not found")
+		return
+	}
+
+	setETag(w, l.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// deletePriceList removes a price list; its customers fall back to retail.
+func deletePriceList(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteVersioned("price_lists", id, version); err != nil {
+		writeSaveError(w, err, "Price list not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func roundPrice(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// applyPriceList replaces the retail price of p with its price in l.
+func applyPriceList(l PriceList, p *Product) {
+	retail := p.Price
+	factor := 1 + l.AdjustmentPercent/100
+
+	for _, item := range l.Items {
+		if item.ProductID != p.ID {
+			continue
+		}
+		if item.Price != nil {
+			// A fixed negotiated price replaces the retail overrides too, other
+			// currencies are converted from it
+			p.Price = *item.Price
+			p.Prices = nil
+			p.RetailPrice = &retail
+			p.PriceList = l.Name
+			return
+		}
+		factor = 1 + *item.AdjustmentPercent/100
+	}
+
+	p.Price = roundPrice(p.Price * factor)
+	adjusted := make(map[string]float64, len(p.Prices))
+	for code, price := range p.Prices {
+		adjusted[code] = roundPrice(price * factor)
+	}
+	if p.Prices != nil {
+		p.Prices = adjusted
+	}
+	p.RetailPrice = &retail
+	p.PriceList = l.Name
+}
+
+// applyCustomerPrices applies the price list of customer, if any, to products.
+func applyCustomerPrices(customer *Customer, products ...*Product) error {
+	if customer == nil || customer.PriceListID == nil {
+		return nil
+	}
+	l, err := fetchPriceList(*customer.PriceListID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		applyPriceList(l, p)
+	}
+	return nil
+}
+
+// priceProducts applies the price list of the authenticated customer and the
+// ?currency= conversion to products. It writes the error response and
+// returns false if the handler should stop. Admin routes always see retail,
+// and so does a request with an expired or unknown token: the catalog keeps
+// working as for an anonymous visitor, only account endpoints answer 401.
+func priceProducts(w http.ResponseWriter, r *http.Request, products ...*Product) bool {
+	w.Header().Add("Vary", "Authorization")
+
+	if !viaAdminRoute(r) {
+		customer, err := customerFromRequest(r)
+		if err == errInvalidCredentials {
+			customer, err = nil, nil
+		}
+		if err == nil {
+			err = applyCustomerPrices(customer, products...)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+	}
+
+	if err := convertPrices(r, products...); err != nil {
+		writeSaveError(w, err, "")
+		return false
+	}
+	return true
+}
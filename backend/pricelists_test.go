@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyPriceList(t *testing.T) {
+	fixed, percent := 800.0, -25.0
+	list := PriceList{
+		Name:              "Hotels",
+		AdjustmentPercent: -10,
+		Items: []PriceListItem{
+			{ProductID: 2, Price: &fixed},
+			{ProductID: 3, AdjustmentPercent: &percent},
+		},
+	}
+	tests := []struct {
+		name    string
+		product Product
+		want    Product
+	}{
+		{
+			name:    "list adjustment",
+			product: Product{ID: 1, Price: 1000.55, Prices: map[string]float64{"EUR": 10.01}},
+			want:    Product{ID: 1, Price: 900.5, Prices: map[string]float64{"EUR": 9.01}},
+		},
+		{
+			name:    "fixed price drops the overrides",
+			product: Product{ID: 2, Price: 1000, Prices: map[string]float64{"EUR": 10}},
+			want:    Product{ID: 2, Price: 800},
+		},
+		{
+			name:    "item adjustment",
+			product: Product{ID: 3, Price: 1000},
+			want:    Product{ID: 3, Price: 750},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.product
+			applyPriceList(list, &got)
+			retail := tt.product.Price
+			tt.want.RetailPrice = &retail
+			tt.want.PriceList = list.Name
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
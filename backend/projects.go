@@ -0,0 +1,608 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Project is a completed hotel fit-out shown in the portfolio, linked to the
+// products and collections that were used in it.
+type Project struct {
+	ID            int          `json:"id"`
+	Slug          string       `json:"slug"`
+	HotelName     string       `json:"hotel_name"`
+	City          string       `json:"city"`
+	Year          int          `json:"year"`
+	Description   string       `json:"description"`
+	Gallery       []string     `json:"gallery"` // image URLs, the first is the cover
+	ClientQuote   string       `json:"client_quote"`
+	ClientName    string       `json:"client_name"` // who gave the quote, e.g. "Анна Петрова, управляющая"
+	ProductIDs    []int        `json:"product_ids"`
+	CollectionIDs []int        `json:"collection_ids"`
+	Products      []Product    `json:"products,omitempty"`    // set on the public project page
+	Collections   []Collection `json:"collections,omitempty"` // set on the public project page
+	Status        string       `json:"status"`                // draft, published or archived
+	CreatedAt     time.Time    `json:"created_at"`
+	Version       int          `json:"version"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+const projectColumns = "id, slug, hotel_name, city, year, description, gallery, client_quote, client_name, status, created_at, version, updated_at"
+
+var projectRequiredFields = []string{"hotel_name", "city", "year", "status"}
+
+// Earliest year a project can be dated.
+const minProjectYear = 1990
+
+// validateProject checks the fields of p and that every linked product and
+// collection exists, and derives a slug from the hotel name when none is set.
+func validateProject(p *Project) error {
+	p.HotelName = strings.TrimSpace(p.HotelName)
+	p.City = strings.TrimSpace(p.City)
+	p.Slug = strings.TrimSpace(p.Slug)
+	if p.HotelName == "" {
+		return &validationError{msg: "Field \"hotel_name\" is required"}
+	}
+	if p.City == "" {
+		return &validationError{msg: "Field \"city\" is required"}
+	}
+	if p.Year < minProjectYear || p.Year > time.Now().Year() {
+		return &validationError{msg: "Field \"year\" must be between " + strconv.Itoa(minProjectYear) + " and the current year"}
+	}
+	if p.Status == "" {
+		p.Status = statusDraft
+	}
+	if err := validatePublication(p.Status, nil, nil); err != nil {
+		return err
+	}
+	if p.Slug != "" && !slugPattern.MatchString(p.Slug) {
+		return &validationError{msg: "Field \"slug\" may only contain lowercase latin letters, digits and single hyphens"}
+	}
+	if err := checkSlug(p.Slug); err != nil {
+		return err
+	}
+	if p.Gallery == nil {
+		p.Gallery = []string{}
+	}
+	for i, image := range p.Gallery {
+		if strings.TrimSpace(image) == "" {
+			return &validationError{msg: "Image " + strconv.Itoa(i+1) + " of the gallery is empty"}
+		}
+	}
+
+	var err error
+	if p.ProductIDs, err = validateLinkedIDs(p.ProductIDs, "products", "product_ids"); err != nil {
+		return err
+	}
+	if p.CollectionIDs, err = validateLinkedIDs(p.CollectionIDs, "collections", "collection_ids"); err != nil {
+		return err
+	}
+
+	return assignSlug("projects", p.ID, p.HotelName, &p.Slug)
+}
+
+// validateLinkedIDs checks that ids are distinct rows of table. Rows in the
+// trash are accepted, so that trashing a product does not block edits of the
+// projects and FAQs linking to it; the public routes drop them with
+// hideLinks.
+func validateLinkedIDs(ids []int, table, field string) ([]int, error) {
+	if ids == nil {
+		return []int{}, nil
+	}
+	seen := map[int]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			return nil, &validationError{msg: "Field \"" + field + "\" lists " + strconv.Itoa(id) + " twice"}
+		}
+		seen[id] = true
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	var found int
+	if err := db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE id = ANY($1)", pq.Array(ids)).Scan(&found); err != nil {
+		return nil, err
+	}
+	if found != len(ids) {
+		return nil, &validationError{msg: "Field \"" + field + "\" refers to an unknown entry"}
+	}
+	return ids, nil
+}
+
+func scanProject(s rowScanner) (Project, error) {
+	var p Project
+	var gallery []byte
+	err := s.Scan(&p.ID, &p.Slug, &p.HotelName, &p.City, &p.Year, &p.Description, &gallery, &p.ClientQuote, &p.ClientName, &p.Status, &p.CreatedAt, &p.Version, &p.UpdatedAt)
+	if err != nil {
+		return p, err
+	}
+	p.Gallery = []string{}
+	json.Unmarshal(gallery, &p.Gallery)
+	return p, nil
+}
+
+// queryProjects runs a query selecting projectColumns and loads the linked
+// product and collection ids of the result.
+func queryProjects(query string, args ...interface{}) ([]Project, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return projects, loadProjectLinks(projects)
+}
+
+func loadProjectLinks(projects []Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(projects))
+	index := map[int]int{}
+	for i := range projects {
+		ids[i] = int64(projects[i].ID)
+		index[projects[i].ID] = i
+		projects[i].ProductIDs = []int{}
+		projects[i].CollectionIDs = []int{}
+	}
+
+	for _, link := range []struct {
+		table, column string
+		field         func(p *Project) *[]int
+	}{
+		{"project_products", "product_id", func(p *Project) *[]int { return &p.ProductIDs }},
+		{"project_collections", "collection_id", func(p *Project) *[]int { return &p.CollectionIDs }},
+	} {
+		rows, err := db.Query("SELECT project_id, "+link.column+" FROM "+link.table+" WHERE project_id = ANY($1) ORDER BY position, "+link.column, pq.Array(ids))
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var projectID, id int
+			if err := rows.Scan(&projectID, &id); err != nil {
+				rows.Close()
+				return err
+			}
+			field := link.field(&projects[index[projectID]])
+			*field = append(*field, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchProject(id int) (Project, error) {
+	p, err := scanProject(db.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = $1", id))
+	if err != nil {
+		return p, err
+	}
+	projects := []Project{p}
+	err = loadProjectLinks(projects)
+	return projects[0], err
+}
+
+// replaceProjectLinks stores the ordered product and collection ids of p.
+func replaceProjectLinks(tx *sql.Tx, p *Project) error {
+	for _, link := range []struct {
+		table, column string
+		ids           []int
+	}{
+		{"project_products", "product_id", p.ProductIDs},
+		{"project_collections", "collection_id", p.CollectionIDs},
+	} {
+		if _, err := tx.Exec("DELETE FROM "+link.table+" WHERE project_id = $1", p.ID); err != nil {
+			return err
+		}
+		for i, id := range link.ids {
+			if _, err := tx.Exec("INSERT INTO "+link.table+" (project_id, "+link.column+", position) VALUES ($1, $2, $3)", p.ID, id, i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// saveProject writes p and its links if the row is still at version. See
+// saveProduct.
+func saveProject(p *Project, version int) error {
+	if err := validateProject(p); err != nil {
+		return err
+	}
+	gallery, _ := json.Marshal(p.Gallery)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	oldSlug, err := currentSlug(tx, "projects", p.ID)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow(
+		"UPDATE projects SET slug=$1, hotel_name=$2, city=$3, year=$4, description=$5, gallery=$6, client_quote=$7, client_name=$8, status=$9, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$10 AND ($11 = -1 OR version=$11) RETURNING created_at, version, updated_at",
+		p.Slug, p.HotelName, p.City, p.Year, p.Description, string(gallery), p.ClientQuote, p.ClientName, p.Status, p.ID, version,
+	).Scan(&p.CreatedAt, &p.Version, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return versionConflict("projects", p.ID)
+	}
+	if err != nil {
+		return uniqueViolation(err, slugTaken("projects"))
+	}
+	if err := replaceProjectLinks(tx, p); err != nil {
+		return err
+	}
+	if err := trackSlugChange(tx, "/portfolio/", oldSlug, p.Slug); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// projectUsesProduct is the SQL condition selecting the projects (aliased
+// pr) that used product $n, directly or through one of their collections
+// visible to r.
+func projectUsesProduct(r *http.Request, n int) string {
+	param := "$" + strconv.Itoa(n)
+	return `(EXISTS (SELECT 1 FROM project_products pp WHERE pp.project_id = pr.id AND pp.product_id = ` + param + `)
+		OR EXISTS (SELECT 1 FROM project_collections pc
+			JOIN collections c ON c.id = pc.collection_id
+			JOIN collection_products cp ON cp.collection_id = pc.collection_id
+			WHERE pc.project_id = pr.id AND cp.product_id = ` + param + ` AND ` + visibleCondition(r, "c") + `))`
+}
+
+// projectStatusCondition limits public requests to published projects.
+func projectStatusCondition(r *http.Request) string {
+	if showDrafts(r) {
+		return "TRUE"
+	}
+	return "pr.status = '" + statusPublished + "'"
+}
+
+// getProjects lists projects, newest first, filtered by ?city=, ?year=,
+// ?product_id= and ?collection_id= and paginated with ?limit= and ?offset=.
+func getProjects(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + qualifyColumns("pr", projectColumns) + " FROM projects pr WHERE " + projectStatusCondition(r)
+	var args []interface{}
+	q := r.URL.Query()
+	if city := strings.TrimSpace(q.Get("city")); city != "" {
+		args = append(args, city)
+		query += " AND LOWER(pr.city) = LOWER($" + strconv.Itoa(len(args)) + ")"
+	}
+	for _, f := range []struct{ param, name string }{
+		{"year", "year"},
+		{"product_id", "product"},
+		{"collection_id", "collection"},
+	} {
+		v := q.Get(f.param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid "+f.name+" filter", http.StatusBadRequest)
+			return
+		}
+		args = append(args, n)
+		switch f.param {
+		case "year":
+			query += " AND pr.year = $" + strconv.Itoa(len(args))
+		case "product_id":
+			query += " AND " + projectUsesProduct(r, len(args))
+		case "collection_id":
+			query += " AND EXISTS (SELECT 1 FROM project_collections pc WHERE pc.project_id = pr.id AND pc.collection_id = $" + strconv.Itoa(len(args)) + ")"
+		}
+	}
+	limit, offset := pageParams(r, 50, 200)
+	query += " ORDER BY pr.year DESC, pr.id DESC LIMIT " + strconv.Itoa(limit) + " OFFSET " + strconv.Itoa(offset)
+
+	projects, err := queryProjects(query, args...)
+	if err == nil && !viaAdminRoute(r) {
+		err = hideProjectLinks(r, projects)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "projects", &projects); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(projects)
+}
+
+// hideProjectLinks drops the ids of products and collections that are not
+// visible to r from projects, as getProject does for a single project.
+func hideProjectLinks(r *http.Request, projects []Project) error {
+	products := make([]*[]int, len(projects))
+	collections := make([]*[]int, len(projects))
+	for i := range projects {
+		products[i] = &projects[i].ProductIDs
+		collections[i] = &projects[i].CollectionIDs
+	}
+	if err := hideLinks("products", visibleCondition(r, ""), products); err != nil {
+		return err
+	}
+	return hideLinks("collections", visibleCondition(r, ""), collections)
+}
+
+// getProject looks a project up by id or slug. Public requests also get the
+// visible products and collections used in it.
+func getProject(w http.ResponseWriter, r *http.Request) {
+	id, err := routeEntityID(r, "projects")
+	if err == sql.ErrNoRows {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p, err := fetchProject(id)
+	if err == sql.ErrNoRows || (err == nil && p.Status != statusPublished && !showDrafts(r)) {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !viaAdminRoute(r) {
+		p.Products, err = queryProducts(`
+			SELECT `+qualifyColumns("p", productColumns)+`
+			FROM products p
+			INNER JOIN project_products pp ON p.id = pp.product_id
+			WHERE pp.project_id = $1 AND `+visibleCondition(r, "p")+`
+			ORDER BY pp.position, p.id
+		`, id)
+		if err == nil {
+			p.Collections, err = queryProjectCollections(r, id)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Ids of hidden products and collections are not exposed either
+		p.ProductIDs, p.CollectionIDs = []int{}, []int{}
+		for _, product := range p.Products {
+			p.ProductIDs = append(p.ProductIDs, product.ID)
+		}
+		for _, c := range p.Collections {
+			p.CollectionIDs = append(p.CollectionIDs, c.ID)
+		}
+	}
+
+	if err := localize(w, r, "projects", &p); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "products", &p.Products); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "collections", &p.Collections); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !priceProducts(w, r, productRefs(p.Products)...) {
+		return
+	}
+
+	setETag(w, p.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+func queryProjectCollections(r *http.Request, projectID int) ([]Collection, error) {
+	rows, err := db.Query(`
+		SELECT `+qualifyColumns("c", collectionColumns)+`
+		FROM collections c
+		INNER JOIN project_collections pc ON c.id = pc.collection_id
+		WHERE pc.project_id = $1 AND `+visibleCondition(r, "c")+`
+		ORDER BY pc.position, c.id
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []Collection
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+// getProductProjects lists the published projects a product was used in,
+// directly or as part of a collection, newest first.
+func getProductProjects(w http.ResponseWriter, r *http.Request) {
+	product, ok := publicProduct(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := pageParams(r, 6, 24)
+
+	projects, err := queryProjects(
+		"SELECT "+qualifyColumns("pr", projectColumns)+" FROM projects pr WHERE "+projectStatusCondition(r)+" AND "+projectUsesProduct(r, 1)+" ORDER BY pr.year DESC, pr.id DESC LIMIT $2",
+		product.ID, limit,
+	)
+	if err == nil && !viaAdminRoute(r) {
+		err = hideProjectLinks(r, projects)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "projects", &projects); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(projects)
+}
+
+// Projects (admin)
+
+func createProject(w http.ResponseWriter, r *http.Request) {
+	var p Project
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateProject(&p); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+	gallery, _ := json.Marshal(p.Gallery)
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		"INSERT INTO projects (slug, hotel_name, city, year, description, gallery, client_quote, client_name, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, version, updated_at",
+		p.Slug, p.HotelName, p.City, p.Year, p.Description, string(gallery), p.ClientQuote, p.ClientName, p.Status,
+	).Scan(&p.ID, &p.CreatedAt, &p.Version, &p.UpdatedAt)
+	if err == nil {
+		err = replaceProjectLinks(tx, &p)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		writeSaveError(w, uniqueViolation(err, slugTaken("projects")), "")
+		return
+	}
+
+	setETag(w, p.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+func updateProject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var p Project
+	if err := decodeFull(r.Body, &p, projectRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	p.ID = id
+	p.Products, p.Collections = nil, nil
+	if err := saveProject(&p, version); err != nil {
+		writeSaveError(w, err, "Project not found")
+		return
+	}
+
+	setETag(w, p.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+func patchProject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := fetchProject(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && p.Version != version {
+		writeSaveError(w, errVersionMismatch, "Project not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &p, projectRequiredFields, "id", "products", "collections", "created_at", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	p.ID = id
+	if err := saveProject(&p, p.Version); err != nil {
+		writeSaveError(w, err, "Project not found")
+		return
+	}
+
+	setETag(w, p.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+func deleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteVersioned("projects", id, version); err != nil {
+		writeSaveError(w, err, "Project not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Publication statuses of products and collections. Only published items are
+// visible on the public API.
+const (
+	statusDraft     = "draft"
+	statusPublished = "published"
+	statusArchived  = "archived"
+)
+
+// validatePublication checks the status and schedule of a product or
+// collection. An empty status on create is treated as draft by the caller.
+func validatePublication(status string, publishAt, unpublishAt *time.Time) error {
+	switch status {
+	case statusDraft, statusPublished, statusArchived:
+	default:
+		return &validationError{msg: fmt.Sprintf("Field \"status\" must be one of %s, %s, %s", statusDraft, statusPublished, statusArchived)}
+	}
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return &validationError{msg: "Field \"unpublish_at\" must be after \"publish_at\""}
+	}
+	return nil
+}
+
+type draftsContextKey struct{}
+
+// withDrafts marks requests served through admin routes so that shared
+// read handlers include unpublished items.
+func withDrafts(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h(w, r.WithContext(context.WithValue(r.Context(), draftsContextKey{}, true)))
+	}
+}
+
+// viaAdminRoute reports whether the request was served through an admin route.
+func viaAdminRoute(r *http.Request) bool {
+	v, _ := r.Context().Value(draftsContextKey{}).(bool)
+	return v
+}
+
+// showDrafts reports whether the request may see unpublished items: either it
+// came through an admin route or it carries a valid preview token.
+func showDrafts(r *http.Request) bool {
+	return viaAdminRoute(r) || hasPreviewToken(r)
+}
+
+// hasPreviewToken reports whether the request carries a valid preview token
+// in the preview parameter or the X-Preview-Token header.
+func hasPreviewToken(r *http.Request) bool {
+	token := r.URL.Query().Get("preview")
+	if token == "" {
+		token = r.Header.Get("X-Preview-Token")
+	}
+	return token != "" && validPreviewToken(token)
+}
+
+// visibleCondition is the SQL condition selecting the rows of a product or
+// collection table (optionally aliased) that the request may see.
+func visibleCondition(r *http.Request, alias string) string {
+	prefix := ""
+	if alias != "" {
+		prefix = alias + "."
+	}
+	if showDrafts(r) {
+		return prefix + "deleted_at IS NULL"
+	}
+	return prefix + "deleted_at IS NULL AND " + prefix + "status = '" + statusPublished + "'"
+}
+
+// hideLinks drops from each of lists the ids of table rows that do not match
+// condition, typically visibleCondition, so that the public routes do not
+// point at drafts or trashed entries. All lists are checked in one query.
+func hideLinks(table, condition string, lists []*[]int) error {
+	var ids []int64
+	for _, list := range lists {
+		for _, id := range *list {
+			ids = append(ids, int64(id))
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query("SELECT id FROM "+table+" WHERE id = ANY($1) AND "+condition, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	visible := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		visible[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, list := range lists {
+		kept := []int{}
+		for _, id := range *list {
+			if visible[id] {
+				kept = append(kept, id)
+			}
+		}
+		*list = kept
+	}
+	return nil
+}
+
+var previewSecret = loadPreviewSecret()
+
+// loadPreviewSecret reads PREVIEW_SECRET. Without it a random secret is
+// used, so preview links stop working when the backend restarts.
+func loadPreviewSecret() []byte {
+	if secret := os.Getenv("PREVIEW_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal("Failed to generate preview secret:", err)
+	}
+	return secret
+}
+
+func signPreview(payload string) string {
+	mac := hmac.New(sha256.New, previewSecret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newPreviewToken returns a token of the form base64(expiry).signature.
+func newPreviewToken(expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(expiresAt.Unix(), 10)))
+	return payload + "." + signPreview(payload)
+}
+
+func validPreviewToken(token string) bool {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(signPreview(parts[0])), []byte(parts[1])) {
+		return false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	expiry, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().Unix() < expiry
+}
+
+func createPreviewToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TTLHours int `json:"ttl_hours"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.TTLHours <= 0 {
+		req.TTLHours = 24
+	}
+	if req.TTLHours > 24*30 {
+		req.TTLHours = 24 * 30
+	}
+
+	expiresAt := time.Now().Add(time.Duration(req.TTLHours) * time.Hour)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":      newPreviewToken(expiresAt),
+		"expires_at": expiresAt,
+	})
+}
+
+// schedulerActor is the audit actor of changes made by the publication
+// scheduler rather than by an admin.
+const schedulerActor = "system"
+
+// scheduledChanges are the status changes applyPublicationSchedule makes: the
+// rows a change applies to, the status it sets and the schedule field it
+// clears.
+var scheduledChanges = []struct {
+	action, status, field, condition string
+}{
+	{"publish", statusPublished, "publish_at", "status IN ('" + statusDraft + "', '" + statusArchived + "')"},
+	{"unpublish", statusArchived, "unpublish_at", "status = '" + statusPublished + "'"},
+}
+
+// applyPublicationSchedule publishes drafts and archived items whose
+// publish_at has passed and archives published items whose unpublish_at has
+// passed. The schedule field is cleared once it has been applied, so a later
+// manual status change sticks. Each change is written to the audit log and
+// the revision history like an admin edit.
+func applyPublicationSchedule() {
+	for _, table := range []string{"products", "collections"} {
+		for _, change := range scheduledChanges {
+			due := change.condition + " AND " + change.field + " IS NOT NULL AND " + change.field + " <= CURRENT_TIMESTAMP AND deleted_at IS NULL"
+			ids, err := queryIDs("SELECT id FROM " + table + " WHERE " + due + " ORDER BY id")
+			if err != nil {
+				log.Printf("Error loading scheduled %s: %v", table, err)
+				continue
+			}
+			applied := 0
+			for _, id := range ids {
+				ok, err := applyScheduledChange(table, id, change.action, change.status, change.field, due)
+				if err != nil {
+					log.Printf("Error applying scheduled %s of %s %d: %v", change.action, table, id, err)
+				} else if ok {
+					applied++
+				}
+			}
+			if applied > 0 {
+				log.Printf("Applied scheduled %s to %d %s", change.action, applied, table)
+			}
+		}
+	}
+}
+
+// queryIDs runs a query selecting a single id column.
+func queryIDs(query string, args ...interface{}) ([]int, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// applyScheduledChange sets the status of one row if it is still due and
+// records the change. It reports whether the row was changed.
+func applyScheduledChange(table string, id int, action, status, field, due string) (bool, error) {
+	loader := auditLoaders[table]
+	before, err := loader(id)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := db.Exec(
+		"UPDATE "+table+" SET status = $1, "+field+" = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND "+due,
+		status, id,
+	)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// Changed by an admin in the meantime
+		return false, nil
+	}
+
+	after, err := loader(id)
+	if err != nil {
+		return true, err
+	}
+	recordRevision(table, id, before, "")
+	recordRevision(table, id, after, schedulerActor)
+	writeAuditEntry(schedulerActor, "", action, table, &id, http.StatusOK, auditDiff(before, after))
+	return true, nil
+}
+
+func startPublicationScheduler() {
+	go func() {
+		for {
+			applyPublicationSchedule()
+			time.Sleep(time.Minute)
+		}
+	}()
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Content quality rules
+const (
+	ruleMissingField    = "missing_field"
+	ruleMissingFile     = "missing_file"
+	ruleDuplicateName   = "duplicate_name"
+	ruleSuspiciousPrice = "suspicious_price"
+	ruleShortText       = "short_text"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// Minimum text lengths (in characters) below which a text is too thin for
+// search engines.
+const (
+	minDescriptionLength = 80
+	minAnswerLength      = 40
+)
+
+// A price further than priceOutlierFactor from the median of its category is
+// reported as suspicious.
+const priceOutlierFactor = 10
+
+const qualityCheckInterval = 6 * time.Hour
+
+type QualityIssue struct {
+	Field    string `json:"field"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type QualityEntity struct {
+	Type   string         `json:"type"`
+	ID     int            `json:"id"`
+	Name   string         `json:"name"`
+	Issues []QualityIssue `json:"issues"`
+}
+
+type QualityReport struct {
+	CheckedAt time.Time       `json:"checked_at"`
+	Errors    int             `json:"errors"`
+	Warnings  int             `json:"warnings"`
+	Entities  []QualityEntity `json:"entities"`
+}
+
+// qualityChecker collects the issues of one run, grouped by entity.
+type qualityChecker struct {
+	entities map[string]*QualityEntity
+	order    []string
+}
+
+func (c *qualityChecker) add(entityType string, id int, name, field, rule, severity, format string, args ...interface{}) {
+	key := fmt.Sprintf("%s/%d", entityType, id)
+	e, ok := c.entities[key]
+	if !ok {
+		e = &QualityEntity{Type: entityType, ID: id, Name: name}
+		c.entities[key] = e
+		c.order = append(c.order, key)
+	}
+	e.Issues = append(e.Issues, QualityIssue{Field: field, Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *qualityChecker) required(entityType string, id int, name string, fields map[string]string) {
+	for _, field := range sortedKeys(fields) {
+		if strings.TrimSpace(fields[field]) == "" {
+			c.add(entityType, id, name, field, ruleMissingField, severityError, "Fill in %s", field)
+		}
+	}
+}
+
+// images reports uploaded images that are no longer on disk. Paths outside
+// /uploads/ are frontend assets and are not checked.
+func (c *qualityChecker) images(entityType string, id int, name, field string, urls ...string) {
+	for _, url := range urls {
+		if !strings.HasPrefix(url, "/uploads/") {
+			continue
+		}
+		rel := strings.TrimPrefix(url, "/uploads/")
+		if _, err := os.Stat(filepath.Join("./uploads", filepath.FromSlash(rel))); os.IsNotExist(err) {
+			c.add(entityType, id, name, field, ruleMissingFile, severityError, "Image %s does not exist; upload it again", url)
+		}
+	}
+}
+
+func (c *qualityChecker) shortText(entityType string, id int, name, field, text string, min int) {
+	if n := len([]rune(strings.TrimSpace(text))); n > 0 && n < min {
+		c.add(entityType, id, name, field, ruleShortText, severityWarning, "%s has %d characters; write at least %d", field, n, min)
+	}
+}
+
+// duplicates reports names used by more than one entity of a type.
+func (c *qualityChecker) duplicates(entityType string, names map[int]string) {
+	byName := map[string][]int{}
+	for id, name := range names {
+		key := strings.ToLower(strings.Join(strings.Fields(name), " "))
+		if key != "" {
+			byName[key] = append(byName[key], id)
+		}
+	}
+	for _, ids := range byName {
+		if len(ids) < 2 {
+			continue
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			var others []string
+			for _, other := range ids {
+				if other != id {
+					others = append(others, fmt.Sprintf("#%d", other))
+				}
+			}
+			c.add(entityType, id, names[id], "name", ruleDuplicateName, severityWarning, "Same name as %s %s; rename one of them", entityType, strings.Join(others, ", "))
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func (c *qualityChecker) checkProducts() error {
+	products, err := queryProducts("SELECT " + productColumns + " FROM products WHERE deleted_at IS NULL ORDER BY id")
+	if err != nil {
+		return err
+	}
+	rates, err := exchangeRates()
+	if err != nil {
+		return err
+	}
+
+	names := map[int]string{}
+	byCategory := map[string][]float64{}
+	basePrices := map[int]float64{}
+	for _, p := range products {
+		names[p.ID] = p.Name
+		c.required("products", p.ID, p.Name, map[string]string{"name": p.Name, "category": p.Category, "description": p.Description, "image": p.Image})
+		if len(p.Features) == 0 || (len(p.Features) == 1 && strings.TrimSpace(p.Features[0]) == "") {
+			c.add("products", p.ID, p.Name, "features", ruleMissingField, severityWarning, "Add key features")
+		}
+		c.images("products", p.ID, p.Name, "image", p.Image)
+		c.images("products", p.ID, p.Name, "images", p.Images...)
+		c.shortText("products", p.ID, p.Name, "description", p.Description, minDescriptionLength)
+
+		if p.Price <= 0 {
+			c.add("products", p.ID, p.Name, "price", ruleSuspiciousPrice, severityError, "Price is %.2f; set the real price", p.Price)
+			continue
+		}
+		if price, err := priceIn(p, baseCurrency, rates); err == nil {
+			basePrices[p.ID] = price.Amount
+			byCategory[p.Category] = append(byCategory[p.Category], price.Amount)
+		}
+	}
+	c.duplicates("products", names)
+
+	for _, p := range products {
+		price, ok := basePrices[p.ID]
+		prices := byCategory[p.Category]
+		if !ok || len(prices) < 3 {
+			continue
+		}
+		m := median(prices)
+		if price > m*priceOutlierFactor || price < m/priceOutlierFactor {
+			c.add("products", p.ID, p.Name, "price", ruleSuspiciousPrice, severityWarning, "Price %.2f %s is far from the category median of %.2f %s; check for a typo", price, baseCurrency, m, baseCurrency)
+		}
+	}
+	return nil
+}
+
+func (c *qualityChecker) checkCategories() error {
+	rows, err := db.Query("SELECT " + categoryColumns + " FROM categories WHERE deleted_at IS NULL ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	names := map[int]string{}
+	for rows.Next() {
+		cat, err := scanCategory(rows)
+		if err != nil {
+			return err
+		}
+		names[cat.ID] = cat.Name
+		c.required("categories", cat.ID, cat.Name, map[string]string{"name": cat.Name, "description": cat.Description, "image": cat.Image})
+		c.images("categories", cat.ID, cat.Name, "image", cat.Image)
+		c.shortText("categories", cat.ID, cat.Name, "description", cat.Description, minDescriptionLength)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	c.duplicates("categories", names)
+	return nil
+}
+
+func (c *qualityChecker) checkCollections() error {
+	rows, err := db.Query("SELECT " + collectionColumns + " FROM collections WHERE deleted_at IS NULL ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	names := map[int]string{}
+	for rows.Next() {
+		col, err := scanCollection(rows)
+		if err != nil {
+			return err
+		}
+		names[col.ID] = col.Name
+		c.required("collections", col.ID, col.Name, map[string]string{"name": col.Name, "description": col.Description, "image": col.Image})
+		c.images("collections", col.ID, col.Name, "image", col.Image)
+		c.shortText("collections", col.ID, col.Name, "description", col.Description, minDescriptionLength)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	c.duplicates("collections", names)
+	return nil
+}
+
+func (c *qualityChecker) checkFAQs() error {
+	rows, err := db.Query("SELECT " + faqColumns + " FROM faqs ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	questions := map[int]string{}
+	for rows.Next() {
+		faq, err := scanFAQ(rows)
+		if err != nil {
+			return err
+		}
+		questions[faq.ID] = faq.Question
+		c.required("faqs", faq.ID, faq.Question, map[string]string{"question": faq.Question, "answer": faq.Answer})
+		c.shortText("faqs", faq.ID, faq.Question, "answer", faq.Answer, minAnswerLength)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	c.duplicates("faqs", questions)
+	return nil
+}
+
+// runQualityCheck scans all live catalog content against the rules.
+func runQualityCheck() (QualityReport, error) {
+	c := &qualityChecker{entities: map[string]*QualityEntity{}}
+	for _, check := range []func() error{c.checkProducts, c.checkCategories, c.checkCollections, c.checkFAQs} {
+		if err := check(); err != nil {
+			return QualityReport{}, err
+		}
+	}
+
+	report := QualityReport{CheckedAt: time.Now(), Entities: []QualityEntity{}}
+	for _, key := range c.order {
+		e := c.entities[key]
+		for _, issue := range e.Issues {
+			if issue.Severity == severityError {
+				report.Errors++
+			} else {
+				report.Warnings++
+			}
+		}
+		report.Entities = append(report.Entities, *e)
+	}
+	return report, nil
+}
+
+var lastQualityReport struct {
+	sync.Mutex
+	report *QualityReport
+}
+
+// startQualityChecker re-runs the content checks in the background and logs
+// a summary, so the admin endpoint can answer from the last run.
+func startQualityChecker() {
+	go func() {
+		for {
+			report, err := runQualityCheck()
+			if err != nil {
+				log.Printf("Content quality check failed: %v", err)
+			} else {
+				lastQualityReport.Lock()
+				lastQualityReport.report = &report
+				lastQualityReport.Unlock()
+				log.Printf("Content quality check: %d errors, %d warnings in %d entities", report.Errors, report.Warnings, len(report.Entities))
+			}
+			time.Sleep(qualityCheckInterval)
+		}
+	}()
+}
+
+// getQualityReport returns the issues of the last scheduled run, or runs the
+// checks now with ?refresh=true. ?type= and ?severity= narrow the list.
+func getQualityReport(w http.ResponseWriter, r *http.Request) {
+	lastQualityReport.Lock()
+	cached := lastQualityReport.report
+	lastQualityReport.Unlock()
+
+	var report QualityReport
+	if cached != nil && r.URL.Query().Get("refresh") != "true" {
+		report = *cached
+	} else {
+		var err error
+		report, err = runQualityCheck()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		lastQualityReport.Lock()
+		lastQualityReport.report = &report
+		lastQualityReport.Unlock()
+	}
+
+	entityType, severity := r.URL.Query().Get("type"), r.URL.Query().Get("severity")
+	if entityType != "" || severity != "" {
+		filtered := QualityReport{CheckedAt: report.CheckedAt, Entities: []QualityEntity{}}
+		for _, e := range report.Entities {
+			if entityType != "" && e.Type != entityType {
+				continue
+			}
+			var issues []QualityIssue
+			for _, issue := range e.Issues {
+				if severity != "" && issue.Severity != severity {
+					continue
+				}
+				issues = append(issues, issue)
+				if issue.Severity == severityError {
+					filtered.Errors++
+				} else {
+					filtered.Warnings++
+				}
+			}
+			if len(issues) > 0 {
+				e.Issues = issues
+				filtered.Entities = append(filtered.Entities, e)
+			}
+		}
+		report = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Quote statuses. Drafts are only visible to admins.
+const (
+	quoteDraft    = "draft"
+	quoteSent     = "sent"
+	quoteAccepted = "accepted"
+	quoteRejected = "rejected"
+	quoteExpired  = "expired"
+)
+
+type QuoteItem struct {
+	ProductID *int    `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Notes     string  `json:"notes,omitempty"`
+}
+
+type Quote struct {
+	ID         int         `json:"id"`
+	Number     string      `json:"number"`
+	CustomerID *int        `json:"customer_id"`
+	ContactID  *int        `json:"contact_id"`
+	Items      []QuoteItem `json:"items"`
+	Currency   string      `json:"currency"`
+	Total      float64     `json:"total"`
+	Status     string      `json:"status"`
+	ValidUntil *time.Time  `json:"valid_until"`
+	Notes      string      `json:"notes"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Version    int         `json:"version"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+const quoteColumns = "id, customer_id, contact_id, items, currency, total, status, valid_until, notes, created_at, version, updated_at"
+
+var quoteRequiredFields = []string{"items", "status"}
+
+func quoteNumber(id int) string {
+	return fmt.Sprintf("Q-%05d", id)
+}
+
+func scanQuote(s rowScanner) (Quote, error) {
+	var q Quote
+	var items []byte
+	err := s.Scan(&q.ID, &q.CustomerID, &q.ContactID, &items, &q.Currency, &q.Total, &q.Status, &q.ValidUntil, &q.Notes, &q.CreatedAt, &q.Version, &q.UpdatedAt)
+	if err != nil {
+		return q, err
+	}
+	q.Number = quoteNumber(q.ID)
+	json.Unmarshal(items, &q.Items)
+	if q.Items == nil {
+		q.Items = []QuoteItem{}
+	}
+	return q, nil
+}
+
+func queryQuotes(query string, args ...interface{}) ([]Quote, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes := []Quote{}
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, rows.Err()
+}
+
+func fetchQuote(id int) (Quote, error) {
+	return scanQuote(db.QueryRow("SELECT "+quoteColumns+" FROM quotes WHERE id = $1", id))
+}
+
+// validateQuote checks q and computes its total.
+func validateQuote(q *Quote) error {
+	switch q.Status {
+	case quoteDraft, quoteSent, quoteAccepted, quoteRejected, quoteExpired:
+	default:
+		return &validationError{msg: fmt.Sprintf("Field \"status\" must be one of %s", strings.Join([]string{quoteDraft, quoteSent, quoteAccepted, quoteRejected, quoteExpired}, ", "))}
+	}
+	if q.Currency == "" {
+		q.Currency = baseCurrency
+	}
+	q.Currency = strings.ToUpper(q.Currency)
+	if _, ok := currencyFormats[q.Currency]; !ok {
+		return &validationError{msg: fmt.Sprintf("Field \"currency\" must be one of %s", strings.Join(currencyCodes(), ", "))}
+	}
+	if q.Items == nil {
+		q.Items = []QuoteItem{}
+	}
+
+	q.Total = 0
+	for i, item := range q.Items {
+		if item.Quantity <= 0 {
+			return &validationError{msg: fmt.Sprintf("Item %d must have a positive quantity", i+1)}
+		}
+		if item.UnitPrice < 0 {
+			return &validationError{msg: fmt.Sprintf("Item %d has a negative price", i+1)}
+		}
+		if strings.TrimSpace(item.Name) == "" {
+			return &validationError{msg: fmt.Sprintf("Item %d must have a name", i+1)}
+		}
+		q.Total += float64(item.Quantity) * item.UnitPrice
+	}
+	q.Total = roundPrice(q.Total)
+	return nil
+}
+
+// saveQuote writes q if the row is still at version. See saveProduct.
+func saveQuote(q *Quote, version int) error {
+	if err := validateQuote(q); err != nil {
+		return err
+	}
+	items, _ := json.Marshal(q.Items)
+
+	err := db.QueryRow(
+		"UPDATE quotes SET customer_id=$1, contact_id=$2, items=$3, currency=$4, total=$5, status=$6, valid_until=$7, notes=$8, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$9 AND ($10 = -1 OR version=$10) RETURNING created_at, version, updated_at",
+		q.CustomerID, q.ContactID, string(items), q.Currency, q.Total, q.Status, q.ValidUntil, q.Notes, q.ID, version,
+	).Scan(&q.CreatedAt, &q.Version, &q.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return versionConflict("quotes", q.ID)
+	}
+	q.Number = quoteNumber(q.ID)
+	return err
+}
+
+func getQuotes(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + quoteColumns + " FROM quotes WHERE TRUE"
+	var args []interface{}
+	if v := r.URL.Query().Get("customer_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid customer_id", http.StatusBadRequest)
+			return
+		}
+		args = append(args, id)
+		query += fmt.Sprintf(" AND customer_id = $%d", len(args))
+	}
+	if v := r.URL.Query().Get("status"); v != "" {
+		args = append(args, v)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	quotes, err := queryQuotes(query+" ORDER BY created_at DESC", args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quotes)
+}
+
+func getQuote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid quote ID", http.StatusBadRequest)
+		return
+	}
+
+	q, err := fetchQuote(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Quote not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, q.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q)
+}
+
+func createQuote(w http.ResponseWriter, r *http.Request) {
+	var q Quote
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if q.Status == "" {
+		q.Status = quoteDraft
+	}
+	if err := validateQuote(&q); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+	items, _ := json.Marshal(q.Items)
+
+	err := db.QueryRow(
+		"INSERT INTO quotes (customer_id, contact_id, items, currency, total, status, valid_until, notes) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at, version, updated_at",
+		q.CustomerID, q.ContactID, string(items), q.Currency, q.Total, q.Status, q.ValidUntil, q.Notes,
+	).Scan(&q.ID, &q.CreatedAt, &q.Version, &q.UpdatedAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	q.Number = quoteNumber(q.ID)
+
+	setETag(w, q.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(q)
+}
+
+func updateQuote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid quote ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var q Quote
+	if err := decodeFull(r.Body, &q, quoteRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	q.ID = id
+	if err := saveQuote(&q, version); err != nil {
+		writeSaveError(w, err, "Quote not found")
+		return
+	}
+
+	setETag(w, q.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q)
+}
+
+func patchQuote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid quote ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	q, err := fetchQuote(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Quote not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && q.Version != version {
+		writeSaveError(w, errVersionMismatch, "Quote not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &q, quoteRequiredFields, "id", "number", "total", "created_at", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	q.ID = id
+	if err := saveQuote(&q, q.Version); err != nil {
+		writeSaveError(w, err, "Quote not found")
+		return
+	}
+
+	setETag(w, q.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(q)
+}
+
+func deleteQuote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid quote ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteVersioned("quotes", id, version); err != nil {
+		writeSaveError(w, err, "Quote not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		quote    Quote
+		total    float64
+		currency string
+		invalid  bool
+	}{
+		{
+			name:     "no items",
+			quote:    Quote{Status: quoteDraft},
+			currency: baseCurrency,
+		},
+		{
+			name: "total of the items",
+			quote: Quote{Status: quoteSent, Currency: "eur", Items: []QuoteItem{
+				{Name: "Chair", Quantity: 3, UnitPrice: 19.99},
+				{Name: "Table", Quantity: 1, UnitPrice: 120.5},
+			}},
+			total:    180.47,
+			currency: "EUR",
+		},
+		{
+			name:     "stale total is recomputed",
+			quote:    Quote{Status: quoteDraft, Total: 999, Items: []QuoteItem{{Name: "Lamp", Quantity: 2, UnitPrice: 0.1}}},
+			total:    0.2,
+			currency: baseCurrency,
+		},
+		{name: "unknown status", quote: Quote{Status: "paid"}, invalid: true},
+		{name: "unknown currency", quote: Quote{Status: quoteDraft, Currency: "XXX"}, invalid: true},
+		{name: "zero quantity", quote: Quote{Status: quoteDraft, Items: []QuoteItem{{Name: "Chair", UnitPrice: 1}}}, invalid: true},
+		{name: "negative price", quote: Quote{Status: quoteDraft, Items: []QuoteItem{{Name: "Chair", Quantity: 1, UnitPrice: -1}}}, invalid: true},
+		{name: "unnamed item", quote: Quote{Status: quoteDraft, Items: []QuoteItem{{Name: " ", Quantity: 1}}}, invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.quote
+			err := validateQuote(&q)
+			if tt.invalid {
+				var ve *validationError
+				if !errors.As(err, &ve) {
+					t.Fatalf("err = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if q.Total != tt.total || q.Currency != tt.currency || q.Items == nil {
+				t.Errorf("got total %v %s, items %v; want %v %s", q.Total, q.Currency, q.Items, tt.total, tt.currency)
+			}
+		})
+	}
+}
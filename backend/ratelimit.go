@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// attemptLimiter counts attempts per key (a client IP, an email address, an
+// admin username) within a sliding window. It slows down password guessing
+// and mail flooding; counts are kept in memory and reset on restart.
+type attemptLimiter struct {
+	sync.Mutex
+	limit    int
+	window   time.Duration
+	attempts map[string][]time.Time
+	swept    time.Time
+}
+
+var errTooManyAttempts = errors.New("Too many attempts, try again later")
+
+func newAttemptLimiter(limit int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{limit: limit, window: window, attempts: map[string][]time.Time{}}
+}
+
+// accountLimiter limits attempts on accounts per client and account pair,
+// and per client across all accounts. Failures never count against the
+// account alone, so nobody can lock its owner out by guessing wrong.
+type accountLimiter struct {
+	pair, client *attemptLimiter
+}
+
+func newAccountLimiter(pairLimit, clientLimit int, window time.Duration) accountLimiter {
+	return accountLimiter{newAttemptLimiter(pairLimit, window), newAttemptLimiter(clientLimit, window)}
+}
+
+// Failed logins, and password reset and verification mails, are limited per
+// client and account and per client.
+var (
+	customerLoginLimiter = newAccountLimiter(10, 50, 15*time.Minute)
+	adminLoginLimiter    = newAccountLimiter(10, 50, 15*time.Minute)
+	emailRequestLimiter  = newAccountLimiter(5, 20, time.Hour)
+)
+
+func accountLimiterKeys(r *http.Request, account string) (pair, client string) {
+	client = "ip:" + clientIP(r)
+	return client + " account:" + account, client
+}
+
+// blocked reports whether the client of r has used up its attempts on
+// account or on accounts in general.
+func (l accountLimiter) blocked(r *http.Request, account string) bool {
+	pair, client := accountLimiterKeys(r, account)
+	return l.pair.blocked(pair) || l.client.blocked(client)
+}
+
+// record counts a failed attempt of the client of r on account.
+func (l accountLimiter) record(r *http.Request, account string) {
+	pair, client := accountLimiterKeys(r, account)
+	l.pair.record(pair)
+	l.client.record(client)
+}
+
+// clear forgets the attempts of the client of r on account after it got in.
+func (l accountLimiter) clear(r *http.Request, account string) {
+	pair, _ := accountLimiterKeys(r, account)
+	l.pair.clear(pair)
+}
+
+// recent drops the attempts of key that fell out of the window. The caller
+// holds the lock.
+func (l *attemptLimiter) recent(key string, now time.Time) []time.Time {
+	attempts := l.attempts[key]
+	i := 0
+	for i < len(attempts) && now.Sub(attempts[i]) >= l.window {
+		i++
+	}
+	attempts = attempts[i:]
+	if len(attempts) == 0 {
+		delete(l.attempts, key)
+	} else {
+		l.attempts[key] = attempts
+	}
+	return attempts
+}
+
+// blocked reports whether any of keys has used up its attempts.
+func (l *attemptLimiter) blocked(keys ...string) bool {
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	for _, key := range keys {
+		if len(l.recent(key, now)) >= l.limit {
+			return true
+		}
+	}
+	return false
+}
+
+// record counts an attempt for each of keys.
+func (l *attemptLimiter) record(keys ...string) {
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	if now.Sub(l.swept) >= l.window {
+		for key := range l.attempts {
+			l.recent(key, now)
+		}
+		l.swept = now
+	}
+	for _, key := range keys {
+		l.attempts[key] = append(l.recent(key, now), now)
+	}
+}
+
+// clear forgets the attempts of keys, e.g. of an account after a successful
+// login.
+func (l *attemptLimiter) clear(keys ...string) {
+	l.Lock()
+	defer l.Unlock()
+	for _, key := range keys {
+		delete(l.attempts, key)
+	}
+}
+
+func writeTooManyAttempts(w http.ResponseWriter, window time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(window.Seconds())))
+	http.Error(w, errTooManyAttempts.Error(), http.StatusTooManyRequests)
+}
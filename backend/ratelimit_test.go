@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAttemptLimiter(t *testing.T) {
+	l := newAttemptLimiter(3, time.Minute)
+	for i := 0; i < 3; i++ {
+		if l.blocked("ip:1", "email:a") {
+			t.Fatalf("blocked after %d attempts", i)
+		}
+		l.record("ip:1", "email:a")
+	}
+	if !l.blocked("ip:1") || !l.blocked("email:a") {
+		t.Error("not blocked after reaching the limit")
+	}
+	if !l.blocked("ip:2", "email:a") {
+		t.Error("one used-up key should block")
+	}
+	if l.blocked("ip:2", "email:b") {
+		t.Error("unrelated keys blocked")
+	}
+
+	l.clear("email:a")
+	if l.blocked("email:a") {
+		t.Error("still blocked after clear")
+	}
+	if !l.blocked("ip:1") {
+		t.Error("clear forgot other keys")
+	}
+
+	// Attempts older than the window no longer count
+	old := time.Now().Add(-2 * time.Minute)
+	l.attempts["ip:3"] = []time.Time{old, old, old}
+	if l.blocked("ip:3") {
+		t.Error("blocked by attempts outside the window")
+	}
+	if _, ok := l.attempts["ip:3"]; ok {
+		t.Error("expired attempts were kept")
+	}
+}
+
+func TestAccountLimiter(t *testing.T) {
+	l := newAccountLimiter(2, 3, time.Minute)
+	request := func(ip string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/account/login", nil)
+		r.Header.Set("X-Real-IP", ip)
+		return r
+	}
+	attacker, owner := request("203.0.113.1"), request("198.51.100.7")
+
+	l.record(attacker, "admin")
+	l.record(attacker, "admin")
+	if !l.blocked(attacker, "admin") {
+		t.Error("attacker not blocked on the account")
+	}
+	if l.blocked(owner, "admin") {
+		t.Error("owner locked out by someone else's failures")
+	}
+	if l.blocked(attacker, "other") {
+		t.Error("attacker blocked on another account before the client limit")
+	}
+	l.record(attacker, "other")
+	if !l.blocked(attacker, "third") {
+		t.Error("attacker not blocked after the client limit")
+	}
+
+	l.record(owner, "admin")
+	l.clear(owner, "admin")
+	l.record(owner, "admin")
+	if l.blocked(owner, "admin") {
+		t.Error("clear did not reset the owner's attempts")
+	}
+}
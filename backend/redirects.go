@@ -0,0 +1,398 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Redirect sends visitors of an old URL to its new address. Auto redirects
+// are recorded when the slug of a product, collection, category or project
+// changes; the others are entered by admins, typically for pages of the old
+// site.
+type Redirect struct {
+	ID         int        `json:"id"`
+	FromPath   string     `json:"from_path"`
+	ToPath     string     `json:"to_path"`
+	StatusCode int        `json:"status_code"`
+	Auto       bool       `json:"auto"`
+	Hits       int        `json:"hits"`
+	LastHitAt  *time.Time `json:"last_hit_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Version    int        `json:"version"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+const redirectColumns = "id, from_path, to_path, status_code, auto, hits, last_hit_at, created_at, version, updated_at"
+
+var redirectRequiredFields = []string{"from_path", "to_path"}
+
+// Longest redirect chain followed when resolving a path.
+const maxRedirectHops = 5
+
+func scanRedirect(s rowScanner) (Redirect, error) {
+	var rd Redirect
+	err := s.Scan(&rd.ID, &rd.FromPath, &rd.ToPath, &rd.StatusCode, &rd.Auto, &rd.Hits, &rd.LastHitAt, &rd.CreatedAt, &rd.Version, &rd.UpdatedAt)
+	return rd, err
+}
+
+func fetchRedirect(id int) (Redirect, error) {
+	return scanRedirect(db.QueryRow("SELECT "+redirectColumns+" FROM redirects WHERE id = $1", id))
+}
+
+// normalizeRedirectPath trims a site path and drops a trailing slash, so
+// "/old-page/" and "/old-page" are the same source.
+func normalizeRedirectPath(path string) string {
+	path = strings.TrimSpace(path)
+	if u, err := url.Parse(path); err == nil && u.Host != "" {
+		path = u.RequestURI()
+	}
+	if q := strings.Index(path, "?"); q > 1 && path[q-1] == '/' {
+		path = path[:q-1] + path[q:]
+	} else if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+func validateRedirect(rd *Redirect) error {
+	rd.FromPath = normalizeRedirectPath(rd.FromPath)
+	rd.ToPath = strings.TrimSpace(rd.ToPath)
+	if !strings.HasPrefix(rd.FromPath, "/") || len(rd.FromPath) > 500 {
+		return &validationError{msg: "Field \"from_path\" must be a path starting with / of at most 500 characters"}
+	}
+	if strings.HasPrefix(rd.ToPath, "/") {
+		rd.ToPath = normalizeRedirectPath(rd.ToPath)
+	} else if u, err := url.Parse(rd.ToPath); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &validationError{msg: "Field \"to_path\" must be a path starting with / or an absolute http(s) URL"}
+	}
+	if len(rd.ToPath) > 500 {
+		return &validationError{msg: "Field \"to_path\" must be at most 500 characters"}
+	}
+	if rd.StatusCode == 0 {
+		rd.StatusCode = http.StatusMovedPermanently
+	}
+	switch rd.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return &validationError{msg: "Field \"status_code\" must be one of 301, 302, 307, 308"}
+	}
+
+	// Follow the target through the existing redirects to reject loops
+	return checkRedirectChain(rd.FromPath, rd.ToPath, func(path string) (string, error) {
+		var next string
+		err := db.QueryRow("SELECT to_path FROM redirects WHERE from_path = $1 AND id <> $2", path, rd.ID).Scan(&next)
+		return next, err
+	})
+}
+
+// checkRedirectChain follows a new redirect from -> to through the existing
+// ones, looked up with next (sql.ErrNoRows where the chain ends), and rejects
+// it if it leads back to from or the chain gets too long.
+func checkRedirectChain(from, to string, next func(path string) (string, error)) error {
+	target := to
+	for hop := 0; hop <= maxRedirectHops; hop++ {
+		if target == from {
+			return &validationError{msg: "The redirect would create a loop"}
+		}
+		following, err := next(target)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target = following
+	}
+	return &validationError{msg: "The redirect target starts a chain of too many redirects"}
+}
+
+// recordSlugRedirect is called in the transaction of a slug change that
+// moved a page from oldPath to newPath. Redirects to the old path are pointed
+// at the new one so visitors never take more than one hop, and a redirect
+// away from the new path (left by an earlier rename back) is dropped.
+func recordSlugRedirect(tx *sql.Tx, oldPath, newPath string) error {
+	if oldPath == newPath {
+		return nil
+	}
+	queries := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"DELETE FROM redirects WHERE from_path = $1", []interface{}{newPath}},
+		{"UPDATE redirects SET to_path = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE to_path = $2", []interface{}{newPath, oldPath}},
+		{`INSERT INTO redirects (from_path, to_path, status_code, auto) VALUES ($1, $2, 301, TRUE)
+			ON CONFLICT (from_path) DO UPDATE SET to_path = EXCLUDED.to_path, status_code = 301, auto = TRUE, version = redirects.version + 1, updated_at = CURRENT_TIMESTAMP`, []interface{}{oldPath, newPath}},
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(q.query, q.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveRedirect follows the redirects starting at path and returns the
+// final target with the status of the first hop. ok is false when path is
+// not redirected.
+func resolveRedirect(path string) (target string, status int, ok bool, err error) {
+	current := path
+	for hop := 0; hop < maxRedirectHops; hop++ {
+		var rd Redirect
+		rd, err = scanRedirect(db.QueryRow("SELECT "+redirectColumns+" FROM redirects WHERE from_path = $1", current))
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return "", 0, false, err
+		}
+		if hop == 0 {
+			status = rd.StatusCode
+			if _, err = db.Exec("UPDATE redirects SET hits = hits + 1, last_hit_at = CURRENT_TIMESTAMP WHERE id = $1", rd.ID); err != nil {
+				return "", 0, false, err
+			}
+		}
+		current, ok = rd.ToPath, true
+		if !strings.HasPrefix(current, "/") {
+			break
+		}
+	}
+	return current, status, ok, nil
+}
+
+// resolvePath answers GET /api/redirects/resolve?path=/old-page. A path with
+// a query string falls back to a redirect of the bare path.
+func resolvePath(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		http.Error(w, "Path parameter is required", http.StatusBadRequest)
+		return
+	}
+	path = normalizeRedirectPath(path)
+
+	candidates := []string{path}
+	if q := strings.Index(path, "?"); q > 0 {
+		candidates = append(candidates, normalizeRedirectPath(path[:q]))
+	}
+
+	for _, candidate := range candidates {
+		target, status, ok, err := resolveRedirect(candidate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if ok {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"found": true, "from": candidate, "to": target, "status_code": status})
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"found": false})
+}
+
+// saveRedirect writes rd if the row is still at version. See saveProduct.
+func saveRedirect(rd *Redirect, version int) error {
+	if err := validateRedirect(rd); err != nil {
+		return err
+	}
+	err := db.QueryRow(
+		"UPDATE redirects SET from_path=$1, to_path=$2, status_code=$3, auto=FALSE, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$4 AND ($5 = -1 OR version=$5) RETURNING auto, hits, last_hit_at, created_at, version, updated_at",
+		rd.FromPath, rd.ToPath, rd.StatusCode, rd.ID, version,
+	).Scan(&rd.Auto, &rd.Hits, &rd.LastHitAt, &rd.CreatedAt, &rd.Version, &rd.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return versionConflict("redirects", rd.ID)
+	}
+	return uniqueViolation(err, "A redirect from this path already exists")
+}
+
+// getRedirects lists redirects, filtered with ?q= (substring of either path)
+// and ?auto=true|false, paginated with ?limit= and ?offset=.
+func getRedirects(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + redirectColumns + " FROM redirects WHERE 1=1"
+	var args []interface{}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		args = append(args, "%"+q+"%")
+		n := strconv.Itoa(len(args))
+		query += " AND (from_path ILIKE $" + n + " OR to_path ILIKE $" + n + ")"
+	}
+	if v := r.URL.Query().Get("auto"); v != "" {
+		auto, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Invalid auto parameter", http.StatusBadRequest)
+			return
+		}
+		args = append(args, auto)
+		query += " AND auto = $" + strconv.Itoa(len(args))
+	}
+	limit, offset := pageParams(r, 100, 1000)
+	query += " ORDER BY created_at DESC, id DESC LIMIT " + strconv.Itoa(limit) + " OFFSET " + strconv.Itoa(offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	redirects := []Redirect{}
+	for rows.Next() {
+		rd, err := scanRedirect(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		redirects = append(redirects, rd)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(redirects)
+}
+
+func getRedirect(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid redirect ID", http.StatusBadRequest)
+		return
+	}
+
+	rd, err := fetchRedirect(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Redirect not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, rd.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rd)
+}
+
+func createRedirect(w http.ResponseWriter, r *http.Request) {
+	var rd Redirect
+	if err := json.NewDecoder(r.Body).Decode(&rd); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateRedirect(&rd); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+
+	err := db.QueryRow(
+		"INSERT INTO redirects (from_path, to_path, status_code) VALUES ($1, $2, $3) RETURNING id, auto, hits, last_hit_at, created_at, version, updated_at",
+		rd.FromPath, rd.ToPath, rd.StatusCode,
+	).Scan(&rd.ID, &rd.Auto, &rd.Hits, &rd.LastHitAt, &rd.CreatedAt, &rd.Version, &rd.UpdatedAt)
+	if err != nil {
+		writeSaveError(w, uniqueViolation(err, "A redirect from this path already exists"), "")
+		return
+	}
+
+	setETag(w, rd.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rd)
+}
+
+func updateRedirect(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid redirect ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var rd Redirect
+	if err := decodeFull(r.Body, &rd, redirectRequiredFields...); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	rd.ID = id
+	if err := saveRedirect(&rd, version); err != nil {
+		writeSaveError(w, err, "Redirect not found")
+		return
+	}
+
+	setETag(w, rd.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rd)
+}
+
+// patchRedirect edits a redirect. An edited auto redirect becomes a manual one.
+func patchRedirect(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid redirect ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	rd, err := fetchRedirect(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Redirect not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && rd.Version != version {
+		writeSaveError(w, errVersionMismatch, "Redirect not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &rd, redirectRequiredFields, "id", "auto", "hits", "last_hit_at", "created_at", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	rd.ID = id
+	if err := saveRedirect(&rd, rd.Version); err != nil {
+		writeSaveError(w, err, "Redirect not found")
+		return
+	}
+
+	setETag(w, rd.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rd)
+}
+
+func deleteRedirect(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid redirect ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := deleteVersioned("redirects", id, version); err != nil {
+		writeSaveError(w, err, "Redirect not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestNormalizeRedirectPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{path: "/old-page/", want: "/old-page"},
+		{path: " /old-page ", want: "/old-page"},
+		{path: "/", want: "/"},
+		{path: "/catalog/?category=beds", want: "/catalog?category=beds"},
+		{path: "https://old.example.com/shop/item?id=4", want: "/shop/item?id=4"},
+	}
+	for _, tt := range tests {
+		if got := normalizeRedirectPath(tt.path); got != tt.want {
+			t.Errorf("normalizeRedirectPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRedirectFields(t *testing.T) {
+	// These are rejected before the existing redirects are looked at
+	tests := []Redirect{
+		{FromPath: "old-page", ToPath: "/new"},
+		{FromPath: "/old", ToPath: "new"},
+		{FromPath: "/old", ToPath: "javascript:alert(1)"},
+		{FromPath: "/old", ToPath: "/new", StatusCode: 200},
+		{FromPath: "/old/", ToPath: "/old"},
+	}
+	for _, rd := range tests {
+		var ve *validationError
+		if err := validateRedirect(&rd); !errors.As(err, &ve) {
+			t.Errorf("validateRedirect(%+v) = %v, want a validation error", rd, err)
+		}
+	}
+}
+
+func TestCheckRedirectChain(t *testing.T) {
+	existing := map[string]string{
+		"/a": "/b",
+		"/b": "/c",
+		"/x": "/y",
+	}
+	next := func(path string) (string, error) {
+		if to, ok := existing[path]; ok {
+			return to, nil
+		}
+		return "", sql.ErrNoRows
+	}
+	long := func(path string) (string, error) { return path + "/more", nil }
+
+	tests := []struct {
+		name     string
+		from, to string
+		next     func(string) (string, error)
+		invalid  bool
+	}{
+		{name: "plain", from: "/old", to: "/new", next: next},
+		{name: "into a chain", from: "/old", to: "/a", next: next},
+		{name: "self", from: "/a", to: "/a", next: next, invalid: true},
+		{name: "loop through the chain", from: "/c", to: "/a", next: next, invalid: true},
+		{name: "two-step loop", from: "/y", to: "/x", next: next, invalid: true},
+		{name: "too long", from: "/old", to: "/new", next: long, invalid: true},
+	}
+	for _, tt := range tests {
+		err := checkRedirectChain(tt.from, tt.to, tt.next)
+		var ve *validationError
+		if errors.As(err, &ve) != tt.invalid || (!tt.invalid && err != nil) {
+			t.Errorf("%s: checkRedirectChain = %v", tt.name, err)
+		}
+	}
+
+	failure := errors.New("db down")
+	if err := checkRedirectChain("/old", "/new", func(string) (string, error) { return "", failure }); err != failure {
+		t.Errorf("lookup error not returned: %v", err)
+	}
+}
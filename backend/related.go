@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Kinds of curated product relations. Automatic suggestions are "similar".
+const (
+	relationAccessory = "accessory"
+	relationMatching  = "matching"
+	relationSimilar   = "similar"
+)
+
+// ProductRelation is a curated link from one product to another, kept in the
+// order the admin chose.
+type ProductRelation struct {
+	ProductID int    `json:"product_id"`
+	Kind      string `json:"kind"`
+}
+
+// RelatedProduct is a suggestion with the reasons it was picked: the curated
+// kind, or any of collection, category, material, color and price.
+type RelatedProduct struct {
+	Product
+	Relation string   `json:"relation"`
+	Reasons  []string `json:"reasons"`
+}
+
+// Weights of the automatic signals. Curated relations always rank first.
+const (
+	curatedScore    = 1000
+	collectionScore = 40
+	categoryScore   = 30
+	materialScore   = 15
+	colorScore      = 10
+	priceScore      = 10
+)
+
+// maxRelatedCandidates caps the products scored for one suggestion list.
+const maxRelatedCandidates = 200
+
+func fetchProductRelations(productID int) ([]ProductRelation, error) {
+	rows, err := db.Query("SELECT related_id, kind FROM product_relations WHERE product_id = $1 ORDER BY position, related_id", productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	relations := []ProductRelation{}
+	for rows.Next() {
+		var rel ProductRelation
+		if err := rows.Scan(&rel.ProductID, &rel.Kind); err != nil {
+			return nil, err
+		}
+		relations = append(relations, rel)
+	}
+	return relations, rows.Err()
+}
+
+func getProductRelations(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	relations, err := fetchProductRelations(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(relations)
+}
+
+// updateProductRelations replaces the curated relations of a product with
+// the ordered list in the body.
+func updateProductRelations(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := fetchProduct(id); err != nil {
+		writeSaveError(w, err, "Product not found")
+		return
+	}
+
+	var relations []ProductRelation
+	if err := json.NewDecoder(r.Body).Decode(&relations); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seen := map[int]bool{}
+	for i, rel := range relations {
+		if rel.Kind != relationAccessory && rel.Kind != relationMatching {
+			http.Error(w, fmt.Sprintf("Relation %d: kind must be one of %s, %s", i+1, relationAccessory, relationMatching), http.StatusUnprocessableEntity)
+			return
+		}
+		if rel.ProductID == id {
+			http.Error(w, fmt.Sprintf("Relation %d: a product cannot be related to itself", i+1), http.StatusUnprocessableEntity)
+			return
+		}
+		if seen[rel.ProductID] {
+			http.Error(w, fmt.Sprintf("Relation %d: product %d is listed twice", i+1, rel.ProductID), http.StatusUnprocessableEntity)
+			return
+		}
+		seen[rel.ProductID] = true
+		if _, err := fetchProduct(rel.ProductID); err != nil {
+			writeSaveError(w, err, fmt.Sprintf("Relation %d: product %d not found", i+1, rel.ProductID))
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM product_relations WHERE product_id = $1", id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for i, rel := range relations {
+		if _, err := tx.Exec(
+			"INSERT INTO product_relations (product_id, related_id, kind, position) VALUES ($1, $2, $3, $4)",
+			id, rel.ProductID, rel.Kind, i,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if relations == nil {
+		relations = []ProductRelation{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(relations)
+}
+
+// words splits s into lower-case words long enough to be meaningful, so
+// "Премиальный орех, металл" and "Орех" share "орех".
+func words(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(w)) >= 4 {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+func sharesWord(a, b map[string]bool) bool {
+	for w := range a {
+		if b[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// getRelatedProducts suggests products for the product page: the curated
+// accessories and matching items first, then visible products scored by
+// shared collection, category, material, color and price band. Candidates are
+// narrowed down in SQL to curated links, the same collections or category and
+// the price band, so material and color only refine the order. ?limit= caps
+// the list.
+func getRelatedProducts(w http.ResponseWriter, r *http.Request) {
+	p, ok := publicProduct(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := pageParams(r, 8, 24)
+
+	curated := map[int]int{} // product ID -> position
+	relations, err := fetchProductRelations(p.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	kinds := map[int]string{}
+	var linked []int64 // curated and same-collection products
+	for i, rel := range relations {
+		curated[rel.ProductID] = i
+		kinds[rel.ProductID] = rel.Kind
+		linked = append(linked, int64(rel.ProductID))
+	}
+
+	sameCollection := map[int]bool{}
+	rows, err := db.Query(`SELECT DISTINCT other.product_id FROM collection_products own
+		JOIN collection_products other ON other.collection_id = own.collection_id
+		JOIN collections c ON c.id = own.collection_id
+		WHERE own.product_id = $1 AND `+visibleCondition(r, "c"), p.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sameCollection[id] = true
+		linked = append(linked, int64(id))
+	}
+	rows.Close()
+
+	rates, err := exchangeRates()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	basePrice, priceErr := priceIn(p, baseCurrency, rates)
+	material, color := words(p.Material), words(p.Color)
+
+	// The price band is checked in SQL on the base currency price: the
+	// manual override if there is one, otherwise the converted price.
+	// Products without a rate fall out of the band, as in priceIn.
+	bandPrice := 0.0
+	if priceErr == nil {
+		bandPrice = basePrice.Amount
+	}
+	candidates, err := queryProducts(
+		"SELECT "+qualifyColumns("p", productColumns)+` FROM products p
+		LEFT JOIN exchange_rates er ON er.currency = p.currency
+		WHERE p.id <> $1 AND `+visibleCondition(r, "p")+` AND (
+			p.id = ANY($2)
+			OR ($3 <> '' AND LOWER(p.category) = LOWER($3))
+			OR ($4::numeric > 0 AND ABS(COALESCE(
+				CASE WHEN p.currency = $5 THEN p.price END,
+				(NULLIF(p.prices, '')::jsonb ->> $5)::numeric,
+				p.price * er.rate
+			) - $4::numeric) <= 0.3 * $4::numeric)
+		)
+		ORDER BY (p.id = ANY($2)) DESC, ($3 <> '' AND LOWER(p.category) = LOWER($3)) DESC, p.rating DESC, p.id
+		LIMIT $6`,
+		p.ID, pq.Array(linked), p.Category, bandPrice, baseCurrency, maxRelatedCandidates,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	type scored struct {
+		product RelatedProduct
+		score   int
+	}
+	var results []scored
+	for _, c := range candidates {
+		rp := RelatedProduct{Product: c, Relation: relationSimilar, Reasons: []string{}}
+		score := 0
+		if pos, ok := curated[c.ID]; ok {
+			rp.Relation = kinds[c.ID]
+			rp.Reasons = append(rp.Reasons, kinds[c.ID])
+			score += curatedScore - pos
+		}
+		if sameCollection[c.ID] {
+			rp.Reasons = append(rp.Reasons, "collection")
+			score += collectionScore
+		}
+		if p.Category != "" && strings.EqualFold(c.Category, p.Category) {
+			rp.Reasons = append(rp.Reasons, "category")
+			score += categoryScore
+		}
+		if sharesWord(material, words(c.Material)) {
+			rp.Reasons = append(rp.Reasons, "material")
+			score += materialScore
+		}
+		if sharesWord(color, words(c.Color)) {
+			rp.Reasons = append(rp.Reasons, "color")
+			score += colorScore
+		}
+		// Within ±30% of the price, compared in the base currency
+		if priceErr == nil && basePrice.Amount > 0 {
+			if other, err := priceIn(c, baseCurrency, rates); err == nil && math.Abs(other.Amount-basePrice.Amount) <= 0.3*basePrice.Amount {
+				rp.Reasons = append(rp.Reasons, "price")
+				score += priceScore
+			}
+		}
+		if score > 0 {
+			results = append(results, scored{rp, score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].product.Rating > results[j].product.Rating
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	products := make([]Product, len(results))
+	for i, res := range results {
+		products[i] = res.product.Product
+	}
+	if err := localize(w, r, "products", &products); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !priceProducts(w, r, productRefs(products)...) {
+		return
+	}
+
+	related := make([]RelatedProduct, len(results))
+	for i, res := range results {
+		related[i] = res.product
+		related[i].Product = products[i]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(related)
+}
@@ -0,0 +1,582 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Review statuses. Submitted reviews wait for moderation; only approved ones
+// are public and count towards the product's rating.
+const (
+	reviewPending  = "pending"
+	reviewApproved = "approved"
+	reviewRejected = "rejected"
+)
+
+const (
+	maxReviewPhotos = 6
+	reviewPhotoDir  = "reviews" // under ./uploads
+
+	// Photos are uploaded before the review is submitted; those no review
+	// refers to after this long are deleted.
+	reviewPhotoRetention = 24 * time.Hour
+)
+
+// reviewPhotoLimiter limits anonymous photo uploads per client.
+var reviewPhotoLimiter = newAttemptLimiter(30, time.Hour)
+
+type Review struct {
+	ID             int        `json:"id"`
+	ProductID      int        `json:"product_id"`
+	CustomerID     *int       `json:"customer_id,omitempty"`
+	Author         string     `json:"author"`
+	HotelName      string     `json:"hotel_name"`
+	Rating         int        `json:"rating"`
+	Text           string     `json:"text"`
+	Photos         []string   `json:"photos"`
+	Status         string     `json:"status,omitempty"`
+	ModerationNote string     `json:"moderation_note,omitempty"`
+	ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Version        int        `json:"version,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+const reviewColumns = "id, product_id, customer_id, author, hotel_name, rating, text, photos, status, moderation_note, moderated_at, created_at, version, updated_at"
+
+func scanReview(s rowScanner) (Review, error) {
+	var rv Review
+	var photos string
+	err := s.Scan(&rv.ID, &rv.ProductID, &rv.CustomerID, &rv.Author, &rv.HotelName, &rv.Rating, &rv.Text, &photos, &rv.Status, &rv.ModerationNote, &rv.ModeratedAt, &rv.CreatedAt, &rv.Version, &rv.UpdatedAt)
+	if err != nil {
+		return rv, err
+	}
+	json.Unmarshal([]byte(photos), &rv.Photos)
+	if rv.Photos == nil {
+		rv.Photos = []string{}
+	}
+	return rv, nil
+}
+
+func queryReviews(query string, args ...interface{}) ([]Review, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []Review{}
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, rv)
+	}
+	return reviews, rows.Err()
+}
+
+func fetchReview(id int) (Review, error) {
+	return scanReview(db.QueryRow("SELECT "+reviewColumns+" FROM reviews WHERE id = $1", id))
+}
+
+func validateReview(rv *Review) error {
+	rv.Author = strings.TrimSpace(rv.Author)
+	rv.HotelName = strings.TrimSpace(rv.HotelName)
+	rv.Text = strings.TrimSpace(rv.Text)
+	if rv.Author == "" || rv.Text == "" {
+		return &validationError{msg: "Missing required fields: author, text"}
+	}
+	if rv.Rating < 1 || rv.Rating > 5 {
+		return &validationError{msg: "Field \"rating\" must be between 1 and 5"}
+	}
+	if rv.Photos == nil {
+		rv.Photos = []string{}
+	}
+	if len(rv.Photos) > maxReviewPhotos {
+		return &validationError{msg: fmt.Sprintf("A review can have at most %d photos", maxReviewPhotos)}
+	}
+	switch rv.Status {
+	case reviewPending, reviewApproved, reviewRejected:
+	default:
+		return &validationError{msg: fmt.Sprintf("Field \"status\" must be one of %s", strings.Join([]string{reviewPending, reviewApproved, reviewRejected}, ", "))}
+	}
+	return nil
+}
+
+// deriveProductRatings replaces the ratings that used to be typed in by hand
+// with the ones derived from approved reviews. This overwrites the hand-entered
+// values, so they are copied to product_ratings_before_reviews first.
+func deriveProductRatings(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS product_ratings_before_reviews (
+		product_id INTEGER PRIMARY KEY,
+		rating DECIMAL(3,1),
+		reviews INTEGER,
+		saved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+
+	derived := `SELECT pr.id, COALESCE(ROUND(AVG(rv.rating), 1), 0) AS rating, COUNT(rv.id) AS reviews
+		FROM products pr LEFT JOIN reviews rv ON rv.product_id = pr.id AND rv.status = 'approved'
+		GROUP BY pr.id`
+	_, err = tx.Exec(`INSERT INTO product_ratings_before_reviews (product_id, rating, reviews)
+		SELECT p.id, p.rating, p.reviews FROM products p JOIN (` + derived + `) s ON s.id = p.id
+		WHERE p.rating IS DISTINCT FROM s.rating OR p.reviews IS DISTINCT FROM s.reviews
+		ON CONFLICT (product_id) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`UPDATE products p SET rating = s.rating, reviews = s.reviews FROM (` + derived + `) s
+		WHERE p.id = s.id AND (p.rating IS DISTINCT FROM s.rating OR p.reviews IS DISTINCT FROM s.reviews)`)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("Replaced the hand-entered ratings of %d products with their review averages; the old values are in product_ratings_before_reviews", n)
+	}
+	return nil
+}
+
+// refreshProductRating recomputes the rating and review count of a product
+// from its approved reviews. They are derived values, so the product's
+// version is left alone.
+func refreshProductRating(productID int) error {
+	_, err := db.Exec(
+		`UPDATE products SET
+			rating = COALESCE((SELECT ROUND(AVG(rating), 1) FROM reviews WHERE product_id = $1 AND status = 'approved'), 0),
+			reviews = (SELECT COUNT(*) FROM reviews WHERE product_id = $1 AND status = 'approved')
+		WHERE id = $1`,
+		productID,
+	)
+	return err
+}
+
+// saveReview writes rv if the row is still at version and refreshes the
+// product's rating. See saveProduct. A status change records the moderation time.
+func saveReview(rv *Review, version int) error {
+	if err := validateReview(rv); err != nil {
+		return err
+	}
+	photos, _ := json.Marshal(rv.Photos)
+
+	err := db.QueryRow(
+		"UPDATE reviews SET author=$1, hotel_name=$2, rating=$3, text=$4, photos=$5, moderated_at=CASE WHEN status <> $6 THEN CURRENT_TIMESTAMP ELSE moderated_at END, status=$6, moderation_note=$7, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=$8 AND ($9 = -1 OR version=$9) RETURNING moderated_at, created_at, version, updated_at",
+		rv.Author, rv.HotelName, rv.Rating, rv.Text, string(photos), rv.Status, rv.ModerationNote, rv.ID, version,
+	).Scan(&rv.ModeratedAt, &rv.CreatedAt, &rv.Version, &rv.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return versionConflict("reviews", rv.ID)
+	}
+	if err != nil {
+		return err
+	}
+	return refreshProductRating(rv.ProductID)
+}
+
+// publicProduct loads the product of a public /products/{id}/... route,
+// writing 404 if it does not exist or is not visible.
+func publicProduct(w http.ResponseWriter, r *http.Request) (Product, bool) {
+	id, err := routeEntityID(r, "products")
+	if err == sql.ErrNoRows {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return Product{}, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return Product{}, false
+	}
+	p, err := fetchProduct(id)
+	if err == sql.ErrNoRows || (err == nil && !productVisible(r, p)) {
+		http.Error(w, "Product not found", http.StatusNotFound)
+		return Product{}, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return Product{}, false
+	}
+	return p, true
+}
+
+// getProductReviews lists the approved reviews of a product, newest first,
+// paginated with ?limit= and ?offset=.
+func getProductReviews(w http.ResponseWriter, r *http.Request) {
+	p, ok := publicProduct(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := pageParams(r, 10, 50)
+
+	reviews, err := queryReviews(
+		"SELECT "+reviewColumns+" FROM reviews WHERE product_id = $1 AND status = $2 ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4",
+		p.ID, reviewApproved, limit, offset,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Moderation details are for admins only
+	for i := range reviews {
+		reviews[i].CustomerID = nil
+		reviews[i].Status = ""
+		reviews[i].ModerationNote = ""
+		reviews[i].ModeratedAt = nil
+		reviews[i].Version = 0
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"reviews": reviews,
+		"total":   p.Reviews,
+		"rating":  p.Rating,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// createReview submits a review of a product for moderation. Logged-in
+// customers are linked to their review.
+func createReview(w http.ResponseWriter, r *http.Request) {
+	p, ok := publicProduct(w, r)
+	if !ok {
+		return
+	}
+	customer, err := customerFromRequest(r)
+	if err == errInvalidCredentials {
+		http.Error(w, "Invalid customer credentials", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var rv Review
+	if err := json.NewDecoder(r.Body).Decode(&rv); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rv.ProductID = p.ID
+	rv.Status = reviewPending
+	rv.ModerationNote = ""
+	rv.CustomerID = nil
+	if customer != nil {
+		rv.CustomerID = &customer.ID
+		if strings.TrimSpace(rv.Author) == "" {
+			rv.Author = customer.Name
+		}
+		if strings.TrimSpace(rv.HotelName) == "" {
+			rv.HotelName = customer.Company
+		}
+	}
+	if err := validateReview(&rv); err != nil {
+		writeSaveError(w, err, "")
+		return
+	}
+	// Public reviews may only show photos uploaded through /reviews/photos
+	for _, photo := range rv.Photos {
+		if !strings.HasPrefix(photo, "/uploads/"+reviewPhotoDir+"/") || strings.Contains(photo, "..") {
+			http.Error(w, "Photos must be uploaded via /api/reviews/photos", http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	photos, _ := json.Marshal(rv.Photos)
+	err = db.QueryRow(
+		"INSERT INTO reviews (product_id, customer_id, author, hotel_name, rating, text, photos, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at, version, updated_at",
+		rv.ProductID, rv.CustomerID, rv.Author, rv.HotelName, rv.Rating, rv.Text, string(photos), rv.Status,
+	).Scan(&rv.ID, &rv.CreatedAt, &rv.Version, &rv.UpdatedAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rv)
+}
+
+// uploadReviewPhoto stores a photo for a review that is about to be
+// submitted. Unlike the admin upload it only accepts images. Uploads are
+// limited per client, and photos that never make it into a review are removed
+// by purgeUnattachedReviewPhotos.
+func uploadReviewPhoto(w http.ResponseWriter, r *http.Request) {
+	ip := "ip:" + clientIP(r)
+	if reviewPhotoLimiter.blocked(ip) {
+		writeTooManyAttempts(w, reviewPhotoLimiter.window)
+		return
+	}
+	reviewPhotoLimiter.record(ip)
+
+	r.Body = http.MaxBytesReader(w, r.Body, 5<<20)
+	if err := r.ParseMultipartForm(5 << 20); err != nil {
+		http.Error(w, "Error parsing form", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "Error retrieving file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	ext := map[string]string{"image/jpeg": ".jpg", "image/png": ".png", "image/webp": ".webp", "image/gif": ".gif"}[http.DetectContentType(head[:n])]
+	if ext == "" {
+		http.Error(w, "Only JPEG, PNG, WebP and GIF images are accepted", http.StatusUnprocessableEntity)
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	uploadDir := filepath.Join("./uploads", reviewPhotoDir)
+	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
+		http.Error(w, "Error creating upload directory", http.StatusInternalServerError)
+		return
+	}
+
+	// Client file names are not trusted
+	token, err := newAPIKey()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	filename := fmt.Sprintf("%d_%s%s", time.Now().Unix(), token[:16], ext)
+
+	dst, err := os.Create(filepath.Join(uploadDir, filename))
+	if err != nil {
+		http.Error(w, "Error creating file", http.StatusInternalServerError)
+		return
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, file); err != nil {
+		http.Error(w, "Error saving file", http.StatusInternalServerError)
+		return
+	}
+
+	invalidateStatsCache()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"url": fmt.Sprintf("/uploads/%s/%s", reviewPhotoDir, filename),
+	})
+}
+
+// purgeUnattachedReviewPhotos deletes uploaded review photos that no review
+// refers to once they are older than reviewPhotoRetention: abandoned review
+// forms, and photos removed from a review during moderation.
+func purgeUnattachedReviewPhotos() {
+	dir := filepath.Join("./uploads", reviewPhotoDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error listing review photos: %v", err)
+		}
+		return
+	}
+
+	rows, err := db.Query("SELECT photos FROM reviews WHERE photos <> '[]'")
+	if err != nil {
+		log.Printf("Error loading review photos: %v", err)
+		return
+	}
+	attached := map[string]bool{}
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			rows.Close()
+			log.Printf("Error loading review photos: %v", err)
+			return
+		}
+		var photos []string
+		json.Unmarshal([]byte(raw), &photos)
+		for _, photo := range photos {
+			attached[photo] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Error loading review photos: %v", err)
+		return
+	}
+
+	cutoff := time.Now().Add(-reviewPhotoRetention)
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || attached["/uploads/"+reviewPhotoDir+"/"+entry.Name()] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			log.Printf("Error removing review photo %s: %v", entry.Name(), err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Printf("Removed %d review photos not attached to any review", removed)
+		invalidateStatsCache()
+	}
+}
+
+func startReviewPhotoPurger() {
+	go func() {
+		for {
+			purgeUnattachedReviewPhotos()
+			time.Sleep(time.Hour)
+		}
+	}()
+}
+
+// getReviews is the moderation queue: ?status= (e.g. pending) and
+// ?product_id= filter it, ?limit= and ?offset= page through it.
+func getReviews(w http.ResponseWriter, r *http.Request) {
+	query := "SELECT " + reviewColumns + " FROM reviews WHERE 1=1"
+	var args []interface{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		args = append(args, status)
+		query += " AND status = $" + strconv.Itoa(len(args))
+	}
+	if v := r.URL.Query().Get("product_id"); v != "" {
+		productID, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid product ID", http.StatusBadRequest)
+			return
+		}
+		args = append(args, productID)
+		query += " AND product_id = $" + strconv.Itoa(len(args))
+	}
+	limit, offset := pageParams(r, 100, 500)
+	query += " ORDER BY created_at DESC, id DESC LIMIT " + strconv.Itoa(limit) + " OFFSET " + strconv.Itoa(offset)
+
+	reviews, err := queryReviews(query, args...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+func getReview(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	rv, err := fetchReview(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Review not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setETag(w, rv.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rv)
+}
+
+// patchReview moderates a review: set status to approved or rejected, and
+// optionally fix typos or drop photos.
+func patchReview(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	rv, err := fetchReview(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Review not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if version != anyVersion && rv.Version != version {
+		writeSaveError(w, errVersionMismatch, "Review not found")
+		return
+	}
+
+	if err := applyMergePatch(r.Body, &rv, nil, "id", "product_id", "customer_id", "moderated_at", "created_at", "version", "updated_at"); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	rv.ID = id
+	if err := saveReview(&rv, rv.Version); err != nil {
+		writeSaveError(w, err, "Review not found")
+		return
+	}
+
+	setETag(w, rv.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rv)
+}
+
+func deleteReview(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	rv, err := fetchReview(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Review not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := deleteVersioned("reviews", id, version); err != nil {
+		writeSaveError(w, err, "Review not found")
+		return
+	}
+	if err := refreshProductRating(rv.ProductID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type Revision struct {
+	ID         int             `json:"id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int             `json:"entity_id"`
+	Version    int             `json:"version"`
+	Actor      string          `json:"actor"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// revisionRestorers write a stored snapshot back as the current state of an
+// entity, provided the row is still at version. They are keyed like
+// auditLoaders and also define which entity types keep revisions.
+var revisionRestorers = map[string]func(id int, data []byte, version int) (interface{}, int, error){
+	"products": func(id int, data []byte, version int) (interface{}, int, error) {
+		var p Product
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, 0, err
+		}
+		p.ID = id
+		err := saveProduct(&p, version)
+		return p, p.Version, err
+	},
+	"categories": func(id int, data []byte, version int) (interface{}, int, error) {
+		var c Category
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, 0, err
+		}
+		c.ID = id
+		err := saveCategory(&c, version)
+		return c, c.Version, err
+	},
+	"collections": func(id int, data []byte, version int) (interface{}, int, error) {
+		var c Collection
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, 0, err
+		}
+		c.ID = id
+		c.Products = nil
+		err := saveCollection(&c, version)
+		return c, c.Version, err
+	},
+	"placeholders": func(id int, data []byte, version int) (interface{}, int, error) {
+		var p Placeholder
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, 0, err
+		}
+		p.ID = id
+		err := savePlaceholder(&p, version)
+		return p, p.Version, err
+	},
+	"faqs": func(id int, data []byte, version int) (interface{}, int, error) {
+		var faq FAQ
+		if err := json.Unmarshal(data, &faq); err != nil {
+			return nil, 0, err
+		}
+		faq.ID = id
+		err := saveFAQ(&faq, version)
+		return faq, faq.Version, err
+	},
+	"content-sections": func(id int, data []byte, version int) (interface{}, int, error) {
+		var s ContentSection
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, 0, err
+		}
+		s.ID = id
+		err := saveContentSection(&s, version)
+		return s, s.Version, err
+	},
+	"content-blocks": func(id int, data []byte, version int) (interface{}, int, error) {
+		var b ContentBlock
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, 0, err
+		}
+		b.ID = id
+		err := saveContentBlock(&b, version)
+		return b, b.Version, err
+	},
+	"projects": func(id int, data []byte, version int) (interface{}, int, error) {
+		var p Project
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, 0, err
+		}
+		p.ID = id
+		err := saveProject(&p, version)
+		return p, p.Version, err
+	},
+}
+
+// recordRevision stores a snapshot of an entity. Snapshots are keyed by the
+// row version, so recording the same state twice is a no-op.
+func recordRevision(entityType string, entityID int, snapshot interface{}, actor string) {
+	if _, ok := revisionRestorers[entityType]; !ok || snapshot == nil {
+		return
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		log.Printf("Error encoding revision: %v", err)
+		return
+	}
+	var meta struct {
+		Version int `json:"version"`
+	}
+	json.Unmarshal(data, &meta)
+
+	_, err = db.Exec(
+		"INSERT INTO revisions (entity_type, entity_id, version, data, actor) VALUES ($1, $2, $3, $4, NULLIF($5, '')) ON CONFLICT (entity_type, entity_id, version) DO NOTHING",
+		entityType, entityID, meta.Version, string(data), actor,
+	)
+	if err != nil {
+		log.Printf("Error writing revision: %v", err)
+	}
+}
+
+// revisionTarget validates the {type} and {id} route variables.
+func revisionTarget(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	vars := mux.Vars(r)
+	entityType := vars["type"]
+	if _, ok := revisionRestorers[entityType]; !ok {
+		http.Error(w, "Invalid entity type", http.StatusBadRequest)
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return entityType, id, true
+}
+
+func fetchRevision(entityType string, entityID, revisionID int) (Revision, error) {
+	var rev Revision
+	var actor sql.NullString
+	var data []byte
+	err := db.QueryRow(
+		"SELECT id, entity_type, entity_id, version, actor, created_at, data FROM revisions WHERE entity_type = $1 AND entity_id = $2 AND id = $3",
+		entityType, entityID, revisionID,
+	).Scan(&rev.ID, &rev.EntityType, &rev.EntityID, &rev.Version, &actor, &rev.CreatedAt, &data)
+	rev.Actor = actor.String
+	rev.Data = data
+	return rev, err
+}
+
+func getRevisions(w http.ResponseWriter, r *http.Request) {
+	entityType, id, ok := revisionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.Query(
+		"SELECT id, entity_type, entity_id, version, actor, created_at FROM revisions WHERE entity_type = $1 AND entity_id = $2 ORDER BY version DESC",
+		entityType, id,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	revisions := []Revision{}
+	for rows.Next() {
+		var rev Revision
+		var actor sql.NullString
+		if err := rows.Scan(&rev.ID, &rev.EntityType, &rev.EntityID, &rev.Version, &actor, &rev.CreatedAt); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rev.Actor = actor.String
+		revisions = append(revisions, rev)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+func getRevision(w http.ResponseWriter, r *http.Request) {
+	entityType, id, ok := revisionTarget(w, r)
+	if !ok {
+		return
+	}
+	revisionID, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		http.Error(w, "Invalid revision ID", http.StatusBadRequest)
+		return
+	}
+
+	rev, err := fetchRevision(entityType, id, revisionID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rev)
+}
+
+// diffRevisions compares two revisions field by field. "to" may be the word
+// "current" to compare against the live row.
+func diffRevisions(w http.ResponseWriter, r *http.Request) {
+	entityType, id, ok := revisionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	load := func(param string) (map[string]interface{}, bool) {
+		value := r.URL.Query().Get(param)
+		if value == "current" {
+			current, err := auditLoaders[entityType](id)
+			if err == sql.ErrNoRows {
+				http.Error(w, "Entity not found", http.StatusNotFound)
+				return nil, false
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return nil, false
+			}
+			return toJSONMap(current), true
+		}
+
+		revisionID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Parameter "+param+" must be a revision ID or \"current\"", http.StatusBadRequest)
+			return nil, false
+		}
+		rev, err := fetchRevision(entityType, id, revisionID)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return nil, false
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return nil, false
+		}
+		var m map[string]interface{}
+		json.Unmarshal(rev.Data, &m)
+		return m, true
+	}
+
+	from, ok := load("from")
+	if !ok {
+		return
+	}
+	to, ok := load("to")
+	if !ok {
+		return
+	}
+
+	// Bookkeeping fields change on every save and would only add noise
+	ignored := map[string]bool{"version": true, "updated_at": true}
+	changes := []FieldChange{}
+	for _, key := range unionKeys(from, to) {
+		if ignored[key] || reflect.DeepEqual(from[key], to[key]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: key, From: from[key], To: to[key]})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+func restoreRevision(w http.ResponseWriter, r *http.Request) {
+	entityType, id, ok := revisionTarget(w, r)
+	if !ok {
+		return
+	}
+	revisionID, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		http.Error(w, "Invalid revision ID", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	rev, err := fetchRevision(entityType, id, revisionID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	entity, newVersion, err := revisionRestorers[entityType](id, rev.Data, version)
+	if err != nil {
+		writeSaveError(w, err, "Entity not found")
+		return
+	}
+
+	setETag(w, newVersion)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entity)
+}
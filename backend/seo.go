@@ -0,0 +1,305 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Search engines cut descriptions at about this many characters.
+const metaDescriptionLength = 160
+
+// validateSEO normalises the SEO fields shared by products, categories and
+// collections. Empty fields fall back to the regular content.
+func validateSEO(slug, metaTitle, canonicalURL *string) error {
+	*slug = strings.ToLower(strings.TrimSpace(*slug))
+	if *slug != "" && (len(*slug) > 200 || !slugPattern.MatchString(*slug)) {
+		return &validationError{msg: "Field \"slug\" may only contain lowercase latin letters, digits and single hyphens"}
+	}
+	if err := checkSlug(*slug); err != nil {
+		return err
+	}
+	*metaTitle = strings.TrimSpace(*metaTitle)
+	if len([]rune(*metaTitle)) > 255 {
+		return &validationError{msg: "Field \"meta_title\" must be at most 255 characters"}
+	}
+	*canonicalURL = strings.TrimSpace(*canonicalURL)
+	if *canonicalURL != "" && !strings.HasPrefix(*canonicalURL, "/") {
+		u, err := url.Parse(*canonicalURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &validationError{msg: "Field \"canonical_url\" must be an absolute http(s) URL or a path starting with /"}
+		}
+	}
+	return nil
+}
+
+// SEOMeta is everything a page needs for its <head>: the meta tags and the
+// schema.org JSON-LD documents to embed as application/ld+json scripts.
+type SEOMeta struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Canonical   string        `json:"canonical"`
+	OGImage     string        `json:"og_image,omitempty"`
+	JSONLD      []interface{} `json:"json_ld"`
+}
+
+func productPath(p Product) string {
+	return "/products/" + firstNonEmpty(p.Slug, strconv.Itoa(p.ID))
+}
+
+func collectionPath(c Collection) string {
+	return "/collections/" + firstNonEmpty(c.Slug, strconv.Itoa(c.ID))
+}
+
+// categoryPath is the catalog page a category links to.
+func categoryPath(c Category) string {
+	if strings.HasPrefix(c.Href, "/") {
+		return c.Href
+	}
+	return "/catalog"
+}
+
+// absoluteURL turns a site path (or an already absolute URL) into an
+// absolute URL on the public site.
+func absoluteURL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return siteURL + "/" + strings.TrimPrefix(path, "/")
+}
+
+// metaDescription returns the explicit description, or the content text cut
+// at a word boundary.
+func metaDescription(explicit, text string) string {
+	if explicit != "" {
+		return explicit
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= metaDescriptionLength {
+		return text
+	}
+	cut := string(runes[:metaDescriptionLength])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Breadcrumb labels of the fixed site sections, per locale.
+var breadcrumbLabels = map[string]map[string]string{
+	"ru": {"home": "Главная", "catalog": "Каталог", "collections": "Коллекции"},
+	"en": {"home": "Home", "catalog": "Catalog", "collections": "Collections"},
+	"zh": {"home": "首页", "catalog": "产品目录", "collections": "系列"},
+}
+
+func breadcrumbLabel(r *http.Request, key string) string {
+	for _, locale := range requestLocales(r) {
+		if label, ok := breadcrumbLabels[locale][key]; ok {
+			return label
+		}
+	}
+	return breadcrumbLabels["ru"][key]
+}
+
+type breadcrumb struct {
+	name, path string
+}
+
+func breadcrumbList(crumbs ...breadcrumb) map[string]interface{} {
+	items := make([]map[string]interface{}, len(crumbs))
+	for i, c := range crumbs {
+		items[i] = map[string]interface{}{
+			"@type":    "ListItem",
+			"position": i + 1,
+			"name":     c.name,
+			"item":     absoluteURL(c.path),
+		}
+	}
+	return map[string]interface{}{
+		"@context":        "https://schema.org",
+		"@type":           "BreadcrumbList",
+		"itemListElement": items,
+	}
+}
+
+func writeSEOMeta(w http.ResponseWriter, meta SEOMeta) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(meta)
+}
+
+// getProductSEO returns the meta tags of a product page with Product (Offer,
+// AggregateRating) and BreadcrumbList JSON-LD. The offer carries the public
+// list price, never a customer price list.
+func getProductSEO(w http.ResponseWriter, r *http.Request) {
+	p, ok := publicProduct(w, r)
+	if !ok {
+		return
+	}
+
+	// The category is matched by its untranslated name
+	var category *Category
+	c, err := scanCategory(db.QueryRow("SELECT "+categoryColumns+" FROM categories WHERE name = $1 AND deleted_at IS NULL ORDER BY id LIMIT 1", p.Category))
+	if err == nil {
+		category = &c
+	} else if err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := localize(w, r, "products", &p); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if category != nil {
+		if err := localize(w, r, "categories", category); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	canonical := absoluteURL(firstNonEmpty(p.CanonicalURL, productPath(p)))
+	var images []string
+	for _, img := range p.Images {
+		images = append(images, absoluteURL(img))
+	}
+
+	product := map[string]interface{}{
+		"@context":    "https://schema.org",
+		"@type":       "Product",
+		"name":        p.Name,
+		"description": p.Description,
+		"sku":         strconv.Itoa(p.ID),
+		"url":         canonical,
+		"offers": map[string]interface{}{
+			"@type":         "Offer",
+			"price":         fmt.Sprintf("%.2f", p.Price),
+			"priceCurrency": p.Currency,
+			"availability":  "https://schema.org/InStock",
+			"url":           canonical,
+		},
+	}
+	if len(images) > 0 {
+		product["image"] = images
+	}
+	for key, value := range map[string]string{"category": p.Category, "color": p.Color, "material": p.Material} {
+		if value != "" {
+			product[key] = value
+		}
+	}
+	if p.Reviews > 0 {
+		product["aggregateRating"] = map[string]interface{}{
+			"@type":       "AggregateRating",
+			"ratingValue": p.Rating,
+			"reviewCount": p.Reviews,
+			"bestRating":  5,
+			"worstRating": 1,
+		}
+	}
+
+	crumbs := []breadcrumb{{breadcrumbLabel(r, "home"), "/"}, {breadcrumbLabel(r, "catalog"), "/catalog"}}
+	if category != nil {
+		crumbs = append(crumbs, breadcrumb{category.Name, categoryPath(*category)})
+	}
+	crumbs = append(crumbs, breadcrumb{p.Name, productPath(p)})
+
+	writeSEOMeta(w, SEOMeta{
+		Title:       firstNonEmpty(p.MetaTitle, p.Name),
+		Description: metaDescription(p.MetaDescription, p.Description),
+		Canonical:   canonical,
+		OGImage:     absoluteURL(firstNonEmpty(p.OGImage, p.Image)),
+		JSONLD:      []interface{}{product, breadcrumbList(crumbs...)},
+	})
+}
+
+// getCollectionSEO returns the meta tags and BreadcrumbList of a collection page.
+func getCollectionSEO(w http.ResponseWriter, r *http.Request) {
+	id, err := routeEntityID(r, "collections")
+	if err == sql.ErrNoRows {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c, err := fetchCollection(id)
+	if err == sql.ErrNoRows || (err == nil && !collectionVisible(r, c)) {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "collections", &c); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeSEOMeta(w, SEOMeta{
+		Title:       firstNonEmpty(c.MetaTitle, c.Name),
+		Description: metaDescription(c.MetaDescription, c.Description),
+		Canonical:   absoluteURL(firstNonEmpty(c.CanonicalURL, collectionPath(c))),
+		OGImage:     absoluteURL(firstNonEmpty(c.OGImage, c.Image)),
+		JSONLD: []interface{}{breadcrumbList(
+			breadcrumb{breadcrumbLabel(r, "home"), "/"},
+			breadcrumb{breadcrumbLabel(r, "collections"), "/collections"},
+			breadcrumb{c.Name, collectionPath(c)},
+		)},
+	})
+}
+
+// getCategorySEO returns the meta tags and BreadcrumbList of a category page.
+func getCategorySEO(w http.ResponseWriter, r *http.Request) {
+	id, err := routeEntityID(r, "categories")
+	if err == sql.ErrNoRows {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c, err := fetchCategory(id)
+	if err == sql.ErrNoRows || (err == nil && c.DeletedAt != nil) {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := localize(w, r, "categories", &c); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeSEOMeta(w, SEOMeta{
+		Title:       firstNonEmpty(c.MetaTitle, c.Name),
+		Description: metaDescription(c.MetaDescription, c.Description),
+		Canonical:   absoluteURL(firstNonEmpty(c.CanonicalURL, categoryPath(c))),
+		OGImage:     absoluteURL(firstNonEmpty(c.OGImage, c.Image)),
+		JSONLD: []interface{}{breadcrumbList(
+			breadcrumb{breadcrumbLabel(r, "home"), "/"},
+			breadcrumb{breadcrumbLabel(r, "catalog"), "/catalog"},
+			breadcrumb{c.Name, categoryPath(c)},
+		)},
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Site-wide settings editable from the admin. Each key has a default that
+// applies until an admin saves a value.
+const settingRobotsTxt = "robots_txt"
+
+var settingDefaults = map[string]func() string{
+	settingRobotsTxt:             defaultRobotsTxt,
+	settingMaintenanceMode:       func() string { return "off" },
+	settingMaintenanceMessage:    func() string { return defaultMaintenanceMessage },
+	settingMaintenanceRetryAfter: func() string { return "3600" },
+}
+
+// settingValidators check the values of settings that are not free text.
+var settingValidators = map[string]func(value string) error{
+	settingMaintenanceMode:       validateMaintenanceMode,
+	settingMaintenanceRetryAfter: validateRetryAfter,
+}
+
+type Setting struct {
+	Key       string     `json:"key"`
+	Value     string     `json:"value"`
+	Default   string     `json:"default"`
+	IsDefault bool       `json:"is_default"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// getSetting returns the stored value of key, or its default.
+func getSetting(key string) (string, error) {
+	var value string
+	err := db.QueryRow("SELECT value FROM settings WHERE key = $1", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return settingDefaults[key](), nil
+	}
+	return value, err
+}
+
+func fetchSetting(key string) (Setting, error) {
+	s := Setting{Key: key, Default: settingDefaults[key]()}
+	var updatedAt time.Time
+	err := db.QueryRow("SELECT value, updated_at FROM settings WHERE key = $1", key).Scan(&s.Value, &updatedAt)
+	if err == sql.ErrNoRows {
+		s.Value, s.IsDefault = s.Default, true
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	s.UpdatedAt = &updatedAt
+	return s, nil
+}
+
+func getSettings(w http.ResponseWriter, r *http.Request) {
+	keys := make([]string, 0, len(settingDefaults))
+	for key := range settingDefaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	settings := []Setting{}
+	for _, key := range keys {
+		s, err := fetchSetting(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		settings = append(settings, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// updateSetting stores {"value": "..."} for a known key. A null value resets
+// the setting to its default.
+func updateSetting(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if _, ok := settingDefaults[key]; !ok {
+		http.Error(w, "Unknown setting", http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Value *string `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if validate := settingValidators[key]; validate != nil && body.Value != nil {
+		if err := validate(strings.TrimSpace(*body.Value)); err != nil {
+			writeSaveError(w, err, "")
+			return
+		}
+		*body.Value = strings.TrimSpace(*body.Value)
+	}
+
+	var err error
+	if body.Value == nil {
+		_, err = db.Exec("DELETE FROM settings WHERE key = $1", key)
+	} else {
+		_, err = db.Exec(
+			"INSERT INTO settings (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP",
+			key, *body.Value,
+		)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	invalidateMaintenanceCache()
+
+	s, err := fetchSetting(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
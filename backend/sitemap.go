@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// The sitemap protocol allows 50,000 URLs per file; larger sitemaps are split
+// into chunks listed by a sitemap index.
+const maxSitemapURLs = 50000
+
+// Frontend pages that are always listed.
+var sitemapStaticPaths = []string{"/", "/catalog", "/collections", "/new", "/portfolio", "/about", "/contact"}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+const sitemapXmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapURLs lists the public pages: static pages, then published products,
+// collections, portfolio projects and categories. Paths covered by an active placeholder are left
+// out, since they only show the placeholder.
+func sitemapURLs() ([]sitemapURL, time.Time, error) {
+	placeholders, _, err := cachedPlaceholders()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var urls []sitemapURL
+	var latest time.Time
+	// aliases are other paths of the same page, e.g. /products/12 for
+	// /products/sofa; a placeholder on any of them hides the page.
+	add := func(path string, lastMod time.Time, aliases ...string) {
+		for _, p := range append(aliases, path) {
+			if _, ok := matchPlaceholder(placeholders, p); ok {
+				return
+			}
+		}
+		u := sitemapURL{Loc: siteURL + path}
+		if !lastMod.IsZero() {
+			u.LastMod = lastMod.UTC().Format(time.RFC3339)
+			if lastMod.After(latest) {
+				latest = lastMod
+			}
+		}
+		urls = append(urls, u)
+	}
+
+	for _, path := range sitemapStaticPaths {
+		add(path, time.Time{})
+	}
+
+	published := "deleted_at IS NULL AND status = '" + statusPublished + "'"
+	for _, q := range []struct{ query, prefix string }{
+		{"SELECT id, slug, updated_at FROM products WHERE " + published + " ORDER BY id", "/products/"},
+		{"SELECT id, slug, updated_at FROM collections WHERE " + published + " ORDER BY id", "/collections/"},
+		{"SELECT id, slug, updated_at FROM projects WHERE status = '" + statusPublished + "' ORDER BY id", "/portfolio/"},
+	} {
+		rows, err := db.Query(q.query)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		for rows.Next() {
+			var id int
+			var slug string
+			var updatedAt time.Time
+			if err := rows.Scan(&id, &slug, &updatedAt); err != nil {
+				rows.Close()
+				return nil, time.Time{}, err
+			}
+			idPath := q.prefix + strconv.Itoa(id)
+			if slug == "" {
+				add(idPath, updatedAt)
+			} else {
+				add(q.prefix+slug, updatedAt, idPath)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	// Categories have no page of their own; they link to a filtered catalog
+	rows, err := db.Query("SELECT href, updated_at FROM categories WHERE deleted_at IS NULL AND href LIKE '/%' ORDER BY id")
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var href string
+		var updatedAt time.Time
+		if err := rows.Scan(&href, &updatedAt); err != nil {
+			return nil, time.Time{}, err
+		}
+		add(href, updatedAt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+	return urls, latest, nil
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.Encode(v)
+}
+
+// getSitemap serves /sitemap.xml: the full URL set, or a sitemap index
+// pointing at /sitemap-1.xml, /sitemap-2.xml, ... once it outgrows one file.
+func getSitemap(w http.ResponseWriter, r *http.Request) {
+	urls, latest, err := sitemapURLs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(urls) <= maxSitemapURLs {
+		writeXML(w, sitemapURLSet{Xmlns: sitemapXmlns, URLs: urls})
+		return
+	}
+
+	index := sitemapIndex{Xmlns: sitemapXmlns}
+	for n := 1; (n-1)*maxSitemapURLs < len(urls); n++ {
+		chunk := sitemapURL{Loc: fmt.Sprintf("%s/sitemap-%d.xml", siteURL, n)}
+		if !latest.IsZero() {
+			chunk.LastMod = latest.UTC().Format(time.RFC3339)
+		}
+		index.Sitemaps = append(index.Sitemaps, chunk)
+	}
+	writeXML(w, index)
+}
+
+// getSitemapChunk serves /sitemap-{n}.xml, the n-th chunk of the URL set.
+func getSitemapChunk(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(mux.Vars(r)["n"])
+	if err != nil || n < 1 {
+		http.Error(w, "Sitemap not found", http.StatusNotFound)
+		return
+	}
+
+	urls, _, err := sitemapURLs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	start := (n - 1) * maxSitemapURLs
+	if start >= len(urls) {
+		http.Error(w, "Sitemap not found", http.StatusNotFound)
+		return
+	}
+	end := start + maxSitemapURLs
+	if end > len(urls) {
+		end = len(urls)
+	}
+	writeXML(w, sitemapURLSet{Xmlns: sitemapXmlns, URLs: urls[start:end]})
+}
+
+func defaultRobotsTxt() string {
+	return strings.Join([]string{
+		"User-agent: *",
+		"Allow: /",
+		"Disallow: /admin",
+		"Disallow: /api/admin",
+		"Disallow: /account",
+		"Disallow: /boards",
+		"",
+		"Sitemap: " + siteURL + "/sitemap.xml",
+		"",
+	}, "\n")
+}
+
+// getRobotsTxt serves the robots.txt configured in the admin settings.
+func getRobotsTxt(w http.ResponseWriter, r *http.Request) {
+	robots, err := getSetting(settingRobotsTxt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(robots))
+}
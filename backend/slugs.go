@@ -0,0 +1,217 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Tables whose rows carry a unique slug, with the fallback base used when a
+// name has no transliterable letters.
+var slugTables = map[string]string{
+	"products":    "product",
+	"categories":  "category",
+	"collections": "collection",
+	"projects":    "project",
+}
+
+// slugNameColumns names the column slugs are derived from where it is not
+// "name".
+var slugNameColumns = map[string]string{
+	"projects": "hotel_name",
+}
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// slugify transliterates Russian text and reduces it to lowercase latin
+// letters and digits separated by single hyphens.
+func slugify(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		s, ok := cyrillicToLatin[r]
+		switch {
+		case ok:
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			s = string(r)
+		default:
+			hyphen = b.Len() > 0
+			continue
+		}
+		if s == "" {
+			continue
+		}
+		if hyphen {
+			b.WriteByte('-')
+			hyphen = false
+		}
+		b.WriteString(s)
+	}
+	slug := b.String()
+	if len(slug) > 180 {
+		slug = strings.TrimRight(slug[:180], "-")
+	}
+	return slug
+}
+
+// reservedSlugs would be shadowed by fixed routes such as
+// /api/products/featured, or kept free for them.
+var reservedSlugs = []string{"featured", "new", "search"}
+
+var digitsPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// reservedSlug reports whether slug cannot address a page: all-digit slugs
+// are taken for ids by routeEntityID, and reserved words for fixed routes.
+func reservedSlug(slug string) bool {
+	for _, reserved := range reservedSlugs {
+		if slug == reserved {
+			return true
+		}
+	}
+	return digitsPattern.MatchString(slug)
+}
+
+// checkSlug rejects a slug the admin typed in that cannot address a page.
+func checkSlug(slug string) error {
+	if slug != "" && reservedSlug(slug) {
+		return &validationError{msg: "Field \"slug\" cannot be a number or one of the reserved words " + strings.Join(reservedSlugs, ", ")}
+	}
+	return nil
+}
+
+// uniqueSlug returns base, or base with the lowest free numeric suffix, that
+// no other row of table uses. Reserved and all-digit bases get a suffix too.
+func uniqueSlug(table, base string, id int) (string, error) {
+	if base == "" {
+		base = slugTables[table]
+	}
+	return freeSlug(base, func(slug string) (bool, error) {
+		var taken bool
+		err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM "+table+" WHERE slug = $1 AND id <> $2)", slug, id).Scan(&taken)
+		return taken, err
+	})
+}
+
+// freeSlug returns base or base with the lowest numeric suffix that is
+// neither reserved nor taken.
+func freeSlug(base string, taken func(slug string) (bool, error)) (string, error) {
+	slug := base
+	for n := 2; ; n++ {
+		used := reservedSlug(slug)
+		if !used {
+			var err error
+			if used, err = taken(slug); err != nil {
+				return "", err
+			}
+		}
+		if !used {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// assignSlug derives a slug from name when the admin left it empty.
+func assignSlug(table string, id int, name string, slug *string) error {
+	if *slug != "" {
+		return nil
+	}
+	s, err := uniqueSlug(table, slugify(name), id)
+	if err != nil {
+		return err
+	}
+	*slug = s
+	return nil
+}
+
+// slugTaken is the validation message for a unique violation on the slug.
+func slugTaken(table string) string {
+	return fmt.Sprintf("Field \"slug\" is already used by another %s", slugTables[table])
+}
+
+// trackSlugChange records a redirect after a page under prefix moved from
+// oldSlug to newSlug, in the transaction that changed the slug.
+func trackSlugChange(tx *sql.Tx, prefix, oldSlug, newSlug string) error {
+	if oldSlug == "" || oldSlug == newSlug {
+		return nil
+	}
+	return recordSlugRedirect(tx, prefix+oldSlug, prefix+newSlug)
+}
+
+// currentSlug reads the slug of a row about to be updated in tx and locks
+// the row, so that a concurrent save cannot change it in between.
+func currentSlug(tx *sql.Tx, table string, id int) (string, error) {
+	var slug string
+	err := tx.QueryRow("SELECT slug FROM "+table+" WHERE id = $1 FOR UPDATE", id).Scan(&slug)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return slug, err
+}
+
+// routeEntityID resolves the {id} route variable, which may be a numeric id
+// or a slug. It returns sql.ErrNoRows for an unknown slug.
+func routeEntityID(r *http.Request, table string) (int, error) {
+	key := mux.Vars(r)["id"]
+	if id, err := strconv.Atoi(key); err == nil {
+		return id, nil
+	}
+	var id int
+	err := db.QueryRow("SELECT id FROM "+table+" WHERE slug = $1 AND slug <> ''", key).Scan(&id)
+	return id, err
+}
+
+// backfillSlugs gives every row created before slugs existed, or whose slug
+// cannot address a page (see reservedSlug), a slug derived from its name,
+// without bumping versions.
+func backfillSlugs() {
+	for table := range slugTables {
+		nameColumn := slugNameColumns[table]
+		if nameColumn == "" {
+			nameColumn = "name"
+		}
+		rows, err := db.Query("SELECT id, "+nameColumn+" FROM "+table+" WHERE slug = '' OR slug = ANY($1) OR slug ~ '^[0-9]+$' ORDER BY id", pq.Array(reservedSlugs))
+		if err != nil {
+			log.Printf("Error backfilling %s slugs: %v", table, err)
+			continue
+		}
+		type row struct {
+			id   int
+			name string
+		}
+		var pending []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.name); err != nil {
+				log.Printf("Error backfilling %s slugs: %v", table, err)
+				break
+			}
+			pending = append(pending, r)
+		}
+		rows.Close()
+
+		for _, r := range pending {
+			slug, err := uniqueSlug(table, slugify(r.name), r.id)
+			if err == nil {
+				_, err = db.Exec("UPDATE "+table+" SET slug = $1 WHERE id = $2", slug, r.id)
+			}
+			if err != nil {
+				log.Printf("Error backfilling slug of %s %d: %v", table, r.id, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{text: "Oak Chair", want: "oak-chair"},
+		{text: "Кресло «Щука» №5", want: "kreslo-shchuka-5"},
+		{text: "  --Hello,   World!-- ", want: "hello-world"},
+		{text: "Объём и мягкость", want: "obem-i-myagkost"},
+		{text: "Café 2024", want: "caf-2024"},
+		{text: "日本", want: ""},
+		{text: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.text); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	// 300 characters cut at 180, which falls right after a hyphen
+	long := slugify(strings.Repeat("abcdefghi ", 30))
+	if len(long) != 179 || strings.HasSuffix(long, "-") {
+		t.Errorf("long slug %q is not cut to 180 characters without a trailing hyphen", long)
+	}
+}
+
+func TestReservedSlug(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{slug: "featured", want: true},
+		{slug: "new", want: true},
+		{slug: "search", want: true},
+		{slug: "42", want: true},
+		{slug: "0", want: true},
+		{slug: "new-chair", want: false},
+		{slug: "chair-42", want: false},
+		{slug: "42a", want: false},
+		{slug: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := reservedSlug(tt.slug); got != tt.want {
+			t.Errorf("reservedSlug(%q) = %v, want %v", tt.slug, got, tt.want)
+		}
+		var ve *validationError
+		if err := checkSlug(tt.slug); errors.As(err, &ve) != tt.want {
+			t.Errorf("checkSlug(%q) = %v", tt.slug, err)
+		}
+	}
+}
+
+func TestFreeSlug(t *testing.T) {
+	used := map[string]bool{"chair": true, "chair-2": true, "lamp-3": true, "new-2": true}
+	taken := func(slug string) (bool, error) { return used[slug], nil }
+	tests := []struct {
+		base, want string
+	}{
+		{base: "table", want: "table"},
+		{base: "chair", want: "chair-3"},
+		{base: "lamp", want: "lamp"},
+		{base: "new", want: "new-3"},
+		{base: "2024", want: "2024-2"},
+	}
+	for _, tt := range tests {
+		got, err := freeSlug(tt.base, taken)
+		if err != nil || got != tt.want {
+			t.Errorf("freeSlug(%q) = %q, %v; want %q", tt.base, got, err, tt.want)
+		}
+	}
+
+	failure := errors.New("db down")
+	if _, err := freeSlug("chair", func(string) (bool, error) { return false, failure }); err != failure {
+		t.Errorf("lookup error not returned: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Filesystem numbers walk ./uploads and ./dumps, so they are cached for
+// statsCacheTTL; uploads and dumps made through the API invalidate the cache.
+const statsCacheTTL = 10 * time.Minute
+
+type BackupInfo struct {
+	File      string    `json:"file"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+}
+
+type diskStats struct {
+	LastBackup   *BackupInfo `json:"last_backup"`
+	UploadsBytes int64       `json:"uploads_bytes"`
+	UploadsFiles int         `json:"uploads_files"`
+	ComputedAt   time.Time   `json:"computed_at"`
+}
+
+var diskStatsCache struct {
+	sync.Mutex
+	stats *diskStats
+}
+
+// invalidateStatsCache makes the next /admin/stats request rescan the disk.
+func invalidateStatsCache() {
+	diskStatsCache.Lock()
+	diskStatsCache.stats = nil
+	diskStatsCache.Unlock()
+}
+
+func cachedDiskStats() (diskStats, error) {
+	diskStatsCache.Lock()
+	defer diskStatsCache.Unlock()
+	if s := diskStatsCache.stats; s != nil && time.Since(s.ComputedAt) < statsCacheTTL {
+		return *s, nil
+	}
+
+	s := diskStats{ComputedAt: time.Now()}
+	err := filepath.WalkDir("./uploads", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		s.UploadsBytes += info.Size()
+		s.UploadsFiles++
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+
+	entries, err := os.ReadDir("./dumps")
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+	for _, e := range entries {
+		// Uploaded restore files are not backups
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "dump_") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return s, err
+		}
+		if s.LastBackup == nil || info.ModTime().After(s.LastBackup.CreatedAt) {
+			s.LastBackup = &BackupInfo{File: e.Name(), CreatedAt: info.ModTime(), Size: info.Size()}
+		}
+	}
+
+	diskStatsCache.stats = &s
+	return s, nil
+}
+
+type PeriodCount struct {
+	Start string `json:"start"`
+	Count int    `json:"count"`
+}
+
+type ProductRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// countPeriods counts contacts per day or week (unit) for the last n periods,
+// oldest first, including periods without contacts.
+func countPeriods(unit string, n int) ([]PeriodCount, error) {
+	step := "1 " + unit
+	rows, err := db.Query(`SELECT to_char(p.start, 'YYYY-MM-DD'), COUNT(c.id)
+		FROM generate_series(date_trunc('`+unit+`', CURRENT_DATE) - ($1 - 1) * INTERVAL '`+step+`', date_trunc('`+unit+`', CURRENT_DATE), INTERVAL '`+step+`') AS p(start)
+		LEFT JOIN contacts c ON c.created_at >= p.start AND c.created_at < p.start + INTERVAL '`+step+`'
+		GROUP BY p.start ORDER BY p.start`, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []PeriodCount{}
+	for rows.Next() {
+		var pc PeriodCount
+		if err := rows.Scan(&pc.Start, &pc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, pc)
+	}
+	return counts, rows.Err()
+}
+
+func queryProductRefs(condition string) ([]ProductRef, error) {
+	rows, err := db.Query("SELECT id, name FROM products WHERE deleted_at IS NULL AND (" + condition + ") ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs := []ProductRef{}
+	for rows.Next() {
+		var p ProductRef
+		if err := rows.Scan(&p.ID, &p.Name); err != nil {
+			return nil, err
+		}
+		refs = append(refs, p)
+	}
+	return refs, rows.Err()
+}
+
+// trendPercent compares the last complete period with the one before it.
+func trendPercent(counts []PeriodCount) *float64 {
+	if len(counts) < 3 {
+		return nil
+	}
+	last, prev := counts[len(counts)-2].Count, counts[len(counts)-3].Count
+	if prev == 0 {
+		return nil
+	}
+	trend := roundPrice(float64(last-prev) / float64(prev) * 100)
+	return &trend
+}
+
+// getStats is the admin dashboard summary. Trends compare the last complete
+// day and week with the ones before; the current period is still filling up.
+func getStats(w http.ResponseWriter, r *http.Request) {
+	counts := map[string]int{}
+	for name, query := range map[string]string{
+		"products":           "SELECT COUNT(*) FROM products WHERE deleted_at IS NULL",
+		"published_products": "SELECT COUNT(*) FROM products WHERE deleted_at IS NULL AND status = '" + statusPublished + "'",
+		"categories":         "SELECT COUNT(*) FROM categories WHERE deleted_at IS NULL",
+		"collections":        "SELECT COUNT(*) FROM collections WHERE deleted_at IS NULL",
+		"contacts":           "SELECT COUNT(*) FROM contacts",
+		"unhandled_leads":    "SELECT COUNT(*) FROM contacts WHERE NOT handled",
+	} {
+		var n int
+		if err := db.QueryRow(query).Scan(&n); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		counts[name] = n
+	}
+
+	perDay, err := countPeriods("day", 14)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	perWeek, err := countPeriods("week", 8)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	missingImages, err := queryProductRefs("COALESCE(image, '') = '' AND COALESCE(images, '') IN ('', '[]', 'null')")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	missingDescriptions, err := queryProductRefs("TRIM(COALESCE(description, '')) = ''")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	disk, err := cachedDiskStats()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"counts":          counts,
+		"unhandled_leads": counts["unhandled_leads"],
+		"contacts": map[string]interface{}{
+			"per_day":    perDay,
+			"per_week":   perWeek,
+			"today":      perDay[len(perDay)-1].Count,
+			"this_week":  perWeek[len(perWeek)-1].Count,
+			"day_trend":  trendPercent(perDay),
+			"week_trend": trendPercent(perWeek),
+		},
+		"last_backup":                  disk.LastBackup,
+		"uploads_bytes":                disk.UploadsBytes,
+		"uploads_files":                disk.UploadsFiles,
+		"disk_computed_at":             disk.ComputedAt,
+		"products_missing_images":      missingImages,
+		"products_missing_description": missingDescriptions,
+	})
+}
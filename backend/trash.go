@@ -0,0 +1,225 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// softDeleteTables are the tables whose rows go to the trash bin instead of
+// being deleted. The value is the column shown as the item name in the trash.
+var softDeleteTables = map[string]string{
+	"products":    "name",
+	"categories":  "name",
+	"collections": "name",
+}
+
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// trashRetention is how long trashed items are kept before the purge job
+// removes them for good. Configured with TRASH_RETENTION_DAYS (default 30).
+func trashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// softDeleteVersioned moves the row with id to the trash if its version matches.
+func softDeleteVersioned(table string, id, version int) error {
+	result, err := db.Exec(
+		"UPDATE "+table+" SET deleted_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL AND ($2 = -1 OR version = $2)",
+		id, version,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return versionConflict(table, id)
+	}
+	if table == "products" {
+		refreshProductCollectionCounts(id)
+	}
+	return nil
+}
+
+// refreshCollectionCount recomputes the cached product count of a collection,
+// ignoring trashed products.
+func refreshCollectionCount(collectionID int) {
+	_, err := db.Exec(`UPDATE collections SET count = (
+		SELECT COUNT(*) FROM collection_products cp
+		INNER JOIN products p ON p.id = cp.product_id
+		WHERE cp.collection_id = $1 AND p.deleted_at IS NULL
+	) WHERE id = $1`, collectionID)
+	if err != nil {
+		log.Printf("Error updating collection count: %v", err)
+	}
+}
+
+// refreshProductCollectionCounts recomputes the counts of every collection
+// the product belongs to.
+func refreshProductCollectionCounts(productID int) {
+	rows, err := db.Query("SELECT collection_id FROM collection_products WHERE product_id = $1", productID)
+	if err != nil {
+		log.Printf("Error loading product collections: %v", err)
+		return
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if rows.Scan(&id) == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		refreshCollectionCount(id)
+	}
+}
+
+func getTrash(w http.ResponseWriter, r *http.Request) {
+	types := []string{"products", "categories", "collections"}
+	if t := r.URL.Query().Get("type"); t != "" {
+		if _, ok := softDeleteTables[t]; !ok {
+			http.Error(w, "Invalid trash type", http.StatusBadRequest)
+			return
+		}
+		types = []string{t}
+	}
+
+	retention := trashRetention()
+	items := []TrashItem{}
+	for _, table := range types {
+		rows, err := db.Query("SELECT id, " + softDeleteTables[table] + ", deleted_at FROM " + table + " WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for rows.Next() {
+			item := TrashItem{Type: table}
+			if err := rows.Scan(&item.ID, &item.Name, &item.DeletedAt); err != nil {
+				rows.Close()
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			item.PurgeAt = item.DeletedAt.Add(retention)
+			items = append(items, item)
+		}
+		rows.Close()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// trashTarget validates the {type} and {id} route variables of trash endpoints.
+func trashTarget(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	vars := mux.Vars(r)
+	table := vars["type"]
+	if _, ok := softDeleteTables[table]; !ok {
+		http.Error(w, "Invalid trash type", http.StatusBadRequest)
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return table, id, true
+}
+
+func restoreFromTrash(w http.ResponseWriter, r *http.Request) {
+	table, id, ok := trashTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var version int
+	err := db.QueryRow(
+		"UPDATE "+table+" SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NOT NULL RETURNING version",
+		id,
+	).Scan(&version)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Item not found in trash", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Collection memberships are never removed on soft delete, so restoring
+	// either side brings them back; only the cached counts need refreshing.
+	switch table {
+	case "products":
+		refreshProductCollectionCounts(id)
+	case "collections":
+		refreshCollectionCount(id)
+	}
+
+	setETag(w, version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "restored", "type": table, "id": id, "version": version})
+}
+
+func purgeFromTrash(w http.ResponseWriter, r *http.Request) {
+	table, id, ok := trashTarget(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM "+table+" WHERE id = $1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		http.Error(w, "Item not found in trash", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// purgeExpiredTrash permanently deletes items that have been in the trash
+// longer than the retention period.
+func purgeExpiredTrash() {
+	cutoff := time.Now().Add(-trashRetention())
+	for table := range softDeleteTables {
+		result, err := db.Exec("DELETE FROM "+table+" WHERE deleted_at IS NOT NULL AND deleted_at < $1", cutoff)
+		if err != nil {
+			log.Printf("Error purging %s trash: %v", table, err)
+			continue
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			log.Printf("Purged %d %s from trash", n, table)
+		}
+	}
+}
+
+func startTrashPurger() {
+	go func() {
+		for {
+			purgeExpiredTrash()
+			time.Sleep(time.Hour)
+		}
+	}()
+}
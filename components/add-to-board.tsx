@@ -0,0 +1,113 @@
+'use client'
+
+import { useState } from 'react'
+import Link from 'next/link'
+import { Bookmark } from 'lucide-react'
+import { boardHeaders, setBoardToken } from '@/lib/customer-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface BoardSummary {
+  id: number
+  name: string
+}
+
+export default function AddToBoard({ productId }: { productId: number }) {
+  const [open, setOpen] = useState(false)
+  const [boards, setBoards] = useState<BoardSummary[]>([])
+  const [newName, setNewName] = useState('')
+  const [added, setAdded] = useState<string | null>(null)
+
+  const toggle = async () => {
+    if (!open) {
+      const res = await fetch(`${API_URL}/boards`, { headers: boardHeaders() })
+      if (res.ok) {
+        setBoards(await res.json())
+      }
+    }
+    setOpen(!open)
+  }
+
+  const addTo = async (board: BoardSummary) => {
+    const res = await fetch(`${API_URL}/boards/${board.id}/items/${productId}`, {
+      method: 'PUT',
+      headers: {
+        'Content-Type': 'application/json',
+        ...boardHeaders(),
+      },
+      body: JSON.stringify({ quantity: 1 }),
+    })
+    if (res.ok) {
+      setAdded(board.name)
+      setOpen(false)
+    } else {
+      alert('Не удалось добавить товар в подборку')
+    }
+  }
+
+  const createAndAdd = async (e: React.FormEvent) => {
+    e.preventDefault()
+    const res = await fetch(`${API_URL}/boards`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...boardHeaders(),
+      },
+      body: JSON.stringify({ name: newName }),
+    })
+    if (!res.ok) {
+      alert('Не удалось создать подборку')
+      return
+    }
+    const board = await res.json()
+    if (board.owner_token) {
+      setBoardToken(board.owner_token)
+    }
+    setNewName('')
+    await addTo(board)
+  }
+
+  return (
+    <div className="relative">
+      <button
+        onClick={toggle}
+        className="flex items-center gap-2 px-6 py-3 border border-border rounded-lg text-foreground hover:bg-muted transition"
+      >
+        <Bookmark className="w-5 h-5" />
+        В подборку
+      </button>
+
+      {added && (
+        <p className="text-sm text-muted-foreground mt-2">
+          Добавлено в «{added}». <Link href="/boards" className="text-primary hover:underline">Мои подборки</Link>
+        </p>
+      )}
+
+      {open && (
+        <div className="absolute z-10 mt-2 w-72 bg-card border border-border rounded-lg shadow-lg p-4 space-y-3">
+          {boards.map((board) => (
+            <button
+              key={board.id}
+              onClick={() => addTo(board)}
+              className="block w-full text-left px-3 py-2 rounded hover:bg-muted text-foreground"
+            >
+              {board.name}
+            </button>
+          ))}
+          <form onSubmit={createAndAdd} className="flex gap-2">
+            <input
+              required
+              placeholder="Новая подборка"
+              value={newName}
+              onChange={(e) => setNewName(e.target.value)}
+              className="flex-1 px-3 py-2 border border-border rounded bg-background text-foreground text-sm"
+            />
+            <button type="submit" className="px-3 py-2 bg-primary text-primary-foreground rounded text-sm">
+              Создать
+            </button>
+          </form>
+        </div>
+      )}
+    </div>
+  )
+}
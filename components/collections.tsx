@@ -8,6 +8,7 @@ const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
 interface Collection {
   id: number
+  slug?: string
   name: string
   description: string
   image: string
@@ -79,7 +80,7 @@ export default function Collections() {
           {collections.map((collection) => (
             <Link
               key={collection.id}
-              href={`/collections/${collection.id}`}
+              href={`/collections/${collection.slug || collection.id}`}
               className="group relative h-64 sm:h-72 md:h-80 rounded-xl overflow-hidden"
             >
               <img
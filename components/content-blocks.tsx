@@ -0,0 +1,149 @@
+import Link from 'next/link'
+import { ArrowRight } from 'lucide-react'
+import type { ReactNode } from 'react'
+import type { ContentBlock, ContentImage, PageSection } from '@/lib/content'
+
+// Links allowed in blocks: site paths, http(s), mailto: and tel: (the backend
+// checks the same rule when blocks are saved)
+function isSafeLink(url: string | undefined): url is string {
+  return !!url && /^(\/([^/]|$)|https?:\/\/|mailto:|tel:)/.test(url)
+}
+
+// Renders the inline markup of rich text blocks: **bold**, *italic* and
+// [label](url). Everything else is plain text, so no HTML reaches the page.
+function renderInline(text: string): ReactNode[] {
+  const nodes: ReactNode[] = []
+  const pattern = /\*\*(.+?)\*\*|\*(.+?)\*|\[(.+?)\]\((\S+?)\)/g
+  let last = 0
+  let match: RegExpExecArray | null
+  while ((match = pattern.exec(text)) !== null) {
+    if (match.index > last) nodes.push(text.slice(last, match.index))
+    const key = match.index
+    if (match[1] !== undefined) {
+      nodes.push(<strong key={key}>{match[1]}</strong>)
+    } else if (match[2] !== undefined) {
+      nodes.push(<em key={key}>{match[2]}</em>)
+    } else if (isSafeLink(match[4])) {
+      nodes.push(
+        <Link key={key} href={match[4]} className="text-primary underline hover:no-underline">
+          {match[3]}
+        </Link>
+      )
+    } else {
+      nodes.push(match[3])
+    }
+    last = pattern.lastIndex
+  }
+  if (last < text.length) nodes.push(text.slice(last))
+  return nodes
+}
+
+function RichText({ title, text }: { title?: string; text: string }) {
+  return (
+    <div className="max-w-3xl mx-auto space-y-4 sm:space-y-6 text-center">
+      {title && <h3 className="text-2xl sm:text-3xl font-serif font-bold text-foreground">{title}</h3>}
+      {text.split(/\n\s*\n/).map((paragraph, idx) => (
+        <p key={idx} className="text-base sm:text-lg text-muted-foreground leading-relaxed px-4 whitespace-pre-line">
+          {renderInline(paragraph.trim())}
+        </p>
+      ))}
+    </div>
+  )
+}
+
+function Figure({ image, className }: { image: ContentImage; className?: string }) {
+  const img = <img src={image.url} alt={image.alt || ''} className="w-full h-full object-cover rounded-lg" />
+  return (
+    <figure className={className}>
+      {isSafeLink(image.link) ? <Link href={image.link}>{img}</Link> : img}
+      {image.caption && <figcaption className="mt-2 text-sm text-muted-foreground text-center">{image.caption}</figcaption>}
+    </figure>
+  )
+}
+
+function Carousel({ title, images }: { title?: string; images: ContentImage[] }) {
+  return (
+    <div>
+      {title && <h2 className="text-3xl md:text-4xl font-serif font-bold text-foreground mb-8 text-center">{title}</h2>}
+      <div className="flex gap-4 overflow-x-auto snap-x snap-mandatory pb-4">
+        {images.map((image, idx) => (
+          <Figure key={idx} image={image} className="snap-start shrink-0 w-4/5 sm:w-1/2 lg:w-1/3 aspect-[4/3]" />
+        ))}
+      </div>
+    </div>
+  )
+}
+
+function KeyFacts({ title, facts }: { title?: string; facts: { value?: string; label: string }[] }) {
+  return (
+    <div>
+      {title && <h2 className="text-3xl md:text-4xl font-serif font-bold text-foreground mb-8 text-center">{title}</h2>}
+      <div className="flex flex-wrap justify-center gap-4 sm:gap-6">
+        {facts.map((fact, idx) => (
+          <div key={idx} className="bg-card border border-border rounded-lg p-4 sm:p-6 text-center w-full sm:w-64 hover:shadow-lg transition">
+            {fact.value && <div className="text-2xl sm:text-3xl md:text-4xl font-bold text-primary mb-1 sm:mb-2">{fact.value}</div>}
+            <div className="text-sm sm:text-base text-foreground">{fact.label}</div>
+          </div>
+        ))}
+      </div>
+    </div>
+  )
+}
+
+function CTA({ title, text, image, button_label, button_url }: { title: string; text?: string; image?: string; button_label: string; button_url: string }) {
+  const onImage = Boolean(image)
+  return (
+    <div className={`relative overflow-hidden rounded-lg ${onImage ? 'py-20 md:py-32' : 'py-12 bg-muted/30'}`}>
+      {image && (
+        <div className="absolute inset-0 z-0">
+          <img src={image} alt="" className="w-full h-full object-cover" />
+          <div className="absolute inset-0 bg-black/50 dark:bg-black/60"></div>
+        </div>
+      )}
+      <div className="relative z-10 max-w-2xl mx-auto px-4 text-center space-y-6">
+        <h2 className={`text-4xl md:text-5xl font-serif font-bold leading-tight text-balance ${onImage ? 'text-white' : 'text-foreground'}`}>
+          {title}
+        </h2>
+        {text && <p className={`text-lg leading-relaxed ${onImage ? 'text-white/90' : 'text-muted-foreground'}`}>{text}</p>}
+        {isSafeLink(button_url) && (
+          <Link
+            href={button_url}
+            className="inline-flex items-center justify-center gap-2 px-6 py-3 bg-primary text-primary-foreground font-medium rounded-lg hover:opacity-90 transition"
+          >
+            {button_label}
+            <ArrowRight className="w-4 h-4" />
+          </Link>
+        )}
+      </div>
+    </div>
+  )
+}
+
+export function Block({ block }: { block: ContentBlock }) {
+  switch (block.type) {
+    case 'rich_text':
+      return <RichText {...block.data} />
+    case 'image':
+      return <Figure image={block.data} className="max-w-4xl mx-auto" />
+    case 'carousel':
+      return <Carousel {...block.data} />
+    case 'key_facts':
+      return <KeyFacts {...block.data} />
+    case 'cta':
+      return <CTA {...block.data} />
+    default:
+      return null
+  }
+}
+
+export function ContentSection({ section }: { section: PageSection }) {
+  return (
+    <section className="py-16 md:py-24">
+      <div className="max-w-7xl mx-auto px-4 sm:px-6 lg:px-8 space-y-12">
+        {section.blocks.map((block) => (
+          <Block key={block.id} block={block} />
+        ))}
+      </div>
+    </section>
+  )
+}
@@ -0,0 +1,87 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import { faqGroupLabels } from '@/components/faq'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export interface FAQLinks {
+  group: string
+  product_ids: number[]
+  category_ids: number[]
+}
+
+interface Option {
+  id: number
+  name: string
+}
+
+interface FAQLinkFieldsProps {
+  value: FAQLinks
+  onChange: (value: FAQLinks) => void
+}
+
+// Group of an FAQ item and the products and categories it is shown on,
+// shared by the create and edit pages
+export function FAQLinkFields({ value, onChange }: FAQLinkFieldsProps) {
+  const [products, setProducts] = useState<Option[]>([])
+  const [categories, setCategories] = useState<Option[]>([])
+
+  useEffect(() => {
+    adminFetch(`${API_URL}/admin/products`).then(res => res.json()).then(data => setProducts(data || [])).catch(console.error)
+    adminFetch(`${API_URL}/admin/categories`).then(res => res.json()).then(data => setCategories(data || [])).catch(console.error)
+  }, [])
+
+  const toggle = (field: 'product_ids' | 'category_ids', id: number) => {
+    const ids = value[field]
+    onChange({ ...value, [field]: ids.includes(id) ? ids.filter(i => i !== id) : [...ids, id] })
+  }
+
+  const picker = (field: 'product_ids' | 'category_ids', options: Option[], title: string) => (
+    <div>
+      <label className="block text-sm font-medium text-foreground mb-2">
+        {title} ({value[field].length})
+      </label>
+      <div className="max-h-48 overflow-y-auto border border-border rounded-lg p-3 space-y-1">
+        {options.map((option) => (
+          <label key={option.id} className="flex items-center gap-3 p-1 hover:bg-muted rounded cursor-pointer">
+            <input
+              type="checkbox"
+              checked={value[field].includes(option.id)}
+              onChange={() => toggle(field, option.id)}
+              className="w-4 h-4 accent-primary"
+            />
+            <span className="text-sm text-foreground">{option.name}</span>
+          </label>
+        ))}
+      </div>
+    </div>
+  )
+
+  return (
+    <>
+      <div>
+        <label htmlFor="group" className="block text-sm font-medium text-foreground mb-2">
+          Раздел
+        </label>
+        <select
+          id="group"
+          value={value.group}
+          onChange={(e) => onChange({ ...value, group: e.target.value })}
+          className="w-full px-4 py-2 bg-background border border-border rounded-lg text-foreground focus:outline-none focus:ring-2 focus:ring-primary"
+        >
+          <option value="">Общие вопросы</option>
+          {Object.entries(faqGroupLabels).map(([group, label]) => (
+            <option key={group} value={group}>{label}</option>
+          ))}
+        </select>
+      </div>
+
+      <div className="grid md:grid-cols-2 gap-6">
+        {picker('product_ids', products, 'Показывать на страницах товаров')}
+        {picker('category_ids', categories, 'Показывать для категорий')}
+      </div>
+    </>
+  )
+}
@@ -1,13 +1,20 @@
 'use client'
 
 import { useState, useEffect } from 'react'
-import { ChevronDown, HelpCircle } from 'lucide-react'
+import { ChevronDown, HelpCircle, Search } from 'lucide-react'
 
 interface FAQItem {
   id: number
   question: string
   answer: string
   order: number
+  group: string
+}
+
+export const faqGroupLabels: Record<string, string> = {
+  delivery: 'Доставка',
+  payment: 'Оплата',
+  warranty: 'Гарантия',
 }
 
 function getApiUrl() {
@@ -17,16 +24,39 @@ function getApiUrl() {
   return process.env.NEXT_PUBLIC_API_URL || 'http://backend:8080/api'
 }
 
-export default function FAQ() {
+interface FAQProps {
+  // Show only the questions attached to this product or its category
+  productId?: number
+}
+
+export default function FAQ({ productId }: FAQProps) {
   const [openIndex, setOpenIndex] = useState<number | null>(null)
   const [faqs, setFaqs] = useState<FAQItem[]>([])
   const [loading, setLoading] = useState(true)
+  const [group, setGroup] = useState('')
+  const [search, setSearch] = useState('')
+  const [query, setQuery] = useState('')
+
+  // Wait for a pause in typing before searching
+  useEffect(() => {
+    const timer = setTimeout(() => setQuery(search.trim()), 300)
+    return () => clearTimeout(timer)
+  }, [search])
 
   useEffect(() => {
     const fetchFAQs = async () => {
       try {
         const apiUrl = getApiUrl()
-        const res = await fetch(`${apiUrl}/faqs`, { 
+        const params = new URLSearchParams()
+        if (group) params.set('group', group)
+        let path = '/faqs'
+        if (productId) {
+          path = `/products/${productId}/faqs`
+        } else if (query) {
+          path = '/faqs/search'
+          params.set('q', query)
+        }
+        const res = await fetch(`${apiUrl}${path}?${params}`, { 
           cache: 'no-store',
           headers: {
             'Content-Type': 'application/json',
@@ -35,16 +65,8 @@ export default function FAQ() {
         
         if (res.ok) {
           const data = await res.json()
-          // Сортируем FAQ по полю order, затем по id
-          const sortedFaqs = Array.isArray(data) 
-            ? data.sort((a: FAQItem, b: FAQItem) => {
-                if (a.order !== b.order) {
-                  return a.order - b.order
-                }
-                return a.id - b.id
-              })
-            : []
-          setFaqs(sortedFaqs)
+          // Порядок задаёт сервер; при поиске совпадения в вопросе идут первыми
+          setFaqs(Array.isArray(data) ? data : [])
         } else {
           console.error('Failed to fetch FAQs:', res.status, res.statusText)
           setFaqs([])
@@ -57,13 +79,44 @@ export default function FAQ() {
       }
     }
 
+    setOpenIndex(null)
     fetchFAQs()
-  }, [])
+  }, [productId, group, query])
 
   const toggleFAQ = (index: number) => {
     setOpenIndex(openIndex === index ? null : index)
   }
 
+  if (productId) {
+    if (faqs.length === 0) return null
+
+    return (
+      <div className="mb-12 sm:mb-16">
+        <h2 className="text-xl sm:text-2xl font-serif font-bold text-foreground mb-4 sm:mb-6">Вопросы и ответы</h2>
+        <div className="space-y-3 sm:space-y-4">
+          {faqs.map((faq, index) => (
+            <div key={faq.id} className="bg-card border border-border rounded-lg overflow-hidden">
+              <button
+                onClick={() => toggleFAQ(index)}
+                className="w-full px-4 sm:px-6 py-4 flex items-center justify-between text-left gap-3 hover:bg-muted/50 transition"
+              >
+                <span className="text-sm sm:text-base font-semibold text-foreground">{faq.question}</span>
+                <ChevronDown
+                  className={`w-4 h-4 sm:w-5 sm:h-5 text-muted-foreground flex-shrink-0 transition-transform ${
+                    openIndex === index ? 'rotate-180' : ''
+                  }`}
+                />
+              </button>
+              {openIndex === index && (
+                <p className="px-4 sm:px-6 pb-4 text-sm sm:text-base text-muted-foreground leading-relaxed">{faq.answer}</p>
+              )}
+            </div>
+          ))}
+        </div>
+      </div>
+    )
+  }
+
   if (loading) {
     return (
       <section className="py-12 sm:py-16 md:py-24 bg-background">
@@ -91,9 +144,37 @@ export default function FAQ() {
           </p>
         </div>
 
+        <div className="flex flex-col sm:flex-row gap-3 mb-6 sm:mb-8">
+          <div className="flex flex-wrap gap-2">
+            {[['', 'Все'], ...Object.entries(faqGroupLabels)].map(([value, label]) => (
+              <button
+                key={value}
+                onClick={() => setGroup(value)}
+                className={`px-4 py-2 rounded-lg text-sm font-medium transition ${
+                  group === value ? 'bg-primary text-primary-foreground' : 'bg-muted text-foreground hover:bg-muted/70'
+                }`}
+              >
+                {label}
+              </button>
+            ))}
+          </div>
+          <div className="relative sm:ml-auto">
+            <Search className="w-4 h-4 absolute left-3 top-1/2 -translate-y-1/2 text-muted-foreground" />
+            <input
+              type="search"
+              value={search}
+              onChange={(e) => setSearch(e.target.value)}
+              placeholder="Поиск по вопросам"
+              className="w-full sm:w-64 pl-9 pr-4 py-2 border border-border rounded-lg bg-background text-foreground text-sm"
+            />
+          </div>
+        </div>
+
         {faqs.length === 0 ? (
           <div className="text-center py-12">
-            <p className="text-muted-foreground">Вопросы пока не добавлены</p>
+            <p className="text-muted-foreground">
+              {query || group ? 'Ничего не найдено' : 'Вопросы пока не добавлены'}
+            </p>
           </div>
         ) : (
           <div className="space-y-3 sm:space-y-4">
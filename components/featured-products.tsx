@@ -8,6 +8,7 @@ const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
 
 interface Product {
   id: number
+  slug?: string
   name: string
   category: string
   price: number
@@ -81,7 +82,7 @@ export default function FeaturedProducts() {
             return (
             <Link
               key={product.id}
-              href={`/products/${product.id}`}
+              href={`/products/${product.slug || product.id}`}
               className="group bg-card border border-border rounded-lg overflow-hidden hover:shadow-lg transition"
             >
               <div className="relative h-48 sm:h-56 md:h-64 bg-muted overflow-hidden">
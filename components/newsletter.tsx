@@ -3,16 +3,32 @@
 import { useState } from 'react'
 import { Mail, ArrowRight } from 'lucide-react'
 
-export default function Newsletter() {
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export default function Newsletter({ source = 'home' }: { source?: string }) {
   const [email, setEmail] = useState('')
-  const [subscribed, setSubscribed] = useState(false)
+  const [status, setStatus] = useState<'idle' | 'sending' | 'subscribed' | 'failed'>('idle')
 
-  const handleSubmit = (e: React.FormEvent) => {
+  const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
-    if (email) {
-      setSubscribed(true)
-      setEmail('')
-      setTimeout(() => setSubscribed(false), 3000)
+    if (!email) return
+
+    setStatus('sending')
+    try {
+      const res = await fetch(`${API_URL}/newsletter/subscribe`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ email, source }),
+      })
+      if (res.ok) {
+        setStatus('subscribed')
+        setEmail('')
+      } else {
+        setStatus('failed')
+      }
+    } catch (error) {
+      console.error('Error subscribing to newsletter:', error)
+      setStatus('failed')
     }
   }
 
@@ -41,13 +57,25 @@ export default function Newsletter() {
             />
             <button
               type="submit"
-              className="px-6 py-3 bg-accent text-accent-foreground font-medium rounded-lg hover:opacity-90 transition flex items-center justify-center gap-2 whitespace-nowrap"
+              disabled={status === 'sending'}
+              className="px-6 py-3 bg-accent text-accent-foreground font-medium rounded-lg hover:opacity-90 transition flex items-center justify-center gap-2 whitespace-nowrap disabled:opacity-50"
             >
-              {subscribed ? 'Спасибо!' : 'Подписаться'}
-              {!subscribed && <ArrowRight className="w-4 h-4" />}
+              {status === 'sending' ? 'Отправка...' : 'Подписаться'}
+              {status !== 'sending' && <ArrowRight className="w-4 h-4" />}
             </button>
           </form>
 
+          {status === 'subscribed' && (
+            <p className="text-primary-foreground">
+              Спасибо! Мы отправили письмо со ссылкой — подтвердите подписку, чтобы получать рассылку.
+            </p>
+          )}
+          {status === 'failed' && (
+            <p className="text-primary-foreground">
+              Не удалось оформить подписку. Проверьте адрес и попробуйте ещё раз.
+            </p>
+          )}
+
           <p className="text-sm text-primary-foreground/60">
             Мы уважаем вашу конфиденциальность. Отписаться можно в любое время.
           </p>
@@ -0,0 +1,75 @@
+'use client'
+
+import { toLocalInput } from '@/components/publication-fields'
+
+export interface PlaceholderRule {
+  priority: number
+  starts_at: string // datetime-local value, '' when not limited
+  ends_at: string
+}
+
+export const defaultPlaceholderRule: PlaceholderRule = {
+  priority: 0,
+  starts_at: '',
+  ends_at: '',
+}
+
+export function placeholderRuleFromEntity(entity: { priority?: number; starts_at?: string | null; ends_at?: string | null }): PlaceholderRule {
+  return {
+    priority: entity.priority || 0,
+    starts_at: toLocalInput(entity.starts_at),
+    ends_at: toLocalInput(entity.ends_at),
+  }
+}
+
+export function placeholderRulePayload(rule: PlaceholderRule) {
+  return {
+    priority: rule.priority,
+    starts_at: rule.starts_at ? new Date(rule.starts_at).toISOString() : null,
+    ends_at: rule.ends_at ? new Date(rule.ends_at).toISOString() : null,
+  }
+}
+
+interface PlaceholderRuleFieldsProps {
+  value: PlaceholderRule
+  onChange: (value: PlaceholderRule) => void
+}
+
+export function PlaceholderRuleFields({ value, onChange }: PlaceholderRuleFieldsProps) {
+  return (
+    <div>
+      <div className="grid md:grid-cols-3 gap-6">
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Приоритет</label>
+          <input
+            type="number"
+            value={value.priority}
+            onChange={(e) => onChange({ ...value, priority: parseInt(e.target.value) || 0 })}
+            className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+        </div>
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Показывать с</label>
+          <input
+            type="datetime-local"
+            value={value.starts_at}
+            onChange={(e) => onChange({ ...value, starts_at: e.target.value })}
+            className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+        </div>
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Показывать до</label>
+          <input
+            type="datetime-local"
+            value={value.ends_at}
+            onChange={(e) => onChange({ ...value, ends_at: e.target.value })}
+            className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+        </div>
+      </div>
+      <p className="mt-1 text-xs text-muted-foreground">
+        Если путь подходит под несколько заглушек, показывается заглушка с большим приоритетом
+      </p>
+    </div>
+  )
+}
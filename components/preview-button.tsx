@@ -0,0 +1,56 @@
+'use client'
+
+import { useState } from 'react'
+import { Eye } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+// Opens a page of the site with a fresh preview token, so drafts and
+// scheduled items can be checked before they are published
+export function PreviewButton({ path }: { path: string }) {
+  const [opening, setOpening] = useState(false)
+
+  const openPreview = async () => {
+    // Opened before the request so the browser does not block the pop-up
+    const win = window.open('', '_blank')
+    setOpening(true)
+    try {
+      const res = await adminFetch(`${API_URL}/admin/preview-token`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ ttl_hours: 24 }),
+      })
+      if (!res.ok) {
+        win?.close()
+        alert('Ошибка при создании ссылки для предпросмотра')
+        return
+      }
+      const data = await res.json()
+      const url = `${path}?preview=${encodeURIComponent(data.token)}`
+      if (win) {
+        win.location.href = url
+      } else {
+        window.location.href = url
+      }
+    } catch (error) {
+      win?.close()
+      console.error('Error creating preview token:', error)
+      alert('Ошибка при создании ссылки для предпросмотра')
+    } finally {
+      setOpening(false)
+    }
+  }
+
+  return (
+    <button
+      type="button"
+      onClick={openPreview}
+      disabled={opening}
+      className="flex items-center gap-2 px-4 py-2 border border-border rounded-lg hover:bg-muted transition disabled:opacity-50"
+    >
+      <Eye className="w-4 h-4" />
+      Предпросмотр
+    </button>
+  )
+}
@@ -0,0 +1,75 @@
+'use client'
+
+export const currencies = ['RUB', 'EUR', 'USD', 'AED']
+
+export interface Pricing {
+  currency: string
+  prices: Record<string, string> // manual overrides, '' when converted by rate
+}
+
+export const defaultPricing: Pricing = {
+  currency: 'RUB',
+  prices: {},
+}
+
+export function pricingFromEntity(entity: { currency?: string; prices?: Record<string, number> | null }): Pricing {
+  const prices: Record<string, string> = {}
+  for (const [code, price] of Object.entries(entity.prices || {})) {
+    prices[code] = price.toString()
+  }
+  return {
+    currency: entity.currency || 'RUB',
+    prices,
+  }
+}
+
+export function pricingPayload(pricing: Pricing) {
+  const prices: Record<string, number> = {}
+  for (const [code, price] of Object.entries(pricing.prices)) {
+    if (code !== pricing.currency && price !== '') {
+      prices[code] = parseFloat(price)
+    }
+  }
+  return {
+    currency: pricing.currency,
+    prices,
+  }
+}
+
+interface PricingFieldsProps {
+  value: Pricing
+  onChange: (value: Pricing) => void
+}
+
+export function PricingFields({ value, onChange }: PricingFieldsProps) {
+  return (
+    <div className="grid md:grid-cols-4 gap-6">
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">Валюта цены</label>
+        <select
+          value={value.currency}
+          onChange={(e) => onChange({ ...value, currency: e.target.value })}
+          className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+        >
+          {currencies.map((code) => (
+            <option key={code} value={code}>{code}</option>
+          ))}
+        </select>
+      </div>
+      {currencies.filter((code) => code !== value.currency).map((code) => (
+        <div key={code}>
+          <label className="block text-sm font-medium text-foreground mb-2">Цена в {code}</label>
+          <input
+            type="number"
+            step="0.01"
+            min="0"
+            placeholder="По курсу"
+            value={value.prices[code] || ''}
+            onChange={(e) => onChange({ ...value, prices: { ...value.prices, [code]: e.target.value } })}
+            className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+        </div>
+      ))}
+    </div>
+  )
+}
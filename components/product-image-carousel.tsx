@@ -2,13 +2,15 @@
 
 import { useState } from 'react'
 import { ChevronLeft, ChevronRight } from 'lucide-react'
+import { track } from '@/lib/analytics'
 
 interface ProductImageCarouselProps {
   images: string[]
   productName: string
+  productId?: number
 }
 
-export default function ProductImageCarousel({ images, productName }: ProductImageCarouselProps) {
+export default function ProductImageCarousel({ images, productName, productId }: ProductImageCarouselProps) {
   const [currentIndex, setCurrentIndex] = useState(0)
 
   if (images.length === 0) {
@@ -23,16 +25,25 @@ export default function ProductImageCarousel({ images, productName }: ProductIma
     )
   }
 
+  const trackGallery = (action: string) => {
+    if (productId) {
+      track('gallery_interaction', { product_id: productId, data: { action } })
+    }
+  }
+
   const nextImage = () => {
     setCurrentIndex((prev) => (prev + 1) % images.length)
+    trackGallery('next')
   }
 
   const prevImage = () => {
     setCurrentIndex((prev) => (prev - 1 + images.length) % images.length)
+    trackGallery('prev')
   }
 
   const goToImage = (index: number) => {
     setCurrentIndex(index)
+    trackGallery('thumbnail')
   }
 
   return (
@@ -0,0 +1,114 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import { ArrowUp, Trash2, Save } from 'lucide-react'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface Relation {
+  product_id: number
+  kind: 'accessory' | 'matching'
+}
+
+const kindLabels: Record<Relation['kind'], string> = {
+  accessory: 'Аксессуар',
+  matching: 'Сочетается',
+}
+
+// Curated "complete the room" suggestions of a product, saved separately from the product form
+export function ProductRelationsEditor({ productId }: { productId: number }) {
+  const [relations, setRelations] = useState<Relation[]>([])
+  const [products, setProducts] = useState<{ id: number; name: string }[]>([])
+  const [saving, setSaving] = useState(false)
+
+  useEffect(() => {
+    Promise.all([
+      adminFetch(`${API_URL}/admin/products/${productId}/relations`).then(res => res.json()),
+      adminFetch(`${API_URL}/admin/products`).then(res => res.json()),
+    ])
+      .then(([relationsData, productsData]) => {
+        setRelations(relationsData)
+        setProducts(productsData)
+      })
+      .catch(error => console.error('Error fetching relations:', error))
+  }, [productId])
+
+  const productName = (id: number) => products.find(p => p.id === id)?.name || `Товар #${id}`
+
+  const move = (idx: number) => {
+    const next = [...relations]
+    ;[next[idx - 1], next[idx]] = [next[idx], next[idx - 1]]
+    setRelations(next)
+  }
+
+  const handleSave = async () => {
+    setSaving(true)
+    try {
+      const res = await adminFetch(`${API_URL}/admin/products/${productId}/relations`, {
+        method: 'PUT',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(relations),
+      })
+      if (!res.ok) {
+        alert(await res.text())
+      }
+    } finally {
+      setSaving(false)
+    }
+  }
+
+  const available = products.filter(p => p.id !== productId && !relations.some(r => r.product_id === p.id))
+
+  return (
+    <div className="bg-card border border-border rounded-lg p-8 mt-8 space-y-4">
+      <h2 className="text-xl font-semibold text-foreground">Сопутствующие товары</h2>
+      <p className="text-sm text-muted-foreground">Показываются первыми в блоке «Вам также может понравиться», остальное подбирается автоматически</p>
+
+      {relations.map((relation, idx) => (
+        <div key={relation.product_id} className="flex items-center gap-4">
+          <span className="flex-1 text-foreground">{productName(relation.product_id)}</span>
+          <select
+            value={relation.kind}
+            onChange={(e) => setRelations(relations.map(r => (r.product_id === relation.product_id ? { ...r, kind: e.target.value as Relation['kind'] } : r)))}
+            className="px-3 py-2 border border-border rounded-lg bg-background text-foreground"
+          >
+            {Object.entries(kindLabels).map(([kind, label]) => (
+              <option key={kind} value={kind}>{label}</option>
+            ))}
+          </select>
+          <button type="button" disabled={idx === 0} onClick={() => move(idx)} className="text-muted-foreground hover:text-foreground disabled:opacity-30">
+            <ArrowUp className="w-4 h-4" />
+          </button>
+          <button type="button" onClick={() => setRelations(relations.filter(r => r.product_id !== relation.product_id))} className="text-red-600 hover:opacity-80">
+            <Trash2 className="w-4 h-4" />
+          </button>
+        </div>
+      ))}
+
+      <div className="flex gap-4">
+        <select
+          value=""
+          onChange={(e) => setRelations([...relations, { product_id: parseInt(e.target.value), kind: 'matching' }])}
+          className="flex-1 px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+        >
+          <option value="">Добавить товар...</option>
+          {available.map((p) => (
+            <option key={p.id} value={p.id}>{p.name}</option>
+          ))}
+        </select>
+        <button
+          type="button"
+          onClick={handleSave}
+          disabled={saving}
+          className="flex items-center gap-2 px-6 py-2 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition disabled:opacity-50"
+        >
+          <Save className="w-4 h-4" />
+          {saving ? 'Сохранение...' : 'Сохранить'}
+        </button>
+      </div>
+    </div>
+  )
+}
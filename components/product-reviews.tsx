@@ -0,0 +1,164 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import { Star } from 'lucide-react'
+import { customerAuthHeaders } from '@/lib/customer-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+const PAGE_SIZE = 5
+
+interface Review {
+  id: number
+  author: string
+  hotel_name: string
+  rating: number
+  text: string
+  photos: string[]
+  created_at: string
+}
+
+const inputClass = 'w-full px-4 py-3 border border-border rounded-lg bg-background text-foreground placeholder-muted-foreground focus:outline-none focus:ring-2 focus:ring-primary'
+
+function photoUrl(path: string) {
+  return `${API_URL.replace('/api', '')}${path}`
+}
+
+function Stars({ value }: { value: number }) {
+  return (
+    <div className="flex gap-0.5">
+      {[1, 2, 3, 4, 5].map((n) => (
+        <Star key={n} className={`w-4 h-4 ${n <= value ? 'fill-accent text-accent' : 'text-muted-foreground'}`} />
+      ))}
+    </div>
+  )
+}
+
+export default function ProductReviews({ productId }: { productId: number }) {
+  const [reviews, setReviews] = useState<Review[]>([])
+  const [total, setTotal] = useState(0)
+  const [offset, setOffset] = useState(0)
+  const [form, setForm] = useState({ author: '', hotel_name: '', rating: 5, text: '' })
+  const [photos, setPhotos] = useState<string[]>([])
+  const [uploading, setUploading] = useState(false)
+  const [submitted, setSubmitted] = useState(false)
+
+  useEffect(() => {
+    fetch(`${API_URL}/products/${productId}/reviews?limit=${PAGE_SIZE}&offset=${offset}`)
+      .then(res => res.json())
+      .then(data => {
+        setReviews(data.reviews)
+        setTotal(data.total)
+      })
+      .catch(error => console.error('Error fetching reviews:', error))
+  }, [productId, offset])
+
+  const handlePhoto = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0]
+    if (!file) return
+    setUploading(true)
+    try {
+      const body = new FormData()
+      body.append('image', file)
+      const res = await fetch(`${API_URL}/reviews/photos`, { method: 'POST', body })
+      if (res.ok) {
+        const data = await res.json()
+        setPhotos([...photos, data.url])
+      } else {
+        alert(await res.text())
+      }
+    } finally {
+      setUploading(false)
+      e.target.value = ''
+    }
+  }
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    const res = await fetch(`${API_URL}/products/${productId}/reviews`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...customerAuthHeaders(),
+      },
+      body: JSON.stringify({ ...form, photos }),
+    })
+    if (res.ok) {
+      setSubmitted(true)
+    } else {
+      alert(await res.text())
+    }
+  }
+
+  return (
+    <div className="mb-12 sm:mb-16">
+      <h2 className="text-xl sm:text-2xl font-serif font-bold text-foreground mb-4 sm:mb-6">Отзывы ({total})</h2>
+
+      {reviews.length === 0 ? (
+        <p className="text-muted-foreground mb-8">Отзывов пока нет. Будьте первым!</p>
+      ) : (
+        <div className="space-y-4 mb-6">
+          {reviews.map((review) => (
+            <div key={review.id} className="bg-card border border-border rounded-lg p-6">
+              <div className="flex justify-between items-start mb-2">
+                <div>
+                  <p className="font-semibold text-foreground">{review.author}</p>
+                  {review.hotel_name && <p className="text-sm text-muted-foreground">{review.hotel_name}</p>}
+                </div>
+                <div className="text-right">
+                  <Stars value={review.rating} />
+                  <p className="text-xs text-muted-foreground mt-1">{new Date(review.created_at).toLocaleDateString('ru-RU')}</p>
+                </div>
+              </div>
+              <p className="text-foreground whitespace-pre-line">{review.text}</p>
+              {review.photos.length > 0 && (
+                <div className="flex gap-2 mt-4 flex-wrap">
+                  {review.photos.map((photo) => (
+                    <img key={photo} src={photoUrl(photo)} alt="" className="w-24 h-24 object-cover rounded" />
+                  ))}
+                </div>
+              )}
+            </div>
+          ))}
+        </div>
+      )}
+
+      {total > PAGE_SIZE && (
+        <div className="flex gap-4 mb-8">
+          <button disabled={offset === 0} onClick={() => setOffset(offset - PAGE_SIZE)} className="text-primary disabled:text-muted-foreground">
+            ← Новее
+          </button>
+          <button disabled={offset + PAGE_SIZE >= total} onClick={() => setOffset(offset + PAGE_SIZE)} className="text-primary disabled:text-muted-foreground">
+            Старее →
+          </button>
+        </div>
+      )}
+
+      {submitted ? (
+        <p className="text-green-700">Спасибо! Отзыв появится после проверки модератором.</p>
+      ) : (
+        <form onSubmit={handleSubmit} className="bg-card border border-border rounded-lg p-6 grid md:grid-cols-2 gap-4">
+          <h3 className="md:col-span-2 font-semibold text-foreground">Оставить отзыв</h3>
+          <input required placeholder="Ваше имя" value={form.author} onChange={(e) => setForm({ ...form, author: e.target.value })} className={inputClass} />
+          <input placeholder="Отель" value={form.hotel_name} onChange={(e) => setForm({ ...form, hotel_name: e.target.value })} className={inputClass} />
+          <select value={form.rating} onChange={(e) => setForm({ ...form, rating: parseInt(e.target.value) })} className={inputClass}>
+            {[5, 4, 3, 2, 1].map((n) => (
+              <option key={n} value={n}>{'★'.repeat(n)}</option>
+            ))}
+          </select>
+          <input type="file" accept="image/*" disabled={uploading || photos.length >= 6} onChange={handlePhoto} className="text-sm text-muted-foreground" />
+          {photos.length > 0 && (
+            <div className="md:col-span-2 flex gap-2 flex-wrap">
+              {photos.map((photo) => (
+                <img key={photo} src={photoUrl(photo)} alt="" className="w-16 h-16 object-cover rounded" />
+              ))}
+            </div>
+          )}
+          <textarea required rows={4} placeholder="Ваш отзыв" value={form.text} onChange={(e) => setForm({ ...form, text: e.target.value })} className={`${inputClass} md:col-span-2`} />
+          <button type="submit" className="md:col-span-2 px-6 py-3 bg-primary text-primary-foreground font-semibold rounded-lg hover:opacity-90 transition">
+            Отправить отзыв
+          </button>
+        </form>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,13 @@
+'use client'
+
+import { useEffect } from 'react'
+import { track } from '@/lib/analytics'
+
+// Reports a product_view from the server-rendered product page
+export default function ProductViewTracker({ productId }: { productId: number }) {
+  useEffect(() => {
+    track('product_view', { product_id: productId })
+  }, [productId])
+
+  return null
+}
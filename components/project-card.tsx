@@ -0,0 +1,43 @@
+import Link from 'next/link'
+import { MapPin, Calendar } from 'lucide-react'
+import { Project, projectImageUrl } from '@/lib/projects'
+
+export default function ProjectCard({ project }: { project: Project }) {
+  return (
+    <Link
+      href={`/portfolio/${project.slug || project.id}`}
+      className="group bg-card border border-border rounded-lg overflow-hidden hover:shadow-lg transition flex flex-col"
+    >
+      <div className="relative h-64 bg-muted overflow-hidden">
+        <img
+          src={projectImageUrl(project.gallery[0])}
+          alt={project.hotel_name}
+          className="w-full h-full object-cover group-hover:scale-105 transition duration-300"
+        />
+      </div>
+      <div className="p-6 space-y-4 flex-1 flex flex-col">
+        <div>
+          <h3 className="text-xl font-semibold text-foreground group-hover:text-primary transition mb-2">
+            {project.hotel_name}
+          </h3>
+          <div className="flex items-center gap-4 text-sm text-muted-foreground">
+            <span className="flex items-center gap-2">
+              <MapPin className="w-4 h-4" />
+              {project.city}
+            </span>
+            <span className="flex items-center gap-2">
+              <Calendar className="w-4 h-4" />
+              {project.year}
+            </span>
+          </div>
+        </div>
+        {project.description && (
+          <p className="text-muted-foreground line-clamp-3">{project.description}</p>
+        )}
+        <div className="mt-auto pt-4 border-t border-border">
+          <span className="text-sm font-medium text-primary">Подробнее</span>
+        </div>
+      </div>
+    </Link>
+  )
+}
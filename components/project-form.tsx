@@ -0,0 +1,281 @@
+'use client'
+
+import { useState, useEffect } from 'react'
+import Link from 'next/link'
+import { Save } from 'lucide-react'
+import { statusLabels, PublicationStatus } from '@/components/publication-fields'
+import { adminFetch } from '@/lib/admin-auth'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export interface ProjectFormValue {
+  hotel_name: string
+  city: string
+  year: string
+  slug: string
+  description: string
+  gallery: string[]
+  client_quote: string
+  client_name: string
+  product_ids: number[]
+  collection_ids: number[]
+  status: PublicationStatus
+}
+
+export const defaultProjectForm: ProjectFormValue = {
+  hotel_name: '',
+  city: '',
+  year: String(new Date().getFullYear()),
+  slug: '',
+  description: '',
+  gallery: [],
+  client_quote: '',
+  client_name: '',
+  product_ids: [],
+  collection_ids: [],
+  status: 'draft',
+}
+
+export function projectFormFromEntity(project: Omit<ProjectFormValue, 'year'> & { year: number }): ProjectFormValue {
+  return {
+    hotel_name: project.hotel_name,
+    city: project.city,
+    year: String(project.year),
+    slug: project.slug || '',
+    description: project.description || '',
+    gallery: project.gallery || [],
+    client_quote: project.client_quote || '',
+    client_name: project.client_name || '',
+    product_ids: project.product_ids || [],
+    collection_ids: project.collection_ids || [],
+    status: project.status,
+  }
+}
+
+export function projectPayload(value: ProjectFormValue) {
+  return { ...value, year: parseInt(value.year, 10) }
+}
+
+interface Option {
+  id: number
+  name: string
+  image: string
+}
+
+interface ProjectFormProps {
+  value: ProjectFormValue
+  onChange: (value: ProjectFormValue) => void
+  onSubmit: () => void
+  saving: boolean
+}
+
+// Fields of a portfolio project, shared by the create and edit pages
+export function ProjectForm({ value, onChange, onSubmit, saving }: ProjectFormProps) {
+  const [products, setProducts] = useState<Option[]>([])
+  const [collections, setCollections] = useState<Option[]>([])
+  const [uploading, setUploading] = useState(false)
+
+  useEffect(() => {
+    adminFetch(`${API_URL}/admin/products`).then(res => res.json()).then(data => setProducts(data || [])).catch(console.error)
+    adminFetch(`${API_URL}/admin/collections`).then(res => res.json()).then(data => setCollections(data || [])).catch(console.error)
+  }, [])
+
+  const set = (patch: Partial<ProjectFormValue>) => onChange({ ...value, ...patch })
+
+  const toggle = (field: 'product_ids' | 'collection_ids', id: number) => {
+    const ids = value[field]
+    set({ [field]: ids.includes(id) ? ids.filter(i => i !== id) : [...ids, id] })
+  }
+
+  const handleImageUpload = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const files = e.target.files
+    if (!files || files.length === 0) return
+
+    setUploading(true)
+    const uploaded: string[] = []
+    try {
+      for (const file of Array.from(files)) {
+        if (!file.type.startsWith('image/')) {
+          alert(`Файл ${file.name} не является изображением`)
+          continue
+        }
+        if (file.size > 5 * 1024 * 1024) {
+          alert(`Размер файла ${file.name} превышает 5MB`)
+          continue
+        }
+        const formDataUpload = new FormData()
+        formDataUpload.append('image', file)
+        const res = await adminFetch(`${API_URL}/admin/upload`, { method: 'POST', body: formDataUpload })
+        if (res.ok) {
+          const data = await res.json()
+          uploaded.push(data.url)
+        } else {
+          alert(`Ошибка при загрузке ${file.name}`)
+        }
+      }
+      if (uploaded.length > 0) {
+        set({ gallery: [...value.gallery, ...uploaded] })
+      }
+    } catch (error) {
+      console.error('Error uploading images:', error)
+      alert('Ошибка при загрузке изображений')
+    } finally {
+      setUploading(false)
+      e.target.value = ''
+    }
+  }
+
+  const isDocker = API_URL.includes('backend:')
+  const backendUrl = isDocker ? 'http://localhost:8080' : API_URL.replace('/api', '')
+  const preview = (image: string) => (image.startsWith('/uploads/') ? `${backendUrl}${image}` : image)
+
+  const inputClass = 'w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground'
+
+  const picker = (field: 'product_ids' | 'collection_ids', options: Option[], title: string) => (
+    <div>
+      <label className="block text-sm font-medium text-foreground mb-2">
+        {title} ({value[field].length})
+      </label>
+      <div className="max-h-64 overflow-y-auto border border-border rounded-lg p-4 space-y-2">
+        {options.map((option) => (
+          <label key={option.id} className="flex items-center gap-3 p-2 hover:bg-muted rounded cursor-pointer">
+            <input
+              type="checkbox"
+              checked={value[field].includes(option.id)}
+              onChange={() => toggle(field, option.id)}
+              className="w-4 h-4 accent-primary"
+            />
+            <img src={option.image || '/placeholder.svg'} alt={option.name} className="w-12 h-12 object-cover rounded" />
+            <span className="text-sm font-medium text-foreground">{option.name}</span>
+          </label>
+        ))}
+      </div>
+    </div>
+  )
+
+  return (
+    <form
+      onSubmit={(e) => {
+        e.preventDefault()
+        onSubmit()
+      }}
+      className="bg-card border border-border rounded-lg p-6 md:p-8 space-y-6"
+    >
+      <div className="grid md:grid-cols-2 gap-6">
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Отель *</label>
+          <input type="text" required value={value.hotel_name} onChange={(e) => set({ hotel_name: e.target.value })} className={inputClass} />
+        </div>
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Город *</label>
+          <input type="text" required value={value.city} onChange={(e) => set({ city: e.target.value })} className={inputClass} />
+        </div>
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Год *</label>
+          <input
+            type="number"
+            required
+            min={1990}
+            max={new Date().getFullYear()}
+            value={value.year}
+            onChange={(e) => set({ year: e.target.value })}
+            className={inputClass}
+          />
+        </div>
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Статус</label>
+          <select value={value.status} onChange={(e) => set({ status: e.target.value as PublicationStatus })} className={inputClass}>
+            {(Object.keys(statusLabels) as PublicationStatus[]).map((status) => (
+              <option key={status} value={status}>{statusLabels[status]}</option>
+            ))}
+          </select>
+        </div>
+        <div className="md:col-span-2">
+          <label className="block text-sm font-medium text-foreground mb-2">Адрес страницы</label>
+          <input
+            type="text"
+            value={value.slug}
+            onChange={(e) => set({ slug: e.target.value })}
+            placeholder="Заполнится из названия отеля"
+            className={inputClass}
+          />
+          <p className="mt-1 text-xs text-muted-foreground">/portfolio/{value.slug || '…'}</p>
+        </div>
+      </div>
+
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">Описание</label>
+        <textarea rows={5} value={value.description} onChange={(e) => set({ description: e.target.value })} className={inputClass} />
+        <p className="mt-1 text-xs text-muted-foreground">Абзацы разделяйте пустой строкой</p>
+      </div>
+
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">Галерея (первое фото — обложка)</label>
+        <input
+          type="file"
+          accept="image/*"
+          multiple
+          onChange={handleImageUpload}
+          disabled={uploading}
+          className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground file:mr-4 file:py-2 file:px-4 file:rounded-lg file:border-0 file:text-sm file:font-semibold file:bg-primary file:text-primary-foreground hover:file:opacity-90 disabled:opacity-50"
+        />
+        {uploading && <p className="text-sm text-muted-foreground mt-2">Загрузка...</p>}
+        {value.gallery.length > 0 && (
+          <div className="mt-4 grid grid-cols-2 md:grid-cols-4 gap-4">
+            {value.gallery.map((image, index) => (
+              <div key={index} className="relative group">
+                <img src={preview(image)} alt={`Фото ${index + 1}`} className="w-full h-32 object-cover rounded-lg border border-border" />
+                <button
+                  type="button"
+                  onClick={() => set({ gallery: value.gallery.filter((_, i) => i !== index) })}
+                  className="absolute top-2 right-2 bg-red-500 text-white rounded-full w-6 h-6 flex items-center justify-center opacity-0 group-hover:opacity-100 transition"
+                >
+                  ×
+                </button>
+                {index === 0 && (
+                  <div className="absolute bottom-2 left-2 bg-primary text-primary-foreground text-xs px-2 py-1 rounded">Обложка</div>
+                )}
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+
+      <div className="grid md:grid-cols-2 gap-6">
+        <div className="md:col-span-2">
+          <label className="block text-sm font-medium text-foreground mb-2">Отзыв клиента</label>
+          <textarea rows={3} value={value.client_quote} onChange={(e) => set({ client_quote: e.target.value })} className={inputClass} />
+        </div>
+        <div className="md:col-span-2">
+          <label className="block text-sm font-medium text-foreground mb-2">Автор отзыва</label>
+          <input
+            type="text"
+            value={value.client_name}
+            onChange={(e) => set({ client_name: e.target.value })}
+            placeholder="Анна Петрова, управляющая отелем"
+            className={inputClass}
+          />
+        </div>
+      </div>
+
+      <div className="grid md:grid-cols-2 gap-6">
+        {picker('collection_ids', collections, 'Коллекции в проекте')}
+        {picker('product_ids', products, 'Товары в проекте')}
+      </div>
+
+      <div className="flex gap-4 pt-6">
+        <button
+          type="submit"
+          disabled={saving || uploading}
+          className="flex items-center gap-2 px-6 py-3 bg-primary text-primary-foreground rounded-lg hover:opacity-90 transition disabled:opacity-50"
+        >
+          <Save className="w-5 h-5" />
+          {saving ? 'Сохранение...' : 'Сохранить'}
+        </button>
+        <Link href="/admin/projects" className="px-6 py-3 border border-border rounded-lg hover:bg-muted transition">
+          Отмена
+        </Link>
+      </div>
+    </form>
+  )
+}
@@ -0,0 +1,87 @@
+'use client'
+
+export type PublicationStatus = 'draft' | 'published' | 'archived'
+
+export interface Publication {
+  status: PublicationStatus
+  publish_at: string // datetime-local value, '' when not scheduled
+  unpublish_at: string
+}
+
+export const defaultPublication: Publication = {
+  status: 'draft',
+  publish_at: '',
+  unpublish_at: '',
+}
+
+export const statusLabels: Record<PublicationStatus, string> = {
+  draft: 'Черновик',
+  published: 'Опубликовано',
+  archived: 'В архиве',
+}
+
+// Converts an ISO timestamp from the API into a datetime-local input value
+export function toLocalInput(value?: string | null) {
+  if (!value) return ''
+  const date = new Date(value)
+  const offset = date.getTimezoneOffset() * 60000
+  return new Date(date.getTime() - offset).toISOString().slice(0, 16)
+}
+
+export function publicationFromEntity(entity: { status?: string; publish_at?: string | null; unpublish_at?: string | null }): Publication {
+  return {
+    status: (entity.status as PublicationStatus) || 'published',
+    publish_at: toLocalInput(entity.publish_at),
+    unpublish_at: toLocalInput(entity.unpublish_at),
+  }
+}
+
+export function publicationPayload(publication: Publication) {
+  return {
+    status: publication.status,
+    publish_at: publication.publish_at ? new Date(publication.publish_at).toISOString() : null,
+    unpublish_at: publication.unpublish_at ? new Date(publication.unpublish_at).toISOString() : null,
+  }
+}
+
+interface PublicationFieldsProps {
+  value: Publication
+  onChange: (value: Publication) => void
+}
+
+export function PublicationFields({ value, onChange }: PublicationFieldsProps) {
+  return (
+    <div className="grid md:grid-cols-3 gap-6">
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">Статус</label>
+        <select
+          value={value.status}
+          onChange={(e) => onChange({ ...value, status: e.target.value as PublicationStatus })}
+          className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+        >
+          {(Object.keys(statusLabels) as PublicationStatus[]).map((status) => (
+            <option key={status} value={status}>{statusLabels[status]}</option>
+          ))}
+        </select>
+      </div>
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">Опубликовать в</label>
+        <input
+          type="datetime-local"
+          value={value.publish_at}
+          onChange={(e) => onChange({ ...value, publish_at: e.target.value })}
+          className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+        />
+      </div>
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">Снять с публикации в</label>
+        <input
+          type="datetime-local"
+          value={value.unpublish_at}
+          onChange={(e) => onChange({ ...value, unpublish_at: e.target.value })}
+          className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+        />
+      </div>
+    </div>
+  )
+}
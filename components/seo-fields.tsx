@@ -0,0 +1,97 @@
+'use client'
+
+export interface SEO {
+  slug: string
+  meta_title: string
+  meta_description: string
+  canonical_url: string
+  og_image: string
+}
+
+export const defaultSEO: SEO = {
+  slug: '',
+  meta_title: '',
+  meta_description: '',
+  canonical_url: '',
+  og_image: '',
+}
+
+export function seoFromEntity(entity: Partial<SEO>): SEO {
+  return {
+    slug: entity.slug || '',
+    meta_title: entity.meta_title || '',
+    meta_description: entity.meta_description || '',
+    canonical_url: entity.canonical_url || '',
+    og_image: entity.og_image || '',
+  }
+}
+
+interface SEOFieldsProps {
+  value: SEO
+  onChange: (value: SEO) => void
+}
+
+export function SEOFields({ value, onChange }: SEOFieldsProps) {
+  return (
+    <div className="border-t border-border pt-6 space-y-6">
+      <div>
+        <h2 className="text-lg font-semibold text-foreground">SEO</h2>
+        <p className="text-sm text-muted-foreground">Пустые поля заполняются из названия, описания и изображения</p>
+      </div>
+      <div className="grid md:grid-cols-2 gap-6">
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Адрес (slug)</label>
+          <input
+            type="text"
+            value={value.slug}
+            onChange={(e) => onChange({ ...value, slug: e.target.value })}
+            placeholder="premium-bed-queen-size"
+            className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+        </div>
+        <div>
+          <label className="block text-sm font-medium text-foreground mb-2">Канонический URL</label>
+          <input
+            type="text"
+            value={value.canonical_url}
+            onChange={(e) => onChange({ ...value, canonical_url: e.target.value })}
+            placeholder="https://..."
+            className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+          />
+        </div>
+      </div>
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">
+          Meta title <span className="text-muted-foreground">({value.meta_title.length}/60)</span>
+        </label>
+        <input
+          type="text"
+          value={value.meta_title}
+          onChange={(e) => onChange({ ...value, meta_title: e.target.value })}
+          className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+        />
+      </div>
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">
+          Meta description <span className="text-muted-foreground">({value.meta_description.length}/160)</span>
+        </label>
+        <textarea
+          rows={3}
+          value={value.meta_description}
+          onChange={(e) => onChange({ ...value, meta_description: e.target.value })}
+          className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+        />
+      </div>
+      <div>
+        <label className="block text-sm font-medium text-foreground mb-2">Изображение для соцсетей (Open Graph)</label>
+        <input
+          type="text"
+          value={value.og_image}
+          onChange={(e) => onChange({ ...value, og_image: e.target.value })}
+          placeholder="/uploads/..."
+          className="w-full px-4 py-2 border border-border rounded-lg bg-background text-foreground"
+        />
+      </div>
+    </div>
+  )
+}
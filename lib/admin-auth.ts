@@ -0,0 +1,49 @@
+import { ADMIN_SESSION_COOKIE } from '@/lib/preview'
+
+// Session of the logged-in admin user, kept in localStorage
+const TOKEN_KEY = 'adminToken'
+const USERNAME_KEY = 'adminUsername'
+
+export function getAdminToken(): string | null {
+  if (typeof window === 'undefined') return null
+  return localStorage.getItem(TOKEN_KEY)
+}
+
+export function getAdminUsername(): string | null {
+  if (typeof window === 'undefined') return null
+  return localStorage.getItem(USERNAME_KEY)
+}
+
+// Matches adminSessionTTL of the backend
+const SESSION_MAX_AGE = 12 * 60 * 60
+
+// setAdminSession also mirrors the token in a cookie, which lets the admin
+// through the maintenance page of the public site
+export function setAdminSession(token: string | null, username?: string) {
+  if (token) {
+    localStorage.setItem(TOKEN_KEY, token)
+    localStorage.setItem(USERNAME_KEY, username || '')
+    document.cookie = `${ADMIN_SESSION_COOKIE}=${encodeURIComponent(token)}; path=/; max-age=${SESSION_MAX_AGE}; SameSite=Lax`
+  } else {
+    localStorage.removeItem(TOKEN_KEY)
+    localStorage.removeItem(USERNAME_KEY)
+    document.cookie = `${ADMIN_SESSION_COOKIE}=; path=/; max-age=0; SameSite=Lax`
+  }
+}
+
+// fetch for the admin API: authenticates the request as the logged-in admin
+// and returns to the login page once the session has expired
+export async function adminFetch(input: string, init: RequestInit = {}): Promise<Response> {
+  const headers = new Headers(init.headers)
+  const token = getAdminToken()
+  if (token) {
+    headers.set('Authorization', `Bearer ${token}`)
+  }
+
+  const res = await fetch(input, { ...init, headers })
+  if (res.status === 401 && typeof window !== 'undefined') {
+    setAdminSession(null)
+    window.location.href = `/admin/login?next=${encodeURIComponent(window.location.pathname)}`
+  }
+  return res
+}
@@ -0,0 +1,32 @@
+// First-party analytics beacon. The session ID lives in sessionStorage for the
+// current visit only; nothing is sent to third parties.
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+const SESSION_KEY = 'analyticsSession'
+
+type EventType = 'product_view' | 'gallery_interaction' | 'contact_open' | 'contact_submit' | 'search'
+
+interface EventPayload {
+  product_id?: number
+  query?: string
+  results?: number
+  data?: Record<string, unknown>
+}
+
+function sessionId(): string {
+  let id = sessionStorage.getItem(SESSION_KEY)
+  if (!id) {
+    id = Array.from(crypto.getRandomValues(new Uint8Array(16)), b => b.toString(16).padStart(2, '0')).join('')
+    sessionStorage.setItem(SESSION_KEY, id)
+  }
+  return id
+}
+
+export function track(type: EventType, payload: EventPayload = {}) {
+  if (typeof window === 'undefined') return
+  const body = JSON.stringify({ type, session_id: sessionId(), path: window.location.pathname, ...payload })
+  if (navigator.sendBeacon) {
+    navigator.sendBeacon(`${API_URL}/events`, body)
+  } else {
+    fetch(`${API_URL}/events`, { method: 'POST', body, keepalive: true }).catch(() => {})
+  }
+}
@@ -0,0 +1,57 @@
+import { requestPreviewHeaders } from '@/lib/preview-server'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export interface ContentImage {
+  url: string
+  alt?: string
+  caption?: string
+  link?: string
+}
+
+export type BlockData =
+  | { type: 'rich_text'; data: { title?: string; text: string } }
+  | { type: 'image'; data: ContentImage }
+  | { type: 'carousel'; data: { title?: string; images: ContentImage[] } }
+  | { type: 'key_facts'; data: { title?: string; facts: { value?: string; label: string }[] } }
+  | { type: 'cta'; data: { title: string; text?: string; image?: string; button_label: string; button_url: string } }
+
+export type BlockType = BlockData['type']
+
+export type ContentBlock = BlockData & {
+  id: number
+  section_id: number
+  locale: string
+  order: number
+  status: string
+  version: number
+}
+
+export interface PageSection {
+  name: string
+  title: string
+  locale: string
+  blocks: ContentBlock[]
+}
+
+export interface PageLayout {
+  page: string
+  sections: PageSection[]
+}
+
+// Fetches the published content of a page (GET /api/pages/{page}). Returns
+// null when the backend is unreachable so pages can fall back to their
+// built-in content.
+export async function getPageLayout(page: string): Promise<PageLayout | null> {
+  try {
+    const res = await fetch(`${API_URL}/pages/${page}`, {
+      cache: 'no-store',
+      headers: await requestPreviewHeaders(),
+    })
+    if (!res.ok) return null
+    return await res.json()
+  } catch (error) {
+    console.error('Error fetching page layout:', error)
+    return null
+  }
+}
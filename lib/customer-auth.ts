@@ -0,0 +1,39 @@
+// Session token of the logged-in customer, kept in localStorage
+const TOKEN_KEY = 'customerToken'
+
+export function getCustomerToken(): string | null {
+  if (typeof window === 'undefined') return null
+  return localStorage.getItem(TOKEN_KEY)
+}
+
+export function setCustomerToken(token: string | null) {
+  if (token) {
+    localStorage.setItem(TOKEN_KEY, token)
+  } else {
+    localStorage.removeItem(TOKEN_KEY)
+  }
+}
+
+// Headers that authenticate the request as the logged-in customer, if any
+export function customerAuthHeaders(): Record<string, string> {
+  const token = getCustomerToken()
+  return token ? { Authorization: `Bearer ${token}` } : {}
+}
+
+// Owner token of anonymous boards, issued by the first POST /boards
+const BOARD_TOKEN_KEY = 'boardToken'
+
+export function setBoardToken(token: string | null) {
+  if (token) {
+    localStorage.setItem(BOARD_TOKEN_KEY, token)
+  } else {
+    localStorage.removeItem(BOARD_TOKEN_KEY)
+  }
+}
+
+// Headers that identify the owner of boards: the customer, or the anonymous owner token
+export function boardHeaders(): Record<string, string> {
+  const headers = customerAuthHeaders()
+  const token = typeof window === 'undefined' ? null : localStorage.getItem(BOARD_TOKEN_KEY)
+  return token ? { ...headers, 'X-Board-Token': token } : headers
+}
@@ -0,0 +1,8 @@
+import { cookies } from 'next/headers'
+import { ADMIN_SESSION_COOKIE, PREVIEW_COOKIE, previewHeaders } from '@/lib/preview'
+
+// Preview token and admin session of the incoming request, for server components
+export async function requestPreviewHeaders(): Promise<Record<string, string>> {
+  const cookieStore = await cookies()
+  return previewHeaders(cookieStore.get(PREVIEW_COOKIE)?.value, cookieStore.get(ADMIN_SESSION_COOKIE)?.value)
+}
@@ -0,0 +1,27 @@
+// Preview of unpublished content. An admin opens a page with ?preview=<token>,
+// the proxy keeps the token in this cookie and the pages forward it to the API
+// in the X-Preview-Token header, which lets drafts through.
+export const PREVIEW_COOKIE = 'preview_token'
+
+// Session of the signed-in admin, mirrored from localStorage by
+// setAdminSession. The pages forward it in the X-Admin-Session header so the
+// admin can look around the site while it is closed for maintenance.
+export const ADMIN_SESSION_COOKIE = 'admin_session'
+
+export function previewHeaders(token?: string | null, adminSession?: string | null): Record<string, string> {
+  const headers: Record<string, string> = {}
+  if (token) headers['X-Preview-Token'] = token
+  if (adminSession) headers['X-Admin-Session'] = adminSession
+  return headers
+}
+
+function browserCookie(name: string): string | null {
+  const cookie = document.cookie.split('; ').find(c => c.startsWith(`${name}=`))
+  return cookie ? decodeURIComponent(cookie.slice(name.length + 1)) : null
+}
+
+// Preview token and admin session of the current browser, for client components
+export function browserPreviewHeaders(): Record<string, string> {
+  if (typeof document === 'undefined') return {}
+  return previewHeaders(browserCookie(PREVIEW_COOKIE), browserCookie(ADMIN_SESSION_COOKIE))
+}
@@ -0,0 +1,89 @@
+import { requestPreviewHeaders } from '@/lib/preview-server'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+export interface ProjectProduct {
+  id: number
+  slug?: string
+  name: string
+  price: number
+  image: string
+  images?: string[]
+}
+
+export interface ProjectCollection {
+  id: number
+  slug?: string
+  name: string
+  image: string
+}
+
+export interface Project {
+  id: number
+  slug: string
+  hotel_name: string
+  city: string
+  year: number
+  description: string
+  gallery: string[]
+  client_quote: string
+  client_name: string
+  product_ids: number[]
+  collection_ids: number[]
+  products?: ProjectProduct[]
+  collections?: ProjectCollection[]
+  status: string
+  version: number
+}
+
+export interface ProjectFilters {
+  city?: string
+  year?: string
+  product_id?: string
+  collection_id?: string
+}
+
+// Uploaded images are served by the backend
+export function projectImageUrl(image: string | undefined): string {
+  if (!image) return '/placeholder.svg'
+  if (image.startsWith('/uploads/')) {
+    const isDocker = API_URL.includes('backend:')
+    const backendUrl = isDocker ? 'http://localhost:8080' : API_URL.replace('/api', '')
+    return `${backendUrl}${image}`
+  }
+  return image
+}
+
+async function fetchJSON<T>(path: string, fallback: T): Promise<T> {
+  try {
+    const res = await fetch(`${API_URL}${path}`, {
+      cache: 'no-store',
+      headers: await requestPreviewHeaders(),
+    })
+    if (!res.ok) return fallback
+    return await res.json()
+  } catch (error) {
+    console.error(`Error fetching ${path}:`, error)
+    return fallback
+  }
+}
+
+// Published projects, newest first (GET /api/projects)
+export function getProjects(filters: ProjectFilters = {}): Promise<Project[]> {
+  const params = new URLSearchParams()
+  for (const [key, value] of Object.entries(filters)) {
+    if (value) params.set(key, value)
+  }
+  const query = params.toString()
+  return fetchJSON(`/projects${query ? `?${query}` : ''}`, [])
+}
+
+// A project by slug or id, with the products and collections used in it
+export function getProject(idOrSlug: string): Promise<Project | null> {
+  return fetchJSON(`/projects/${encodeURIComponent(idOrSlug)}`, null)
+}
+
+// Projects a product was used in, directly or as part of a collection
+export function getProductProjects(productId: number, limit = 3): Promise<Project[]> {
+  return fetchJSON(`/products/${productId}/projects?limit=${limit}`, [])
+}
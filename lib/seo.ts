@@ -0,0 +1,48 @@
+import type { Metadata } from 'next'
+import { requestPreviewHeaders } from '@/lib/preview-server'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+// Meta tags and schema.org JSON-LD prepared by the backend (GET .../{id}/seo)
+export interface SEOMeta {
+  title: string
+  description: string
+  canonical: string
+  og_image?: string
+  json_ld: object[]
+}
+
+export async function getSEO(path: string): Promise<SEOMeta | null> {
+  try {
+    const res = await fetch(`${API_URL}${path}/seo`, {
+      cache: 'no-store',
+      headers: await requestPreviewHeaders(),
+    })
+    if (!res.ok) return null
+    return await res.json()
+  } catch (error) {
+    console.error('Error fetching SEO metadata:', error)
+    return null
+  }
+}
+
+export function seoMetadata(meta: SEOMeta | null): Metadata {
+  if (!meta) return {}
+  return {
+    title: meta.title,
+    description: meta.description,
+    alternates: { canonical: meta.canonical },
+    openGraph: {
+      title: meta.title,
+      description: meta.description,
+      url: meta.canonical,
+      images: meta.og_image ? [meta.og_image] : undefined,
+    },
+  }
+}
+
+// Serialises JSON-LD for a <script type="application/ld+json"> tag; '<' is
+// escaped so text from the catalog cannot close the script element.
+export function jsonLd(data: object): string {
+  return JSON.stringify(data).replace(/</g, '\\u003c')
+}
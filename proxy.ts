@@ -0,0 +1,76 @@
+import { NextResponse, type NextRequest } from 'next/server'
+import { ADMIN_SESSION_COOKIE, PREVIEW_COOKIE, previewHeaders } from '@/lib/preview'
+
+const API_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080/api'
+
+interface RedirectMatch {
+  found: boolean
+  to?: string
+  status_code?: number
+}
+
+interface MaintenanceStatus {
+  enabled: boolean
+  retry_after?: number
+  bypass?: boolean
+}
+
+interface PlaceholderMatch {
+  exists: boolean
+}
+
+async function getJSON<T>(url: string, headers: Record<string, string> = {}): Promise<T | null> {
+  try {
+    const res = await fetch(url, { cache: 'no-store', headers })
+    if (!res.ok) return null
+    return await res.json()
+  } catch (error) {
+    console.error(`Error fetching ${url}:`, error)
+    return null
+  }
+}
+
+// Runs before every page: shows the maintenance page while the site is
+// closed, except to signed-in admins, applies the redirects managed in the
+// admin panel (including the ones recorded automatically when a slug changes)
+// and shows the placeholder of pages covered by an active placeholder rule.
+export async function proxy(request: NextRequest) {
+  const { pathname, search } = request.nextUrl
+
+  // A preview link: keep the token in a cookie for the following requests
+  // and drop it from the address so it is not shared by accident
+  const previewToken = request.nextUrl.searchParams.get('preview')
+  if (previewToken) {
+    const url = request.nextUrl.clone()
+    url.searchParams.delete('preview')
+    const response = NextResponse.redirect(url, 307)
+    response.cookies.set(PREVIEW_COOKIE, previewToken, { path: '/', sameSite: 'lax', maxAge: 24 * 60 * 60 })
+    return response
+  }
+
+  const [maintenance, redirect, placeholder] = await Promise.all([
+    getJSON<MaintenanceStatus>(`${API_URL}/maintenance`, previewHeaders(null, request.cookies.get(ADMIN_SESSION_COOKIE)?.value)),
+    getJSON<RedirectMatch>(`${API_URL}/redirects/resolve?path=${encodeURIComponent(pathname + search)}`),
+    getJSON<PlaceholderMatch>(`${API_URL}/placeholder/check?path=${encodeURIComponent(pathname)}`),
+  ])
+
+  if (maintenance?.enabled && !maintenance.bypass) {
+    return NextResponse.rewrite(new URL('/maintenance', request.url), {
+      status: 503,
+      headers: { 'Retry-After': String(maintenance.retry_after || 3600) },
+    })
+  }
+  if (redirect?.found && redirect.to) {
+    return NextResponse.redirect(new URL(redirect.to, request.url), redirect.status_code || 301)
+  }
+  if (placeholder?.exists) {
+    const url = new URL('/placeholder', request.url)
+    url.searchParams.set('path', pathname)
+    return NextResponse.rewrite(url)
+  }
+  return NextResponse.next()
+}
+
+export const config = {
+  matcher: ['/((?!api|_next|uploads|admin|maintenance|placeholder|favicon.ico|.*\\.[a-zA-Z0-9]+$).*)'],
+}